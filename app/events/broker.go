@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"uas/app/models"
+)
+
+// Broker menyebarkan event yang sudah tersimpan ke subscriber stream.
+type Broker interface {
+	Publish(ctx context.Context, ev *models.Event) error
+	// Subscribe mengembalikan channel event dan fungsi untuk berhenti
+	// berlangganan. Channel ditutup jika subscriber tertinggal atau broker
+	// kehilangan koneksi; klien diharapkan tersambung ulang dengan
+	// Last-Event-ID.
+	Subscribe() (<-chan models.Event, func())
+}
+
+// DefaultBuffer adalah jumlah event yang boleh tertahan per subscriber.
+const DefaultBuffer = 64
+
+// MemoryBroker menyebarkan event di dalam satu proses. Cukup untuk satu
+// replika; lihat PostgresBroker untuk beberapa replika.
+type MemoryBroker struct {
+	Buffer int
+
+	mu   sync.Mutex
+	subs map[chan models.Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{Buffer: DefaultBuffer, subs: map[chan models.Event]struct{}{}}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, ev *models.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- *ev:
+		default:
+			// subscriber lambat diputus agar tidak menahan yang lain; event
+			// yang terlewat diambil dari log saat tersambung ulang
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe() (<-chan models.Event, func()) {
+	ch := make(chan models.Event, b.Buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// CloseAll memutus seluruh subscriber.
+func (b *MemoryBroker) CloseAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
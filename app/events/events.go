@@ -0,0 +1,112 @@
+// Package events mencatat perubahan status prestasi dan notifikasi baru ke
+// event_log lalu menyebarkannya ke klien stream SSE. Log memungkinkan klien
+// yang terputus melanjutkan dari Last-Event-ID; broker dapat diganti dari
+// in-process ke LISTEN/NOTIFY PostgreSQL saat berjalan di beberapa replika.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+)
+
+// Jenis event.
+const (
+	TypeStatusChanged       = "achievement.status_changed"
+	TypeNotificationCreated = "notification.created"
+)
+
+// Sink menerima setiap event yang sudah tersimpan di log, mis. untuk
+// diteruskan ke webhook. Handle tidak boleh memblokir lama karena dipanggil
+// dari request yang memicu event.
+type Sink interface {
+	Handle(ctx context.Context, ev *models.Event)
+}
+
+// Publisher menyimpan event ke log lalu menyebarkannya. Publisher nil tidak
+// melakukan apa pun.
+type Publisher struct {
+	Repo   repository.EventRepository
+	Broker Broker
+	Sinks  []Sink
+}
+
+func NewPublisher(repo repository.EventRepository, broker Broker, sinks ...Sink) *Publisher {
+	return &Publisher{Repo: repo, Broker: broker, Sinks: sinks}
+}
+
+// Publish hanya mencatat kegagalan; event yang gagal disebarkan tetap dapat
+// diambil klien dari log saat tersambung ulang.
+func (p *Publisher) Publish(ctx context.Context, ev *models.Event) {
+	if p == nil {
+		return
+	}
+
+	if err := p.Repo.Append(ctx, ev); err != nil {
+		helper.Log.Warn().Err(err).Str("type", ev.Type).Msg("gagal menyimpan event")
+		return
+	}
+	if err := p.Broker.Publish(ctx, ev); err != nil {
+		helper.Log.Warn().Err(err).Int64("event_id", ev.ID).Msg("gagal menyebarkan event")
+	}
+	for _, sink := range p.Sinks {
+		sink.Handle(ctx, ev)
+	}
+}
+
+// StatusChanged dikirim ke mahasiswa pemilik, dosen wali advisorID dan admin.
+// Dosen wali mengetahui pengajuan baru dari event dengan status submitted.
+// Seperti policy, draft dan prestasi terhapus tidak dikirim ke dosen wali.
+func StatusChanged(ref *models.AchievementReference, from, advisorID string) *models.Event {
+	if ref.Status == utils.AchievementStatusDraft || ref.Status == utils.AchievementStatusDeleted {
+		advisorID = ""
+	}
+
+	return &models.Event{
+		Type:      TypeStatusChanged,
+		StudentID: ref.StudentID,
+		AdvisorID: advisorID,
+		Data: map[string]any{
+			"achievement_id": ref.MongoAchievementID,
+			"reference_id":   ref.ID,
+			"student_id":     ref.StudentID,
+			"from_status":    from,
+			"status":         ref.Status,
+		},
+	}
+}
+
+// NotificationCreated hanya dikirim ke penerima notifikasi.
+func NotificationCreated(n *models.Notification) *models.Event {
+	return &models.Event{
+		Type:   TypeNotificationCreated,
+		UserID: n.UserID,
+		Data: map[string]any{
+			"id":         n.ID,
+			"type":       n.Type,
+			"title":      n.Title,
+			"body":       n.Body,
+			"data":       n.Data,
+			"created_at": n.CreatedAt,
+		},
+	}
+}
+
+// BrokerFromEnv memilih broker berdasarkan EVENT_BROKER ("memory" atau
+// "postgres", default "memory").
+func BrokerFromEnv(ctx context.Context, dsn string, db *sql.DB, repo repository.EventRepository) (Broker, error) {
+	switch strings.ToLower(os.Getenv("EVENT_BROKER")) {
+	case "", "memory":
+		return NewMemoryBroker(), nil
+	case "postgres":
+		return NewPostgresBroker(ctx, dsn, db, repo)
+	}
+	return nil, fmt.Errorf("events: EVENT_BROKER %q tidak dikenal", os.Getenv("EVENT_BROKER"))
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/lib/pq"
+)
+
+// NotifyChannel adalah channel LISTEN/NOTIFY PostgreSQL untuk event stream.
+const NotifyChannel = "achievement_events"
+
+// PostgresBroker menyebarkan event ke seluruh replika lewat LISTEN/NOTIFY.
+// Payload NOTIFY hanya berisi ID event; setiap replika mengambil isinya dari
+// event_log lalu meneruskannya ke subscriber lokal.
+type PostgresBroker struct {
+	DB       *sql.DB
+	Repo     repository.EventRepository
+	local    *MemoryBroker
+	listener *pq.Listener
+}
+
+// NewPostgresBroker mulai LISTEN pada dsn sampai ctx dibatalkan.
+func NewPostgresBroker(ctx context.Context, dsn string, db *sql.DB, repo repository.EventRepository) (*PostgresBroker, error) {
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			helper.Log.Warn().Err(err).Msg("koneksi LISTEN event terganggu")
+		}
+	})
+	if err := listener.Listen(NotifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	b := &PostgresBroker{
+		DB:       db,
+		Repo:     repo,
+		local:    NewMemoryBroker(),
+		listener: listener,
+	}
+	go b.run(ctx)
+	return b, nil
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, ev *models.Event) error {
+	// event tidak diteruskan langsung; replika ini juga menerima NOTIFY-nya
+	_, err := b.DB.ExecContext(ctx, `SELECT pg_notify($1, $2)`, NotifyChannel, strconv.FormatInt(ev.ID, 10))
+	return err
+}
+
+func (b *PostgresBroker) Subscribe() (<-chan models.Event, func()) {
+	return b.local.Subscribe()
+}
+
+func (b *PostgresBroker) run(ctx context.Context) {
+	defer b.listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.local.CloseAll()
+			return
+
+		case n := <-b.listener.Notify:
+			if n == nil {
+				// koneksi tersambung ulang dan NOTIFY selama terputus hilang;
+				// subscriber diputus agar melanjutkan dari log
+				b.local.CloseAll()
+				continue
+			}
+
+			id, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				continue
+			}
+			ev, err := b.Repo.GetByID(ctx, id)
+			if err != nil || ev == nil {
+				helper.Log.Warn().Err(err).Int64("event_id", id).Msg("event dari NOTIFY tidak dapat diambil")
+				continue
+			}
+			_ = b.local.Publish(ctx, ev)
+
+		case <-time.After(90 * time.Second):
+			go b.listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvWriter tidak menulis kop dokumen agar baris pertama tetap berupa header
+// kolom dan mudah diolah ulang.
+type csvWriter struct {
+	w      *csv.Writer
+	tables int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Table(title string, columns []string) error {
+	// tabel berikutnya dipisah baris kosong dan diberi judul
+	if c.tables > 0 {
+		if err := c.w.Write([]string{}); err != nil {
+			return err
+		}
+		if err := c.w.Write([]string{title}); err != nil {
+			return err
+		}
+	}
+	c.tables++
+
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatValue(v)
+		if _, ok := v.(string); ok {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+
+	// flush berkala agar data mengalir ke klien
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula mencegah CSV injection: sel yang diawali karakter formula
+// diberi tanda kutip tunggal agar tidak dieksekusi aplikasi spreadsheet.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,141 @@
+// Package export merender data laporan tabular ke CSV, XLSX dan PDF. Writer
+// menulis baris demi baris ke io.Writer sehingga ekspor besar dapat
+// di-stream tanpa membangun seluruh dokumen di memori.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	PDF  Format = "pdf"
+)
+
+var contentTypes = map[Format]string{
+	CSV:  "text/csv; charset=utf-8",
+	XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	PDF:  "application/pdf",
+}
+
+// ErrUnknownFormat dikembalikan Negotiate untuk nilai ?format= yang tidak
+// didukung.
+var ErrUnknownFormat = errors.New("export: format tidak didukung")
+
+// Negotiate menentukan format ekspor dari parameter ?format= atau header
+// Accept. Format kosong berarti respon JSON biasa.
+func Negotiate(query, accept string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(query)) {
+	case "":
+	case "json":
+		return "", nil
+	case "csv":
+		return CSV, nil
+	case "xlsx":
+		return XLSX, nil
+	case "pdf":
+		return PDF, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		for f, ct := range contentTypes {
+			if base, _, _ := mime.ParseMediaType(ct); base == mediaType {
+				return f, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Filename menghasilkan nama file unduhan, mis. statistik-prestasi-20250101.pdf.
+func (f Format) Filename(base string, now time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", base, now.Format("20060102"), f)
+}
+
+// Document adalah metadata laporan yang ditampilkan sebagai kop dokumen PDF
+// dan properti workbook XLSX.
+type Document struct {
+	Institution string
+	Title       string
+	// Subtitle berisi keterangan tambahan, mis. identitas mahasiswa.
+	Subtitle    []string
+	GeneratedAt time.Time
+}
+
+// InstitutionFromEnv membaca REPORT_INSTITUTION_NAME untuk kop laporan.
+func InstitutionFromEnv() string {
+	if name := os.Getenv("REPORT_INSTITUTION_NAME"); name != "" {
+		return name
+	}
+	return "Sistem Pelaporan Prestasi Mahasiswa"
+}
+
+// Writer menulis satu atau lebih tabel secara berurutan. Nilai baris boleh
+// berupa string, bilangan bulat/desimal, time.Time atau *time.Time.
+type Writer interface {
+	// Table memulai tabel baru (sheet pada XLSX, bagian pada PDF/CSV).
+	Table(title string, columns []string) error
+	Row(values ...any) error
+	// Close menulis bagian penutup dokumen. Writer tidak boleh dipakai lagi.
+	Close() error
+}
+
+// NewWriter membuat Writer untuk format f yang menulis ke w.
+func NewWriter(f Format, w io.Writer, doc Document) (Writer, error) {
+	if doc.GeneratedAt.IsZero() {
+		doc.GeneratedAt = time.Now()
+	}
+
+	switch f {
+	case CSV:
+		return newCSVWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w, doc), nil
+	case PDF:
+		return newPDFWriter(w, doc), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// formatValue mengubah nilai sel menjadi teks untuk format berbasis teks.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format("2006-01-02 15:04")
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return formatValue(*val)
+	case float64:
+		return fmt.Sprintf("%.2f", val)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,297 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ukuran A4 potret dalam point.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	pageMargin   = 40.0
+	rowHeight    = 16.0
+	cellPadding  = 4.0
+	bodyFontSize = 9.0
+)
+
+// Objek tetap; konten dan halaman dialokasikan mulai dari firstPageObj.
+const (
+	catalogObj   = 1
+	pagesObj     = 2
+	fontObj      = 3
+	fontBoldObj  = 4
+	firstPageObj = 5
+)
+
+// pdfWriter menulis PDF 1.4 memakai font standar Helvetica sehingga tidak
+// perlu menyematkan font. Setiap halaman langsung ditulis ke output begitu
+// penuh; hanya halaman aktif yang disimpan di memori.
+type pdfWriter struct {
+	out     *countingWriter
+	doc     Document
+	offsets map[int]int64
+	nextObj int
+	pages   []int
+
+	page    *bytes.Buffer
+	y       float64
+	title   string
+	columns []string
+	widths  []float64
+	err     error
+}
+
+func newPDFWriter(w io.Writer, doc Document) *pdfWriter {
+	p := &pdfWriter{
+		out:     &countingWriter{w: w},
+		doc:     doc,
+		offsets: map[int]int64{},
+		nextObj: firstPageObj,
+	}
+
+	p.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	p.object(fontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	p.object(fontBoldObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return p
+}
+
+func (p *pdfWriter) Table(title string, columns []string) error {
+	if p.page == nil {
+		p.newPage()
+	}
+
+	p.title, p.columns = title, columns
+	p.widths = columnWidths(columns, pageWidth-2*pageMargin)
+
+	// judul + header + minimal satu baris harus muat di halaman ini
+	if p.y-3*rowHeight-8 < pageMargin+20 {
+		p.newPage()
+	}
+
+	p.y -= 8
+	p.text(pageMargin, p.y-12, 11, true, title)
+	p.y -= rowHeight + 4
+	p.tableHeader()
+	return p.err
+}
+
+func (p *pdfWriter) Row(values ...any) error {
+	if p.columns == nil {
+		return fmt.Errorf("export: Row dipanggil sebelum Table")
+	}
+
+	if p.y-rowHeight < pageMargin+20 {
+		p.newPage()
+		p.text(pageMargin, p.y-12, 9, false, p.title+" (lanjutan)")
+		p.y -= rowHeight
+		p.tableHeader()
+	}
+
+	x := pageMargin
+	for i, w := range p.widths {
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		p.text(x+cellPadding, p.y-11.5, bodyFontSize, false, fitText(formatValue(v), bodyFontSize, w-2*cellPadding))
+		x += w
+	}
+
+	p.y -= rowHeight
+	p.line(pageMargin, p.y, pageWidth-pageMargin, p.y, 0.85)
+	return p.err
+}
+
+func (p *pdfWriter) Close() error {
+	if p.page == nil {
+		p.newPage()
+	}
+	p.flushPage()
+
+	kids := make([]string, len(p.pages))
+	for i, id := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	p.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	p.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	infoObj := p.nextObj
+	p.nextObj++
+	p.object(infoObj, fmt.Sprintf("<< /Title %s /Author %s /Producer (uas) /CreationDate (D:%s) >>",
+		pdfString(p.doc.Title), pdfString(p.doc.Institution), p.doc.GeneratedAt.UTC().Format("20060102150405Z")))
+
+	xref := p.out.n
+	p.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", p.nextObj))
+	for id := 1; id < p.nextObj; id++ {
+		p.write(fmt.Sprintf("%010d 00000 n \n", p.offsets[id]))
+	}
+	p.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextObj, catalogObj, infoObj, xref))
+
+	return p.err
+}
+
+// newPage menutup halaman aktif lalu memulai halaman baru dengan kop.
+func (p *pdfWriter) newPage() {
+	if p.page != nil {
+		p.flushPage()
+	}
+	p.page = &bytes.Buffer{}
+	p.y = pageHeight - pageMargin
+
+	p.text(pageMargin, p.y-14, 14, true, p.doc.Institution)
+	p.y -= 20
+	p.text(pageMargin, p.y-12, 12, true, p.doc.Title)
+	p.y -= 16
+	for _, line := range p.doc.Subtitle {
+		p.text(pageMargin, p.y-10, 10, false, line)
+		p.y -= 13
+	}
+	p.text(pageMargin, p.y-9, 8, false, "Dibuat: "+p.doc.GeneratedAt.Format("02-01-2006 15:04"))
+	p.y -= 16
+	p.line(pageMargin, p.y, pageWidth-pageMargin, p.y, 0)
+	p.y -= 4
+
+	footer := fmt.Sprintf("Halaman %d", len(p.pages)+1)
+	p.text(pageWidth-pageMargin-textWidth(footer, 8), pageMargin-16, 8, false, footer)
+}
+
+func (p *pdfWriter) flushPage() {
+	contentObj, pageObj := p.nextObj, p.nextObj+1
+	p.nextObj += 2
+
+	content := p.page.Bytes()
+	p.object(contentObj, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	p.object(pageObj, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pagesObj, pageWidth, pageHeight, contentObj, fontObj, fontBoldObj,
+	))
+
+	p.pages = append(p.pages, pageObj)
+	p.page = nil
+}
+
+func (p *pdfWriter) tableHeader() {
+	fmt.Fprintf(p.page, "0.88 g %.2f %.2f %.2f %.2f re f 0 g\n", pageMargin, p.y-rowHeight, pageWidth-2*pageMargin, rowHeight)
+
+	x := pageMargin
+	for i, w := range p.widths {
+		p.text(x+cellPadding, p.y-11.5, bodyFontSize, true, fitText(p.columns[i], bodyFontSize, w-2*cellPadding))
+		x += w
+	}
+	p.y -= rowHeight
+}
+
+func (p *pdfWriter) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (p *pdfWriter) line(x1, y1, x2, y2, gray float64) {
+	fmt.Fprintf(p.page, "%.2f G 0.5 w %.2f %.2f m %.2f %.2f l S 0 G\n", gray, x1, y1, x2, y2)
+}
+
+func (p *pdfWriter) object(id int, body string) {
+	p.offsets[id] = p.out.n
+	p.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", id, body))
+}
+
+func (p *pdfWriter) write(s string) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = io.WriteString(p.out, s)
+}
+
+// columnWidths membagi lebar tabel sebanding panjang judul kolom (minimal
+// 6 karakter) karena isi baris belum diketahui saat header ditulis.
+func columnWidths(columns []string, total float64) []float64 {
+	weights := make([]float64, len(columns))
+	sum := 0.0
+	for i, col := range columns {
+		weights[i] = float64(max(len(col), 6))
+		sum += weights[i]
+	}
+
+	widths := make([]float64, len(columns))
+	for i := range columns {
+		widths[i] = total * weights[i] / sum
+	}
+	return widths
+}
+
+// fitText memotong s dengan "..." agar tidak melebihi lebar kolom.
+func fitText(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+
+	r := []rune(s)
+	for len(r) > 0 && textWidth(string(r)+"...", size) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
+
+// textWidth menghitung lebar teks Helvetica dalam point.
+func textWidth(s string, size float64) float64 {
+	w := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			w += helveticaWidths[r-32]
+		} else {
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// pdfString meng-encode s sebagai string literal PDF WinAnsi. Karakter di
+// luar Latin-1 diganti "?".
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// helveticaWidths adalah lebar glyph Helvetica (AFM) untuk karakter 32-126.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
@@ -0,0 +1,211 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxWriter menulis workbook SpreadsheetML minimal. Setiap tabel menjadi
+// satu sheet yang ditulis langsung ke arsip zip; string disimpan inline
+// sehingga tidak perlu tabel shared string di memori.
+type xlsxWriter struct {
+	zip    *zip.Writer
+	doc    Document
+	sheet  io.Writer
+	sheets []string
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, doc Document) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), doc: doc}
+}
+
+func (x *xlsxWriter) Table(title string, columns []string) error {
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	sheet, err := x.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)+1))
+	if err != nil {
+		return err
+	}
+	x.sheet = sheet
+	x.sheets = append(x.sheets, sheetName(title, len(x.sheets)+1, x.sheets))
+	x.row = 0
+
+	if _, err := io.WriteString(sheet, xml.Header+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" state="frozen"/></sheetView></sheetViews>`+
+		`<sheetData>`); err != nil {
+		return err
+	}
+
+	header := make([]any, len(columns))
+	for i, col := range columns {
+		header[i] = col
+	}
+	return x.writeRow(header, 1)
+}
+
+func (x *xlsxWriter) Row(values ...any) error {
+	if x.sheet == nil {
+		return fmt.Errorf("export: Row dipanggil sebelum Table")
+	}
+	return x.writeRow(values, 0)
+}
+
+func (x *xlsxWriter) writeRow(values []any, style int) error {
+	x.row++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.row)
+
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		styleAttr := ""
+		if style > 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+
+		switch val := v.(type) {
+		case int, int32, int64:
+			fmt.Fprintf(&b, `<c r="%s"%s><v>%d</v></c>`, ref, styleAttr, val)
+		case float64:
+			fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(val, 'f', -1, 64))
+		default:
+			fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, styleAttr)
+			xml.EscapeText(&b, []byte(formatValue(v)))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+func (x *xlsxWriter) endSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(x.sheet, `</sheetData></worksheet>`)
+	x.sheet = nil
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	// workbook wajib memiliki minimal satu sheet
+	if len(x.sheets) == 0 {
+		if err := x.Table(x.doc.Title, nil); err != nil {
+			return err
+		}
+	}
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	var sheets, rels, overrides strings.Builder
+	for i, name := range x.sheets {
+		n := i + 1
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeAttr(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+	stylesID := len(x.sheets) + 1
+
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+			`</Relationships>`},
+		{"docProps/core.xml", `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+			`<dc:title>` + escapeAttr(x.doc.Title) + `</dc:title>` +
+			`<dc:creator>` + escapeAttr(x.doc.Institution) + `</dc:creator>` +
+			`<dcterms:created xsi:type="dcterms:W3CDTF">` + x.doc.GeneratedAt.UTC().Format(time.RFC3339) + `</dcterms:created>` +
+			`</cp:coreProperties>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesID) +
+			`</Relationships>`},
+		// style 1 = header tebal
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+
+	for _, f := range files {
+		w, err := x.zip.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xml.Header+f.body); err != nil {
+			return err
+		}
+	}
+
+	return x.zip.Close()
+}
+
+// columnName mengubah indeks kolom (0-based) menjadi huruf kolom Excel.
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// sheetName membersihkan judul agar memenuhi aturan nama sheet Excel
+// (maks. 31 karakter, tanpa []:*?/\ dan unik).
+func sheetName(title string, n int, existing []string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, strings.TrimSpace(title))
+
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", n)
+	}
+
+	for _, e := range existing {
+		if strings.EqualFold(e, name) {
+			suffix := fmt.Sprintf(" (%d)", n)
+			r := []rune(name)
+			if len(r)+len(suffix) > 31 {
+				r = r[:31-len(suffix)]
+			}
+			return string(r) + suffix
+		}
+	}
+	return name
+}
+
+func escapeAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
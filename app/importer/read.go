@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedFormat dikembalikan jika berkas bukan CSV atau XLSX.
+var ErrUnsupportedFormat = errors.New("format berkas harus CSV atau XLSX")
+
+// ReadTable membaca seluruh baris berkas CSV atau XLSX. Format ditentukan
+// dari ekstensi name, atau dari isi berkas jika ekstensi tidak dikenal.
+// Untuk XLSX hanya sheet pertama yang dibaca; indeks baris mengikuti nomor
+// baris di spreadsheet.
+func ReadTable(name string, data []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv", ".txt":
+		return readCSV(data)
+	case ".xlsx":
+		return readXLSX(data)
+	}
+
+	// berkas zip (XLSX) selalu diawali "PK"
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return readXLSX(data)
+	}
+	if name == "" || path.Ext(name) == "" {
+		return readCSV(data)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	// Excel dengan locale Indonesia menyimpan CSV dengan pemisah titik koma
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV tidak valid: %w", err)
+	}
+	return rows, nil
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// maxSheetRows membatasi nomor baris XLSX agar sel yang letaknya sangat jauh
+// tidak membuat tabel raksasa di memori.
+const maxSheetRows = 100000
+
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("XLSX tidak valid: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("XLSX tidak valid: %s tidak ada", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("XLSX tidak valid: %s: %w", name, err)
+		}
+		return nil
+	}
+
+	sheetPath, err := firstSheet(decode)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decode(sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		index := row.Index
+		if index <= 0 {
+			index = len(rows) + 1
+		}
+		if index > maxSheetRows {
+			return nil, fmt.Errorf("XLSX melebihi %d baris", maxSheetRows)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("XLSX tidak valid: shared string %q", c.Value)
+				}
+				cells[col] = shared.Items[n].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "", "n":
+				cells[col] = formatNumber(c.Value)
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows[index-1] = cells
+	}
+
+	return rows, nil
+}
+
+// firstSheet mencari lokasi sheet pertama melalui workbook.xml dan
+// relasinya.
+func firstSheet(decode func(string, any) error) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decode("xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("XLSX tidak memiliki sheet")
+	}
+
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Items {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("XLSX tidak valid: relasi sheet pertama tidak ditemukan")
+}
+
+// columnIndex mengubah referensi sel seperti "C12" menjadi indeks kolom 2.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("XLSX tidak valid: referensi sel %q", ref)
+}
+
+// formatNumber menuliskan angka tanpa notasi ilmiah sehingga NIM yang
+// tersimpan sebagai angka (mis. 2.1010001E+9) terbaca utuh.
+func formatNumber(v string) string {
+	if !strings.ContainsAny(v, "eE") {
+		return v
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
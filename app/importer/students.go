@@ -0,0 +1,532 @@
+// Package importer membuat akun mahasiswa secara massal dari berkas CSV atau
+// XLSX. Setiap baris divalidasi lebih dulu (format, duplikat di dalam berkas
+// dan data yang sudah terdaftar) sehingga dry-run menghasilkan laporan per
+// baris yang sama dengan import sebenarnya.
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+)
+
+var (
+	// ErrInvalidMode dikembalikan untuk mode transaksi yang tidak dikenal.
+	ErrInvalidMode = errors.New("mode harus all_or_nothing atau best_effort")
+	// ErrNoStudentRole dikembalikan jika belum ada role berprofil mahasiswa.
+	ErrNoStudentRole = errors.New("role mahasiswa tidak ditemukan")
+	// ErrTooManyRows dikembalikan jika berkas melebihi MaxRows.
+	ErrTooManyRows = errors.New("jumlah baris melebihi batas import")
+)
+
+// HeaderError menandakan baris header berkas tidak dapat dipakai.
+type HeaderError struct {
+	Missing []string
+}
+
+func (e *HeaderError) Error() string {
+	return "kolom wajib tidak ditemukan: " + strings.Join(e.Missing, ", ")
+}
+
+// Kolom berkas import beserta nama alternatif yang diterima di header.
+const (
+	ColUsername     = "username"
+	ColEmail        = "email"
+	ColFullName     = "full_name"
+	ColNIM          = "nim"
+	ColProgramStudy = "program_study"
+	ColAcademicYear = "academic_year"
+	ColAdvisor      = "advisor_lecturer_id"
+	ColPassword     = "password"
+)
+
+var columnAliases = map[string]string{
+	"username":            ColUsername,
+	"email":               ColEmail,
+	"full_name":           ColFullName,
+	"fullname":            ColFullName,
+	"nama":                ColFullName,
+	"nama_lengkap":        ColFullName,
+	"nim":                 ColNIM,
+	"student_id":          ColNIM,
+	"program_study":       ColProgramStudy,
+	"prodi":               ColProgramStudy,
+	"program_studi":       ColProgramStudy,
+	"academic_year":       ColAcademicYear,
+	"angkatan":            ColAcademicYear,
+	"advisor_lecturer_id": ColAdvisor,
+	"advisor":             ColAdvisor,
+	"lecturer_id":         ColAdvisor,
+	"dosen_wali":          ColAdvisor,
+	"password":            ColPassword,
+}
+
+var requiredColumns = []string{ColUsername, ColEmail, ColFullName, ColNIM}
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,50}$`)
+
+// StudentRow adalah satu baris berkas import.
+type StudentRow struct {
+	Line         int
+	Username     string
+	Email        string
+	FullName     string
+	NIM          string
+	ProgramStudy string
+	AcademicYear string
+	// Advisor berisi kode dosen (lecturer_id) atau ID dosen wali.
+	Advisor string
+	// Password kosong berarti akun belum dapat dipakai login; mahasiswa
+	// mengaturnya lewat fitur lupa password.
+	Password string
+}
+
+// ParseStudents memetakan tabel hasil ReadTable ke StudentRow berdasarkan
+// header di baris pertama. Baris kosong dilewati.
+func ParseStudents(table [][]string) ([]StudentRow, error) {
+	if len(table) == 0 {
+		return nil, &HeaderError{Missing: requiredColumns}
+	}
+
+	index := map[string]int{}
+	for i, name := range table[0] {
+		key := strings.ToLower(strings.TrimSpace(name))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if col, ok := columnAliases[key]; ok {
+			if _, dup := index[col]; !dup {
+				index[col] = i
+			}
+		}
+	}
+
+	var missing []string
+	for _, col := range requiredColumns {
+		if _, ok := index[col]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &HeaderError{Missing: missing}
+	}
+
+	var rows []StudentRow
+	for n, record := range table[1:] {
+		get := func(col string) string {
+			i, ok := index[col]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := StudentRow{
+			Line:         n + 2,
+			Username:     get(ColUsername),
+			Email:        get(ColEmail),
+			FullName:     get(ColFullName),
+			NIM:          get(ColNIM),
+			ProgramStudy: get(ColProgramStudy),
+			AcademicYear: get(ColAcademicYear),
+			Advisor:      get(ColAdvisor),
+			Password:     get(ColPassword),
+		}
+		if row == (StudentRow{Line: row.Line}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+type Options struct {
+	// Mode adalah ImportAllOrNothing (default) atau ImportBestEffort.
+	Mode   string
+	DryRun bool
+}
+
+// StudentImporter membuat user beserta profil mahasiswanya untuk setiap
+// baris import.
+type StudentImporter struct {
+	DB        *sql.DB
+	Users     repository.UserRepository
+	Students  repository.StudentRepository
+	Lecturers repository.LecturerRepository
+	Roles     repository.RoleRepository
+
+	// MaxRows membatasi jumlah baris dalam satu import.
+	MaxRows int
+	Now     func() time.Time
+}
+
+func NewStudentImporter(
+	db *sql.DB,
+	users repository.UserRepository,
+	students repository.StudentRepository,
+	lecturers repository.LecturerRepository,
+	roles repository.RoleRepository,
+) *StudentImporter {
+	return &StudentImporter{
+		DB:        db,
+		Users:     users,
+		Students:  students,
+		Lecturers: lecturers,
+		Roles:     roles,
+		MaxRows:   2000,
+		Now:       time.Now,
+	}
+}
+
+// pending adalah baris valid yang siap disimpan.
+type pending struct {
+	result  *models.ImportRowResult
+	user    models.Users
+	student models.Student
+	// password diisi saat penyimpanan agar dry-run tidak perlu hashing
+	password string
+}
+
+// Run memvalidasi rows lalu menyimpannya sesuai opt. Error hanya
+// dikembalikan untuk kegagalan di luar baris (mis. database tidak dapat
+// diakses); masalah per baris dicatat di laporan.
+func (im *StudentImporter) Run(ctx context.Context, rows []StudentRow, opt Options) (*models.ImportReport, error) {
+	if opt.Mode == "" {
+		opt.Mode = models.ImportAllOrNothing
+	}
+	if opt.Mode != models.ImportAllOrNothing && opt.Mode != models.ImportBestEffort {
+		return nil, ErrInvalidMode
+	}
+	if im.MaxRows > 0 && len(rows) > im.MaxRows {
+		return nil, fmt.Errorf("%w (maksimal %d baris)", ErrTooManyRows, im.MaxRows)
+	}
+
+	report := &models.ImportReport{
+		Mode:      opt.Mode,
+		DryRun:    opt.DryRun,
+		Total:     len(rows),
+		Rows:      make([]models.ImportRowResult, len(rows)),
+		StartedAt: im.Now(),
+	}
+
+	role, err := im.studentRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := im.validate(ctx, rows, report)
+	if err != nil {
+		return nil, err
+	}
+	for i := range valid {
+		valid[i].user.RoleID = role.ID
+	}
+
+	switch {
+	case opt.DryRun:
+	case opt.Mode == models.ImportAllOrNothing:
+		if report.Invalid == 0 {
+			err = im.saveAll(ctx, valid)
+		}
+	default:
+		err = im.saveEach(ctx, valid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range report.Rows {
+		switch r.Status {
+		case models.ImportRowCreated:
+			report.Created++
+		case models.ImportRowFailed:
+			report.Failed++
+		}
+	}
+	report.FinishedAt = im.Now()
+
+	return report, nil
+}
+
+func (im *StudentImporter) studentRole(ctx context.Context) (*models.Role, error) {
+	roles, err := im.Roles.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *models.Role
+	for i := range roles {
+		if roles[i].ProfileType != models.ProfileStudent {
+			continue
+		}
+		// role bawaan didahulukan jika ada beberapa role berprofil mahasiswa
+		if found == nil || roles[i].Name == "Mahasiswa" {
+			found = &roles[i]
+		}
+	}
+	if found == nil {
+		return nil, ErrNoStudentRole
+	}
+	return found, nil
+}
+
+// validate mengisi report.Rows dan mengembalikan baris yang valid.
+func (im *StudentImporter) validate(ctx context.Context, rows []StudentRow, report *models.ImportReport) ([]pending, error) {
+	lecturers, err := im.Lecturers.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	advisors := map[string]string{}
+	for _, l := range lecturers {
+		advisors[l.LecturerID] = l.ID
+		advisors[l.ID] = l.ID
+	}
+
+	var usernames, emails, nims []string
+	for _, row := range rows {
+		usernames = append(usernames, row.Username)
+		emails = append(emails, row.Email)
+		nims = append(nims, row.NIM)
+	}
+	takenUsernames, takenEmails, err := im.Users.FindTaken(ctx, usernames, emails)
+	if err != nil {
+		return nil, err
+	}
+	takenNIMs, err := im.Students.FindTakenStudentIDs(ctx, nims)
+	if err != nil {
+		return nil, err
+	}
+
+	seenUsernames := map[string]int{}
+	seenEmails := map[string]int{}
+	seenNIMs := map[string]int{}
+
+	var valid []pending
+	for i, row := range rows {
+		errs := rowErrors(row)
+
+		email := strings.ToLower(row.Email)
+		checkUnique := func(field, value string, seen map[string]int, taken map[string]bool) {
+			if value == "" || errs[field] != "" {
+				return
+			}
+			if line, ok := seen[value]; ok {
+				errs[field] = fmt.Sprintf("sama dengan baris %d", line)
+				return
+			}
+			seen[value] = row.Line
+			if taken[value] {
+				errs[field] = "sudah terdaftar"
+			}
+		}
+		checkUnique(ColUsername, row.Username, seenUsernames, takenUsernames)
+		checkUnique(ColEmail, email, seenEmails, takenEmails)
+		checkUnique(ColNIM, row.NIM, seenNIMs, takenNIMs)
+
+		var advisorID *string
+		if row.Advisor != "" {
+			if id, ok := advisors[row.Advisor]; ok {
+				advisorID = &id
+			} else {
+				errs[ColAdvisor] = "dosen wali " + row.Advisor + " tidak ditemukan"
+			}
+		}
+
+		result := &report.Rows[i]
+		*result = models.ImportRowResult{
+			Row:      row.Line,
+			Username: row.Username,
+			NIM:      row.NIM,
+		}
+		if len(errs) > 0 {
+			result.Status = models.ImportRowInvalid
+			result.Errors = errs
+			report.Invalid++
+			continue
+		}
+
+		result.Status = models.ImportRowValid
+		report.Valid++
+		valid = append(valid, pending{
+			result: result,
+			user: models.Users{
+				Username: row.Username,
+				Email:    row.Email,
+				FullName: row.FullName,
+			},
+			student: models.Student{
+				StudentID:    row.NIM,
+				ProgramStudy: optional(row.ProgramStudy),
+				AcademicYear: optional(row.AcademicYear),
+				AdvisorID:    advisorID,
+			},
+			password: row.Password,
+		})
+	}
+
+	return valid, nil
+}
+
+// rowErrors memeriksa format setiap kolom pada satu baris.
+func rowErrors(row StudentRow) map[string]string {
+	errs := map[string]string{}
+
+	switch {
+	case row.Username == "":
+		errs[ColUsername] = "wajib diisi"
+	case !usernamePattern.MatchString(row.Username):
+		errs[ColUsername] = "3-50 karakter berupa huruf, angka, titik, garis bawah atau tanda hubung"
+	}
+
+	switch {
+	case row.Email == "":
+		errs[ColEmail] = "wajib diisi"
+	case len(row.Email) > 100 || !utils.IsEmail(row.Email):
+		errs[ColEmail] = "format email tidak valid"
+	}
+
+	switch {
+	case row.FullName == "":
+		errs[ColFullName] = "wajib diisi"
+	case len(row.FullName) > 100:
+		errs[ColFullName] = "maksimal 100 karakter"
+	}
+
+	switch {
+	case row.NIM == "":
+		errs[ColNIM] = "wajib diisi"
+	case !utils.IsNIM(row.NIM):
+		errs[ColNIM] = "harus 5-20 digit angka"
+	}
+
+	if len(row.ProgramStudy) > 100 {
+		errs[ColProgramStudy] = "maksimal 100 karakter"
+	}
+	if row.AcademicYear != "" && !utils.IsAcademicYear(row.AcademicYear) {
+		errs[ColAcademicYear] = "harus tahun angkatan 4 digit, mis. 2024"
+	}
+	if row.Password != "" && len(row.Password) < 8 {
+		errs[ColPassword] = "minimal 8 karakter"
+	}
+
+	return errs
+}
+
+// saveAll menyimpan seluruh baris dalam satu transaksi. Jika satu baris
+// gagal, transaksi dibatalkan dan baris lain tetap berstatus valid.
+func (im *StudentImporter) saveAll(ctx context.Context, rows []pending) error {
+	if err := hashPasswords(rows); err != nil {
+		return err
+	}
+
+	tx, err := im.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		if err := im.insert(tx, &rows[i]); err != nil {
+			tx.Rollback()
+			markFailed(rows[i].result, err)
+			for j := 0; j < i; j++ {
+				rows[j].result.Status = models.ImportRowValid
+				rows[j].result.UserID = ""
+				rows[j].result.StudentID = ""
+			}
+			return nil
+		}
+	}
+
+	return tx.Commit()
+}
+
+// saveEach menyimpan setiap baris dalam transaksinya sendiri.
+func (im *StudentImporter) saveEach(ctx context.Context, rows []pending) error {
+	if err := hashPasswords(rows); err != nil {
+		return err
+	}
+
+	for i := range rows {
+		tx, err := im.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := im.insert(tx, &rows[i]); err != nil {
+			tx.Rollback()
+			markFailed(rows[i].result, err)
+			rows[i].result.UserID = ""
+			rows[i].result.StudentID = ""
+			continue
+		}
+
+		if err := tx.Commit(); err != nil {
+			markFailed(rows[i].result, err)
+			rows[i].result.UserID = ""
+			rows[i].result.StudentID = ""
+		}
+	}
+
+	return nil
+}
+
+func (im *StudentImporter) insert(tx *sql.Tx, p *pending) error {
+	userID, err := im.Users.Create(tx, &p.user)
+	if err != nil {
+		return err
+	}
+	if err := im.Students.CreateProfile(tx, userID, &p.student); err != nil {
+		return err
+	}
+
+	p.result.Status = models.ImportRowCreated
+	p.result.UserID = userID
+	p.result.StudentID = p.student.ID
+	return nil
+}
+
+// hashPasswords hanya meng-hash password yang diisi di berkas; baris tanpa
+// password mendapat utils.UnusablePasswordHash.
+func hashPasswords(rows []pending) error {
+	for i := range rows {
+		password := rows[i].password
+		if password == "" {
+			rows[i].user.PasswordHash = utils.UnusablePasswordHash
+			continue
+		}
+
+		hashed, err := utils.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		rows[i].user.PasswordHash = hashed
+	}
+	return nil
+}
+
+func markFailed(result *models.ImportRowResult, err error) {
+	helper.Log.Warn().Err(err).Int("row", result.Row).Msg("gagal menyimpan baris import")
+
+	result.Status = models.ImportRowFailed
+	if utils.IsUniqueViolation(err) {
+		// didahului request lain setelah validasi
+		result.Errors = map[string]string{"row": "username, email atau NIM sudah terdaftar"}
+		return
+	}
+	result.Errors = map[string]string{"row": "gagal disimpan"}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"uas/app/repository"
+	"uas/helper"
+)
+
+// StartEventLogCleanup menghapus event stream yang lebih tua dari retention.
+// Klien yang terputus lebih lama dari itu melanjutkan tanpa replay.
+func StartEventLogCleanup(ctx context.Context, repo repository.EventRepository, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := repo.PurgeBefore(ctx, time.Now().Add(-retention))
+				if err != nil {
+					helper.Log.Error().Err(err).Msg("event log cleanup gagal")
+					continue
+				}
+				if n > 0 {
+					helper.Log.Info().Int64("purged", n).Msg("event log lama dihapus")
+				}
+			}
+		}
+	}()
+}
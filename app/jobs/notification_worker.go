@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+
+	"uas/app/notify"
+)
+
+// StartNotificationWorker mengirim notifikasi yang diantrekan Notifier ke
+// kanal di luar aplikasi (email) sampai ctx dibatalkan.
+func StartNotificationWorker(ctx context.Context, notifier *notify.Notifier) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-notifier.Queue():
+				notifier.Deliver(ctx, msg)
+			}
+		}
+	}()
+}
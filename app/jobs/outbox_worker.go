@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"uas/app/outbox"
+	"uas/app/repository"
+	"uas/helper"
+)
+
+// StartOutboxWorker menerapkan operasi MongoDB yang tertunda di mongo_outbox
+// secara berkala sampai ctx dibatalkan.
+func StartOutboxWorker(ctx context.Context, outboxRepo repository.OutboxRepository, mongoRepo repository.AchievementMongoRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				done, failed, err := outbox.Process(ctx, outboxRepo, mongoRepo, 100, outbox.DefaultMaxAttempts)
+				if err != nil {
+					helper.Log.Error().Err(err).Msg("outbox worker gagal")
+					continue
+				}
+				if done > 0 || failed > 0 {
+					helper.Log.Info().Int("done", done).Int("failed", failed).Msg("outbox diproses")
+				}
+			}
+		}
+	}()
+}
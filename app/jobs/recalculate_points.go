@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+
+	"uas/app/models"
+	"uas/app/outbox"
+	"uas/app/repository"
+	"uas/app/scoring"
+	"uas/utils"
+)
+
+type RecalculateResult struct {
+	Checked int `json:"checked"`
+	Changed int `json:"changed"`
+	Failed  int `json:"failed"`
+}
+
+// recalculateBatch adalah jumlah dokumen yang diambil per query $in.
+const recalculateBatch = 500
+
+// RecalculatePoints menghitung ulang poin seluruh prestasi terverifikasi
+// dengan set aturan yang diberikan. Poin baru disimpan lewat outbox pada
+// transaksi yang sama dengan salinan facts-nya, seperti saat verifikasi.
+// Jika dryRun, tidak ada yang disimpan.
+func RecalculatePoints(
+	ctx context.Context,
+	refs repository.AchievementReferenceRepository,
+	achievements repository.AchievementMongoRepository,
+	outboxRepo repository.OutboxRepository,
+	set *models.PointRuleSet,
+	dryRun bool,
+) (RecalculateResult, error) {
+	var res RecalculateResult
+
+	list, err := refs.FindAll(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	var ids []string
+	for _, ref := range list {
+		if ref.Status == utils.AchievementStatusVerified {
+			ids = append(ids, ref.MongoAchievementID)
+		}
+	}
+	res.Checked = len(ids)
+
+	for start := 0; start < len(ids); start += recalculateBatch {
+		chunk := ids[start:min(start+recalculateBatch, len(ids))]
+
+		docs, err := achievements.FindByIDs(ctx, chunk)
+		if err != nil {
+			res.Failed += len(chunk)
+			continue
+		}
+
+		for _, id := range chunk {
+			ach := docs[id]
+			if ach == nil {
+				res.Failed++
+				continue
+			}
+
+			before, beforeVersion := ach.Points, ach.PointsVersion
+			scoring.Apply(set, ach)
+			if ach.Points == before && ach.PointsVersion == beforeVersion {
+				continue
+			}
+			res.Changed++
+
+			if dryRun {
+				continue
+			}
+			msg := outbox.NewSetPoints(id, ach.Points, ach.PointsVersion)
+			if err := refs.UpdateFactsWithOutbox(ctx, id, models.FactsOf(ach), msg); err != nil {
+				res.Failed++
+				continue
+			}
+			// kegagalan di sini diulang oleh worker outbox
+			_ = outbox.Dispatch(ctx, outboxRepo, achievements, msg)
+		}
+	}
+
+	return res, nil
+}
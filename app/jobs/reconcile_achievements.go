@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/utils"
+)
+
+// ReconcileReport adalah hasil pemeriksaan konsistensi PostgreSQL–MongoDB.
+type ReconcileReport struct {
+	CheckedRefs int `json:"checked_refs"`
+	CheckedDocs int `json:"checked_docs"`
+
+	// dokumen aktif tanpa reference sama sekali
+	OrphanDocs []string `json:"orphan_docs"`
+	// reference aktif yang dokumennya tidak ada
+	MissingDocs []string `json:"missing_docs"`
+	// reference aktif yang dokumennya sudah di-soft delete
+	DeletedDocs []string `json:"deleted_docs"`
+	// reference deleted yang dokumennya masih aktif
+	StaleDocs []string `json:"stale_docs"`
+	// aggregate yang dilewati karena masih ada operasi outbox tertunda
+	Skipped []string `json:"skipped"`
+
+	Repaired int `json:"repaired"`
+	Failed   int `json:"failed"`
+}
+
+// ReconcileAchievements membandingkan achievement_references dengan koleksi
+// achievements. Jika repair, ketidaksesuaian diperbaiki dengan PostgreSQL
+// sebagai sumber kebenaran status:
+//   - orphan & stale: dokumen di-soft delete
+//   - deleted: dokumen dipulihkan
+//   - missing: reference ditandai deleted beserta catatan history
+func ReconcileAchievements(
+	ctx context.Context,
+	refs repository.AchievementReferenceRepository,
+	docs repository.AchievementMongoRepository,
+	outboxRepo repository.OutboxRepository,
+	repair bool,
+) (ReconcileReport, error) {
+	report := ReconcileReport{
+		OrphanDocs:  []string{},
+		MissingDocs: []string{},
+		DeletedDocs: []string{},
+		StaleDocs:   []string{},
+		Skipped:     []string{},
+	}
+
+	pending, err := outboxRepo.Pending(ctx, math.MaxInt32, math.MaxInt32)
+	if err != nil {
+		return report, err
+	}
+	inFlight := map[string]bool{}
+	for _, m := range pending {
+		inFlight[m.AggregateID] = true
+	}
+
+	list, err := refs.FindAllWithDeleted(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	states, err := docs.States(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.CheckedRefs = len(list)
+	report.CheckedDocs = len(states)
+
+	referenced := map[string]bool{}
+
+	for i := range list {
+		ref := &list[i]
+		referenced[ref.MongoAchievementID] = true
+
+		if inFlight[ref.MongoAchievementID] {
+			report.Skipped = append(report.Skipped, ref.MongoAchievementID)
+			continue
+		}
+
+		deleted, exists := states[ref.MongoAchievementID]
+		refDeleted := ref.Status == utils.AchievementStatusDeleted
+
+		switch {
+		case !refDeleted && !exists:
+			report.MissingDocs = append(report.MissingDocs, ref.ID)
+			if repair {
+				report.count(markReferenceDeleted(ctx, refs, ref))
+			}
+
+		case !refDeleted && deleted:
+			report.DeletedDocs = append(report.DeletedDocs, ref.ID)
+			if repair {
+				report.count(docs.Restore(ctx, ref.MongoAchievementID))
+			}
+
+		case refDeleted && exists && !deleted:
+			report.StaleDocs = append(report.StaleDocs, ref.MongoAchievementID)
+			if repair {
+				report.count(docs.SoftDelete(ctx, ref.MongoAchievementID))
+			}
+		}
+	}
+
+	for id, deleted := range states {
+		if deleted || referenced[id] || inFlight[id] {
+			continue
+		}
+		report.OrphanDocs = append(report.OrphanDocs, id)
+		if repair {
+			report.count(docs.SoftDelete(ctx, id))
+		}
+	}
+
+	return report, nil
+}
+
+func (r *ReconcileReport) count(err error) {
+	if err != nil {
+		r.Failed++
+		return
+	}
+	r.Repaired++
+}
+
+// markReferenceDeleted menandai reference yang kehilangan dokumennya sebagai
+// deleted. Workflow sengaja dilewati karena data sudah tidak dapat dipulihkan.
+func markReferenceDeleted(ctx context.Context, refs repository.AchievementReferenceRepository, ref *models.AchievementReference) error {
+	from := ref.Status
+	note := "rekonsiliasi: dokumen MongoDB tidak ditemukan"
+
+	ref.Status = utils.AchievementStatusDeleted
+
+	return refs.Transition(ctx, ref, &models.AchievementStatusHistory{
+		AchievementRefID: ref.ID,
+		FromStatus:       &from,
+		ToStatus:         ref.Status,
+		Note:             &note,
+		CreatedAt:        time.Now(),
+	})
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"uas/app/repository"
+	"uas/helper"
+)
+
+// StartRevocationCleanup menghapus jti yang sudah expired dari store
+// secara berkala sampai ctx dibatalkan.
+func StartRevocationCleanup(ctx context.Context, repo repository.TokenRevocationRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := repo.PurgeExpired(ctx)
+				if err != nil {
+					helper.Log.Error().Err(err).Msg("revocation cleanup gagal")
+					continue
+				}
+				if n > 0 {
+					helper.Log.Info().Int64("purged", n).Msg("revoked token expired dihapus")
+				}
+			}
+		}
+	}()
+}
+
+// StartSessionCleanup menghapus session expired / dicabut yang sudah
+// melewati masa retensi.
+func StartSessionCleanup(ctx context.Context, repo repository.SessionRepository, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := repo.PurgeExpired(ctx, retention)
+				if err != nil {
+					helper.Log.Error().Err(err).Msg("session cleanup gagal")
+					continue
+				}
+				if n > 0 {
+					helper.Log.Info().Int64("purged", n).Msg("session expired dihapus")
+				}
+			}
+		}
+	}()
+}
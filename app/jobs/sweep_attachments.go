@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"uas/app/repository"
+	"uas/app/storage"
+	"uas/helper"
+)
+
+// attachmentKeyPattern membatasi sweeper pada key yang dibuat upload
+// lampiran; file lain di storage tidak pernah disentuh.
+var attachmentKeyPattern = regexp.MustCompile(`^achievements/[0-9a-f]{24}/[^/]+$`)
+
+// SweepReport adalah hasil satu kali pembersihan lampiran.
+type SweepReport struct {
+	Checked int `json:"checked"`
+	// file milik prestasi yang sudah dihapus
+	Deleted []string `json:"deleted"`
+	// file yang tidak dirujuk dokumen mana pun
+	Orphaned []string `json:"orphaned"`
+	Failed   int      `json:"failed"`
+}
+
+// SweepAttachments menghapus file lampiran milik prestasi yang sudah dihapus
+// lebih lama dari grace, serta file tanpa rujukan yang lebih tua dari grace.
+// Grace melindungi upload yang objeknya sudah tersimpan tetapi dokumennya
+// belum diperbarui.
+func SweepAttachments(ctx context.Context, store storage.Storage, docs repository.AchievementMongoRepository, grace time.Duration, now time.Time) (SweepReport, error) {
+	report := SweepReport{Deleted: []string{}, Orphaned: []string{}}
+
+	objects, err := store.List(ctx, "achievements/")
+	if err != nil {
+		return report, err
+	}
+
+	refs, err := docs.AttachmentKeys(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, obj := range objects {
+		if !attachmentKeyPattern.MatchString(obj.Key) {
+			continue
+		}
+		report.Checked++
+
+		deletedAt, referenced := refs[obj.Key]
+
+		switch {
+		case referenced && deletedAt == nil:
+			continue
+
+		case referenced:
+			if now.Sub(*deletedAt) < grace {
+				continue
+			}
+			report.Deleted = append(report.Deleted, obj.Key)
+
+		default:
+			if now.Sub(obj.ModTime) < grace {
+				continue
+			}
+			report.Orphaned = append(report.Orphaned, obj.Key)
+		}
+
+		if err := store.Delete(ctx, obj.Key); err != nil {
+			helper.Log.Warn().Err(err).Str("key", obj.Key).Msg("gagal menghapus lampiran")
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// StartAttachmentSweeper menjalankan SweepAttachments secara berkala sampai
+// ctx dibatalkan.
+func StartAttachmentSweeper(ctx context.Context, store storage.Storage, docs repository.AchievementMongoRepository, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := SweepAttachments(ctx, store, docs, grace, time.Now())
+				if err != nil {
+					helper.Log.Error().Err(err).Msg("sweeper lampiran gagal")
+					continue
+				}
+				if removed := len(report.Deleted) + len(report.Orphaned); removed > 0 || report.Failed > 0 {
+					helper.Log.Info().Int("removed", removed).Int("failed", report.Failed).Msg("lampiran dibersihkan")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+
+	"uas/app/models"
+	"uas/app/repository"
+)
+
+// syncBatchSize membatasi jumlah ID per query FindByIDs.
+const syncBatchSize = 500
+
+type SyncFactsResult struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+	// reference yang dokumennya tidak ditemukan atau gagal disimpan
+	Failed int `json:"failed"`
+}
+
+// SyncAchievementFacts menyalin ulang tipe, tingkat kompetisi dan poin
+// seluruh prestasi aktif dari MongoDB ke achievement_references. Dipakai
+// untuk mengisi data lama dan memperbaiki salinan yang tertinggal.
+func SyncAchievementFacts(
+	ctx context.Context,
+	refs repository.AchievementReferenceRepository,
+	achievements repository.AchievementMongoRepository,
+) (SyncFactsResult, error) {
+	var res SyncFactsResult
+
+	list, err := refs.FindAll(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	for start := 0; start < len(list); start += syncBatchSize {
+		batch := list[start:min(start+syncBatchSize, len(list))]
+
+		ids := make([]string, len(batch))
+		for i, ref := range batch {
+			ids[i] = ref.MongoAchievementID
+		}
+
+		docs, err := achievements.FindByIDs(ctx, ids, "achievementType", "details", "points")
+		if err != nil {
+			return res, err
+		}
+
+		for _, ref := range batch {
+			res.Checked++
+
+			ach, ok := docs[ref.MongoAchievementID]
+			if !ok || ach == nil {
+				res.Failed++
+				continue
+			}
+
+			if err := refs.UpdateFacts(ctx, ref.MongoAchievementID, models.FactsOf(ach)); err != nil {
+				res.Failed++
+				continue
+			}
+			res.Updated++
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"uas/app/webhook"
+	"uas/helper"
+)
+
+// StartWebhookWorker mengirim webhook yang jatuh tempo secara berkala, dan
+// segera setelah ada event baru, sampai ctx dibatalkan.
+func StartWebhookWorker(ctx context.Context, dispatcher *webhook.Dispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-dispatcher.Woken():
+			}
+
+			done, failed, err := dispatcher.ProcessDue(ctx, 50)
+			if err != nil {
+				helper.Log.Error().Err(err).Msg("webhook worker gagal")
+				continue
+			}
+			if done > 0 || failed > 0 {
+				helper.Log.Info().Int("done", done).Int("failed", failed).Msg("webhook diproses")
+			}
+		}
+	}()
+}
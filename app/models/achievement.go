@@ -16,6 +16,7 @@ type AchievementMongo struct {
     Attachments 	[]AchievementFile  		`bson:"attachments" json:"attachments"`
     Tags   			[]string  				`bson:"tags" json:"tags"`
     Points 			int       				`bson:"points" json:"points"`
+    PointsVersion 	int       				`bson:"pointsVersion,omitempty" json:"points_version,omitempty"`
     CreatedAt 		time.Time            	`bson:"createdAt" json:"created_at"`
     UpdatedAt		time.Time            	`bson:"updatedAt" json:"updated_at"`
 }
@@ -69,8 +70,14 @@ type Period struct {
 }
 
 type AchievementFile struct {
+    ID         string    `bson:"id,omitempty" json:"id,omitempty"`
     FileName   string    `bson:"fileName" json:"file_name"`
     FileURL    string    `bson:"fileUrl" json:"file_url"`
     FileType   string    `bson:"fileType" json:"file_type"`
+    Size       int64     `bson:"size,omitempty" json:"size,omitempty"`
+    // SHA-256 isi file (hex)
+    Checksum   string    `bson:"checksum,omitempty" json:"checksum,omitempty"`
+    // key objek di storage; kosong untuk lampiran lama yang disimpan per path
+    Key        string    `bson:"key,omitempty" json:"-"`
     UploadedAt time.Time `bson:"uploadedAt" json:"uploaded_at"`
 }
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AchievementFilter adalah kriteria pencarian list prestasi. Filter yang
+// menyangkut isi prestasi (tipe, tag, teks, tanggal kegiatan) dijalankan di
+// MongoDB terlebih dahulu dan hasilnya dikirim ke PostgreSQL lewat MongoIDs.
+type AchievementFilter struct {
+	// cakupan data dari policy; nil berarti seluruh mahasiswa
+	StudentIDs      []string
+	ExcludeStatuses []string
+	// NIM mahasiswa dalam cakupan; dipakai menyaring dokumen MongoDB
+	StudentCodes []string
+
+	// filter PostgreSQL
+	Statuses      []string
+	StudentCode   string
+	StudentName   string
+	ProgramStudy  string
+	AcademicYear  string
+	SubmittedFrom *time.Time
+	SubmittedTo   *time.Time
+	VerifiedFrom  *time.Time
+	VerifiedTo    *time.Time
+
+	// filter MongoDB
+	AchievementType string
+	Tags            []string
+	Query           string
+	EventFrom       *time.Time
+	EventTo         *time.Time
+
+	// hasil filter MongoDB; nil berarti tidak dibatasi
+	MongoIDs []string
+
+	Sort  string
+	Order string
+}
+
+// HasMongoFilter mengembalikan true jika ada filter yang harus dijalankan
+// di MongoDB.
+func (f AchievementFilter) HasMongoFilter() bool {
+	return f.AchievementType != "" || len(f.Tags) > 0 || f.Query != "" || f.EventFrom != nil || f.EventTo != nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AchievementStatusHistory adalah satu baris audit transisi status prestasi.
+type AchievementStatusHistory struct {
+	ID               string    `db:"id" json:"id"`
+	AchievementRefID string    `db:"achievement_ref_id" json:"-"`
+	FromStatus       *string   `db:"from_status" json:"from_status"`
+	ToStatus         string    `db:"to_status" json:"status"`
+	ActorID          *string   `db:"actor_id" json:"actor_id"`
+	ActorName        *string   `db:"actor_name" json:"actor_name"`
+	Note             *string   `db:"note" json:"note"`
+	CreatedAt        time.Time `db:"created_at" json:"timestamp"`
+}
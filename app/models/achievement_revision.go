@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// AchievementSnapshot adalah salinan isi prestasi (bagian yang bisa diubah
+// mahasiswa) pada satu titik waktu.
+type AchievementSnapshot struct {
+	AchievementType string                 `json:"achievement_type"`
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description"`
+	Details         map[string]interface{} `json:"details"`
+	Tags            []string               `json:"tags"`
+	Attachments     []string               `json:"attachments"`
+}
+
+type AchievementReviewSnapshot struct {
+	ID               string              `db:"id" json:"id"`
+	AchievementRefID string              `db:"achievement_ref_id" json:"-"`
+	ReviewedBy       *string             `db:"reviewed_by" json:"reviewed_by"`
+	Content          AchievementSnapshot `db:"content" json:"content"`
+	CreatedAt        time.Time           `db:"created_at" json:"created_at"`
+}
+
+// FieldChange adalah satu perbedaan field antara dua snapshot.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Tipe field yang didukung FieldSpec.Type.
+const (
+	FieldString  = "string"
+	FieldInteger = "integer"
+	FieldNumber  = "number"
+	FieldBoolean = "boolean"
+	FieldDate    = "date"
+	FieldArray   = "array"
+	FieldObject  = "object"
+)
+
+// FieldSpec mendeskripsikan satu key pada details prestasi.
+type FieldSpec struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Items    string   `json:"items,omitempty"` // tipe elemen untuk array
+}
+
+type AchievementType struct {
+	ID        string      `db:"id" json:"id"`
+	Code      string      `db:"code" json:"code"`
+	Name      string      `db:"name" json:"name"`
+	Fields    []FieldSpec `db:"fields" json:"fields"`
+	IsActive  bool        `db:"is_active" json:"is_active"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+type AchievementTypeRequest struct {
+	Code     string      `json:"code"`
+	Name     string      `json:"name"`
+	Fields   []FieldSpec `json:"fields"`
+	IsActive *bool       `json:"is_active"`
+}
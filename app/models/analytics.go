@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AchievementFacts adalah salinan ringkas isi prestasi MongoDB yang disimpan
+// di achievement_references agar dapat diagregasi dengan SQL.
+type AchievementFacts struct {
+	AchievementType  string
+	CompetitionLevel string
+	Points           int
+}
+
+// FactsOf mengambil AchievementFacts dari dokumen prestasi.
+func FactsOf(a *AchievementMongo) AchievementFacts {
+	f := AchievementFacts{
+		AchievementType: a.AchievementType,
+		Points:          a.Points,
+	}
+	if v, ok := a.Details["competition_level"]; ok && v != nil {
+		f.CompetitionLevel = strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+	}
+	return f
+}
+
+// AnalyticsFilter adalah kriteria agregasi analitik prestasi.
+type AnalyticsFilter struct {
+	// cakupan data dari policy; nil/kosong berarti tidak dibatasi
+	StudentIDs []string
+	AdvisorID  string
+
+	// kolom tanggal acuan rentang waktu dan time series:
+	// verified_at, submitted_at atau created_at
+	DateField string
+	From      *time.Time
+	To        *time.Time
+
+	Statuses         []string
+	ProgramStudy     string
+	AcademicYear     string
+	AchievementType  string
+	CompetitionLevel string
+}
+
+// AnalyticsBucket adalah hasil agregasi untuk satu nilai dimensi.
+type AnalyticsBucket struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Count  int    `json:"count"`
+	Points int    `json:"points"`
+}
@@ -22,9 +22,10 @@ type UserProfile struct {
 }
 
 type LoginResponse struct {
-	User         UserProfile `json:"user"`
-	Token        string      `json:"token"`
-	RefreshToken string      `json:"refresh_token"`
+	User          UserProfile `json:"user"`
+	Token         string      `json:"token"`
+	RefreshToken  string      `json:"refresh_token"`
+	RecoveryCodes []string    `json:"recovery_codes,omitempty"`
 }
 
 type RefreshReq struct {
@@ -32,5 +33,6 @@ type RefreshReq struct {
 }
 
 type RefreshResp struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Event adalah perubahan yang dikirim ke klien lewat stream SSE. UserID,
+// StudentID dan AdvisorID menentukan audiens dan tidak ikut dikirim.
+type Event struct {
+	ID        int64          `json:"id"`
+	Type      string         `json:"type"`
+	UserID    string         `json:"-"`
+	StudentID string         `json:"-"`
+	AdvisorID string         `json:"-"`
+	Data      map[string]any `json:"data"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// EventAudience adalah identitas penerima stream.
+type EventAudience struct {
+	UserID     string
+	StudentID  string
+	LecturerID string
+	// All untuk admin: seluruh event prestasi.
+	All bool
+}
+
+// Sees menentukan apakah a boleh menerima ev. Event yang ditujukan ke satu
+// pengguna (UserID terisi) hanya untuk pengguna tersebut; event prestasi
+// untuk mahasiswa pemilik, dosen walinya dan admin. Aturan ini harus sama
+// dengan filter EventRepository.Replay.
+func (a EventAudience) Sees(ev *Event) bool {
+	if ev.UserID != "" {
+		return ev.UserID == a.UserID
+	}
+	return a.All ||
+		(a.StudentID != "" && ev.StudentID == a.StudentID) ||
+		(a.LecturerID != "" && ev.AdvisorID == a.LecturerID)
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Mode transaksi import.
+const (
+	// ImportAllOrNothing membatalkan seluruh import jika ada satu baris
+	// yang tidak valid atau gagal disimpan.
+	ImportAllOrNothing = "all_or_nothing"
+	// ImportBestEffort menyimpan setiap baris valid secara terpisah dan
+	// melewati baris yang bermasalah.
+	ImportBestEffort = "best_effort"
+)
+
+// Status baris pada laporan import.
+const (
+	ImportRowValid   = "valid"
+	ImportRowInvalid = "invalid"
+	ImportRowCreated = "created"
+	ImportRowFailed  = "failed"
+)
+
+type ImportRowResult struct {
+	// Row adalah nomor baris di berkas, termasuk baris header.
+	Row       int               `json:"row"`
+	Username  string            `json:"username"`
+	NIM       string            `json:"nim"`
+	Status    string            `json:"status"`
+	UserID    string            `json:"user_id,omitempty"`
+	StudentID string            `json:"student_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	Mode       string            `json:"mode"`
+	DryRun     bool              `json:"dry_run"`
+	Total      int               `json:"total"`
+	Valid      int               `json:"valid"`
+	Invalid    int               `json:"invalid"`
+	Created    int               `json:"created"`
+	Failed     int               `json:"failed"`
+	Rows       []ImportRowResult `json:"rows"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
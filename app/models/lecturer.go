@@ -7,9 +7,21 @@ type Lecturer struct {
     UserID     string    `db:"user_id" json:"user_id"`
     LecturerID string    `db:"lecturer_id" json:"lecturer_id"`  
     Department *string    `db:"department" json:"department"`      
+    Phone      *string   `db:"phone" json:"phone"`
+    Office     *string   `db:"office" json:"office"`
     CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
+// LecturerProfileUpdateRequest mengubah profil dosen. Field nil tidak
+// diubah; string kosong mengosongkan field opsional. lecturer_id dan
+// department hanya boleh diubah admin.
+type LecturerProfileUpdateRequest struct {
+    LecturerID *string `json:"lecturer_id"`
+    Department *string `json:"department"`
+    Phone      *string `json:"phone"`
+    Office     *string `json:"office"`
+}
+
 type LecturerReq struct {
     ID     string `db:"id"`
     UserID string `db:"user_id"`
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+type LoginAttempt struct {
+	ID           string     `db:"id" json:"id"`
+	Scope        string     `db:"scope" json:"scope"`
+	Identifier   string     `db:"identifier" json:"identifier"`
+	FailedCount  int        `db:"failed_count" json:"failed_count"`
+	LastFailedAt *time.Time `db:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until"`
+}
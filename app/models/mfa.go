@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	MFAPurposeVerify = "mfa_verify"
+	MFAPurposeEnroll = "mfa_enroll"
+)
+
+type UserMFA struct {
+	UserID       string     `db:"user_id"`
+	Secret       string     `db:"secret" json:"-"`
+	Enabled      bool       `db:"enabled"`
+	LastUsedStep *int64     `db:"last_used_step"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// MFAChallengeClaims adalah token berumur pendek yang diterbitkan Login
+// ketika user masih harus menyelesaikan langkah MFA.
+type MFAChallengeClaims struct {
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ChallengeToken     string `json:"challenge_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+type MFAVerifyReq struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type MFAEnrollReq struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type MFACodeReq struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled  bool       `json:"enabled"`
+	Required bool       `json:"required"`
+	Since    *time.Time `json:"enabled_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Notification adalah pemberitahuan untuk satu pengguna. Data berisi
+// rujukan (mis. achievement_id) agar frontend dapat membuka halaman terkait.
+type Notification struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	Data      map[string]any `json:"data"`
+	ReadAt    *time.Time     `json:"read_at"`
+	CreatedAt time.Time      `json:"created_at"`
+}
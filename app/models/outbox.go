@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage adalah operasi MongoDB yang menunggu diterapkan. Ditulis
+// dalam transaksi PostgreSQL yang sama dengan perubahan reference.
+type OutboxMessage struct {
+	ID          int64           `db:"id" json:"id"`
+	AggregateID string          `db:"aggregate_id" json:"aggregate_id"`
+	Operation   string          `db:"operation" json:"operation"`
+	Payload     json.RawMessage `db:"payload" json:"payload,omitempty"`
+	Attempts    int             `db:"attempts" json:"attempts"`
+	LastError   *string         `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	ProcessedAt *time.Time      `db:"processed_at" json:"processed_at,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type PasswordReset struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	TokenHash   string     `db:"token_hash"`
+	RequestedIP string     `db:"requested_ip"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+	UsedAt      *time.Time `db:"used_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PointRule memberi Points untuk AchievementType. Field kosong berarti poin
+// dasar; selain itu poin diberikan jika details[Field] sama dengan Value.
+type PointRule struct {
+	AchievementType string `db:"achievement_type" json:"achievement_type"`
+	Field           string `db:"field" json:"field,omitempty"`
+	Value           string `db:"value" json:"value,omitempty"`
+	Points          int    `db:"points" json:"points"`
+}
+
+type PointRuleSet struct {
+	ID        string      `db:"id" json:"id"`
+	Version   int         `db:"version" json:"version"`
+	IsActive  bool        `db:"is_active" json:"is_active"`
+	Note      *string     `db:"note" json:"note"`
+	CreatedBy *string     `db:"created_by" json:"created_by"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	Rules     []PointRule `json:"rules,omitempty"`
+}
+
+type PointRuleSetRequest struct {
+	Note     string      `json:"note"`
+	Rules    []PointRule `json:"rules"`
+	Activate *bool       `json:"activate"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Portfolio adalah portofolio prestasi (SKPI) yang sudah diterbitkan.
+// Document disimpan apa adanya sehingga verifikasi menampilkan isi saat
+// diterbitkan, bukan data terkini.
+type Portfolio struct {
+	ID               string
+	VerificationCode string
+	StudentID        string
+	Document         PortfolioDocument
+	Signature        string
+	IssuedBy         *string
+	IssuedAt         time.Time
+}
+
+// PortfolioDocument adalah isi portofolio yang ditandatangani. Urutan field
+// menentukan hasil JSON dan karenanya tanda tangan; jangan diubah.
+type PortfolioDocument struct {
+	VerificationCode  string           `json:"verification_code"`
+	Institution       string           `json:"institution"`
+	IssuedAt          time.Time        `json:"issued_at"`
+	Student           PortfolioStudent `json:"student"`
+	Groups            []PortfolioGroup `json:"groups"`
+	TotalAchievements int              `json:"total_achievements"`
+	TotalPoints       int              `json:"total_points"`
+}
+
+type PortfolioStudent struct {
+	ID           string `json:"id"`
+	StudentCode  string `json:"student_code"`
+	Name         string `json:"name"`
+	ProgramStudy string `json:"program_study"`
+	AcademicYear string `json:"academic_year"`
+}
+
+// PortfolioGroup berisi prestasi terverifikasi dengan tipe yang sama.
+type PortfolioGroup struct {
+	AchievementType string           `json:"achievement_type"`
+	TypeName        string           `json:"type_name"`
+	Points          int              `json:"points"`
+	Achievements    []PortfolioEntry `json:"achievements"`
+}
+
+type PortfolioEntry struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Points     int        `json:"points"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	VerifiedBy string     `json:"verified_by"`
+}
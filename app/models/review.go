@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AdviseeQueueStat adalah jumlah prestasi yang menunggu verifikasi untuk
+// satu mahasiswa bimbingan.
+type AdviseeQueueStat struct {
+	StudentID   string     `json:"student_id"`
+	StudentCode string     `json:"student_code"`
+	StudentName string     `json:"student_name"`
+	Pending     int        `json:"pending"`
+	Breached    int        `json:"breached"`
+	OldestAt    *time.Time `json:"oldest_submitted_at"`
+}
+
+// ReviewerMetrics adalah beban dan kinerja verifikasi satu dosen wali.
+type ReviewerMetrics struct {
+	LecturerID   string `json:"lecturer_id"`
+	LecturerCode string `json:"lecturer_code"`
+	Name         string `json:"name"`
+
+	// antrian saat ini
+	Pending  int        `json:"pending"`
+	Breached int        `json:"breached"`
+	OldestAt *time.Time `json:"oldest_submitted_at"`
+
+	// review pada rentang waktu filter
+	Reviewed int `json:"reviewed"`
+	Approved int `json:"approved"`
+	Rejected int `json:"rejected"`
+	// nil jika belum ada review
+	ApprovalRate   *float64 `json:"approval_rate"`
+	AvgReviewHours *float64 `json:"avg_review_hours"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Jenis profil yang dibuat otomatis untuk user dengan role tertentu.
+const (
+	ProfileStudent  = "student"
+	ProfileLecturer = "lecturer"
+	ProfileNone     = "none"
+)
+
+type Role struct {
+	ID          string       `db:"id" json:"id"`
+	Name        string       `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	ProfileType string       `db:"profile_type" json:"profile_type"`
+	MFARequired bool         `db:"mfa_required" json:"mfa_required"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+type Permission struct {
+	ID          string `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Resource    string `db:"resource" json:"resource"`
+	Action      string `db:"action" json:"action"`
+	Description string `db:"description" json:"description"`
+}
+
+type RoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ProfileType string `json:"profile_type"`
+	MFARequired bool   `json:"mfa_required"`
+}
+
+type PermissionRequest struct {
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+type RolePermissionsRequest struct {
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+// UserAccess adalah role dan permission user yang dibaca ulang dari
+// database pada setiap request.
+type UserAccess struct {
+	RoleName    string
+	IsActive    bool
+	Permissions []string
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	SessionRevokeLogout        = "logout"
+	SessionRevokeUser          = "revoked_by_user"
+	SessionRevokeReuse         = "refresh_reuse"
+	SessionRevokePasswordReset = "password_reset"
+)
+
+type Session struct {
+	ID           string     `db:"id" json:"id"`
+	UserID       string     `db:"user_id" json:"user_id"`
+	UserAgent    string     `db:"user_agent" json:"user_agent"`
+	IPAddress    string     `db:"ip_address" json:"ip_address"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt   time.Time  `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt    time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	RevokeReason *string    `db:"revoke_reason" json:"revoke_reason,omitempty"`
+}
+
+type RefreshToken struct {
+	JTI        string     `db:"jti"`
+	SessionID  string     `db:"session_id"`
+	IssuedAt   time.Time  `db:"issued_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	UsedAt     *time.Time `db:"used_at"`
+	ReplacedBy *string    `db:"replaced_by"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
@@ -9,6 +9,8 @@ type Student struct {
     ProgramStudy *string    `db:"program_study" json:"program_study"`     
     AcademicYear *string    `db:"academic_year" json:"academic_year"`     
     AdvisorID    *string    `db:"advisor_id" json:"advisor_id"`           
+    Phone        *string    `db:"phone" json:"phone"`
+    Address      *string    `db:"address" json:"address"`
     CreatedAt    time.Time  `db:"created_at" json:"created_at"`
 }
 
@@ -16,6 +18,17 @@ type UpdateAdvisorRequest struct {
     AdvisorID *string `json:"advisor_id"`
 }
 
+// StudentProfileUpdateRequest mengubah profil mahasiswa. Field nil tidak
+// diubah; string kosong mengosongkan field opsional. student_id,
+// program_study dan academic_year hanya boleh diubah admin.
+type StudentProfileUpdateRequest struct {
+    StudentID    *string `json:"student_id"`
+    ProgramStudy *string `json:"program_study"`
+    AcademicYear *string `json:"academic_year"`
+    Phone        *string `json:"phone"`
+    Address      *string `json:"address"`
+}
+
 type StudentStat struct {
     StudentID   string `json:"student_id"`
     StudentName string `json:"student_name"`
@@ -24,6 +37,7 @@ type StudentStat struct {
     Submitted   int    `json:"submitted"`
     Verified    int    `json:"verified"`
     Rejected    int    `json:"rejected"`
+    Revision    int    `json:"revision"`
 }
 
 type Item struct {
@@ -5,6 +5,12 @@ import ("github.com/golang-jwt/jwt/v5")
 type JWTClaims struct {
 	UserID      string   `json:"user_id"`      
 	RoleID      string   `json:"role_id"`      
+	SessionID   string   `json:"sid"`
 	Permissions []string `json:"permissions"`
 	jwt.RegisteredClaims
 }
+
+type RefreshClaims struct {
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
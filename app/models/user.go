@@ -22,6 +22,7 @@ type UserWithRole struct {
 	PasswordHash string `db:"password_hash" json:"-"`
 	RoleName     string `db:"role"`
 	IsActive     bool   `db:"is_active"`
+	MFARequired  bool   `db:"mfa_required"`
 }
 
 type UserCreateRequest struct {
@@ -30,6 +31,10 @@ type UserCreateRequest struct {
 	Password  string `json:"password"`
 	FullName  string `json:"full_name"`
 	RoleID    string `json:"role_id"`
+	// StudentID (NIM) atau LecturerID (NIDN) opsional sesuai profil role;
+	// dibangkitkan otomatis jika kosong.
+	StudentID  string `json:"student_id,omitempty"`
+	LecturerID string `json:"lecturer_id,omitempty"`
 }
 
 type UserUpdateRequest struct {
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"fmt"
+
+	"uas/app/models"
+)
+
+// AchievementSubmitted memberi tahu dosen wali bahwa ada prestasi yang
+// menunggu verifikasi.
+func AchievementSubmitted(advisorUserID string, ref *models.AchievementReference, title string) *models.Notification {
+	student := ref.StudentName
+	if student == "" {
+		student = ref.StudentCode
+	}
+
+	return &models.Notification{
+		UserID: advisorUserID,
+		Type:   TypeAchievementSubmitted,
+		Title:  "Prestasi baru menunggu verifikasi",
+		Body:   fmt.Sprintf("%s mengajukan prestasi %q untuk diverifikasi.", student, title),
+		Data:   achievementData(ref),
+	}
+}
+
+// AchievementVerified memberi tahu mahasiswa bahwa prestasinya diterima.
+func AchievementVerified(studentUserID string, ref *models.AchievementReference, title string, points int) *models.Notification {
+	data := achievementData(ref)
+	data["points"] = points
+
+	return &models.Notification{
+		UserID: studentUserID,
+		Type:   TypeAchievementVerified,
+		Title:  "Prestasi terverifikasi",
+		Body:   fmt.Sprintf("Prestasi %q telah diverifikasi dosen wali dengan %d poin.", title, points),
+		Data:   data,
+	}
+}
+
+// AchievementRejected memberi tahu mahasiswa bahwa prestasinya ditolak
+// beserta catatan dosen wali.
+func AchievementRejected(studentUserID string, ref *models.AchievementReference, title, note string) *models.Notification {
+	data := achievementData(ref)
+	data["rejection_note"] = note
+
+	return &models.Notification{
+		UserID: studentUserID,
+		Type:   TypeAchievementRejected,
+		Title:  "Prestasi ditolak",
+		Body:   fmt.Sprintf("Prestasi %q ditolak dosen wali dengan catatan: %s\nSilakan perbaiki lalu kirim ulang.", title, note),
+		Data:   data,
+	}
+}
+
+// AdvisorAssigned memberi tahu mahasiswa bahwa dosen walinya berubah.
+// advisorUserID kosong berarti dosen wali dilepas.
+func AdvisorAssigned(studentUserID string, student *models.Student, advisorUserID string) *models.Notification {
+	n := &models.Notification{
+		UserID: studentUserID,
+		Type:   TypeAdvisorAssigned,
+		Title:  "Dosen wali diperbarui",
+		Body:   "Dosen wali Anda telah diperbarui. Prestasi yang diajukan selanjutnya akan diverifikasi oleh dosen wali baru.",
+		Data:   map[string]any{"student_id": student.ID, "advisor_user_id": advisorUserID},
+	}
+	if advisorUserID == "" {
+		n.Title = "Dosen wali dilepas"
+		n.Body = "Anda saat ini tidak memiliki dosen wali. Hubungi admin program studi untuk penetapan dosen wali."
+		n.Data["advisor_user_id"] = nil
+	}
+	return n
+}
+
+// AdviseeAssigned memberi tahu dosen wali bahwa ia mendapat mahasiswa
+// bimbingan baru.
+func AdviseeAssigned(advisorUserID string, student *models.Student) *models.Notification {
+	return &models.Notification{
+		UserID: advisorUserID,
+		Type:   TypeAdviseeAssigned,
+		Title:  "Mahasiswa bimbingan baru",
+		Body:   fmt.Sprintf("Mahasiswa %s kini menjadi mahasiswa bimbingan Anda.", student.StudentID),
+		Data:   map[string]any{"student_id": student.ID},
+	}
+}
+
+func achievementData(ref *models.AchievementReference) map[string]any {
+	return map[string]any{
+		"achievement_id": ref.MongoAchievementID,
+		"reference_id":   ref.ID,
+		"student_id":     ref.StudentID,
+		"status":         ref.Status,
+	}
+}
@@ -0,0 +1,177 @@
+// Package notify menyimpan notifikasi in-app untuk event workflow prestasi
+// lalu meneruskannya ke kanal pengiriman lain (email) lewat worker sesuai
+// preferensi penerima. Kegagalan hanya dicatat agar tidak membatalkan aksi
+// pemicunya.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"uas/app/events"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+)
+
+// Jenis notifikasi.
+const (
+	TypeAchievementSubmitted = "achievement.submitted"
+	TypeAchievementVerified  = "achievement.verified"
+	TypeAchievementRejected  = "achievement.rejected"
+	TypeAdvisorAssigned      = "advisor.assigned"
+	TypeAdviseeAssigned      = "advisee.assigned"
+)
+
+// ChannelInApp adalah kotak masuk notifikasi; selalu aktif dan tidak dapat
+// dimatikan lewat preferensi.
+const ChannelInApp = "in_app"
+
+// Channel adalah kanal pengiriman di luar aplikasi.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, to *models.UserWithRole, n *models.Notification) error
+}
+
+// EmailChannel mengirim notifikasi lewat utils.Mailer; dengan MAIL_DRIVER=log
+// email ditulis ke file untuk development lokal.
+type EmailChannel struct {
+	Mailer utils.Mailer
+}
+
+func (e *EmailChannel) Name() string { return "email" }
+
+func (e *EmailChannel) Deliver(ctx context.Context, to *models.UserWithRole, n *models.Notification) error {
+	if to.Email == "" {
+		return nil
+	}
+
+	body := fmt.Sprintf("Halo %s,\n\n%s\n\nPesan ini dikirim otomatis oleh sistem pelaporan prestasi.\n", to.FullName, n.Body)
+	return e.Mailer.Send(ctx, utils.MailMessage{
+		To:      to.Email,
+		Subject: n.Title,
+		Body:    body,
+	})
+}
+
+// ChannelsFromEnv membaca NOTIFICATION_CHANNELS (dipisah koma, default
+// "email"; "none" mematikan semua kanal di luar aplikasi).
+func ChannelsFromEnv(mailer utils.Mailer) ([]Channel, error) {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("NOTIFICATION_CHANNELS")))
+	if raw == "" {
+		raw = "email"
+	}
+	if raw == "none" {
+		return nil, nil
+	}
+
+	var channels []Channel
+	for _, name := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(name) {
+		case "email":
+			channels = append(channels, &EmailChannel{Mailer: mailer})
+		default:
+			return nil, fmt.Errorf("notify: kanal %q tidak dikenal", name)
+		}
+	}
+	return channels, nil
+}
+
+// queueSize adalah kapasitas antrian pengiriman kanal. Jika penuh, pesan
+// hanya tersimpan di kotak masuk.
+const queueSize = 256
+
+// Notifier menyimpan dan mengirim notifikasi. Notifier nil tidak melakukan
+// apa pun sehingga service dapat dipakai tanpa notifikasi.
+type Notifier struct {
+	Repo     repository.NotificationRepository
+	Users    repository.UserRepository
+	Events   *events.Publisher
+	Channels []Channel
+
+	queue chan *models.Notification
+}
+
+func New(repo repository.NotificationRepository, users repository.UserRepository, publisher *events.Publisher, channels ...Channel) *Notifier {
+	return &Notifier{
+		Repo:     repo,
+		Users:    users,
+		Events:   publisher,
+		Channels: channels,
+		queue:    make(chan *models.Notification, queueSize),
+	}
+}
+
+// ChannelNames mengembalikan kanal yang dapat diatur pengguna, diawali in_app.
+func (n *Notifier) ChannelNames() []string {
+	names := []string{ChannelInApp}
+	if n == nil {
+		return names
+	}
+	for _, ch := range n.Channels {
+		names = append(names, ch.Name())
+	}
+	return names
+}
+
+// Queue mengembalikan antrian notifikasi yang menunggu dikirim ke kanal;
+// dibaca oleh worker notifikasi.
+func (n *Notifier) Queue() <-chan *models.Notification {
+	return n.queue
+}
+
+// Send menyimpan msg ke kotak masuk penerima dan mengabarkannya ke stream,
+// lalu menitipkannya ke antrian agar kanal lain dikirim oleh worker di luar
+// request.
+func (n *Notifier) Send(ctx context.Context, msg *models.Notification) {
+	if n == nil || msg == nil || msg.UserID == "" {
+		return
+	}
+
+	if err := n.Repo.Create(ctx, msg); err != nil {
+		helper.Log.Warn().Err(err).Str("user_id", msg.UserID).Str("type", msg.Type).Msg("gagal menyimpan notifikasi")
+		return
+	}
+	n.Events.Publish(ctx, events.NotificationCreated(msg))
+
+	if len(n.Channels) == 0 {
+		return
+	}
+
+	select {
+	case n.queue <- msg:
+	default:
+		helper.Log.Warn().Str("user_id", msg.UserID).Str("type", msg.Type).Msg("antrian notifikasi penuh, hanya tersimpan di kotak masuk")
+	}
+}
+
+// Deliver mengirim msg ke setiap kanal yang tidak dimatikan penerima.
+func (n *Notifier) Deliver(ctx context.Context, msg *models.Notification) {
+	prefs, err := n.Repo.Preferences(ctx, msg.UserID)
+	if err != nil {
+		// tanpa preferensi, kanal dianggap aktif
+		helper.Log.Warn().Err(err).Str("user_id", msg.UserID).Msg("gagal mengambil preferensi notifikasi")
+	}
+
+	var user *models.UserWithRole
+	for _, ch := range n.Channels {
+		if enabled, ok := prefs[ch.Name()]; ok && !enabled {
+			continue
+		}
+
+		if user == nil {
+			user, err = n.Users.GetByID(msg.UserID)
+			if err != nil || user == nil {
+				helper.Log.Warn().Err(err).Str("user_id", msg.UserID).Msg("penerima notifikasi tidak ditemukan")
+				return
+			}
+		}
+
+		if err := ch.Deliver(ctx, user, msg); err != nil {
+			helper.Log.Warn().Err(err).Str("user_id", msg.UserID).Str("channel", ch.Name()).Msg("gagal mengirim notifikasi")
+		}
+	}
+}
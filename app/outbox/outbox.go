@@ -0,0 +1,172 @@
+// Package outbox menerapkan operasi MongoDB yang dicatat di tabel
+// mongo_outbox. Pesan ditulis dalam transaksi PostgreSQL yang sama dengan
+// perubahan reference sehingga kedua store tidak lagi bergantung pada
+// kompensasi manual; operasi yang gagal diterapkan diulang oleh worker.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Operasi yang didukung.
+const (
+	OpCreate     = "achievement.create"
+	OpSoftDelete = "achievement.soft_delete"
+	OpSetPoints  = "achievement.set_points"
+	// OpRenameStudent mengganti NIM pada seluruh dokumen milik satu
+	// mahasiswa; AggregateID berisi ID mahasiswa.
+	OpRenameStudent = "student.rename"
+)
+
+// DefaultMaxAttempts adalah batas percobaan sebelum pesan dibiarkan untuk
+// diperiksa manual.
+const DefaultMaxAttempts = 10
+
+// NewCreate membuat pesan pembuatan dokumen. doc.ID harus sudah diisi agar
+// penerapan ulang tidak menghasilkan dokumen ganda.
+func NewCreate(doc *models.AchievementMongo) (*models.OutboxMessage, error) {
+	if doc.ID.IsZero() {
+		return nil, errors.New("outbox: dokumen belum memiliki ID")
+	}
+
+	// extended JSON menjaga tipe BSON (ObjectID, tanggal, int) tetap utuh
+	payload, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OutboxMessage{
+		AggregateID: doc.ID.Hex(),
+		Operation:   OpCreate,
+		Payload:     payload,
+	}, nil
+}
+
+// NewSoftDelete membuat pesan soft delete dokumen mongoID.
+func NewSoftDelete(mongoID string) *models.OutboxMessage {
+	return &models.OutboxMessage{AggregateID: mongoID, Operation: OpSoftDelete}
+}
+
+// pointsPayload adalah isi pesan OpSetPoints.
+type pointsPayload struct {
+	Points  int `json:"points"`
+	Version int `json:"points_version"`
+}
+
+// NewSetPoints membuat pesan penyimpanan poin hasil verifikasi dokumen
+// mongoID. Ditulis bersama transisi verified agar poin tidak hilang jika
+// MongoDB sedang gagal.
+func NewSetPoints(mongoID string, points, version int) *models.OutboxMessage {
+	payload, _ := json.Marshal(pointsPayload{Points: points, Version: version})
+	return &models.OutboxMessage{AggregateID: mongoID, Operation: OpSetPoints, Payload: payload}
+}
+
+// renamePayload adalah isi pesan OpRenameStudent.
+type renamePayload struct {
+	OldCode string `json:"old_code"`
+	NewCode string `json:"new_code"`
+}
+
+// NewRenameStudent membuat pesan penggantian NIM mahasiswa studentID pada
+// dokumen prestasinya. Ditulis bersama update profil agar NIM di MongoDB
+// tidak tertinggal jika MongoDB sedang gagal.
+func NewRenameStudent(studentID, oldCode, newCode string) *models.OutboxMessage {
+	payload, _ := json.Marshal(renamePayload{OldCode: oldCode, NewCode: newCode})
+	return &models.OutboxMessage{AggregateID: studentID, Operation: OpRenameStudent, Payload: payload}
+}
+
+// Apply menerapkan msg ke MongoDB. Aman dipanggil berulang kali untuk pesan
+// yang sama.
+func Apply(ctx context.Context, mongoRepo repository.AchievementMongoRepository, msg *models.OutboxMessage) error {
+	switch msg.Operation {
+	case OpCreate:
+		var doc models.AchievementMongo
+		if err := bson.UnmarshalExtJSON(msg.Payload, true, &doc); err != nil {
+			return fmt.Errorf("payload tidak valid: %w", err)
+		}
+		if _, err := mongoRepo.Create(ctx, &doc); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		return nil
+
+	case OpSoftDelete:
+		return mongoRepo.SoftDelete(ctx, msg.AggregateID)
+
+	case OpSetPoints:
+		var p pointsPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			return fmt.Errorf("payload tidak valid: %w", err)
+		}
+		return mongoRepo.SetPoints(ctx, msg.AggregateID, p.Points, p.Version)
+
+	case OpRenameStudent:
+		var p renamePayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			return fmt.Errorf("payload tidak valid: %w", err)
+		}
+		return mongoRepo.UpdateStudentCode(ctx, p.OldCode, p.NewCode)
+	}
+
+	return fmt.Errorf("operasi outbox tidak dikenal: %s", msg.Operation)
+}
+
+// Dispatch menerapkan msg segera setelah transaksi commit lalu menandai
+// hasilnya. Pesan yang sedang dipegang worker atau masih menunggu pesan
+// lebih lama untuk dokumen yang sama dibiarkan untuk worker. Kegagalan hanya
+// dicatat; pesan akan diulang oleh worker.
+func Dispatch(ctx context.Context, outboxRepo repository.OutboxRepository, mongoRepo repository.AchievementMongoRepository, msg *models.OutboxMessage) error {
+	if err := outboxRepo.Claim(ctx, msg.ID); err != nil {
+		if !errors.Is(err, repository.ErrOutboxBusy) {
+			helper.Log.Error().Err(err).Int64("outbox_id", msg.ID).Msg("gagal mengambil pesan outbox")
+		}
+		return err
+	}
+
+	return apply(ctx, outboxRepo, mongoRepo, msg)
+}
+
+// apply menerapkan pesan yang sudah diambil alih lalu menandai hasilnya.
+func apply(ctx context.Context, outboxRepo repository.OutboxRepository, mongoRepo repository.AchievementMongoRepository, msg *models.OutboxMessage) error {
+	if err := Apply(ctx, mongoRepo, msg); err != nil {
+		helper.Log.Warn().Err(err).Int64("outbox_id", msg.ID).Str("operation", msg.Operation).Msg("operasi outbox gagal, akan diulang")
+		if markErr := outboxRepo.MarkFailed(ctx, msg.ID, err.Error()); markErr != nil {
+			helper.Log.Error().Err(markErr).Int64("outbox_id", msg.ID).Msg("gagal menandai outbox")
+		}
+		return err
+	}
+
+	if err := outboxRepo.MarkDone(ctx, msg.ID); err != nil {
+		helper.Log.Error().Err(err).Int64("outbox_id", msg.ID).Msg("gagal menandai outbox")
+	}
+	return nil
+}
+
+// Process mengambil alih lalu menerapkan hingga limit pesan yang siap.
+// Setiap dokumen hanya diwakili pesan tertuanya sehingga urutan operasi
+// tetap terjaga.
+func Process(ctx context.Context, outboxRepo repository.OutboxRepository, mongoRepo repository.AchievementMongoRepository, limit, maxAttempts int) (done, failed int, err error) {
+	pending, err := outboxRepo.ClaimPending(ctx, limit, maxAttempts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range pending {
+		if apply(ctx, outboxRepo, mongoRepo, &pending[i]) != nil {
+			failed++
+			continue
+		}
+		done++
+	}
+
+	return done, failed, nil
+}
@@ -0,0 +1,143 @@
+// Package policy menjawab pertanyaan "apakah user X boleh melakukan aksi Y
+// pada prestasi / mahasiswa Z" berdasarkan permission role, kepemilikan
+// (mahasiswa pemilik) dan relasi dosen wali (students.advisor_id).
+package policy
+
+import "uas/utils"
+
+// Actor adalah user yang sedang melakukan request beserta profilnya.
+type Actor struct {
+	UserID      string
+	Role        string
+	Permissions []string
+	StudentID   string // students.id, kosong jika user bukan mahasiswa
+	LecturerID  string // lecturers.id, kosong jika user bukan dosen
+}
+
+// Subject adalah atribut resource yang relevan untuk keputusan akses.
+// Untuk prestasi, StudentID adalah pemilik; untuk mahasiswa, dirinya sendiri;
+// untuk profil dosen, LecturerID adalah dosen itu sendiri.
+type Subject struct {
+	StudentID  string
+	LecturerID string
+	AdvisorID  *string
+	Status     string
+}
+
+type Action string
+
+const (
+	ReadAchievement   Action = "achievement.read"
+	UpdateAchievement Action = "achievement.update"
+	SubmitAchievement Action = "achievement.submit"
+	DeleteAchievement Action = "achievement.delete"
+	VerifyAchievement Action = "achievement.verify"
+
+	ReadStudent   Action = "student.read"
+	ManageStudent Action = "student.manage"
+
+	ReadStatistics Action = "report.statistics"
+
+	// UpdateProfile mengubah data akademik profil (NIM/NIDN, prodi,
+	// angkatan, departemen); UpdateContact hanya field kontak.
+	UpdateProfile Action = "profile.update"
+	UpdateContact Action = "profile.update_contact"
+)
+
+// rule mendefinisikan permission yang dibutuhkan sebuah aksi dan relasi
+// yang membuat permission tersebut berlaku. global adalah permission yang
+// memberi akses tanpa melihat relasi.
+type rule struct {
+	permission string
+	owner      bool
+	advisor    bool
+	global     string
+}
+
+var rules = map[Action]rule{
+	ReadAchievement:   {permission: "achievement:read", owner: true, advisor: true},
+	UpdateAchievement: {permission: "achievement:update", owner: true},
+	SubmitAchievement: {permission: "achievement:update", owner: true},
+	DeleteAchievement: {permission: "achievement:update", owner: true},
+	VerifyAchievement: {permission: "achievement:verify", advisor: true},
+
+	ReadStudent:   {permission: "achievement:read", owner: true, advisor: true, global: "user:manage"},
+	ManageStudent: {global: "user:manage"},
+
+	ReadStatistics: {permission: "achievement:read", owner: true, advisor: true},
+
+	UpdateProfile: {global: "user:manage"},
+	UpdateContact: {permission: "profile:update", owner: true, global: "user:manage"},
+}
+
+// Can mengembalikan true jika actor boleh melakukan action pada subject.
+// Permission "*" selalu diizinkan.
+func Can(a Actor, action Action, s Subject) bool {
+	if utils.HasPermission(a.Permissions, utils.PermissionAll) {
+		return true
+	}
+
+	r, ok := rules[action]
+	if !ok {
+		return false
+	}
+
+	if r.global != "" && utils.HasPermission(a.Permissions, r.global) {
+		return true
+	}
+
+	if r.permission == "" || !utils.HasPermission(a.Permissions, r.permission) {
+		return false
+	}
+
+	if r.owner && a.IsOwner(s) {
+		return true
+	}
+
+	if r.advisor && a.IsAdvisor(s) {
+		// draft dan prestasi terhapus bersifat privat bagi mahasiswa
+		if action == ReadAchievement && (s.Status == utils.AchievementStatusDraft || s.Status == utils.AchievementStatusDeleted) {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+func (a Actor) IsOwner(s Subject) bool {
+	if a.StudentID != "" && a.StudentID == s.StudentID {
+		return true
+	}
+	return a.LecturerID != "" && a.LecturerID == s.LecturerID
+}
+
+func (a Actor) IsAdvisor(s Subject) bool {
+	return a.LecturerID != "" && s.AdvisorID != nil && *s.AdvisorID == a.LecturerID
+}
+
+// Scope menentukan cakupan data yang boleh dilihat actor pada endpoint list.
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	ScopeOwn
+	ScopeAdvisees
+	ScopeAll
+)
+
+// ListScope menentukan cakupan list prestasi untuk actor.
+func ListScope(a Actor) Scope {
+	switch {
+	case utils.HasPermission(a.Permissions, utils.PermissionAll):
+		return ScopeAll
+	case !utils.HasPermission(a.Permissions, "achievement:read"):
+		return ScopeNone
+	case a.StudentID != "":
+		return ScopeOwn
+	case a.LecturerID != "":
+		return ScopeAdvisees
+	default:
+		return ScopeNone
+	}
+}
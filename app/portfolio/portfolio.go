@@ -0,0 +1,111 @@
+// Package portfolio menandatangani dan memverifikasi dokumen portofolio
+// prestasi mahasiswa.
+package portfolio
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"uas/app/models"
+)
+
+// codeAlphabet tidak memuat karakter yang mudah tertukar (0/O, 1/I/L).
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// Signer menandatangani dokumen dengan HMAC-SHA256 memakai kunci server.
+type Signer struct {
+	Key []byte
+}
+
+// SignerFromEnv membaca PORTFOLIO_SIGNING_KEY. Kunci wajib diisi dan tidak
+// boleh sama dengan JWT_SECRET agar bocornya satu kunci tidak membuka
+// pemalsuan token maupun portofolio sekaligus.
+func SignerFromEnv() (Signer, error) {
+	key := os.Getenv("PORTFOLIO_SIGNING_KEY")
+	if key == "" {
+		return Signer{}, errors.New("portfolio: PORTFOLIO_SIGNING_KEY belum diatur")
+	}
+	if key == os.Getenv("JWT_SECRET") {
+		return Signer{}, errors.New("portfolio: PORTFOLIO_SIGNING_KEY tidak boleh sama dengan JWT_SECRET")
+	}
+	return Signer{Key: []byte(key)}, nil
+}
+
+// Sign menghasilkan tanda tangan hex atas representasi JSON doc.
+func (s Signer) Sign(doc *models.PortfolioDocument) (string, error) {
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte("portfolio:v1\n"))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify memeriksa apakah signature cocok dengan isi doc.
+func (s Signer) Verify(doc *models.PortfolioDocument, signature string) bool {
+	expected, err := s.Sign(doc)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// NewCode membuat kode verifikasi acak berbentuk XXXX-XXXX-XXXX.
+func NewCode() (string, error) {
+	// byte di atas kelipatan panjang alfabet dibuang agar distribusi merata
+	limit := byte(256 / len(codeAlphabet) * len(codeAlphabet))
+
+	code := make([]byte, 0, 12)
+	buf := make([]byte, 16)
+	for len(code) < 12 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, v := range buf {
+			if v < limit && len(code) < 12 {
+				code = append(code, codeAlphabet[int(v)%len(codeAlphabet)])
+			}
+		}
+	}
+
+	return string(code[0:4]) + "-" + string(code[4:8]) + "-" + string(code[8:12]), nil
+}
+
+// NormalizeCode merapikan kode yang diketik pengguna (huruf kecil, spasi,
+// tanpa tanda hubung). Mengembalikan "" jika formatnya tidak valid.
+func NormalizeCode(raw string) string {
+	var clean []byte
+	for _, r := range strings.ToUpper(raw) {
+		switch {
+		case r == '-' || r == ' ':
+			continue
+		case r < 128 && strings.IndexByte(codeAlphabet, byte(r)) >= 0:
+			clean = append(clean, byte(r))
+		default:
+			return ""
+		}
+	}
+	if len(clean) != 12 {
+		return ""
+	}
+	return string(clean[0:4]) + "-" + string(clean[4:8]) + "-" + string(clean[8:12])
+}
+
+// VerifyURL mengembalikan alamat verifikasi publik untuk code. Basis URL
+// dapat diatur lewat PORTFOLIO_VERIFY_URL.
+func VerifyURL(code string) string {
+	base := os.Getenv("PORTFOLIO_VERIFY_URL")
+	if base == "" {
+		base = "/api/v1/portfolios/verify"
+	}
+	return strings.TrimRight(base, "/") + "/" + code
+}
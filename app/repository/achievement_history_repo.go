@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type AchievementHistoryRepository interface {
+	FindByReferenceID(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error)
+}
+
+type achievementHistoryRepository struct {
+	DB *sql.DB
+}
+
+func NewAchievementHistoryRepo(db *sql.DB) AchievementHistoryRepository {
+	return &achievementHistoryRepository{DB: db}
+}
+
+func (r *achievementHistoryRepository) FindByReferenceID(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error) {
+	query := `
+		SELECT h.id, h.achievement_ref_id, h.from_status, h.to_status,
+		       h.actor_id, u.full_name, h.note, h.created_at
+		FROM achievement_status_history h
+		LEFT JOIN users u ON u.id = h.actor_id
+		WHERE h.achievement_ref_id = $1
+		ORDER BY h.created_at ASC
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AchievementStatusHistory{}
+	for rows.Next() {
+		var h models.AchievementStatusHistory
+		if err := rows.Scan(
+			&h.ID,
+			&h.AchievementRefID,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ActorID,
+			&h.ActorName,
+			&h.Note,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+
+	return list, rows.Err()
+}
@@ -2,20 +2,33 @@ package repository
 
 import (
     "context"
+    "errors"
+    "regexp"
     "uas/app/models"
     "time"
 
     "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
-    "go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrAchievementNotFound dikembalikan operasi tulis yang tidak menemukan
+// dokumen prestasinya.
+var ErrAchievementNotFound = errors.New("dokumen prestasi tidak ditemukan")
+
 type AchievementMongoRepository interface {
 	Create(ctx context.Context, data *models.AchievementMongo) (string, error)
 	FindByID(ctx context.Context, id string) (*models.AchievementMongo, error)
+	FindByIDs(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error)
 	SoftDelete(ctx context.Context, id string) error
     Update(ctx context.Context, a *models.AchievementMongo) error
+	SetPoints(ctx context.Context, id string, points, version int) error
+	UpdateStudentCode(ctx context.Context, oldCode, newCode string) error
+	FindIDs(ctx context.Context, f models.AchievementFilter, limit int) ([]string, error)
+	States(ctx context.Context) (map[string]bool, error)
+	Restore(ctx context.Context, id string) error
+	AttachmentKeys(ctx context.Context) (map[string]*time.Time, error)
 }
 
 type achievementMongoRepository struct {
@@ -41,12 +54,6 @@ func (r *achievementMongoRepository) FindByID(ctx context.Context, id string) (*
 		return nil, err
 	}
 
-    opts := options.FindOne().SetProjection(bson.M{
-        "description": 0,
-        "details":     0,
-        "tags":        0,
-        "points":      0,
-    })
 	var out models.AchievementMongo
 	err = r.col.FindOne(
         ctx,
@@ -54,7 +61,6 @@ func (r *achievementMongoRepository) FindByID(ctx context.Context, id string) (*
             "_id": objID,
             "isDeleted": bson.M{"$ne": true},
         },
-        opts,
     ).Decode(&out)
     
 	if err != nil {
@@ -64,8 +70,57 @@ func (r *achievementMongoRepository) FindByID(ctx context.Context, id string) (*
 }
 
 
+// FindByIDs mengambil banyak dokumen sekaligus dengan $in. Hasil di-key
+// dengan hex ID; ID yang tidak valid, tidak ada atau sudah dihapus tidak
+// muncul di map. Jika fields diisi hanya field tersebut (beserta _id) yang
+// diambil.
+func (r *achievementMongoRepository) FindByIDs(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error) {
+	out := make(map[string]*models.AchievementMongo, len(ids))
+
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return out, nil
+	}
+
+	opts := options.Find()
+	if len(fields) > 0 {
+		projection := bson.M{}
+		for _, f := range fields {
+			projection[f] = 1
+		}
+		opts.SetProjection(projection)
+	}
+
+	cur, err := r.col.Find(ctx, bson.M{
+		"_id":       bson.M{"$in": oids},
+		"isDeleted": bson.M{"$ne": true},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var a models.AchievementMongo
+		if err := cur.Decode(&a); err != nil {
+			return nil, err
+		}
+		out[a.ID.Hex()] = &a
+	}
+
+	return out, cur.Err()
+}
+
 func (r *achievementMongoRepository) SoftDelete(ctx context.Context, id string) error {
-    oid, _ := primitive.ObjectIDFromHex(id)
+    oid, err := primitive.ObjectIDFromHex(id)
+    if err != nil {
+        return err
+    }
 
     update := bson.M{
         "$set": bson.M{
@@ -74,26 +129,223 @@ func (r *achievementMongoRepository) SoftDelete(ctx context.Context, id string)
         },
     }
 
-    _, err := r.col.UpdateByID(ctx, oid, update)
-    return err
+    res, err := r.col.UpdateByID(ctx, oid, update)
+    if err != nil {
+        return err
+    }
+    if res.MatchedCount == 0 {
+        return ErrAchievementNotFound
+    }
+    return nil
 }
 
 func (r *achievementMongoRepository) Update(ctx context.Context, a *models.AchievementMongo) error {
 
     update := bson.M{
         "$set": bson.M{
-            "student_id":       a.StudentID,
-            "achievement_type": a.AchievementType,
-            "title":            a.Title,
-            "description":      a.Description,
-            "details":          a.Details,
-            "tags":             a.Tags,
-            "attachments":      a.Attachments,
-            "points":           a.Points,
-            "updated_at":       time.Now(),
+            "studentId":       a.StudentID,
+            "achievementType": a.AchievementType,
+            "title":           a.Title,
+            "description":     a.Description,
+            "details":         a.Details,
+            "tags":            a.Tags,
+            "attachments":     a.Attachments,
+            "points":          a.Points,
+            "pointsVersion":   a.PointsVersion,
+            "updatedAt":       time.Now(),
         },
     }
 
      _, err := r.col.UpdateByID(ctx, a.ID, update)
     return err
 }
+
+// SetPoints menyimpan poin hasil verifikasi beserta versi aturannya.
+func (r *achievementMongoRepository) SetPoints(ctx context.Context, id string, points, version int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.col.UpdateByID(ctx, oid, bson.M{
+		"$set": bson.M{
+			"points":        points,
+			"pointsVersion": version,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrAchievementNotFound
+	}
+	return nil
+}
+
+// UpdateStudentCode mengganti studentId (NIM) pada seluruh dokumen milik
+// mahasiswa yang NIM-nya diubah agar tetap masuk cakupan FindIDs.
+func (r *achievementMongoRepository) UpdateStudentCode(ctx context.Context, oldCode, newCode string) error {
+	_, err := r.col.UpdateMany(ctx,
+		bson.M{"studentId": oldCode},
+		bson.M{"$set": bson.M{"studentId": newCode}},
+	)
+	return err
+}
+
+// FindIDs mengembalikan ID dokumen yang cocok dengan filter isi prestasi
+// (tipe, tag, teks judul/deskripsi dan tanggal kegiatan) di dalam cakupan
+// f.StudentCodes, paling banyak limit ID. Hanya _id yang diambil dari
+// database.
+func (r *achievementMongoRepository) FindIDs(ctx context.Context, f models.AchievementFilter, limit int) ([]string, error) {
+	query := bson.M{"isDeleted": bson.M{"$ne": true}}
+
+	if f.StudentCodes != nil {
+		query["studentId"] = bson.M{"$in": f.StudentCodes}
+	}
+
+	if f.AchievementType != "" {
+		query["achievementType"] = f.AchievementType
+	}
+	if len(f.Tags) > 0 {
+		query["tags"] = bson.M{"$all": f.Tags}
+	}
+	if f.Query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(f.Query), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"title": pattern},
+			bson.M{"description": pattern},
+		}
+	}
+
+	// eventDate disimpan sebagai string YYYY-MM-DD sehingga dapat dibandingkan
+	// secara leksikografis
+	if f.EventFrom != nil || f.EventTo != nil {
+		rng := bson.M{}
+		if f.EventFrom != nil {
+			rng["$gte"] = f.EventFrom.Format("2006-01-02")
+		}
+		if f.EventTo != nil {
+			rng["$lt"] = f.EventTo.Format("2006-01-02")
+		}
+		query["details.eventDate"] = rng
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cur, err := r.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	ids := []string{}
+	for cur.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+
+	return ids, cur.Err()
+}
+
+// EnsureAchievementIndexes membuat index yang dipakai FindIDs.
+func EnsureAchievementIndexes(ctx context.Context, col *mongo.Collection) error {
+	_, err := col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "achievementType", Value: 1}, {Key: "details.eventDate", Value: 1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "studentId", Value: 1}}},
+	})
+	return err
+}
+
+// States mengembalikan seluruh ID dokumen beserta status soft delete-nya
+// (true = isDeleted). Dipakai oleh rekonsiliasi.
+func (r *achievementMongoRepository) States(ctx context.Context) (map[string]bool, error) {
+	cur, err := r.col.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1, "isDeleted": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := map[string]bool{}
+	for cur.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			IsDeleted bool               `bson:"isDeleted"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		out[doc.ID.Hex()] = doc.IsDeleted
+	}
+
+	return out, cur.Err()
+}
+
+// Restore membatalkan soft delete dokumen.
+func (r *achievementMongoRepository) Restore(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.col.UpdateByID(ctx, oid, bson.M{
+		"$set":   bson.M{"isDeleted": false},
+		"$unset": bson.M{"deletedAt": ""},
+	})
+	return err
+}
+
+// AttachmentKeys mengembalikan key storage seluruh lampiran beserta waktu
+// soft delete dokumennya (nil untuk dokumen aktif). Dipakai oleh sweeper
+// lampiran.
+func (r *achievementMongoRepository) AttachmentKeys(ctx context.Context) (map[string]*time.Time, error) {
+	cur, err := r.col.Find(ctx,
+		bson.M{"attachments.key": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"attachments.key": 1, "isDeleted": 1, "deletedAt": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := map[string]*time.Time{}
+	for cur.Next(ctx) {
+		var doc struct {
+			Attachments []struct {
+				Key string `bson:"key"`
+			} `bson:"attachments"`
+			IsDeleted bool      `bson:"isDeleted"`
+			DeletedAt time.Time `bson:"deletedAt"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		var deletedAt *time.Time
+		if doc.IsDeleted {
+			t := doc.DeletedAt
+			deletedAt = &t
+		}
+
+		for _, a := range doc.Attachments {
+			if a.Key == "" {
+				continue
+			}
+			// key yang sama dirujuk dokumen aktif: jangan sampai terhapus
+			if prev, ok := out[a.Key]; ok && prev == nil {
+				continue
+			}
+			out[a.Key] = deletedAt
+		}
+	}
+
+	return out, cur.Err()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas/app/models"
+)
+
+// AchievementTypeRepository menyimpan registry tipe prestasi beserta
+// spesifikasi field details-nya.
+type AchievementTypeRepository interface {
+	FindAll(ctx context.Context, activeOnly bool) ([]models.AchievementType, error)
+	GetByCode(ctx context.Context, code string) (*models.AchievementType, error)
+	Create(ctx context.Context, t *models.AchievementType) error
+	Update(ctx context.Context, t *models.AchievementType) error
+}
+
+type achievementTypeRepository struct {
+	DB *sql.DB
+}
+
+func NewAchievementTypeRepo(db *sql.DB) AchievementTypeRepository {
+	return &achievementTypeRepository{DB: db}
+}
+
+const achievementTypeColumns = `id, code, name, fields, is_active, created_at, updated_at`
+
+func scanAchievementType(row interface{ Scan(...any) error }) (*models.AchievementType, error) {
+	var t models.AchievementType
+	var fields []byte
+
+	if err := row.Scan(&t.ID, &t.Code, &t.Name, &fields, &t.IsActive, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &t.Fields); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *achievementTypeRepository) FindAll(ctx context.Context, activeOnly bool) ([]models.AchievementType, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types`
+	if activeOnly {
+		query += ` WHERE is_active`
+	}
+	query += ` ORDER BY code`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AchievementType{}
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *t)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *achievementTypeRepository) GetByCode(ctx context.Context, code string) (*models.AchievementType, error) {
+	t, err := scanAchievementType(r.DB.QueryRowContext(ctx,
+		`SELECT `+achievementTypeColumns+` FROM achievement_types WHERE code = $1`, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *achievementTypeRepository) Create(ctx context.Context, t *models.AchievementType) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO achievement_types (code, name, fields, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, t.Code, t.Name, fields, t.IsActive).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *achievementTypeRepository) Update(ctx context.Context, t *models.AchievementType) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx, `
+		UPDATE achievement_types
+		SET name = $2, fields = $3, is_active = $4, updated_at = NOW()
+		WHERE code = $1
+		RETURNING updated_at
+	`, t.Code, t.Name, fields, t.IsActive).Scan(&t.UpdatedAt)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"uas/app/models"
+
+	"github.com/lib/pq"
+)
+
+// AnalyticsRepository mengagregasi jumlah dan poin prestasi langsung di
+// PostgreSQL memakai salinan data MongoDB pada achievement_references.
+type AnalyticsRepository interface {
+	Aggregate(ctx context.Context, dimension string, f models.AnalyticsFilter) ([]models.AnalyticsBucket, error)
+}
+
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+type analyticsDimension struct {
+	// ekspresi key; %[1]s diganti kolom tanggal acuan
+	key   string
+	label string
+	joins string
+	// time series diurutkan berdasarkan key, selain itu berdasarkan jumlah
+	timeSeries bool
+}
+
+// Semester Ganjil berlangsung Agustus–Januari dan Genap Februari–Juli;
+// key semester berbentuk "<tahun awal>-1" (Ganjil) atau "<tahun awal>-2".
+var analyticsDimensions = map[string]analyticsDimension{
+	"month": {
+		key:        `to_char(date_trunc('month', %[1]s), 'YYYY-MM')`,
+		timeSeries: true,
+	},
+	"semester": {
+		key:        `EXTRACT(YEAR FROM %[1]s - INTERVAL '7 months')::int || CASE WHEN EXTRACT(MONTH FROM %[1]s) BETWEEN 2 AND 7 THEN '-2' ELSE '-1' END`,
+		timeSeries: true,
+	},
+	"program_study":     {key: `COALESCE(s.program_study, '')`},
+	"academic_year":     {key: `COALESCE(s.academic_year, '')`},
+	"competition_level": {key: `COALESCE(ar.competition_level, '')`},
+	"achievement_type": {
+		key:   `COALESCE(ar.achievement_type, '')`,
+		label: `COALESCE(MAX(t.name), '')`,
+		joins: `LEFT JOIN achievement_types t ON t.code = ar.achievement_type`,
+	},
+	"advisor": {
+		key:   `COALESCE(s.advisor_id::text, '')`,
+		label: `COALESCE(MAX(lu.full_name), '')`,
+		joins: `LEFT JOIN lecturers l ON l.id = s.advisor_id LEFT JOIN users lu ON lu.id = l.user_id`,
+	},
+}
+
+var analyticsDateColumns = map[string]string{
+	"verified_at":  "ar.verified_at",
+	"submitted_at": "ar.submitted_at",
+	"created_at":   "ar.created_at",
+}
+
+// IsAnalyticsDimension mengembalikan true jika dimension dapat dipakai
+// untuk Aggregate.
+func IsAnalyticsDimension(dimension string) bool {
+	_, ok := analyticsDimensions[dimension]
+	return ok
+}
+
+// IsAnalyticsDateField mengembalikan true jika field dapat dipakai sebagai
+// tanggal acuan.
+func IsAnalyticsDateField(field string) bool {
+	_, ok := analyticsDateColumns[field]
+	return ok
+}
+
+// buildAnalyticsWhere menyusun klausa WHERE untuk Aggregate beserta
+// argumennya.
+func buildAnalyticsWhere(f models.AnalyticsFilter, dateColumn string, timeSeries bool) (string, []any) {
+	conds := []string{"ar.status != 'deleted'"}
+	args := []any{}
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if len(f.StudentIDs) > 0 {
+		add("ar.student_id = ANY(?)", pq.Array(f.StudentIDs))
+	}
+	if f.AdvisorID != "" {
+		add("s.advisor_id = ?", f.AdvisorID)
+	}
+	if len(f.Statuses) > 0 {
+		add("ar.status::text = ANY(?)", pq.Array(f.Statuses))
+	}
+	if f.ProgramStudy != "" {
+		add("s.program_study ILIKE ?", escapeLike(f.ProgramStudy))
+	}
+	if f.AcademicYear != "" {
+		add("s.academic_year = ?", f.AcademicYear)
+	}
+	if f.AchievementType != "" {
+		add("ar.achievement_type = ?", f.AchievementType)
+	}
+	if f.CompetitionLevel != "" {
+		add("ar.competition_level = ?", strings.ToLower(f.CompetitionLevel))
+	}
+	if f.From != nil {
+		add(dateColumn+" >= ?", *f.From)
+	}
+	if f.To != nil {
+		add(dateColumn+" < ?", *f.To)
+	}
+	if timeSeries {
+		conds = append(conds, dateColumn+" IS NOT NULL")
+	}
+
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Aggregate menghitung jumlah prestasi dan total poin per nilai dimensi.
+func (r *analyticsRepository) Aggregate(ctx context.Context, dimension string, f models.AnalyticsFilter) ([]models.AnalyticsBucket, error) {
+	dim, ok := analyticsDimensions[dimension]
+	if !ok {
+		return nil, fmt.Errorf("dimensi analitik tidak dikenal: %s", dimension)
+	}
+
+	dateColumn, ok := analyticsDateColumns[f.DateField]
+	if !ok {
+		dateColumn = analyticsDateColumns["verified_at"]
+	}
+
+	key := fmt.Sprintf(dim.key, dateColumn)
+	label := dim.label
+	if label == "" {
+		label = "''"
+	}
+	order := "count DESC, key"
+	if dim.timeSeries {
+		order = "key"
+	}
+
+	where, args := buildAnalyticsWhere(f, dateColumn, dim.timeSeries)
+
+	query := fmt.Sprintf(`
+		SELECT %s AS key, %s AS label, COUNT(*) AS count, COALESCE(SUM(ar.points), 0) AS points
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		%s
+		%s
+		GROUP BY 1
+		ORDER BY %s
+	`, key, label, dim.joins, where, order)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []models.AnalyticsBucket{}
+	for rows.Next() {
+		var b models.AnalyticsBucket
+		if err := rows.Scan(&b.Key, &b.Label, &b.Count, &b.Points); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
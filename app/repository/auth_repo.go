@@ -47,7 +47,8 @@ func (r *authRepository) GetUserByEmail(email string) (*models.UserWithRole, err
 		u.password_hash,
 		u.full_name,
 		r.name AS role,
-		u.is_active
+		u.is_active,
+		r.mfa_required
 	FROM users u
 	JOIN roles r ON u.role_id = r.id
 	WHERE u.email = $1
@@ -62,6 +63,7 @@ func (r *authRepository) GetUserByEmail(email string) (*models.UserWithRole, err
 		&u.FullName,
 		&u.RoleName,
 		&u.IsActive,
+		&u.MFARequired,
 	)
 
 	return u, err
@@ -77,7 +79,8 @@ func (r *authRepository) GetUserByID(userID string) (*models.UserWithRole, error
 		u.password_hash,
 		u.full_name,
 		r.name AS role,
-		u.is_active
+		u.is_active,
+		r.mfa_required
 	FROM users u
 	JOIN roles r ON u.role_id = r.id
 	WHERE u.id = $1
@@ -92,6 +95,7 @@ func (r *authRepository) GetUserByID(userID string) (*models.UserWithRole, error
 		&u.FullName,
 		&u.RoleName,
 		&u.IsActive,
+		&u.MFARequired,
 	)
 
 	return u, err
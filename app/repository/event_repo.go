@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+	"uas/app/models"
+)
+
+// EventRepository menyimpan log event stream agar klien yang terputus dapat
+// melanjutkan dari Last-Event-ID.
+type EventRepository interface {
+	Append(ctx context.Context, ev *models.Event) error
+	GetByID(ctx context.Context, id int64) (*models.Event, error)
+	// Replay mengambil event setelah afterID yang boleh diterima audiens,
+	// urut dari yang terlama.
+	Replay(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error)
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type eventRepository struct {
+	DB *sql.DB
+}
+
+func NewEventRepo(db *sql.DB) EventRepository {
+	return &eventRepository{DB: db}
+}
+
+const eventColumns = `id, type, COALESCE(user_id::text, ''), COALESCE(student_id::text, ''), COALESCE(advisor_id::text, ''), data, created_at`
+
+func scanEvent(scan func(dest ...any) error) (*models.Event, error) {
+	var ev models.Event
+	var raw []byte
+	if err := scan(&ev.ID, &ev.Type, &ev.UserID, &ev.StudentID, &ev.AdvisorID, &raw, &ev.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &ev.Data); err != nil {
+		return nil, err
+	}
+	return &ev, nil
+}
+
+func (r *eventRepository) Append(ctx context.Context, ev *models.Event) error {
+	data := ev.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO event_log (type, user_id, student_id, advisor_id, data)
+		VALUES ($1, NULLIF($2, '')::uuid, NULLIF($3, '')::uuid, NULLIF($4, '')::uuid, $5)
+		RETURNING id, created_at
+	`, ev.Type, ev.UserID, ev.StudentID, ev.AdvisorID, raw).Scan(&ev.ID, &ev.CreatedAt)
+}
+
+// GetByID mengembalikan nil tanpa error jika event tidak ditemukan.
+func (r *eventRepository) GetByID(ctx context.Context, id int64) (*models.Event, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+eventColumns+` FROM event_log WHERE id = $1`, id)
+
+	ev, err := scanEvent(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ev, err
+}
+
+func (r *eventRepository) Replay(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+eventColumns+`
+		FROM event_log
+		WHERE id > $1
+		  AND (
+			user_id::text = $2
+			OR (user_id IS NULL AND (
+				$3
+				OR ($4 <> '' AND student_id::text = $4)
+				OR ($5 <> '' AND advisor_id::text = $5)
+			))
+		  )
+		ORDER BY id
+		LIMIT $6
+	`, afterID, a.UserID, a.All, a.StudentID, a.LecturerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Event{}
+	for rows.Next() {
+		ev, err := scanEvent(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *ev)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *eventRepository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM event_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"uas/app/models"
 	"context"
-	
+
+	"github.com/lib/pq"
 )
 
 type LecturerRepository interface {
@@ -12,7 +13,12 @@ type LecturerRepository interface {
     DeleteByUserID(tx *sql.Tx, userID string) error
     GetIDByUserID(userID string) (string, error)
 	GetByUserID(ctx context.Context, userID string) (*models.Lecturer, error)
+	GetByID(ctx context.Context, id string) (*models.Lecturer, error)
     FindAll(ctx context.Context) ([]models.Lecturer, error)
+	// UpdateProfile menyimpan NIDN, departemen dan kontak l berdasarkan l.ID.
+	UpdateProfile(ctx context.Context, l *models.Lecturer) error
+	// FindTakenLecturerIDs mengembalikan NIDN di antara ids yang sudah terdaftar.
+	FindTakenLecturerIDs(ctx context.Context, ids []string) (map[string]bool, error)
 }
 
 
@@ -53,7 +59,7 @@ func (r *lecturerRepository) GetIDByUserID(userID string) (string, error) {
 
 func (r *lecturerRepository) GetByUserID(ctx context.Context, userID string) (*models.Lecturer, error) {
     const query = `
-        SELECT id, user_id, lecturer_id, department, created_at
+        SELECT id, user_id, lecturer_id, department, phone, office, created_at
         FROM lecturers
         WHERE user_id = $1
         LIMIT 1
@@ -66,6 +72,8 @@ func (r *lecturerRepository) GetByUserID(ctx context.Context, userID string) (*m
         &lec.UserID,
         &lec.LecturerID,
         &lec.Department,
+        &lec.Phone,
+        &lec.Office,
         &lec.CreatedAt,
     )
 
@@ -76,9 +84,33 @@ func (r *lecturerRepository) GetByUserID(ctx context.Context, userID string) (*m
     return lec, err
 }
 
+func (r *lecturerRepository) GetByID(ctx context.Context, id string) (*models.Lecturer, error) {
+	lec := new(models.Lecturer)
+
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, user_id, lecturer_id, department, phone, office, created_at
+		FROM lecturers
+		WHERE id = $1
+	`, id).Scan(
+		&lec.ID,
+		&lec.UserID,
+		&lec.LecturerID,
+		&lec.Department,
+		&lec.Phone,
+		&lec.Office,
+		&lec.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return lec, err
+}
+
 func (r *lecturerRepository) FindAll(ctx context.Context) ([]models.Lecturer, error) {
 	query := `
-		SELECT id, user_id, lecturer_id, department, created_at
+		SELECT id, user_id, lecturer_id, department, phone, office, created_at
 		FROM lecturers
 		ORDER BY created_at ASC
 	`
@@ -97,6 +129,8 @@ func (r *lecturerRepository) FindAll(ctx context.Context) ([]models.Lecturer, er
 			&l.UserID,
 			&l.LecturerID,
 			&l.Department,
+			&l.Phone,
+			&l.Office,
 			&l.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -107,3 +141,32 @@ func (r *lecturerRepository) FindAll(ctx context.Context) ([]models.Lecturer, er
 	return list, nil
 }
 
+func (r *lecturerRepository) UpdateProfile(ctx context.Context, l *models.Lecturer) error {
+	query := `
+	UPDATE lecturers
+	SET lecturer_id = $1, department = $2, phone = $3, office = $4
+	WHERE id = $5;
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, l.LecturerID, l.Department, l.Phone, l.Office, l.ID)
+	return err
+}
+
+func (r *lecturerRepository) FindTakenLecturerIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT lecturer_id FROM lecturers WHERE lecturer_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		taken[id] = true
+	}
+
+	return taken, rows.Err()
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+)
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error)
+	RegisterFailure(ctx context.Context, scope, identifier string, window time.Duration) (*models.LoginAttempt, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+	Reset(ctx context.Context, scope, identifier string) error
+	FindActive(ctx context.Context) ([]models.LoginAttempt, error)
+	DeleteByID(ctx context.Context, id string) (bool, error)
+}
+
+type loginAttemptRepository struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptRepo(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{DB: db}
+}
+
+func scanLoginAttempt(row interface{ Scan(...any) error }) (*models.LoginAttempt, error) {
+	var a models.LoginAttempt
+	err := row.Scan(
+		&a.ID,
+		&a.Scope,
+		&a.Identifier,
+		&a.FailedCount,
+		&a.LastFailedAt,
+		&a.LockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *loginAttemptRepository) Get(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error) {
+	query := `
+		SELECT id, scope, identifier, failed_count, last_failed_at, locked_until
+		FROM login_attempts
+		WHERE scope = $1 AND identifier = $2
+	`
+
+	a, err := scanLoginAttempt(r.DB.QueryRowContext(ctx, query, scope, identifier))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+// RegisterFailure menaikkan counter gagal secara atomik. Counter dimulai
+// ulang dari 1 jika kegagalan terakhir sudah lebih lama dari window.
+func (r *loginAttemptRepository) RegisterFailure(ctx context.Context, scope, identifier string, window time.Duration) (*models.LoginAttempt, error) {
+	query := `
+		INSERT INTO login_attempts (scope, identifier, failed_count, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, identifier) DO UPDATE
+		SET failed_count = CASE
+				WHEN login_attempts.last_failed_at IS NULL
+				  OR login_attempts.last_failed_at < NOW() - make_interval(secs => $3)
+				THEN 1
+				ELSE login_attempts.failed_count + 1
+			END,
+			last_failed_at = NOW()
+		RETURNING id, scope, identifier, failed_count, last_failed_at, locked_until
+	`
+
+	return scanLoginAttempt(r.DB.QueryRowContext(ctx, query, scope, identifier, window.Seconds()))
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE login_attempts SET locked_until = $2 WHERE id = $1`, id, until)
+	return err
+}
+
+func (r *loginAttemptRepository) Reset(ctx context.Context, scope, identifier string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		DELETE FROM login_attempts WHERE scope = $1 AND identifier = $2
+	`, scope, identifier)
+	return err
+}
+
+// FindActive mengambil akun / IP yang sedang terkunci atau masih punya
+// percobaan gagal.
+func (r *loginAttemptRepository) FindActive(ctx context.Context) ([]models.LoginAttempt, error) {
+	query := `
+		SELECT id, scope, identifier, failed_count, last_failed_at, locked_until
+		FROM login_attempts
+		WHERE failed_count > 0 OR locked_until > NOW()
+		ORDER BY locked_until DESC NULLS LAST, last_failed_at DESC
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.LoginAttempt
+	for rows.Next() {
+		a, err := scanLoginAttempt(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *a)
+	}
+
+	return list, nil
+}
+
+func (r *loginAttemptRepository) DeleteByID(ctx context.Context, id string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM login_attempts WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
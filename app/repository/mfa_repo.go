@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type MFARepository interface {
+	Get(ctx context.Context, userID string) (*models.UserMFA, error)
+	SaveSecret(ctx context.Context, userID string, secret string) error
+	Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) error
+	Disable(ctx context.Context, userID string) error
+	MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+}
+
+type mfaRepository struct {
+	DB *sql.DB
+}
+
+func NewMFARepo(db *sql.DB) MFARepository {
+	return &mfaRepository{DB: db}
+}
+
+func (r *mfaRepository) Get(ctx context.Context, userID string) (*models.UserMFA, error) {
+	query := `
+		SELECT user_id, secret, enabled, last_used_step, enabled_at, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var m models.UserMFA
+	err := r.DB.QueryRowContext(ctx, query, userID).Scan(
+		&m.UserID,
+		&m.Secret,
+		&m.Enabled,
+		&m.LastUsedStep,
+		&m.EnabledAt,
+		&m.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SaveSecret menyimpan secret baru selama MFA belum aktif. Secret milik
+// user yang sudah mengaktifkan MFA tidak bisa ditimpa lewat setup ulang.
+func (r *mfaRepository) SaveSecret(ctx context.Context, userID string, secret string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+		WHERE user_mfa.enabled = false
+	`, userID, secret)
+	return err
+}
+
+// Enable mengaktifkan MFA dan mengganti seluruh recovery code dalam satu transaksi.
+func (r *mfaRepository) Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa
+		SET enabled = true, enabled_at = NOW(), last_used_step = $2
+		WHERE user_id = $1
+	`, userID, step); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, h := range recoveryHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, h); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) Disable(ctx context.Context, userID string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkStepUsed mencatat step TOTP terakhir yang dipakai. Mengembalikan false
+// jika step tersebut (atau yang lebih baru) sudah pernah dipakai (replay).
+func (r *mfaRepository) MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1
+		  AND (last_used_step IS NULL OR last_used_step < $2)
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas/app/models"
+)
+
+// NotificationRepository menyimpan notifikasi in-app dan preferensi kanal
+// pengiriman setiap pengguna.
+type NotificationRepository interface {
+	Create(ctx context.Context, n *models.Notification) error
+	// FindByUser mengambil notifikasi terbaru milik userID beserta total datanya.
+	FindByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	// MarkRead mengembalikan false jika notifikasi bukan milik userID.
+	MarkRead(ctx context.Context, userID, id string) (bool, error)
+	MarkAllRead(ctx context.Context, userID string) (int, error)
+
+	// Preferences hanya berisi kanal yang pernah diatur pengguna.
+	Preferences(ctx context.Context, userID string) (map[string]bool, error)
+	SetPreferences(ctx context.Context, userID string, prefs map[string]bool) error
+}
+
+type notificationRepository struct {
+	DB *sql.DB
+}
+
+func NewNotificationRepo(db *sql.DB) NotificationRepository {
+	return &notificationRepository{DB: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	data := n.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO notifications (user_id, type, title, body, data)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, n.UserID, n.Type, n.Title, n.Body, raw).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (r *notificationRepository) FindByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+	var total int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1
+		  AND (NOT $2 OR read_at IS NULL)
+	`, userID, unreadOnly).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, user_id, type, title, body, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		  AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var raw []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &raw, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(raw, &n.Data); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, n)
+	}
+
+	return list, total, rows.Err()
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id string) (bool, error) {
+	// notifikasi yang sudah dibaca tetap dianggap ditemukan
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *notificationRepository) Preferences(ctx context.Context, userID string) (map[string]bool, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT channel, enabled FROM notification_preferences WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := map[string]bool{}
+	for rows.Next() {
+		var channel string
+		var enabled bool
+		if err := rows.Scan(&channel, &enabled); err != nil {
+			return nil, err
+		}
+		prefs[channel] = enabled
+	}
+
+	return prefs, rows.Err()
+}
+
+func (r *notificationRepository) SetPreferences(ctx context.Context, userID string, prefs map[string]bool) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for channel, enabled := range prefs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, channel, enabled, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (user_id, channel)
+			DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()
+		`, userID, channel, enabled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
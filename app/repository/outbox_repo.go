@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"uas/app/models"
+)
+
+// ErrOutboxBusy dikembalikan Claim jika pesan sedang dipegang pemroses lain
+// atau masih ada pesan lebih lama untuk dokumen yang sama.
+var ErrOutboxBusy = errors.New("pesan outbox sedang diproses atau menunggu pesan sebelumnya")
+
+// OutboxRepository mengelola antrian operasi MongoDB (tabel mongo_outbox).
+// Pesan ditulis oleh repository lain di dalam transaksi PostgreSQL lewat
+// insertOutbox.
+type OutboxRepository interface {
+	Pending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error)
+	ClaimPending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error)
+	Claim(ctx context.Context, id int64) error
+	MarkDone(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
+
+type outboxRepository struct {
+	DB *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) OutboxRepository {
+	return &outboxRepository{DB: db}
+}
+
+// insertOutbox menulis msg di dalam tx dan mengisi ID serta CreatedAt.
+func insertOutbox(ctx context.Context, tx *sql.Tx, msg *models.OutboxMessage) error {
+	var payload any
+	if len(msg.Payload) > 0 {
+		payload = []byte(msg.Payload)
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO mongo_outbox (aggregate_id, operation, payload)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, msg.AggregateID, msg.Operation, payload).Scan(&msg.ID, &msg.CreatedAt)
+}
+
+// outboxLease adalah lama sebuah pesan dipegang pemroses sebelum boleh
+// diambil ulang (misalnya karena prosesnya mati di tengah jalan).
+const outboxLease = "INTERVAL '5 minutes'"
+
+// outboxReady adalah syarat pesan o boleh diterapkan: belum diproses, tidak
+// sedang dipegang pemroses lain dan tidak ada pesan lebih lama untuk dokumen
+// yang sama yang belum selesai, sehingga urutan per dokumen terjaga.
+const outboxReady = `
+	o.processed_at IS NULL
+	AND (o.locked_until IS NULL OR o.locked_until < NOW())
+	AND NOT EXISTS (
+		SELECT 1 FROM mongo_outbox p
+		WHERE p.aggregate_id = o.aggregate_id
+		  AND p.processed_at IS NULL
+		  AND p.id < o.id
+	)`
+
+// Pending mengembalikan pesan yang belum diproses tanpa mengambil alihnya.
+func (r *outboxRepository) Pending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, aggregate_id, operation, payload, attempts, last_error, created_at
+		FROM mongo_outbox
+		WHERE processed_at IS NULL AND attempts < $1
+		ORDER BY id
+		LIMIT $2
+	`, maxAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutbox(rows)
+}
+
+// ClaimPending mengambil alih hingga limit pesan yang siap diterapkan.
+// Baris yang sedang dikunci replika lain dilewati (SKIP LOCKED) dan pesan
+// yang diambil dipegang selama outboxLease.
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		WITH claimed AS (
+			UPDATE mongo_outbox
+			SET locked_until = NOW() + `+outboxLease+`
+			WHERE id IN (
+				SELECT o.id
+				FROM mongo_outbox o
+				WHERE `+outboxReady+`
+				  AND o.attempts < $1
+				ORDER BY o.id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, aggregate_id, operation, payload, attempts, last_error, created_at
+		)
+		SELECT id, aggregate_id, operation, payload, attempts, last_error, created_at
+		FROM claimed
+		ORDER BY id
+	`, maxAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutbox(rows)
+}
+
+// Claim mengambil alih satu pesan yang baru ditulis agar dapat langsung
+// diterapkan. Mengembalikan ErrOutboxBusy jika pesan belum boleh diterapkan.
+func (r *outboxRepository) Claim(ctx context.Context, id int64) error {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE mongo_outbox o
+		SET locked_until = NOW() + `+outboxLease+`
+		WHERE o.id = $1 AND `+outboxReady, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrOutboxBusy
+	}
+	return nil
+}
+
+func scanOutbox(rows *sql.Rows) ([]models.OutboxMessage, error) {
+	list := []models.OutboxMessage{}
+	for rows.Next() {
+		var m models.OutboxMessage
+		var payload []byte
+		if err := rows.Scan(&m.ID, &m.AggregateID, &m.Operation, &payload, &m.Attempts, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.Payload = payload
+		list = append(list, m)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *outboxRepository) MarkDone(ctx context.Context, id int64) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE mongo_outbox
+		SET processed_at = NOW(), attempts = attempts + 1, last_error = NULL, locked_until = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE mongo_outbox
+		SET attempts = attempts + 1, last_error = $2, locked_until = NULL
+		WHERE id = $1
+	`, id, reason)
+	return err
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset *models.PasswordReset) error
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (string, error)
+}
+
+type passwordResetRepository struct {
+	DB *sql.DB
+}
+
+func NewPasswordResetRepo(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{DB: db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset *models.PasswordReset) error {
+	query := `
+	INSERT INTO password_reset_tokens (user_id, token_hash, requested_ip, expires_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at
+	`
+
+	return r.DB.QueryRowContext(ctx, query,
+		reset.UserID,
+		reset.TokenHash,
+		reset.RequestedIP,
+		reset.ExpiresAt,
+	).Scan(&reset.ID, &reset.CreatedAt)
+}
+
+// ResetPassword memakai token (sekali pakai) lalu mengganti password user
+// dalam satu transaksi. Mengembalikan user_id, atau "" jika token tidak
+// ditemukan, sudah dipakai, atau sudah expired.
+func (r *passwordResetRepository) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > NOW()
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return "", nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2
+	`, passwordHash, userID); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	// token reset lain milik user yang masih aktif ikut dihanguskan
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return userID, tx.Commit()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type PermissionRepository interface {
+	FindAll(ctx context.Context) ([]models.Permission, error)
+	GetByID(ctx context.Context, id string) (*models.Permission, error)
+	Create(ctx context.Context, p *models.Permission) error
+	Update(ctx context.Context, p *models.Permission) error
+	Delete(ctx context.Context, id string) error
+}
+
+type permissionRepository struct {
+	DB *sql.DB
+}
+
+func NewPermissionRepo(db *sql.DB) PermissionRepository {
+	return &permissionRepository{DB: db}
+}
+
+func (r *permissionRepository) FindAll(ctx context.Context) ([]models.Permission, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	return list, nil
+}
+
+func (r *permissionRepository) GetByID(ctx context.Context, id string) (*models.Permission, error) {
+	var p models.Permission
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		WHERE id = $1
+	`, id).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *permissionRepository) Create(ctx context.Context, p *models.Permission) error {
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO permissions (name, resource, action, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, p.Name, p.Resource, p.Action, p.Description).Scan(&p.ID)
+}
+
+func (r *permissionRepository) Update(ctx context.Context, p *models.Permission) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE permissions
+		SET name = $2, resource = $3, action = $4, description = $5
+		WHERE id = $1
+	`, p.ID, p.Name, p.Resource, p.Action, p.Description)
+	return err
+}
+
+func (r *permissionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM permissions WHERE id = $1`, id)
+	return err
+}
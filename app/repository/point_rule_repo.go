@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+// PointRuleRepository menyimpan tabel aturan poin berversi. Setiap perubahan
+// membuat versi baru agar skor lama tetap dapat dihitung ulang.
+type PointRuleRepository interface {
+	Active(ctx context.Context) (*models.PointRuleSet, error)
+	GetByVersion(ctx context.Context, version int) (*models.PointRuleSet, error)
+	ListVersions(ctx context.Context) ([]models.PointRuleSet, error)
+	Create(ctx context.Context, set *models.PointRuleSet, activate bool) error
+	Activate(ctx context.Context, version int) (bool, error)
+}
+
+type pointRuleRepository struct {
+	DB *sql.DB
+}
+
+func NewPointRuleRepo(db *sql.DB) PointRuleRepository {
+	return &pointRuleRepository{DB: db}
+}
+
+func scanPointRuleSet(row interface{ Scan(...any) error }) (*models.PointRuleSet, error) {
+	var s models.PointRuleSet
+	err := row.Scan(&s.ID, &s.Version, &s.IsActive, &s.Note, &s.CreatedBy, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+const pointRuleSetColumns = `id, version, is_active, note, created_by, created_at`
+
+func (r *pointRuleRepository) Active(ctx context.Context) (*models.PointRuleSet, error) {
+	return r.findOne(ctx, `SELECT `+pointRuleSetColumns+` FROM point_rule_sets WHERE is_active`)
+}
+
+func (r *pointRuleRepository) GetByVersion(ctx context.Context, version int) (*models.PointRuleSet, error) {
+	return r.findOne(ctx, `SELECT `+pointRuleSetColumns+` FROM point_rule_sets WHERE version = $1`, version)
+}
+
+func (r *pointRuleRepository) findOne(ctx context.Context, query string, args ...any) (*models.PointRuleSet, error) {
+	set, err := scanPointRuleSet(r.DB.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT achievement_type, COALESCE(field, ''), COALESCE(value, ''), points
+		FROM point_rules
+		WHERE rule_set_id = $1
+		ORDER BY achievement_type, field NULLS FIRST, value
+	`, set.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set.Rules = []models.PointRule{}
+	for rows.Next() {
+		var rule models.PointRule
+		if err := rows.Scan(&rule.AchievementType, &rule.Field, &rule.Value, &rule.Points); err != nil {
+			return nil, err
+		}
+		set.Rules = append(set.Rules, rule)
+	}
+
+	return set, rows.Err()
+}
+
+func (r *pointRuleRepository) ListVersions(ctx context.Context) ([]models.PointRuleSet, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+pointRuleSetColumns+` FROM point_rule_sets ORDER BY version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.PointRuleSet{}
+	for rows.Next() {
+		s, err := scanPointRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+
+	return list, rows.Err()
+}
+
+// Create menyimpan set aturan sebagai versi berikutnya. Version, ID dan
+// CreatedAt diisi dari database.
+func (r *pointRuleRepository) Create(ctx context.Context, set *models.PointRuleSet, activate bool) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// kunci tabel agar dua admin tidak mendapat nomor versi yang sama
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE point_rule_sets IN EXCLUSIVE MODE`); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if activate {
+		if _, err := tx.ExecContext(ctx, `UPDATE point_rule_sets SET is_active = FALSE WHERE is_active`); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO point_rule_sets (version, is_active, note, created_by)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2, $3 FROM point_rule_sets
+		RETURNING id, version, created_at
+	`, activate, set.Note, set.CreatedBy).Scan(&set.ID, &set.Version, &set.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, rule := range set.Rules {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO point_rules (rule_set_id, achievement_type, field, value, points)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		`, set.ID, rule.AchievementType, rule.Field, rule.Value, rule.Points); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	set.IsActive = activate
+	return tx.Commit()
+}
+
+// Activate menjadikan version sebagai set aturan aktif. Mengembalikan false
+// jika versi tidak ditemukan.
+func (r *pointRuleRepository) Activate(ctx context.Context, version int) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE point_rule_sets SET is_active = FALSE WHERE is_active`); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE point_rule_sets SET is_active = TRUE WHERE version = $1`, version)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"uas/app/models"
+)
+
+// PortfolioRepository menyimpan portofolio prestasi yang sudah diterbitkan.
+type PortfolioRepository interface {
+	Create(ctx context.Context, p *models.Portfolio) error
+	GetByCode(ctx context.Context, code string) (*models.Portfolio, error)
+}
+
+type portfolioRepository struct {
+	DB *sql.DB
+}
+
+func NewPortfolioRepo(db *sql.DB) PortfolioRepository {
+	return &portfolioRepository{DB: db}
+}
+
+func (r *portfolioRepository) Create(ctx context.Context, p *models.Portfolio) error {
+	doc, err := json.Marshal(p.Document)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO portfolios (verification_code, student_id, document, signature, issued_by, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		p.VerificationCode,
+		p.StudentID,
+		doc,
+		p.Signature,
+		p.IssuedBy,
+		p.IssuedAt,
+	).Scan(&p.ID)
+}
+
+// GetByCode mengembalikan nil tanpa error jika kode tidak ditemukan.
+func (r *portfolioRepository) GetByCode(ctx context.Context, code string) (*models.Portfolio, error) {
+	var p models.Portfolio
+	var doc []byte
+
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, verification_code, student_id, document, signature, issued_by, issued_at
+		FROM portfolios
+		WHERE verification_code = $1
+	`, code).Scan(&p.ID, &p.VerificationCode, &p.StudentID, &doc, &p.Signature, &p.IssuedBy, &p.IssuedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(doc, &p.Document); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
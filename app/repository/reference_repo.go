@@ -3,24 +3,48 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"uas/app/models"
 
 	"github.com/lib/pq"
 )
 
+// ErrStatusConflict dikembalikan Transition jika status reference sudah
+// diubah request lain sejak dibaca, sehingga entry.FromStatus tidak lagi
+// berlaku.
+var ErrStatusConflict = errors.New("status prestasi sudah berubah")
+
 type AchievementReferenceRepository interface {
 	Create(ctx context.Context, ref *models.AchievementReference) error
     GetByMongoID(ctx context.Context, mongoID string) (*models.AchievementReference, error)
     Update(ctx context.Context, ref *models.AchievementReference) error
+	Touch(ctx context.Context, id, expectedStatus string) error
+	Transition(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error
+	CreateWithOutbox(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error
+	TransitionWithOutbox(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, msg *models.OutboxMessage) error
+	TransitionWithSnapshot(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error
+	FindAllWithDeleted(ctx context.Context) ([]models.AchievementReference, error)
+	UpdateFacts(ctx context.Context, mongoID string, facts models.AchievementFacts) error
+	UpdateFactsWithOutbox(ctx context.Context, mongoID string, facts models.AchievementFacts, msg *models.OutboxMessage) error
 
 	FindByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error)
 	FindAll(ctx context.Context) ([]models.AchievementReference, error)
 	FindByStudentIDs(ctx context.Context, ids []string) ([]models.AchievementReference, error)
     FindForAdvisor(ctx context.Context, ids []string) ([]models.AchievementReference, error)
-
     FindAllPaginated(ctx context.Context, limit, offset int) ([]models.AchievementReference, int, error)
 	FindByStudentIDPaginated(ctx context.Context, studentID string, limit, offset int) ([]models.AchievementReference, int, error)
 	FindForAdvisorPaginated(ctx context.Context, ids []string, limit, offset int) ([]models.AchievementReference, int, error)
+
+	Search(ctx context.Context, f models.AchievementFilter, limit, offset int) ([]models.AchievementReference, int, error)
+
+	// CountByStatus menghitung jumlah prestasi per status dalam cakupan f.
+	CountByStatus(ctx context.Context, f models.AchievementFilter) (map[string]int, error)
+	// EachStudentStat mengalirkan statistik per mahasiswa dalam cakupan f,
+	// urut berdasarkan nama, ke fn satu baris per panggilan langsung dari
+	// cursor query.
+	EachStudentStat(ctx context.Context, f models.AchievementFilter, fn func(models.StudentStat) error) error
 }
 
 type achievementReferenceRepository struct {
@@ -116,6 +140,229 @@ func (r *achievementReferenceRepository) Update(ctx context.Context, ref *models
     return err
 }
 
+// Touch memperbarui updated_at reference id setelah isi prestasi diedit.
+// Kolom status tidak disentuh; jika status di database bukan lagi
+// expectedStatus, ErrStatusConflict dikembalikan.
+func (r *achievementReferenceRepository) Touch(ctx context.Context, id, expectedStatus string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET updated_at = NOW()
+		WHERE id = $1 AND status::text = $2
+	`, id, expectedStatus)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStatusConflict
+	}
+	return nil
+}
+
+// Transition menyimpan perubahan status ref dan entry history-nya dalam
+// satu transaksi.
+func (r *achievementReferenceRepository) Transition(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+	return r.TransitionWithOutbox(ctx, ref, entry, nil)
+}
+
+// TransitionWithOutbox sama dengan Transition, ditambah menulis msg ke
+// mongo_outbox pada transaksi yang sama jika msg tidak nil.
+func (r *achievementReferenceRepository) TransitionWithOutbox(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, msg *models.OutboxMessage) error {
+	return r.transition(ctx, ref, entry, func(tx *sql.Tx) error {
+		if msg == nil {
+			return nil
+		}
+		return insertOutbox(ctx, tx, msg)
+	})
+}
+
+// TransitionWithSnapshot sama dengan Transition, ditambah menyimpan snapshot
+// isi yang direview pada transaksi yang sama sehingga snapshot hanya ada
+// jika transisinya tersimpan.
+func (r *achievementReferenceRepository) TransitionWithSnapshot(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error {
+	return r.transition(ctx, ref, entry, func(tx *sql.Tx) error {
+		return insertSnapshot(ctx, tx, snap)
+	})
+}
+
+// transition menyimpan status ref, entry history dan tulisan tambahan dari
+// extra dalam satu transaksi. Update hanya berlaku jika status di database
+// masih entry.FromStatus; jika tidak, ErrStatusConflict dikembalikan dan
+// tidak ada yang disimpan.
+func (r *achievementReferenceRepository) transition(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, extra func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET status = $1,
+		    submitted_at = $2,
+		    verified_at = $3,
+		    verified_by = $4,
+		    rejection_note = $5,
+		    updated_at = NOW()
+		WHERE id = $6
+		  AND status::text = $7
+	`,
+		ref.Status,
+		ref.SubmittedAt,
+		ref.VerifiedAt,
+		ref.VerifiedBy,
+		ref.RejectionNote,
+		ref.ID,
+		entry.FromStatus,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return ErrStatusConflict
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO achievement_status_history
+			(achievement_ref_id, from_status, to_status, actor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		ref.ID,
+		entry.FromStatus,
+		entry.ToStatus,
+		entry.ActorID,
+		entry.Note,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := extra(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateWithOutbox menyimpan reference baru beserta msg (biasanya operasi
+// pembuatan dokumen MongoDB) dalam satu transaksi.
+func (r *achievementReferenceRepository) CreateWithOutbox(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO achievement_references
+			(id, student_id, mongo_achievement_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+	`,
+		ref.ID,
+		ref.StudentID,
+		ref.MongoAchievementID,
+		ref.Status,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertOutbox(ctx, tx, msg); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateFacts menyimpan salinan tipe, tingkat kompetisi dan poin prestasi
+// yang dipakai agregasi analitik.
+func (r *achievementReferenceRepository) UpdateFacts(ctx context.Context, mongoID string, facts models.AchievementFacts) error {
+	return r.UpdateFactsWithOutbox(ctx, mongoID, facts, nil)
+}
+
+// UpdateFactsWithOutbox sama dengan UpdateFacts, ditambah menulis msg ke
+// mongo_outbox pada transaksi yang sama jika msg tidak nil.
+func (r *achievementReferenceRepository) UpdateFactsWithOutbox(ctx context.Context, mongoID string, facts models.AchievementFacts, msg *models.OutboxMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET achievement_type = NULLIF($1, ''),
+		    competition_level = NULLIF($2, ''),
+		    points = $3
+		WHERE mongo_achievement_id = $4
+	`,
+		facts.AchievementType,
+		facts.CompetitionLevel,
+		facts.Points,
+		mongoID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if msg != nil {
+		if err := insertOutbox(ctx, tx, msg); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindAllWithDeleted mengambil seluruh reference termasuk yang berstatus
+// deleted. Dipakai oleh rekonsiliasi PostgreSQL–MongoDB.
+func (r *achievementReferenceRepository) FindAllWithDeleted(ctx context.Context) ([]models.AchievementReference, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+			ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+			ar.created_at, ar.updated_at,
+			COALESCE(s.student_id, '') AS student_code,
+			COALESCE(u.full_name, '')  AS student_name
+		FROM achievement_references ar
+		LEFT JOIN students s ON s.id = ar.student_id
+		LEFT JOIN users u    ON u.id = s.user_id
+		ORDER BY ar.created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := []models.AchievementReference{}
+	for rows.Next() {
+		ref, err := scanAchievementRef(rows)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
 func (r *achievementReferenceRepository) FindByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
     query := `
         SELECT 
@@ -311,7 +558,6 @@ func (r *achievementReferenceRepository) FindForAdvisor(ctx context.Context, ids
     return refs, nil
 }
 
-
 func (r *achievementReferenceRepository) FindAllPaginated(
 	ctx context.Context,
 	limit, offset int,
@@ -438,7 +684,7 @@ func (r *achievementReferenceRepository) FindForAdvisorPaginated(
 		JOIN users u    ON u.id = s.user_id
 		WHERE ar.student_id = ANY($1)
 		  AND ar.status = 'submitted'
-		ORDER BY ar.submitted_at DESC
+		ORDER BY ar.submitted_at ASC NULLS LAST, ar.id
 		LIMIT $2 OFFSET $3
 	`
 
@@ -459,3 +705,213 @@ func (r *achievementReferenceRepository) FindForAdvisorPaginated(
 
 	return refs, total, nil
 }
+
+// referenceSortColumns memetakan nilai query sort ke kolom yang aman dipakai
+// di ORDER BY.
+var referenceSortColumns = map[string]string{
+	"created_at":   "ar.created_at",
+	"updated_at":   "ar.updated_at",
+	"submitted_at": "ar.submitted_at",
+	"verified_at":  "ar.verified_at",
+	"status":       "ar.status",
+	"student_code": "s.student_id",
+	"student_name": "u.full_name",
+}
+
+// IsReferenceSortField mengembalikan true jika field dapat dipakai untuk sort.
+func IsReferenceSortField(field string) bool {
+	_, ok := referenceSortColumns[field]
+	return ok
+}
+
+// buildReferenceWhere menyusun klausa WHERE untuk Search beserta argumennya.
+func buildReferenceWhere(f models.AchievementFilter) (string, []any) {
+	conds := []string{"ar.status != 'deleted'"}
+	args := []any{}
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if f.StudentIDs != nil {
+		add("ar.student_id = ANY(?)", pq.Array(f.StudentIDs))
+	}
+	if len(f.ExcludeStatuses) > 0 {
+		add("ar.status::text != ALL(?)", pq.Array(f.ExcludeStatuses))
+	}
+	if len(f.Statuses) > 0 {
+		add("ar.status::text = ANY(?)", pq.Array(f.Statuses))
+	}
+	if f.MongoIDs != nil {
+		add("ar.mongo_achievement_id = ANY(?)", pq.Array(f.MongoIDs))
+	}
+	if f.StudentCode != "" {
+		add("s.student_id ILIKE ?", "%"+escapeLike(f.StudentCode)+"%")
+	}
+	if f.StudentName != "" {
+		add("u.full_name ILIKE ?", "%"+escapeLike(f.StudentName)+"%")
+	}
+	if f.ProgramStudy != "" {
+		add("s.program_study ILIKE ?", escapeLike(f.ProgramStudy))
+	}
+	if f.AcademicYear != "" {
+		add("s.academic_year = ?", f.AcademicYear)
+	}
+	if f.SubmittedFrom != nil {
+		add("ar.submitted_at >= ?", *f.SubmittedFrom)
+	}
+	if f.SubmittedTo != nil {
+		add("ar.submitted_at < ?", *f.SubmittedTo)
+	}
+	if f.VerifiedFrom != nil {
+		add("ar.verified_at >= ?", *f.VerifiedFrom)
+	}
+	if f.VerifiedTo != nil {
+		add("ar.verified_at < ?", *f.VerifiedTo)
+	}
+
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// Search mengambil reference prestasi sesuai filter beserta total datanya.
+func (r *achievementReferenceRepository) Search(
+	ctx context.Context,
+	f models.AchievementFilter,
+	limit, offset int,
+) ([]models.AchievementReference, int, error) {
+
+	where, args := buildReferenceWhere(f)
+
+	from := `
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u    ON u.id = s.user_id
+	`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) `+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := referenceSortColumns[f.Sort]
+	if !ok {
+		column = "ar.updated_at"
+	}
+	direction := "DESC"
+	if strings.EqualFold(f.Order, "asc") {
+		direction = "ASC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+			ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+			ar.created_at, ar.updated_at,
+			s.student_id AS student_code,
+			u.full_name  AS student_name
+		%s
+		%s
+		ORDER BY %s %s NULLS LAST, ar.id
+		LIMIT $%d OFFSET $%d
+	`, from, where, column, direction, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	refs := []models.AchievementReference{}
+	for rows.Next() {
+		ref, err := scanAchievementRef(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, total, rows.Err()
+}
+
+func (r *achievementReferenceRepository) CountByStatus(ctx context.Context, f models.AchievementFilter) (map[string]int, error) {
+	where, args := buildReferenceWhere(f)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ar.status::text, COUNT(*)
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u    ON u.id = s.user_id
+		`+where+`
+		GROUP BY ar.status
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *achievementReferenceRepository) EachStudentStat(ctx context.Context, f models.AchievementFilter, fn func(models.StudentStat) error) error {
+	where, args := buildReferenceWhere(f)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			ar.student_id,
+			u.full_name,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE ar.status = 'draft'),
+			COUNT(*) FILTER (WHERE ar.status = 'submitted'),
+			COUNT(*) FILTER (WHERE ar.status = 'verified'),
+			COUNT(*) FILTER (WHERE ar.status = 'rejected'),
+			COUNT(*) FILTER (WHERE ar.status = 'revision')
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u    ON u.id = s.user_id
+		`+where+`
+		GROUP BY ar.student_id, u.full_name
+		ORDER BY u.full_name, ar.student_id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var st models.StudentStat
+		if err := rows.Scan(
+			&st.StudentID,
+			&st.StudentName,
+			&st.Total,
+			&st.Draft,
+			&st.Submitted,
+			&st.Verified,
+			&st.Rejected,
+			&st.Revision,
+		); err != nil {
+			return err
+		}
+		if err := fn(st); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+
+	"github.com/lib/pq"
+)
+
+// ReviewRepository menghitung antrian dan kinerja verifikasi dosen wali dari
+// timestamp achievement_references dan achievement_status_history.
+type ReviewRepository interface {
+	// PendingByStudent mengelompokkan prestasi submitted milik mahasiswa
+	// ids; yang diajukan sebelum breachBefore dihitung melewati SLA.
+	PendingByStudent(ctx context.Context, ids []string, breachBefore time.Time) ([]models.AdviseeQueueStat, error)
+	// Metrics menghitung antrian saat ini dan review pada rentang
+	// [from, to) untuk seluruh dosen. from/to nil berarti tidak dibatasi.
+	Metrics(ctx context.Context, from, to *time.Time, breachBefore time.Time) ([]models.ReviewerMetrics, error)
+}
+
+type reviewRepository struct {
+	DB *sql.DB
+}
+
+func NewReviewRepo(db *sql.DB) ReviewRepository {
+	return &reviewRepository{DB: db}
+}
+
+func (r *reviewRepository) PendingByStudent(ctx context.Context, ids []string, breachBefore time.Time) ([]models.AdviseeQueueStat, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT
+			s.id, s.student_id, u.full_name,
+			COUNT(*) AS pending,
+			COUNT(*) FILTER (WHERE ar.submitted_at < $2) AS breached,
+			MIN(ar.submitted_at) AS oldest
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u    ON u.id = s.user_id
+		WHERE ar.student_id = ANY($1)
+		  AND ar.status = 'submitted'
+		GROUP BY s.id, s.student_id, u.full_name
+		ORDER BY oldest ASC NULLS LAST, s.student_id
+	`, pq.Array(ids), breachBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AdviseeQueueStat{}
+	for rows.Next() {
+		var st models.AdviseeQueueStat
+		if err := rows.Scan(&st.StudentID, &st.StudentCode, &st.StudentName, &st.Pending, &st.Breached, &st.OldestAt); err != nil {
+			return nil, err
+		}
+		list = append(list, st)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *reviewRepository) Metrics(ctx context.Context, from, to *time.Time, breachBefore time.Time) ([]models.ReviewerMetrics, error) {
+	// waktu review dihitung dari pengajuan terakhir sebelum keputusan,
+	// sehingga pengajuan ulang setelah revisi tidak ikut terhitung
+	rows, err := r.DB.QueryContext(ctx, `
+		WITH reviews AS (
+			SELECT
+				l.id AS lecturer_id,
+				COUNT(*) AS reviewed,
+				COUNT(*) FILTER (WHERE h.to_status = 'verified') AS approved,
+				COUNT(*) FILTER (WHERE h.to_status = 'rejected') AS rejected,
+				AVG(EXTRACT(EPOCH FROM h.created_at - sub.created_at)) / 3600 AS avg_hours
+			FROM achievement_status_history h
+			JOIN lecturers l ON l.user_id = h.actor_id
+			LEFT JOIN LATERAL (
+				SELECT p.created_at
+				FROM achievement_status_history p
+				WHERE p.achievement_ref_id = h.achievement_ref_id
+				  AND p.to_status = 'submitted'
+				  AND p.created_at <= h.created_at
+				ORDER BY p.created_at DESC
+				LIMIT 1
+			) sub ON TRUE
+			WHERE h.from_status = 'submitted'
+			  AND h.to_status IN ('verified', 'rejected')
+			  AND ($1::timestamp IS NULL OR h.created_at >= $1)
+			  AND ($2::timestamp IS NULL OR h.created_at < $2)
+			GROUP BY l.id
+		),
+		pending AS (
+			SELECT
+				s.advisor_id,
+				COUNT(*) AS pending,
+				COUNT(*) FILTER (WHERE ar.submitted_at < $3) AS breached,
+				MIN(ar.submitted_at) AS oldest
+			FROM achievement_references ar
+			JOIN students s ON s.id = ar.student_id
+			WHERE ar.status = 'submitted'
+			GROUP BY s.advisor_id
+		)
+		SELECT
+			l.id, l.lecturer_id, COALESCE(u.full_name, ''),
+			COALESCE(p.pending, 0), COALESCE(p.breached, 0), p.oldest,
+			COALESCE(rv.reviewed, 0), COALESCE(rv.approved, 0), COALESCE(rv.rejected, 0),
+			rv.avg_hours
+		FROM lecturers l
+		LEFT JOIN users u    ON u.id = l.user_id
+		LEFT JOIN pending p  ON p.advisor_id = l.id
+		LEFT JOIN reviews rv ON rv.lecturer_id = l.id
+		ORDER BY u.full_name, l.lecturer_id
+	`, from, to, breachBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ReviewerMetrics{}
+	for rows.Next() {
+		var m models.ReviewerMetrics
+		var avg sql.NullFloat64
+		if err := rows.Scan(
+			&m.LecturerID, &m.LecturerCode, &m.Name,
+			&m.Pending, &m.Breached, &m.OldestAt,
+			&m.Reviewed, &m.Approved, &m.Rejected,
+			&avg,
+		); err != nil {
+			return nil, err
+		}
+		if avg.Valid {
+			m.AvgReviewHours = &avg.Float64
+		}
+		if decided := m.Approved + m.Rejected; decided > 0 {
+			rate := float64(m.Approved) / float64(decided)
+			m.ApprovalRate = &rate
+		}
+		list = append(list, m)
+	}
+
+	return list, rows.Err()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas/app/models"
+)
+
+type ReviewSnapshotRepository interface {
+	Create(ctx context.Context, snap *models.AchievementReviewSnapshot) error
+	Latest(ctx context.Context, refID string) (*models.AchievementReviewSnapshot, error)
+}
+
+type reviewSnapshotRepository struct {
+	DB *sql.DB
+}
+
+func NewReviewSnapshotRepo(db *sql.DB) ReviewSnapshotRepository {
+	return &reviewSnapshotRepository{DB: db}
+}
+
+func (r *reviewSnapshotRepository) Create(ctx context.Context, snap *models.AchievementReviewSnapshot) error {
+	return insertSnapshot(ctx, r.DB, snap)
+}
+
+// rowQuerier dipenuhi *sql.DB maupun *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertSnapshot menulis snap lewat q sehingga dapat ikut transaksi lain,
+// mis. transisi reject pada TransitionWithSnapshot.
+func insertSnapshot(ctx context.Context, q rowQuerier, snap *models.AchievementReviewSnapshot) error {
+	content, err := json.Marshal(snap.Content)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRowContext(ctx, `
+		INSERT INTO achievement_review_snapshots (achievement_ref_id, reviewed_by, content)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, snap.AchievementRefID, snap.ReviewedBy, content).Scan(&snap.ID, &snap.CreatedAt)
+}
+
+// Latest mengembalikan snapshot review terakhir, nil jika belum pernah direview.
+func (r *reviewSnapshotRepository) Latest(ctx context.Context, refID string) (*models.AchievementReviewSnapshot, error) {
+	var (
+		snap    models.AchievementReviewSnapshot
+		content []byte
+	)
+
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, achievement_ref_id, reviewed_by, content, created_at
+		FROM achievement_review_snapshots
+		WHERE achievement_ref_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, refID).Scan(&snap.ID, &snap.AchievementRefID, &snap.ReviewedBy, &content, &snap.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &snap.Content); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
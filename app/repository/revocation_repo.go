@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// TokenRevocationRepository menyimpan jti token yang sudah dicabut (logout)
+// sampai waktu expired token tersebut.
+type TokenRevocationRepository interface {
+	Revoke(ctx context.Context, jti string, userID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type tokenRevocationRepository struct {
+	DB *sql.DB
+}
+
+func NewTokenRevocationRepo(db *sql.DB) TokenRevocationRepository {
+	return &tokenRevocationRepository{DB: db}
+}
+
+func (r *tokenRevocationRepository) Revoke(ctx context.Context, jti string, userID string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO revoked_tokens (jti, user_id, expires_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (jti) DO NOTHING
+	`
+
+	var uid sql.NullString
+	if userID != "" {
+		uid = sql.NullString{String: userID, Valid: true}
+	}
+
+	_, err := r.DB.ExecContext(ctx, query, jti, uid, expiresAt)
+	return err
+}
+
+func (r *tokenRevocationRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM revoked_tokens
+		WHERE jti = $1 AND expires_at > NOW()
+	)
+	`
+
+	var revoked bool
+	err := r.DB.QueryRowContext(ctx, query, jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *tokenRevocationRepository) PurgeExpired(ctx context.Context) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// memoryRevocationRepository dipakai untuk unit test / single instance.
+type memoryRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+}
+
+func NewMemoryRevocationRepo() TokenRevocationRepository {
+	return &memoryRevocationRepository{tokens: make(map[string]time.Time)}
+}
+
+func (r *memoryRevocationRepository) Revoke(ctx context.Context, jti string, userID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r *memoryRevocationRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	exp, ok := r.tokens[jti]
+	return ok && exp.After(time.Now()), nil
+}
+
+func (r *memoryRevocationRepository) PurgeExpired(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	now := time.Now()
+	for jti, exp := range r.tokens {
+		if !exp.After(now) {
+			delete(r.tokens, jti)
+			n++
+		}
+	}
+	return n, nil
+}
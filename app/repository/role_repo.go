@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/lib/pq"
+)
+
+type RoleRepository interface {
+	FindAll(ctx context.Context) ([]models.Role, error)
+	GetByID(ctx context.Context, id string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id string) error
+	CountUsers(ctx context.Context, id string) (int, error)
+	// CountRolesWithPermission menghitung role selain exceptRoleID yang
+	// memegang salah satu permission bernama names.
+	CountRolesWithPermission(ctx context.Context, names []string, exceptRoleID string) (int, error)
+
+	ListPermissions(ctx context.Context, roleID string) ([]models.Permission, error)
+	SetPermissions(ctx context.Context, roleID string, permissionIDs []string) error
+	AddPermission(ctx context.Context, roleID string, permissionID string) error
+	RemovePermission(ctx context.Context, roleID string, permissionID string) (bool, error)
+
+	GetAccess(ctx context.Context, userID string) (*models.UserAccess, error)
+}
+
+type roleRepository struct {
+	DB *sql.DB
+}
+
+func NewRoleRepo(db *sql.DB) RoleRepository {
+	return &roleRepository{DB: db}
+}
+
+func scanRole(row interface{ Scan(...any) error }) (*models.Role, error) {
+	var r models.Role
+	err := row.Scan(
+		&r.ID,
+		&r.Name,
+		&r.Description,
+		&r.ProfileType,
+		&r.MFARequired,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *roleRepository) FindAll(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), profile_type, mfa_required, created_at
+		FROM roles
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *role)
+	}
+
+	return list, nil
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id string) (*models.Role, error) {
+	role, err := scanRole(r.DB.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), profile_type, mfa_required, created_at
+		FROM roles
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return role, err
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO roles (name, description, profile_type, mfa_required)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`,
+		role.Name,
+		role.Description,
+		role.ProfileType,
+		role.MFARequired,
+	).Scan(&role.ID, &role.CreatedAt)
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE roles
+		SET name = $2, description = $3, profile_type = $4, mfa_required = $5
+		WHERE id = $1
+	`,
+		role.ID,
+		role.Name,
+		role.Description,
+		role.ProfileType,
+		role.MFARequired,
+	)
+	return err
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	return err
+}
+
+func (r *roleRepository) CountUsers(ctx context.Context, id string) (int, error) {
+	var n int
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role_id = $1`, id).Scan(&n)
+	return n, err
+}
+
+func (r *roleRepository) CountRolesWithPermission(ctx context.Context, names []string, exceptRoleID string) (int, error) {
+	var n int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT rp.role_id)
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.name = ANY($1) AND rp.role_id <> $2
+	`, pq.Array(names), exceptRoleID).Scan(&n)
+	return n, err
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context, roleID string) ([]models.Permission, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.name, p.resource, p.action, COALESCE(p.description, '')
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.name ASC
+	`, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	return list, nil
+}
+
+// SetPermissions mengganti seluruh permission role dalam satu transaksi.
+func (r *roleRepository) SetPermissions(ctx context.Context, roleID string, permissionIDs []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, pid := range permissionIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO role_permissions (role_id, permission_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, roleID, pid); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepository) AddPermission(ctx context.Context, roleID string, permissionID string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, roleID, permissionID)
+	return err
+}
+
+func (r *roleRepository) RemovePermission(ctx context.Context, roleID string, permissionID string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND permission_id = $2
+	`, roleID, permissionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *roleRepository) GetAccess(ctx context.Context, userID string) (*models.UserAccess, error) {
+	var a models.UserAccess
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT r.name, u.is_active
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE u.id = $1
+	`, userID).Scan(&a.RoleName, &a.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.name
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN users u ON u.role_id = rp.role_id
+		WHERE u.id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		a.Permissions = append(a.Permissions, p)
+	}
+
+	return &a, nil
+}
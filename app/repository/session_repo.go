@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	ListActiveByUserID(ctx context.Context, userID string) ([]models.Session, error)
+	IsActive(ctx context.Context, id string) (bool, error)
+	Revoke(ctx context.Context, id string, reason string) error
+	RevokeAllByUserID(ctx context.Context, userID string, reason string, exceptID string) error
+
+	GetRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error)
+	Rotate(ctx context.Context, oldJTI string, next *models.RefreshToken) (bool, error)
+	PurgeExpired(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type sessionRepository struct {
+	DB *sql.DB
+}
+
+func NewSessionRepo(db *sql.DB) SessionRepository {
+	return &sessionRepository{DB: db}
+}
+
+func scanSession(row interface{ Scan(...any) error }) (*models.Session, error) {
+	var s models.Session
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.UserAgent,
+		&s.IPAddress,
+		&s.CreatedAt,
+		&s.LastUsedAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+		&s.RevokeReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Create menyimpan session baru beserta refresh token pertamanya.
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, last_used_at
+	`,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+	).Scan(&session.CreatedAt, &session.LastUsedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (jti, session_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, token.JTI, token.SessionID, token.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+		       created_at, last_used_at, expires_at, revoked_at, revoke_reason
+		FROM sessions
+		WHERE id = $1
+	`
+
+	s, err := scanSession(r.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *sessionRepository) ListActiveByUserID(ctx context.Context, userID string) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+		       created_at, last_used_at, expires_at, revoked_at, revoke_reason
+		FROM sessions
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+
+	return list, nil
+}
+
+func (r *sessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)
+	`
+
+	var active bool
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&active)
+	return active, err
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id string, reason string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, reason)
+	return err
+}
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID string, reason string, exceptID string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $2
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND ($3 = '' OR id::text <> $3)
+	`, userID, reason, exceptID)
+	return err
+}
+
+func (r *sessionRepository) GetRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	query := `
+		SELECT jti, session_id, issued_at, expires_at, used_at, replaced_by
+		FROM refresh_tokens
+		WHERE jti = $1
+	`
+
+	var t models.RefreshToken
+	err := r.DB.QueryRowContext(ctx, query, jti).Scan(
+		&t.JTI,
+		&t.SessionID,
+		&t.IssuedAt,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.ReplacedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Rotate menandai refresh token lama sebagai terpakai dan menyimpan penggantinya.
+// Mengembalikan false jika token lama sudah pernah dipakai (indikasi reuse).
+func (r *sessionRepository) Rotate(ctx context.Context, oldJTI string, next *models.RefreshToken) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = NOW(), replaced_by = $2
+		WHERE jti = $1 AND used_at IS NULL
+	`, oldJTI, next.JTI)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (jti, session_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, next.JTI, next.SessionID, next.ExpiresAt); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET last_used_at = NOW(), expires_at = $2
+		WHERE id = $1
+	`, next.SessionID, next.ExpiresAt); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// PurgeExpired menghapus session (beserta refresh token-nya) yang sudah
+// expired atau dicabut lebih lama dari retention.
+func (r *sessionRepository) PurgeExpired(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	res, err := r.DB.ExecContext(ctx, `
+		DELETE FROM sessions
+		WHERE expires_at < $1
+		   OR (revoked_at IS NOT NULL AND revoked_at < $1)
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
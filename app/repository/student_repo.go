@@ -5,14 +5,26 @@ import (
 	"uas/app/models"
 
 	"context"
+
+	"github.com/lib/pq"
 )
 
 type StudentRepository interface {
     Create(tx *sql.Tx, userID string, studentID string) error
+	// CreateProfile membuat profil mahasiswa lengkap untuk userID; ID profil
+	// baru diisi ke s.ID.
+	CreateProfile(tx *sql.Tx, userID string, s *models.Student) error
+	// FindTakenStudentIDs mengembalikan NIM di antara ids yang sudah terdaftar.
+	FindTakenStudentIDs(ctx context.Context, ids []string) (map[string]bool, error)
     DeleteByUserID(tx *sql.Tx, userID string) error
     RemoveAdvisor(tx *sql.Tx, lecturerID string) error
     GetByUserID(ctx context.Context, userID string) (*models.Student, error)
 	UpdateAdvisor(tx *sql.Tx, studentID string, advisorID *string) error
+	// UpdateProfile menyimpan NIM, prodi, angkatan dan kontak s berdasarkan s.ID.
+	UpdateProfile(ctx context.Context, s *models.Student) error
+	// UpdateProfileWithOutbox sama dengan UpdateProfile, ditambah menulis msg
+	// ke mongo_outbox dalam transaksi yang sama.
+	UpdateProfileWithOutbox(ctx context.Context, s *models.Student, msg *models.OutboxMessage) error
 	GetIDByIndex(idx int) (string, error)
 	FindAll(ctx context.Context) ([]models.Student, error)
     FindByID(ctx context.Context, id string) (*models.Student, error)
@@ -38,6 +50,36 @@ func (r *studentRepository) Create(tx *sql.Tx, userID string, studentID string)
 	return err
 }
 
+func (r *studentRepository) CreateProfile(tx *sql.Tx, userID string, s *models.Student) error {
+	query := `
+	INSERT INTO students (user_id, student_id, program_study, academic_year, advisor_id)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at;
+	`
+
+	s.UserID = userID
+	return tx.QueryRow(query, userID, s.StudentID, s.ProgramStudy, s.AcademicYear, s.AdvisorID).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *studentRepository) FindTakenStudentIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT student_id FROM students WHERE student_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		taken[id] = true
+	}
+
+	return taken, rows.Err()
+}
+
 func (r *studentRepository) DeleteByUserID(tx *sql.Tx, userID string) error {
 	_, err := tx.Exec(`DELETE FROM students WHERE user_id=$1`, userID)
 	return err
@@ -55,7 +97,7 @@ func (r *studentRepository) RemoveAdvisor(tx *sql.Tx, lecturerID string) error {
 
 func (r *studentRepository) GetByUserID(ctx context.Context, userID string) (*models.Student, error) {
 	query := `
-	SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+	SELECT id, user_id, student_id, program_study, academic_year, advisor_id, phone, address, created_at
 	FROM students
 	WHERE user_id = $1
 	LIMIT 1;
@@ -69,6 +111,8 @@ func (r *studentRepository) GetByUserID(ctx context.Context, userID string) (*mo
 		&s.ProgramStudy,
 		&s.AcademicYear,
 		&s.AdvisorID,
+		&s.Phone,
+		&s.Address,
 		&s.CreatedAt,
 	)
 
@@ -89,6 +133,37 @@ func (r *studentRepository) UpdateAdvisor(tx *sql.Tx, studentID string, advisorI
     return err
 }
 
+func (r *studentRepository) UpdateProfile(ctx context.Context, s *models.Student) error {
+	return r.UpdateProfileWithOutbox(ctx, s, nil)
+}
+
+func (r *studentRepository) UpdateProfileWithOutbox(ctx context.Context, s *models.Student, msg *models.OutboxMessage) error {
+	query := `
+	UPDATE students
+	SET student_id = $1, program_study = $2, academic_year = $3, phone = $4, address = $5
+	WHERE id = $6;
+	`
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, s.StudentID, s.ProgramStudy, s.AcademicYear, s.Phone, s.Address, s.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if msg != nil {
+		if err := insertOutbox(ctx, tx, msg); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *studentRepository) GetIDByIndex(idx int) (string, error) {
     query := `
         SELECT id
@@ -108,7 +183,7 @@ func (r *studentRepository) GetIDByIndex(idx int) (string, error) {
 
 func (r *studentRepository) GetByStudentID(studentID string) (*models.Student, error) {
     query := `
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, phone, address, created_at
         FROM students
         WHERE id = $1
         LIMIT 1;
@@ -122,6 +197,8 @@ func (r *studentRepository) GetByStudentID(studentID string) (*models.Student, e
         &s.ProgramStudy,
         &s.AcademicYear,
         &s.AdvisorID,
+        &s.Phone,
+        &s.Address,
         &s.CreatedAt,
     )
 
@@ -134,7 +211,7 @@ func (r *studentRepository) GetByStudentID(studentID string) (*models.Student, e
 
 func (r *studentRepository) FindAll(ctx context.Context) ([]models.Student, error) {
     query := `
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, phone, address, created_at
         FROM students
         ORDER BY created_at ASC
     `
@@ -151,7 +228,7 @@ func (r *studentRepository) FindAll(ctx context.Context) ([]models.Student, erro
         if err := rows.Scan(
             &st.ID, &st.UserID, &st.StudentID,
             &st.ProgramStudy, &st.AcademicYear,
-            &st.AdvisorID, &st.CreatedAt,
+            &st.AdvisorID, &st.Phone, &st.Address, &st.CreatedAt,
         ); err != nil {
             return nil, err
         }
@@ -163,7 +240,7 @@ func (r *studentRepository) FindAll(ctx context.Context) ([]models.Student, erro
 
 func (r *studentRepository) FindByID(ctx context.Context, id string) (*models.Student, error) {
     query := `
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, phone, address, created_at
         FROM students
         WHERE id = $1
         LIMIT 1
@@ -173,7 +250,7 @@ func (r *studentRepository) FindByID(ctx context.Context, id string) (*models.St
     err := r.DB.QueryRowContext(ctx, query, id).Scan(
         &st.ID, &st.UserID, &st.StudentID,
         &st.ProgramStudy, &st.AcademicYear,
-        &st.AdvisorID, &st.CreatedAt,
+        &st.AdvisorID, &st.Phone, &st.Address, &st.CreatedAt,
     )
 
     if err != nil {
@@ -185,7 +262,7 @@ func (r *studentRepository) FindByID(ctx context.Context, id string) (*models.St
 
 func (r *studentRepository) FindByAdvisorID(ctx context.Context, advisorID string) ([]models.Student, error) {
     query := `
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, phone, address, created_at
         FROM students
         WHERE advisor_id = $1
     `
@@ -203,7 +280,7 @@ func (r *studentRepository) FindByAdvisorID(ctx context.Context, advisorID strin
         if err := rows.Scan(
             &s.ID, &s.UserID, &s.StudentID,
             &s.ProgramStudy, &s.AcademicYear,
-            &s.AdvisorID, &s.CreatedAt,
+            &s.AdvisorID, &s.Phone, &s.Address, &s.CreatedAt,
         ); err != nil {
             return nil, err
         }
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"uas/app/models"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
@@ -13,6 +17,9 @@ type UserRepository interface {
     UpdateRole(tx *sql.Tx, userID string, roleID string) error
 	Delete(tx *sql.Tx, id string) error
 	GetIDByIndex(idx int) (string, error)
+	// FindTaken mengembalikan username dan email (huruf kecil) yang sudah
+	// dipakai di antara usernames dan emails.
+	FindTaken(ctx context.Context, usernames, emails []string) (takenUsernames, takenEmails map[string]bool, err error)
 }
 
 type userRepository struct {
@@ -157,3 +164,39 @@ func (r *userRepository) GetIDByIndex(idx int) (string, error) {
     return id, nil
 }
 
+func (r *userRepository) FindTaken(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error) {
+	lowered := make([]string, len(emails))
+	for i, e := range emails {
+		lowered[i] = strings.ToLower(e)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT username, LOWER(email)
+		FROM users
+		WHERE username = ANY($1) OR LOWER(email) = ANY($2)
+	`, pq.Array(usernames), pq.Array(lowered))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	wanted := map[string]bool{}
+	for _, u := range usernames {
+		wanted[u] = true
+	}
+
+	takenUsernames := map[string]bool{}
+	takenEmails := map[string]bool{}
+	for rows.Next() {
+		var username, email string
+		if err := rows.Scan(&username, &email); err != nil {
+			return nil, nil, err
+		}
+		if wanted[username] {
+			takenUsernames[username] = true
+		}
+		takenEmails[email] = true
+	}
+
+	return takenUsernames, takenEmails, rows.Err()
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"uas/app/models"
+
+	"github.com/lib/pq"
+)
+
+// WebhookRepository menyimpan langganan webhook dan log pengirimannya.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	// GetSubscription mengembalikan nil tanpa error jika tidak ditemukan.
+	GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	// ActiveSubscriptionsFor mengambil langganan aktif untuk eventType.
+	ActiveSubscriptionsFor(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+
+	CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error
+	// GetDelivery mengembalikan nil tanpa error jika tidak ditemukan.
+	GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	// ClaimDue mengambil pengiriman pending yang sudah jatuh tempo dan
+	// menundanya selama lease agar tidak diambil replika lain.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	// SaveAttempt menyimpan hasil percobaan pengiriman d.
+	SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	DB *sql.DB
+}
+
+func NewWebhookRepo(db *sql.DB) WebhookRepository {
+	return &webhookRepository{DB: db}
+}
+
+const subscriptionColumns = `id, url, secret, events, description, is_active, created_by, created_at, updated_at`
+
+func scanSubscription(scan func(dest ...any) error) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := scan(&sub.ID, &sub.URL, &sub.Secret, pq.Array(&sub.Events), &sub.Description, &sub.IsActive, &sub.CreatedBy, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (url, secret, events, description, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, sub.URL, sub.Secret, pq.Array(sub.Events), sub.Description, sub.IsActive, sub.CreatedBy).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.DB.QueryRowContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2, secret = $3, events = $4, description = $5, is_active = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, sub.ID, sub.URL, sub.Secret, pq.Array(sub.Events), sub.Description, sub.IsActive).Scan(&sub.UpdatedAt)
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	return err
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id)
+
+	sub, err := scanSubscription(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return r.querySubscriptions(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions ORDER BY created_at`)
+}
+
+func (r *webhookRepository) ActiveSubscriptionsFor(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	return r.querySubscriptions(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE is_active AND $1 = ANY(events)
+		ORDER BY created_at
+	`, eventType)
+}
+
+func (r *webhookRepository) querySubscriptions(ctx context.Context, query string, args ...any) ([]models.WebhookSubscription, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *sub)
+	}
+
+	return list, rows.Err()
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, response_code, response_body, last_error, next_attempt_at, delivered_at, created_at`
+
+func scanDelivery(scan func(dest ...any) error) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	err := scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseCode, &d.ResponseBody, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	return r.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, d.SubscriptionID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.NextAttemptAt).Scan(&d.ID, &d.CreatedAt)
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+
+	d, err := scanDelivery(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	var total int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1
+	`, subscriptionID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows.Scan)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *d)
+	}
+
+	return list, total, rows.Err()
+}
+
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *webhookRepository) SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_code = $4, response_body = $5,
+		    last_error = $6, next_attempt_at = $7, delivered_at = $8
+		WHERE id = $1
+	`, d.ID, d.Status, d.Attempts, d.ResponseCode, d.ResponseBody, d.LastError, d.NextAttemptAt, d.DeliveredAt)
+	return err
+}
@@ -0,0 +1,240 @@
+// Package schema memvalidasi details prestasi terhadap spesifikasi field
+// milik tipe prestasi (models.AchievementType) di registry.
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"uas/app/models"
+)
+
+// DateLayout adalah format tanggal yang disimpan untuk field bertipe date.
+const DateLayout = "2006-01-02"
+
+var (
+	codePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	namePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,49}$`)
+)
+
+// Validate memeriksa details terhadap field spec tipe t. Mengembalikan
+// details yang sudah dinormalisasi (integer, tanggal) dan error per field
+// dengan key "details.<field>". Key yang tidak dikenal ditolak.
+func Validate(t *models.AchievementType, details map[string]interface{}) (map[string]interface{}, map[string]string) {
+	clean := map[string]interface{}{}
+	errs := map[string]string{}
+
+	specs := map[string]models.FieldSpec{}
+	for _, f := range t.Fields {
+		specs[f.Name] = f
+	}
+
+	for k := range details {
+		if _, ok := specs[k]; !ok {
+			errs["details."+k] = "field tidak dikenal untuk tipe " + t.Code
+		}
+	}
+
+	for _, f := range t.Fields {
+		key := "details." + f.Name
+
+		v, ok := details[f.Name]
+		if !ok || v == nil || v == "" {
+			if f.Required {
+				errs[key] = "wajib diisi"
+			}
+			continue
+		}
+
+		norm, msg := checkValue(f, v)
+		if msg != "" {
+			errs[key] = msg
+			continue
+		}
+		clean[f.Name] = norm
+	}
+
+	return clean, errs
+}
+
+func checkValue(f models.FieldSpec, v interface{}) (interface{}, string) {
+	norm, msg := checkType(f.Type, f.Items, v)
+	if msg != "" {
+		return nil, msg
+	}
+
+	if len(f.Enum) > 0 {
+		s := fmt.Sprint(norm)
+		found := false
+		for _, e := range f.Enum {
+			if strings.EqualFold(e, s) {
+				norm, found = e, true
+				break
+			}
+		}
+		if !found {
+			return nil, "harus salah satu dari: " + strings.Join(f.Enum, ", ")
+		}
+	}
+
+	if n, ok := toFloat(norm); ok && (f.Type == models.FieldInteger || f.Type == models.FieldNumber) {
+		if f.Min != nil && n < *f.Min {
+			return nil, fmt.Sprintf("minimal %v", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return nil, fmt.Sprintf("maksimal %v", *f.Max)
+		}
+	}
+
+	if f.Pattern != "" {
+		s, _ := norm.(string)
+		if ok, _ := regexp.MatchString(f.Pattern, s); !ok {
+			return nil, "format tidak valid"
+		}
+	}
+
+	return norm, ""
+}
+
+func checkType(typ, items string, v interface{}) (interface{}, string) {
+	switch typ {
+	case models.FieldString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, "harus berupa teks"
+		}
+		return strings.TrimSpace(s), ""
+
+	case models.FieldInteger:
+		n, ok := toFloat(v)
+		if !ok || n != math.Trunc(n) {
+			return nil, "harus berupa bilangan bulat"
+		}
+		return int(n), ""
+
+	case models.FieldNumber:
+		n, ok := toFloat(v)
+		if !ok {
+			return nil, "harus berupa angka"
+		}
+		return n, ""
+
+	case models.FieldBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, "harus berupa boolean"
+		}
+		return b, ""
+
+	case models.FieldDate:
+		s, ok := v.(string)
+		if !ok {
+			return nil, "harus berupa tanggal (YYYY-MM-DD)"
+		}
+		if d, err := time.Parse(DateLayout, s); err == nil {
+			return d.Format(DateLayout), ""
+		}
+		if d, err := time.Parse(time.RFC3339, s); err == nil {
+			return d.Format(DateLayout), ""
+		}
+		return nil, "harus berupa tanggal (YYYY-MM-DD)"
+
+	case models.FieldArray:
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, "harus berupa array"
+		}
+		if items == "" {
+			return list, ""
+		}
+		out := make([]interface{}, 0, len(list))
+		for i, item := range list {
+			norm, msg := checkType(items, "", item)
+			if msg != "" {
+				return nil, fmt.Sprintf("elemen ke-%d %s", i, msg)
+			}
+			out = append(out, norm)
+		}
+		return out, ""
+
+	case models.FieldObject:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, "harus berupa object"
+		}
+		return obj, ""
+	}
+
+	return nil, "tipe field tidak didukung"
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// ValidateType memeriksa definisi tipe prestasi dari admin.
+func ValidateType(req *models.AchievementTypeRequest) map[string]string {
+	errs := map[string]string{}
+
+	req.Code = strings.ToLower(strings.TrimSpace(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if !codePattern.MatchString(req.Code) {
+		errs["code"] = "code harus huruf kecil, angka atau underscore (2-50 karakter)"
+	}
+	if req.Name == "" {
+		errs["name"] = "Nama tipe wajib diisi"
+	}
+
+	seen := map[string]bool{}
+	for i, f := range req.Fields {
+		key := fmt.Sprintf("fields[%d]", i)
+
+		switch {
+		case !namePattern.MatchString(f.Name):
+			errs[key] = "name field tidak valid"
+		case seen[f.Name]:
+			errs[key] = "name field duplikat"
+		case !validType(f.Type):
+			errs[key] = "type harus string, integer, number, boolean, date, array atau object"
+		case f.Type == models.FieldArray && f.Items != "" && (!validType(f.Items) || f.Items == models.FieldArray):
+			errs[key] = "items tidak valid"
+		case f.Min != nil && f.Max != nil && *f.Min > *f.Max:
+			errs[key] = "min tidak boleh lebih besar dari max"
+		}
+
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				errs[key] = "pattern bukan regex yang valid"
+			}
+		}
+
+		seen[f.Name] = true
+	}
+
+	return errs
+}
+
+func validType(t string) bool {
+	switch t {
+	case models.FieldString, models.FieldInteger, models.FieldNumber, models.FieldBoolean,
+		models.FieldDate, models.FieldArray, models.FieldObject:
+		return true
+	}
+	return false
+}
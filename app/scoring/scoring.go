@@ -0,0 +1,77 @@
+// Package scoring menghitung poin prestasi dari tabel aturan poin
+// (models.PointRuleSet) berdasarkan achievement_type dan details.
+package scoring
+
+import (
+	"fmt"
+	"strings"
+
+	"uas/app/models"
+)
+
+// Score menjumlahkan poin dasar dan seluruh aturan field yang cocok untuk
+// prestasi a. Perbandingan value tidak membedakan huruf besar/kecil.
+func Score(rules []models.PointRule, a *models.AchievementMongo) int {
+	total := 0
+
+	for _, r := range rules {
+		if r.AchievementType != a.AchievementType {
+			continue
+		}
+
+		if r.Field == "" {
+			total += r.Points
+			continue
+		}
+
+		v, ok := a.Details[r.Field]
+		if !ok || v == nil {
+			continue
+		}
+
+		if strings.EqualFold(strings.TrimSpace(fmt.Sprint(v)), strings.TrimSpace(r.Value)) {
+			total += r.Points
+		}
+	}
+
+	return total
+}
+
+// Apply menghitung ulang poin a dengan set aturan dan mencatat versinya.
+func Apply(set *models.PointRuleSet, a *models.AchievementMongo) {
+	a.Points = Score(set.Rules, a)
+	a.PointsVersion = set.Version
+}
+
+// Validate memeriksa isi tabel aturan dan mengembalikan error per baris
+// (key "rules[i]").
+func Validate(rules []models.PointRule) map[string]string {
+	errs := map[string]string{}
+
+	if len(rules) == 0 {
+		errs["rules"] = "minimal satu aturan"
+		return errs
+	}
+
+	seen := map[string]bool{}
+	for i, r := range rules {
+		key := fmt.Sprintf("rules[%d]", i)
+
+		switch {
+		case strings.TrimSpace(r.AchievementType) == "":
+			errs[key] = "achievement_type wajib diisi"
+		case r.Field == "" && r.Value != "":
+			errs[key] = "value hanya boleh diisi bersama field"
+		case r.Field != "" && strings.TrimSpace(r.Value) == "":
+			errs[key] = "value wajib diisi jika field diisi"
+		}
+
+		id := strings.ToLower(r.AchievementType + "|" + r.Field + "|" + r.Value)
+		if _, ok := errs[key]; !ok && seen[id] {
+			errs[key] = "aturan duplikat"
+		}
+		seen[id] = true
+	}
+
+	return errs
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+)
+
+// listFields adalah field Mongo yang dibutuhkan endpoint list.
+var listFields = []string{"title", "achievementType", "points", "updatedAt"}
+
+// maxFilterIDs membatasi jumlah ID hasil filter MongoDB yang diteruskan ke
+// PostgreSQL. Filter yang lebih luas harus dipersempit oleh pemanggil.
+const maxFilterIDs = 2000
+
+// stitchAchievements mengambil dokumen Mongo untuk seluruh refs dengan satu
+// query lalu mengembalikannya sejajar dengan urutan refs. Reference yang
+// dokumennya hilang (dangling) bernilai nil pada hasil dan ID-nya dikembalikan
+// agar dapat dilaporkan ke client.
+func stitchAchievements(
+	ctx context.Context,
+	mongoRepo repository.AchievementMongoRepository,
+	refs []models.AchievementReference,
+	fields ...string,
+) ([]*models.AchievementMongo, []string, error) {
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.MongoAchievementID)
+	}
+
+	docs, err := mongoRepo.FindByIDs(ctx, ids, fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]*models.AchievementMongo, len(refs))
+	dangling := []string{}
+
+	for i, ref := range refs {
+		if doc, ok := docs[ref.MongoAchievementID]; ok {
+			out[i] = doc
+			continue
+		}
+		dangling = append(dangling, ref.ID)
+	}
+
+	if len(dangling) > 0 {
+		helper.Log.Warn().Strs("reference_ids", dangling).Msg("reference prestasi tanpa dokumen mongo")
+	}
+
+	return out, dangling, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var filterableStatuses = map[string]bool{
+	utils.AchievementStatusDraft:     true,
+	utils.AchievementStatusSubmitted: true,
+	utils.AchievementStatusVerified:  true,
+	utils.AchievementStatusRejected:  true,
+	utils.AchievementStatusRevision:  true,
+}
+
+// splitQuery memecah query parameter berformat "a,b,c".
+func splitQuery(raw string) []string {
+	out := []string{}
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseDateRange membaca query <name>_from dan <name>_to (YYYY-MM-DD).
+// Batas akhir dikembalikan sebagai awal hari berikutnya (eksklusif).
+func parseDateRange(c *fiber.Ctx, name string, errs map[string]string) (*time.Time, *time.Time) {
+	var from, to *time.Time
+
+	if raw := c.Query(name + "_from"); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			errs[name+"_from"] = "format tanggal harus YYYY-MM-DD"
+		} else {
+			from = &d
+		}
+	}
+
+	if raw := c.Query(name + "_to"); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			errs[name+"_to"] = "format tanggal harus YYYY-MM-DD"
+		} else {
+			d = d.AddDate(0, 0, 1)
+			to = &d
+		}
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		errs[name+"_to"] = "tanggal akhir tidak boleh sebelum tanggal awal"
+	}
+
+	return from, to
+}
+
+// parseAchievementFilter membaca query parameter list prestasi.
+func parseAchievementFilter(c *fiber.Ctx) (models.AchievementFilter, map[string]string) {
+	errs := map[string]string{}
+
+	f := models.AchievementFilter{
+		StudentCode:     strings.TrimSpace(c.Query("student_code")),
+		StudentName:     strings.TrimSpace(c.Query("student_name")),
+		ProgramStudy:    strings.TrimSpace(c.Query("program_study")),
+		AcademicYear:    strings.TrimSpace(c.Query("academic_year")),
+		AchievementType: strings.TrimSpace(c.Query("achievement_type")),
+		Tags:            splitQuery(c.Query("tags")),
+		Query:           strings.TrimSpace(c.Query("q")),
+		Sort:            c.Query("sort"),
+		Order:           strings.ToLower(c.Query("order", "desc")),
+	}
+
+	for _, st := range splitQuery(c.Query("status")) {
+		if !filterableStatuses[st] {
+			errs["status"] = "status tidak dikenal: " + st
+			continue
+		}
+		f.Statuses = append(f.Statuses, st)
+	}
+
+	f.EventFrom, f.EventTo = parseDateRange(c, "event", errs)
+	f.SubmittedFrom, f.SubmittedTo = parseDateRange(c, "submitted", errs)
+	f.VerifiedFrom, f.VerifiedTo = parseDateRange(c, "verified", errs)
+
+	if f.Sort != "" && !repository.IsReferenceSortField(f.Sort) {
+		errs["sort"] = "sort harus salah satu dari created_at, updated_at, submitted_at, verified_at, status, student_code, student_name"
+	}
+	if f.Order != "asc" && f.Order != "desc" {
+		errs["order"] = "order harus asc atau desc"
+	}
+
+	return f, errs
+}
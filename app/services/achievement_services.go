@@ -1,15 +1,26 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"uas/app/events"
 	"uas/app/models"
+	"uas/app/notify"
+	"uas/app/outbox"
+	"uas/app/policy"
 	"uas/app/repository"
+	"uas/app/schema"
+	"uas/app/scoring"
+	"uas/app/storage"
+	"uas/app/workflow"
 	"uas/helper"
 	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AchievementService struct {
@@ -18,6 +29,19 @@ type AchievementService struct {
 	PgRepo       repository.AchievementReferenceRepository
 	lecturerRepo repository.LecturerRepository
 	UserRepo     repository.UserRepository
+	HistoryRepo  repository.AchievementHistoryRepository
+	SnapshotRepo repository.ReviewSnapshotRepository
+	PointRules   repository.PointRuleRepository
+	TypeRepo     repository.AchievementTypeRepository
+	OutboxRepo   repository.OutboxRepository
+	Storage      storage.Storage
+	Notifier     *notify.Notifier
+	Events       *events.Publisher
+
+	// Attachments membatasi jenis dan ukuran lampiran.
+	Attachments AttachmentPolicy
+	// MaxResubmissions membatasi pengiriman ulang setelah ditolak (0 = tanpa batas).
+	MaxResubmissions int
 }
 
 func NewAchievementService(
@@ -26,6 +50,14 @@ func NewAchievementService(
 	pgRepo repository.AchievementReferenceRepository,
 	lecturerRepo repository.LecturerRepository,
 	usrRepo repository.UserRepository,
+	historyRepo repository.AchievementHistoryRepository,
+	snapshotRepo repository.ReviewSnapshotRepository,
+	pointRuleRepo repository.PointRuleRepository,
+	typeRepo repository.AchievementTypeRepository,
+	outboxRepo repository.OutboxRepository,
+	store storage.Storage,
+	notifier *notify.Notifier,
+	publisher *events.Publisher,
 ) *AchievementService {
 	return &AchievementService{
 		StudentRepo:  stdRepo,
@@ -33,23 +65,236 @@ func NewAchievementService(
 		PgRepo:       pgRepo,
 		lecturerRepo: lecturerRepo,
 		UserRepo:     usrRepo,
+		HistoryRepo:  historyRepo,
+		SnapshotRepo: snapshotRepo,
+		PointRules:   pointRuleRepo,
+		TypeRepo:     typeRepo,
+		OutboxRepo:   outboxRepo,
+		Storage:      store,
+		Notifier:     notifier,
+		Events:       publisher,
+
+		Attachments:      AttachmentPolicyFromEnv(),
+		MaxResubmissions: workflow.MaxResubmissionsFromEnv(),
 	}
 }
 
+// transition menjalankan event workflow pada ref dan menyimpan status baru
+// beserta audit trail-nya.
+func (s *AchievementService) transition(c *fiber.Ctx, ref *models.AchievementReference, event workflow.Event, note string) error {
+	return s.applyTransition(c, ref, event, note, func(entry *models.AchievementStatusHistory) error {
+		return s.PgRepo.Transition(c.Context(), ref, entry)
+	})
+}
+
+// transitionWithOutbox sama dengan transition, ditambah mencatat operasi
+// MongoDB msg pada transaksi yang sama lalu langsung menerapkannya.
+func (s *AchievementService) transitionWithOutbox(c *fiber.Ctx, ref *models.AchievementReference, event workflow.Event, note string, msg *models.OutboxMessage) error {
+	err := s.applyTransition(c, ref, event, note, func(entry *models.AchievementStatusHistory) error {
+		return s.PgRepo.TransitionWithOutbox(c.Context(), ref, entry, msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	// kegagalan di sini tidak membatalkan request; worker outbox akan mengulang
+	_ = outbox.Dispatch(c.Context(), s.OutboxRepo, s.MongoRepo, msg)
+	return nil
+}
+
+// applyTransition menjalankan event workflow pada ref, menyimpannya lewat
+// save lalu mengabarkan perubahan status.
+func (s *AchievementService) applyTransition(c *fiber.Ctx, ref *models.AchievementReference, event workflow.Event, note string, save func(entry *models.AchievementStatusHistory) error) error {
+	actorID, _ := c.Locals("user_id").(string)
+	from := ref.Status
+
+	entry, err := workflow.Apply(ref, event, actorID, note, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := save(entry); err != nil {
+		return err
+	}
+
+	s.publishTransition(c, ref, from)
+	return nil
+}
+
+// transitionError memetakan kegagalan transition ke respon HTTP. Status yang
+// sudah diubah request lain menghasilkan 409 agar klien memuat ulang data.
+func transitionError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, repository.ErrStatusConflict) {
+		return helper.Conflict(c, "Status prestasi sudah berubah, muat ulang data lalu coba lagi")
+	}
+	return helper.InternalServerError(c, message)
+}
+
+// publishTransition mengabarkan perubahan status ref ke stream mahasiswa
+// pemilik, dosen walinya dan admin.
+func (s *AchievementService) publishTransition(c *fiber.Ctx, ref *models.AchievementReference, from string) {
+	if s.Events == nil {
+		return
+	}
+
+	advisorID := ""
+	if student, err := s.StudentRepo.FindByID(c.Context(), ref.StudentID); err == nil && student != nil && student.AdvisorID != nil {
+		advisorID = *student.AdvisorID
+	}
+
+	s.Events.Publish(c.Context(), events.StatusChanged(ref, from, advisorID))
+}
+
+// contentEditable menentukan apakah isi prestasi (termasuk lampiran) masih
+// boleh diubah pada status tersebut.
+func contentEditable(status string) bool {
+	return workflow.Editable(status) || workflow.Can(status, workflow.Revise)
+}
+
+// reviseIfRejected memindahkan prestasi yang ditolak ke status revisi saat
+// mulai diperbaiki.
+func (s *AchievementService) reviseIfRejected(c *fiber.Ctx, ref *models.AchievementReference) error {
+	if ref.Status != utils.AchievementStatusRejected {
+		return nil
+	}
+	return s.transition(c, ref, workflow.Revise, "")
+}
+
+// syncFacts menyalin tipe, tingkat kompetisi dan poin prestasi ke
+// reference untuk analitik. Kegagalan hanya dicatat karena salinan ini dapat
+// dibangun ulang dengan `make sync-analytics`.
+func (s *AchievementService) syncFacts(c *fiber.Ctx, ach *models.AchievementMongo) {
+	if err := s.PgRepo.UpdateFacts(c.Context(), ach.ID.Hex(), models.FactsOf(ach)); err != nil {
+		helper.Log.Warn().Err(err).Str("achievement_id", ach.ID.Hex()).Msg("gagal menyalin data analitik prestasi")
+	}
+}
+
+// notifySubmitted memberi tahu dosen wali pemilik ref bahwa ada prestasi
+// yang menunggu verifikasi.
+func (s *AchievementService) notifySubmitted(c *fiber.Ctx, ref *models.AchievementReference) {
+	if s.Notifier == nil {
+		return
+	}
+	ctx := c.Context()
+
+	student, err := s.StudentRepo.FindByID(ctx, ref.StudentID)
+	if err != nil || student == nil || student.AdvisorID == nil {
+		return
+	}
+	advisor, err := s.lecturerRepo.GetByID(ctx, *student.AdvisorID)
+	if err != nil || advisor == nil {
+		helper.Log.Warn().Err(err).Str("student_id", student.ID).Msg("dosen wali penerima notifikasi tidak ditemukan")
+		return
+	}
+
+	title := ""
+	if ach, err := s.MongoRepo.FindByID(ctx, ref.MongoAchievementID); err == nil && ach != nil {
+		title = ach.Title
+	}
+
+	s.Notifier.Send(ctx, notify.AchievementSubmitted(advisor.UserID, ref, title))
+}
+
+// notifyStudent mengirim notifikasi hasil review ke mahasiswa pemilik ref.
+func (s *AchievementService) notifyStudent(c *fiber.Ctx, ref *models.AchievementReference, build func(userID string) *models.Notification) {
+	if s.Notifier == nil {
+		return
+	}
+
+	student, err := s.StudentRepo.FindByID(c.Context(), ref.StudentID)
+	if err != nil || student == nil {
+		return
+	}
+
+	s.Notifier.Send(c.Context(), build(student.UserID))
+}
+
+// authorize mengambil reference prestasi lalu memeriksa apakah user yang
+// sedang login boleh melakukan action tersebut. Jika tidak, respon 404/403
+// sudah dikirim dan ref bernilai nil.
+func (s *AchievementService) authorize(c *fiber.Ctx, mongoID string, action policy.Action, denied string) (*models.AchievementReference, error) {
+	ref, err := s.PgRepo.GetByMongoID(c.Context(), mongoID)
+	if err != nil || ref == nil {
+		return nil, helper.NotFound(c, "Prestasi tidak ditemukan")
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.lecturerRepo)
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+
+	subject := policy.Subject{StudentID: ref.StudentID, Status: ref.Status}
+	if student, err := s.StudentRepo.FindByID(c.Context(), ref.StudentID); err == nil && student != nil {
+		subject = studentSubject(student, ref.Status)
+	}
+
+	if !policy.Can(actor, action, subject) {
+		return nil, helper.Forbidden(c, denied)
+	}
+
+	return ref, nil
+}
+
+// validateDetails mencari tipe prestasi di registry lalu memvalidasi details
+// terhadap spesifikasi field-nya. Jika gagal, respon 400 sudah dikirim dan
+// details bernilai nil.
+func (s *AchievementService) validateDetails(c *fiber.Ctx, code string, details map[string]interface{}) (map[string]interface{}, error) {
+	t, err := s.TypeRepo.GetByCode(c.Context(), code)
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal mengambil tipe prestasi")
+	}
+	if t == nil || !t.IsActive {
+		return nil, helper.BadRequest(c, "Validasi gagal", fiber.Map{
+			"achievement_type": "tipe prestasi tidak dikenal atau nonaktif",
+		})
+	}
+
+	clean, errs := schema.Validate(t, utils.SanitizeMongoMap(details))
+	if len(errs) > 0 {
+		return nil, helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	return clean, nil
+}
+
 // List achievements
 // @Summary      List prestasi
 // @Description  Mengambil daftar prestasi sesuai hak akses user
 // @Tags         Achievements
 // @Security     BearerAuth
-// @Param        page   query int false "Page number"
-// @Param        limit  query int false "Limit per page"
+// @Param        page              query int    false "Page number"
+// @Param        limit             query int    false "Limit per page"
+// @Param        status            query string false "Status, dipisah koma (draft,submitted,verified,rejected,revision)"
+// @Param        achievement_type  query string false "Kode tipe prestasi"
+// @Param        q                 query string false "Cari di judul dan deskripsi"
+// @Param        tags              query string false "Tag, dipisah koma (semua harus cocok)"
+// @Param        student_code      query string false "NIM (sebagian)"
+// @Param        student_name      query string false "Nama mahasiswa (sebagian)"
+// @Param        program_study     query string false "Program studi"
+// @Param        academic_year     query string false "Angkatan"
+// @Param        event_from        query string false "Tanggal kegiatan dari (YYYY-MM-DD)"
+// @Param        event_to          query string false "Tanggal kegiatan sampai (YYYY-MM-DD)"
+// @Param        submitted_from    query string false "Tanggal submit dari (YYYY-MM-DD)"
+// @Param        submitted_to      query string false "Tanggal submit sampai (YYYY-MM-DD)"
+// @Param        verified_from     query string false "Tanggal verifikasi dari (YYYY-MM-DD)"
+// @Param        verified_to       query string false "Tanggal verifikasi sampai (YYYY-MM-DD)"
+// @Param        sort              query string false "created_at, updated_at, submitted_at, verified_at, status, student_code, student_name"
+// @Param        order             query string false "asc atau desc (default desc)"
 // @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
 // @Failure      401 {object} models.MetaInfo
 // @Failure      403 {object} models.MetaInfo
 // @Router       /achievements [get]
 func (s *AchievementService) List(c *fiber.Ctx) error {
-	role := c.Locals("role_id").(string)
-	userID := c.Locals("user_id").(string)
+	actor, err := currentActor(c, s.StudentRepo, s.lecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+
+	filter, errs := parseAchievementFilter(c)
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Parameter filter tidak valid", errs)
+	}
 
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
@@ -61,44 +306,25 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
 	}
 	offset := (page - 1) * limit
 
-	var (
-		refs         []models.AchievementReference
-		total        int
-		err          error
-		emptyMessage string
-	)
+	emptyMessage := "Belum ada prestasi"
 
-	switch role {
+	switch policy.ListScope(actor) {
 
-	case "Mahasiswa":
-		student, err := s.StudentRepo.GetByUserID(c.Context(), userID)
-		if err != nil || student == nil {
-			return helper.BadRequest(c, "Mahasiswa tidak ditemukan", nil)
-		}
+	case policy.ScopeOwn:
+		filter.StudentIDs = []string{actor.StudentID}
 
-		refs, total, err = s.PgRepo.FindByStudentIDPaginated(
-			c.Context(),
-			student.ID,
-			limit,
-			offset,
-		)
-		emptyMessage = "Belum ada prestasi"
-
-	case "Admin":
-		refs, total, err = s.PgRepo.FindAllPaginated(
-			c.Context(),
-			limit,
-			offset,
-		)
-		emptyMessage = "Belum ada prestasi"
-
-	case "Dosen Wali":
-		lecturer, err := s.lecturerRepo.GetByUserID(c.Context(), userID)
-		if err != nil || lecturer == nil {
-			return helper.Forbidden(c, "Anda bukan dosen wali")
+		if filter.HasMongoFilter() {
+			student, err := s.StudentRepo.FindByID(c.Context(), actor.StudentID)
+			if err != nil || student == nil {
+				return helper.InternalServerError(c, "Gagal mengambil data mahasiswa")
+			}
+			filter.StudentCodes = []string{student.StudentID}
 		}
 
-		advisees, err := s.StudentRepo.FindByAdvisorID(c.Context(), lecturer.ID)
+	case policy.ScopeAll:
+
+	case policy.ScopeAdvisees:
+		advisees, err := s.StudentRepo.FindByAdvisorID(c.Context(), actor.LecturerID)
 		if err != nil {
 			return helper.InternalServerError(c, "Gagal mengambil data mahasiswa bimbingan")
 		}
@@ -111,23 +337,43 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
 			)
 		}
 
-		studentIDs := make([]string, 0, len(advisees))
+		filter.StudentIDs = make([]string, 0, len(advisees))
+		filter.StudentCodes = make([]string, 0, len(advisees))
 		for _, st := range advisees {
-			studentIDs = append(studentIDs, st.ID)
+			filter.StudentIDs = append(filter.StudentIDs, st.ID)
+			filter.StudentCodes = append(filter.StudentCodes, st.StudentID)
 		}
 
-		refs, total, err = s.PgRepo.FindForAdvisorPaginated(
-			c.Context(),
-			studentIDs,
-			limit,
-			offset,
-		)
-		emptyMessage = "Belum ada prestasi yang disubmit"
+		// draft bersifat privat bagi mahasiswa; tanpa filter status dosen
+		// wali melihat antrian verifikasi
+		filter.ExcludeStatuses = []string{utils.AchievementStatusDraft}
+		if len(filter.Statuses) == 0 {
+			filter.Statuses = []string{utils.AchievementStatusSubmitted}
+			emptyMessage = "Belum ada prestasi yang disubmit"
+		}
+		if filter.Sort == "" {
+			filter.Sort = "submitted_at"
+		}
 
 	default:
 		return helper.Forbidden(c, "Role tidak memiliki akses")
 	}
 
+	if filter.HasMongoFilter() {
+		ids, err := s.MongoRepo.FindIDs(c.Context(), filter, maxFilterIDs+1)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal mencari data prestasi")
+		}
+		if len(ids) > maxFilterIDs {
+			return helper.BadRequest(c, "Filter terlalu luas, persempit pencarian", nil)
+		}
+		if len(ids) == 0 {
+			return helper.Success(c, emptyMessage, []fiber.Map{})
+		}
+		filter.MongoIDs = ids
+	}
+
+	refs, total, err := s.PgRepo.Search(c.Context(), filter, limit, offset)
 	if err != nil {
 		return helper.InternalServerError(c, "Gagal mengambil data prestasi")
 	}
@@ -136,37 +382,32 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
 		return helper.Success(c, emptyMessage, []fiber.Map{})
 	}
 
-	list := make([]fiber.Map, 0, len(refs))
+	docs, _, err := stitchAchievements(c.Context(), s.MongoRepo, refs, listFields...)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil data prestasi")
+	}
 
-	for _, ref := range refs {
-		ach, err := s.MongoRepo.FindByID(c.Context(), ref.MongoAchievementID)
-		if err != nil || ach == nil {
-			continue
-		}
+	list := make([]fiber.Map, 0, len(refs))
 
-		// eventDate := ""
-		// if ach.Details != nil {
-		// 	if raw, ok := ach.Details["eventDate"]; ok && raw != nil {
-		// 		eventDate = utils.FormatDate(raw)
-		// 	}
-		// }
-
-		// thumbnail := ""
-		// if len(ach.Attachments) > 0 {
-		// 	thumbnail = ach.Attachments[0].FileURL
-		// }
-
-		list = append(list, fiber.Map{
-			"id":           ach.ID.Hex(),
-			"title":        ach.Title,
-			"type":         ach.AchievementType,
+	for i, ref := range refs {
+		item := fiber.Map{
+			"id":           ref.MongoAchievementID,
 			"status":       ref.Status,
-			// "event_date":   eventDate,
-			// "thumbnail":    thumbnail,
-			"updated_at":   ach.UpdatedAt,
 			"student_code": ref.StudentCode,
 			"student_name": ref.StudentName,
-		})
+		}
+
+		// dokumen mongo hilang: tetap ditampilkan agar ukuran halaman konsisten
+		if ach := docs[i]; ach != nil {
+			item["title"] = ach.Title
+			item["type"] = ach.AchievementType
+			item["updated_at"] = ach.UpdatedAt
+		} else {
+			item["missing"] = true
+			item["updated_at"] = ref.UpdatedAt
+		}
+
+		list = append(list, item)
 	}
 
 	meta := models.PaginationMeta{
@@ -191,9 +432,9 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
 func (s *AchievementService) Detail(c *fiber.Ctx) error {
 	mongoID := c.Params("id")
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), mongoID)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	ref, err := s.authorize(c, mongoID, policy.ReadAchievement, "Tidak dapat melihat prestasi milik pengguna lain")
+	if ref == nil {
+		return err
 	}
 
 	ach, err := s.MongoRepo.FindByID(c.Context(), mongoID)
@@ -268,12 +509,15 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		return helper.InternalServerError(c, "Gagal mengambil data user")
 	}
 
-	sanitized := utils.SanitizeMongoMap(input.Details)
+	filtered, err := s.validateDetails(c, input.AchievementType, input.Details)
+	if filtered == nil {
+		return err
+	}
 
-	filtered := utils.FilterDetails(input.AchievementType, sanitized)
 	now := time.Now()
 
 	achievement := models.AchievementMongo{
+		ID:              primitive.NewObjectID(),
 		StudentID:       student.StudentID,
 		AchievementType: input.AchievementType,
 		Title:           input.Title,
@@ -286,10 +530,11 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		UpdatedAt:       now,
 	}
 
-	mongoID, err := s.MongoRepo.Create(c.Context(), &achievement)
+	msg, err := outbox.NewCreate(&achievement)
 	if err != nil {
-		return helper.InternalServerError(c, "Gagal menyimpan prestasi")
+		return helper.InternalServerError(c, "Gagal menyiapkan prestasi")
 	}
+	mongoID := achievement.ID.Hex()
 
 	ref := models.AchievementReference{
 		ID:                 uuid.NewString(),
@@ -302,11 +547,16 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		UpdatedAt:          now,
 	}
 
-	if err := s.PgRepo.Create(c.Context(), &ref); err != nil {
-		_ = s.MongoRepo.SoftDelete(c.Context(), mongoID)
-		return helper.InternalServerError(c, "Gagal menyimpan reference prestasi")
+	// reference dan operasi pembuatan dokumen ditulis dalam satu transaksi;
+	// dokumen MongoDB dibuat setelahnya dan diulang oleh worker jika gagal
+	if err := s.PgRepo.CreateWithOutbox(c.Context(), &ref, msg); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan prestasi")
 	}
 
+	_ = outbox.Dispatch(c.Context(), s.OutboxRepo, s.MongoRepo, msg)
+	s.syncFacts(c, &achievement)
+	s.publishTransition(c, &ref, "")
+
 	return helper.Created(c, "Prestasi berhasil dibuat", fiber.Map{
 		"id":     mongoID,
 		"status": ref.Status,
@@ -321,36 +571,42 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 // @Param        id path string true "Achievement ID"
 // @Success      200 {object} models.MetaInfo
 // @Failure      400 {object} models.MetaInfo
+// @Failure      409 {object} models.MetaInfo
 // @Router       /achievements/{id}/submit [post]
 func (s *AchievementService) Submit(c *fiber.Ctx) error {
-	userID, _ := c.Locals("user_id").(string)
 	mongoID := c.Params("id")
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), mongoID)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	ref, err := s.authorize(c, mongoID, policy.SubmitAchievement, "Tidak dapat submit prestasi milik pengguna lain")
+	if ref == nil {
+		return err
 	}
 
-	student, err := s.StudentRepo.GetByUserID(c.Context(), userID)
-	if err != nil || student == nil {
-		return helper.BadRequest(c, "Mahasiswa tidak ditemukan", nil)
-	}
-	if ref.StudentID != student.ID {
-		return helper.Forbidden(c, "Tidak dapat submit prestasi milik pengguna lain")
+	resubmit := ref.Status == utils.AchievementStatusRejected || ref.Status == utils.AchievementStatusRevision
+
+	if resubmit && s.MaxResubmissions > 0 {
+		history, err := s.HistoryRepo.FindByReferenceID(c.Context(), ref.ID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal mengambil history prestasi")
+		}
+		if workflow.Resubmissions(history) >= s.MaxResubmissions {
+			return helper.BadRequest(c, "Batas pengiriman ulang prestasi sudah tercapai", nil)
+		}
 	}
 
-	if ref.Status != utils.AchievementStatusDraft {
-		return helper.BadRequest(c, "Prestasi hanya dapat disubmit dari status draft", nil)
+	if ref.Status == utils.AchievementStatusRejected {
+		if err := s.transition(c, ref, workflow.Revise, ""); err != nil {
+			return transitionError(c, err, "Gagal update status prestasi")
+		}
 	}
 
-	now := time.Now()
-	ref.Status = utils.AchievementStatusSubmitted
-	ref.SubmittedAt = &now
-	ref.UpdatedAt = now
+	if !workflow.Can(ref.Status, workflow.Submit) {
+		return helper.BadRequest(c, "Prestasi hanya dapat disubmit dari status draft atau revisi", nil)
+	}
 
-	if err := s.PgRepo.Update(c.Context(), ref); err != nil {
-		return helper.InternalServerError(c, "Gagal update status prestasi")
+	if err := s.transition(c, ref, workflow.Submit, ""); err != nil {
+		return transitionError(c, err, "Gagal update status prestasi")
 	}
+	s.notifySubmitted(c, ref)
 
 	return helper.Success(c, "Prestasi berhasil dikirim untuk verifikasi", fiber.Map{
 		"status":       ref.Status,
@@ -366,38 +622,23 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
 // @Param        id path string true "Achievement ID"
 // @Success      200 {object} models.MetaInfo
 // @Failure      403 {object} models.MetaInfo
+// @Failure      409 {object} models.MetaInfo
 // @Router       /achievements/{id} [delete]
 func (s *AchievementService) Delete(c *fiber.Ctx) error {
-	userID, _ := c.Locals("user_id").(string)
 	mongoID := c.Params("id")
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), mongoID)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
-	}
-
-	student, err := s.StudentRepo.GetByUserID(c.Context(), userID)
-	if err != nil || student == nil {
-		return helper.BadRequest(c, "Mahasiswa tidak ditemukan", nil)
-	}
-	if ref.StudentID != student.ID {
-		return helper.Forbidden(c, "Tidak dapat menghapus prestasi milik pengguna lain")
+	ref, err := s.authorize(c, mongoID, policy.DeleteAchievement, "Tidak dapat menghapus prestasi milik pengguna lain")
+	if ref == nil {
+		return err
 	}
 
-	if ref.Status != utils.AchievementStatusDraft {
+	if !workflow.Can(ref.Status, workflow.Delete) {
 		return helper.BadRequest(c, "Hanya prestasi draft yang dapat dihapus", nil)
 	}
 
-	if err := s.MongoRepo.SoftDelete(c.Context(), ref.MongoAchievementID); err != nil {
-		return helper.InternalServerError(c, "Gagal menghapus data MongoDB")
-	}
-
-	now := time.Now()
-	ref.Status = utils.AchievementStatusDeleted
-	ref.UpdatedAt = now
-
-	if err := s.PgRepo.Update(c.Context(), ref); err != nil {
-		return helper.InternalServerError(c, "Gagal memperbarui reference di PostgreSQL")
+	msg := outbox.NewSoftDelete(ref.MongoAchievementID)
+	if err := s.transitionWithOutbox(c, ref, workflow.Delete, "", msg); err != nil {
+		return transitionError(c, err, "Gagal menghapus prestasi")
 	}
 
 	return helper.Success(c, "Prestasi draft berhasil dihapus", nil)
@@ -411,33 +652,53 @@ func (s *AchievementService) Delete(c *fiber.Ctx) error {
 // @Param        id path string true "Achievement ID"
 // @Success      200 {object} models.MetaInfo
 // @Failure      400 {object} models.MetaInfo
+// @Failure      409 {object} models.MetaInfo
 // @Router       /achievements/{id}/verify [post]
 func (s *AchievementService) Verify(c *fiber.Ctx) error {
 	id := c.Params("id")
-	advisorID := c.Locals("user_id").(string)
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), id)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	ref, err := s.authorize(c, id, policy.VerifyAchievement, "Anda bukan dosen wali mahasiswa ini")
+	if ref == nil {
+		return err
 	}
 
-	if ref.Status != utils.AchievementStatusSubmitted {
+	if !workflow.Can(ref.Status, workflow.Verify) {
 		return helper.BadRequest(c, "Prestasi belum dikirim atau sudah diverifikasi", nil)
 	}
 
-	now := time.Now()
-	ref.Status = utils.AchievementStatusVerified
-	ref.VerifiedAt = &now
-	ref.VerifiedBy = &advisorID
+	ach, err := s.MongoRepo.FindByID(c.Context(), ref.MongoAchievementID)
+	if err != nil || ach == nil {
+		return helper.NotFound(c, "Data prestasi tidak ditemukan")
+	}
 
-	if err := s.PgRepo.Update(c.Context(), ref); err != nil {
-		return helper.InternalServerError(c, "Gagal memverifikasi prestasi")
+	rules, err := s.PointRules.Active(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil aturan poin")
 	}
 
+	// poin dihitung sebelum transisi lalu disimpan lewat outbox pada transaksi
+	// yang sama, sehingga prestasi tidak pernah verified tanpa poinnya;
+	// tanpa aturan aktif poin tetap 0
+	if rules != nil {
+		scoring.Apply(rules, ach)
+		msg := outbox.NewSetPoints(ref.MongoAchievementID, ach.Points, ach.PointsVersion)
+		err = s.transitionWithOutbox(c, ref, workflow.Verify, "", msg)
+	} else {
+		err = s.transition(c, ref, workflow.Verify, "")
+	}
+	if err != nil {
+		return transitionError(c, err, "Gagal memverifikasi prestasi")
+	}
+	s.syncFacts(c, ach)
+	s.notifyStudent(c, ref, func(userID string) *models.Notification {
+		return notify.AchievementVerified(userID, ref, ach.Title, ach.Points)
+	})
+
 	return helper.Success(c, "Prestasi berhasil diverifikasi", fiber.Map{
 		"status":      ref.Status,
 		"verified_at": ref.VerifiedAt,
 		"verified_by": ref.VerifiedBy,
+		"points":      ach.Points,
 	})
 }
 
@@ -450,10 +711,10 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 // @Param        id   path string true "Achievement ID"
 // @Param        body body object true "Catatan penolakan"
 // @Success      200 {object} models.MetaInfo
+// @Failure      409 {object} models.MetaInfo
 // @Router       /achievements/{id}/reject [post]
 func (s *AchievementService) Reject(c *fiber.Ctx) error {
 	id := c.Params("id")
-	advisorID := c.Locals("user_id").(string)
 
 	var body struct {
 		Note string `json:"note"`
@@ -472,34 +733,38 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
     	)
 	}
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), id)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	ref, err := s.authorize(c, id, policy.VerifyAchievement, "Anda bukan dosen wali mahasiswa ini")
+	if ref == nil {
+		return err
 	}
 
-	if ref.Status != utils.AchievementStatusSubmitted {
+	if !workflow.Can(ref.Status, workflow.Reject) {
 		return helper.BadRequest(c, "Prestasi belum dikirim atau sudah diproses", nil)
 	}
 
-	student, err := s.StudentRepo.FindByID(c.Context(), ref.StudentID)
-	if err != nil || student == nil {
-		return helper.NotFound(c, "Mahasiswa tidak ditemukan")
+	// simpan isi yang direview agar revisi berikutnya bisa di-diff
+	ach, err := s.MongoRepo.FindByID(c.Context(), ref.MongoAchievementID)
+	if err != nil || ach == nil {
+		return helper.NotFound(c, "Data prestasi tidak ditemukan")
 	}
 
-	// if student.AdvisorID == nil || *student.AdvisorID != advisorID {
-	//     return helper.Forbidden(c, "Anda bukan dosen wali mahasiswa ini")
-	// }
-
-	now := time.Now()
-	ref.Status = utils.AchievementStatusRejected
-	ref.RejectionNote = &body.Note
-	ref.VerifiedAt = &now
-	ref.VerifiedBy = &advisorID
-
-	// Simpan ke database
-	if err := s.PgRepo.Update(c.Context(), ref); err != nil {
-		return helper.InternalServerError(c, "Gagal menolak prestasi")
+	reviewerID, _ := c.Locals("user_id").(string)
+	snap := &models.AchievementReviewSnapshot{
+		AchievementRefID: ref.ID,
+		ReviewedBy:       &reviewerID,
+		Content:          workflow.Snapshot(ach),
 	}
+	// snapshot disimpan bersama transisi agar tidak ada review yang tercatat
+	// tanpa penolakannya
+	err = s.applyTransition(c, ref, workflow.Reject, body.Note, func(entry *models.AchievementStatusHistory) error {
+		return s.PgRepo.TransitionWithSnapshot(c.Context(), ref, entry, snap)
+	})
+	if err != nil {
+		return transitionError(c, err, "Gagal menolak prestasi")
+	}
+	s.notifyStudent(c, ref, func(userID string) *models.Notification {
+		return notify.AchievementRejected(userID, ref, ach.Title, body.Note)
+	})
 
 	// Response
 	return helper.Success(c, "Prestasi berhasil ditolak", fiber.Map{
@@ -512,17 +777,27 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 
 // Upload achievement attachments
 // @Summary      Upload lampiran
-// @Description  Upload file prestasi
+// @Description  Upload file prestasi (PDF, JPEG, PNG, WebP) saat draft, ditolak atau revisi. Jenis file ditentukan dari isinya dan ukuran dibatasi per file maupun total per prestasi.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Accept       multipart/form-data
 // @Param        id    path string true "Achievement ID"
 // @Param        files formData file true "Files"
 // @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
 // @Router       /achievements/{id}/attachments [post]
 func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	ref, err := s.authorize(c, id, policy.UpdateAchievement, "Tidak dapat mengubah prestasi milik orang lain")
+	if ref == nil {
+		return err
+	}
+
+	if !contentEditable(ref.Status) {
+		return helper.BadRequest(c, "Lampiran hanya bisa diubah saat draft, ditolak atau revisi", nil)
+	}
+
 	ach, err := s.MongoRepo.FindByID(c.Context(), id)
 	if err != nil || ach == nil {
 		return helper.NotFound(c, "Prestasi tidak ditemukan")
@@ -538,81 +813,300 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Tidak ada file yang diupload", nil)
 	}
 
-	uploaded := make([]models.AchievementFile, 0)
+	total := attachmentsSize(ach.Attachments)
 
-	for _, file := range files {
-		savePath := "./uploads/achievements" + file.Filename
-		if err := c.SaveFile(file, savePath); err != nil {
-			return helper.InternalServerError(c, "Gagal menyimpan file")
+	pending := make([]*pendingFile, 0, len(files))
+	errs := map[string]string{}
+	for i, fh := range files {
+		file, msg := s.Attachments.readAttachment(fh)
+		if msg != "" {
+			errs[fmt.Sprintf("files[%d]", i)] = msg
+			continue
 		}
+		total += int64(len(file.data))
+		pending = append(pending, file)
+	}
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "File lampiran tidak valid", errs)
+	}
+	if total > s.Attachments.MaxTotalSize {
+		return helper.BadRequest(c, "Total ukuran lampiran melebihi batas "+formatSize(s.Attachments.MaxTotalSize), nil)
+	}
+
+	uploaded := make([]models.AchievementFile, 0, len(pending))
 
-		uploadedFile := models.AchievementFile{
-			FileName:   file.Filename,
-			FileURL:    savePath,
-			FileType:   file.Header.Get("Content-Type"),
-			UploadedAt: time.Now(),
+	for _, file := range pending {
+		stored, err := s.storeAttachment(c, ach, file)
+		if err != nil {
+			s.removeObjects(c, uploaded)
+			return helper.InternalServerError(c, "Gagal menyimpan file")
 		}
-		uploaded = append(uploaded, uploadedFile)
+		uploaded = append(uploaded, stored)
+	}
+
+	if err := s.reviseIfRejected(c, ref); err != nil {
+		s.removeObjects(c, uploaded)
+		return transitionError(c, err, "Gagal update status prestasi")
 	}
 
 	ach.Attachments = append(ach.Attachments, uploaded...)
 
 	if err := s.MongoRepo.Update(c.Context(), ach); err != nil {
+		s.removeObjects(c, uploaded)
 		return helper.InternalServerError(c, "Gagal menambahkan attachment")
 	}
 
 	return helper.Success(c, "Attachment berhasil diupload", uploaded)
 }
 
-// Achievement history
-// @Summary      Riwayat prestasi
-// @Description  Mengambil history status prestasi
+// Replace achievement attachment
+// @Summary      Ganti lampiran
+// @Description  Mengganti isi satu lampiran saat draft, ditolak atau revisi. ID lampiran tetap sama.
 // @Tags         Achievements
 // @Security     BearerAuth
-// @Param        id path string true "Achievement ID"
+// @Accept       multipart/form-data
+// @Param        id           path string true "Achievement ID"
+// @Param        attachmentId path string true "Attachment ID"
+// @Param        file         formData file true "File pengganti"
 // @Success      200 {object} models.MetaInfo
-// @Router       /achievements/{id}/history [get]
-func (s *AchievementService) History(c *fiber.Ctx) error {
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievements/{id}/attachments/{attachmentId} [put]
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+	ref, ach, idx, err := s.editableAttachment(c)
+	if ref == nil {
+		return err
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return helper.BadRequest(c, "Tidak ada file yang diupload", nil)
+	}
+
+	file, msg := s.Attachments.readAttachment(fh)
+	if msg != "" {
+		return helper.BadRequest(c, "File lampiran tidak valid", map[string]string{"file": msg})
+	}
+
+	old := ach.Attachments[idx]
+	if attachmentsSize(ach.Attachments)-old.Size+int64(len(file.data)) > s.Attachments.MaxTotalSize {
+		return helper.BadRequest(c, "Total ukuran lampiran melebihi batas "+formatSize(s.Attachments.MaxTotalSize), nil)
+	}
+
+	stored, err := s.storeAttachment(c, ach, file)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan file")
+	}
+	stored.ID = old.ID
+	stored.FileURL = old.FileURL
+
+	if err := s.reviseIfRejected(c, ref); err != nil {
+		s.removeObjects(c, []models.AchievementFile{stored})
+		return transitionError(c, err, "Gagal update status prestasi")
+	}
+
+	ach.Attachments[idx] = stored
+
+	if err := s.MongoRepo.Update(c.Context(), ach); err != nil {
+		s.removeObjects(c, []models.AchievementFile{stored})
+		return helper.InternalServerError(c, "Gagal mengganti lampiran")
+	}
+
+	// file lama yang gagal dihapus akan dibersihkan sweeper
+	s.removeObjects(c, []models.AchievementFile{old})
+
+	return helper.Success(c, "Lampiran berhasil diganti", stored)
+}
+
+// Delete achievement attachment
+// @Summary      Hapus lampiran
+// @Description  Menghapus satu lampiran saat draft, ditolak atau revisi
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id           path string true "Achievement ID"
+// @Param        attachmentId path string true "Attachment ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievements/{id}/attachments/{attachmentId} [delete]
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	ref, ach, idx, err := s.editableAttachment(c)
+	if ref == nil {
+		return err
+	}
+
+	removed := ach.Attachments[idx]
+	ach.Attachments = append(ach.Attachments[:idx:idx], ach.Attachments[idx+1:]...)
+
+	if err := s.reviseIfRejected(c, ref); err != nil {
+		return transitionError(c, err, "Gagal update status prestasi")
+	}
+
+	if err := s.MongoRepo.Update(c.Context(), ach); err != nil {
+		return helper.InternalServerError(c, "Gagal menghapus lampiran")
+	}
+
+	// file yang gagal dihapus akan dibersihkan sweeper
+	s.removeObjects(c, []models.AchievementFile{removed})
+
+	return helper.Success(c, "Lampiran berhasil dihapus", nil)
+}
+
+// editableAttachment memuat prestasi dan posisi lampiran dari parameter
+// route untuk operasi ubah lampiran. Jika tidak diizinkan atau tidak
+// ditemukan, respon sudah dikirim dan ref bernilai nil.
+func (s *AchievementService) editableAttachment(c *fiber.Ctx) (*models.AchievementReference, *models.AchievementMongo, int, error) {
 	id := c.Params("id")
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), id)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	ref, err := s.authorize(c, id, policy.UpdateAchievement, "Tidak dapat mengubah prestasi milik orang lain")
+	if ref == nil {
+		return nil, nil, -1, err
 	}
 
-	history := make([]fiber.Map, 0)
+	if !contentEditable(ref.Status) {
+		return nil, nil, -1, helper.BadRequest(c, "Lampiran hanya bisa diubah saat draft, ditolak atau revisi", nil)
+	}
 
-	// 1. Draft (selalu ada)
-	history = append(history, fiber.Map{
-		"status":    "draft",
-		"timestamp": ref.CreatedAt,
-	})
+	ach, err := s.MongoRepo.FindByID(c.Context(), id)
+	if err != nil || ach == nil {
+		return nil, nil, -1, helper.NotFound(c, "Prestasi tidak ditemukan")
+	}
 
-	// 2. Submitted
-	if ref.SubmittedAt != nil {
-		history = append(history, fiber.Map{
-			"status":    "submitted",
-			"timestamp": ref.SubmittedAt,
-		})
+	idx := findAttachment(ach.Attachments, c.Params("attachmentId"))
+	if idx < 0 {
+		return nil, nil, -1, helper.NotFound(c, "Lampiran tidak ditemukan")
+	}
+
+	return ref, ach, idx, nil
+}
+
+// storeAttachment menyimpan file ke storage dengan key acak di bawah
+// achievements/<id>/. Nama file dari klien tidak pernah dipakai sebagai key.
+func (s *AchievementService) storeAttachment(c *fiber.Ctx, ach *models.AchievementMongo, file *pendingFile) (models.AchievementFile, error) {
+	fileID := uuid.NewString()
+	key := "achievements/" + ach.ID.Hex() + "/" + uuid.NewString() + file.ext
+
+	if err := s.Storage.Put(c.Context(), key, file.data, file.contentType); err != nil {
+		helper.Log.Error().Err(err).Str("key", key).Msg("gagal menyimpan lampiran")
+		return models.AchievementFile{}, err
 	}
 
-	// 3. Verified / Rejected
-	if ref.VerifiedAt != nil {
-		if ref.Status == utils.AchievementStatusVerified {
-			history = append(history, fiber.Map{
-				"status":    "verified",
-				"timestamp": ref.VerifiedAt,
-			})
+	return models.AchievementFile{
+		ID:         fileID,
+		FileName:   file.name,
+		FileURL:    "/api/v1/achievements/" + ach.ID.Hex() + "/attachments/" + fileID,
+		FileType:   file.contentType,
+		Size:       int64(len(file.data)),
+		Checksum:   file.checksum,
+		Key:        key,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+// removeObjects menghapus objek lampiran dari storage. Kegagalan hanya
+// dicatat karena objek tanpa rujukan akan dibersihkan sweeper.
+func (s *AchievementService) removeObjects(c *fiber.Ctx, files []models.AchievementFile) {
+	for _, f := range files {
+		if f.Key == "" {
+			continue
+		}
+		if err := s.Storage.Delete(c.Context(), f.Key); err != nil {
+			helper.Log.Warn().Err(err).Str("key", f.Key).Msg("gagal menghapus lampiran")
 		}
+	}
+}
 
-		if ref.Status == utils.AchievementStatusRejected {
-			history = append(history, fiber.Map{
-				"status":    "rejected",
-				"timestamp": ref.VerifiedAt,
-				"note":      ref.RejectionNote,
-			})
+func findAttachment(files []models.AchievementFile, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := range files {
+		if files[i].ID == id {
+			return i
 		}
 	}
+	return -1
+}
+
+func attachmentsSize(files []models.AchievementFile) int64 {
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	return total
+}
+
+// Attachment download URL
+// @Summary      URL unduhan lampiran
+// @Description  Menerbitkan URL unduhan bertanda tangan yang hanya berlaku singkat
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id           path string true "Achievement ID"
+// @Param        attachmentId path string true "Attachment ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) AttachmentURL(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	ref, err := s.authorize(c, id, policy.ReadAchievement, "Tidak dapat melihat prestasi milik pengguna lain")
+	if ref == nil {
+		return err
+	}
+
+	ach, err := s.MongoRepo.FindByID(c.Context(), id)
+	if err != nil || ach == nil {
+		return helper.NotFound(c, "Prestasi tidak ditemukan")
+	}
+
+	idx := findAttachment(ach.Attachments, c.Params("attachmentId"))
+	// lampiran lama tidak memiliki key storage sehingga tidak dapat diunduh
+	if idx < 0 || ach.Attachments[idx].Key == "" {
+		return helper.NotFound(c, "Lampiran tidak ditemukan")
+	}
+	file := ach.Attachments[idx]
+
+	url, err := s.Storage.SignedURL(c.Context(), file.Key, s.Attachments.URLTTL)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat URL unduhan")
+	}
+
+	return helper.Success(c, "URL unduhan berhasil dibuat", fiber.Map{
+		"url":        url,
+		"expires_at": time.Now().Add(s.Attachments.URLTTL),
+		"file_name":  file.FileName,
+		"file_type":  file.FileType,
+		"checksum":   file.Checksum,
+	})
+}
+
+// Achievement history
+// @Summary      Riwayat prestasi
+// @Description  Mengambil history status prestasi
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id path string true "Achievement ID"
+// @Success      200 {object} models.MetaInfo
+// @Router       /achievements/{id}/history [get]
+func (s *AchievementService) History(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	ref, err := s.authorize(c, id, policy.ReadAchievement, "Tidak dapat melihat prestasi milik pengguna lain")
+	if ref == nil {
+		return err
+	}
+
+	entries, err := s.HistoryRepo.FindByReferenceID(c.Context(), ref.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil history prestasi")
+	}
+
+	// pembuatan draft tidak tercatat sebagai transisi
+	history := append([]models.AchievementStatusHistory{{
+		ToStatus:  utils.AchievementStatusDraft,
+		CreatedAt: ref.CreatedAt,
+	}}, entries...)
 
 	return helper.Success(c, "History prestasi ditemukan", history)
 }
@@ -629,28 +1123,18 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 // @Success      200 {object} models.MetaInfo
 // @Failure      400 {object} models.MetaInfo
 // @Failure      403 {object} models.MetaInfo
+// @Failure      409 {object} models.MetaInfo
 // @Router       /achievements/{id} [put]
 func (s *AchievementService) Update(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
 	mongoID := c.Params("id")
 
-	ref, err := s.PgRepo.GetByMongoID(c.Context(), mongoID)
-	if err != nil || ref == nil {
-		return helper.NotFound(c, "Prestasi tidak ditemukan")
-	}
-
-	// hanya owner
-	student, err := s.StudentRepo.GetByUserID(c.Context(), userID)
-	if err != nil || student == nil {
-		return helper.BadRequest(c, "Mahasiswa tidak ditemukan", nil)
-	}
-	if ref.StudentID != student.ID {
-		return helper.Forbidden(c, "Tidak dapat mengubah prestasi milik orang lain")
+	ref, err := s.authorize(c, mongoID, policy.UpdateAchievement, "Tidak dapat mengubah prestasi milik orang lain")
+	if ref == nil {
+		return err
 	}
 
-	// hanya draft
-	if ref.Status != utils.AchievementStatusDraft {
-		return helper.BadRequest(c, "Prestasi hanya bisa diubah saat draft", nil)
+	if !contentEditable(ref.Status) {
+		return helper.BadRequest(c, "Prestasi hanya bisa diubah saat draft, ditolak atau revisi", nil)
 	}
 
 	var input models.AchievementCreateInput
@@ -663,9 +1147,25 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		return helper.NotFound(c, "Data prestasi tidak ditemukan")
 	}
 
-	// sanitize & filter details
-	sanitized := utils.SanitizeMongoMap(input.Details)
-	filtered := utils.FilterDetails(input.AchievementType, sanitized)
+	if input.AchievementType == "" {
+		input.AchievementType = ach.AchievementType
+	}
+
+	filtered, err := s.validateDetails(c, input.AchievementType, input.Details)
+	if filtered == nil {
+		return err
+	}
+
+	if err := s.reviseIfRejected(c, ref); err != nil {
+		return transitionError(c, err, "Gagal update status prestasi")
+	}
+
+	// status hanya berubah lewat transition; edit isi cukup memastikan
+	// status belum diubah request lain
+	if err := s.PgRepo.Touch(c.Context(), ref.ID, ref.Status); err != nil {
+		return transitionError(c, err, "Gagal update reference prestasi")
+	}
+	ref.UpdatedAt = time.Now()
 
 	ach.Title = input.Title
 	ach.Description = input.Description
@@ -677,13 +1177,49 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 	if err := s.MongoRepo.Update(c.Context(), ach); err != nil {
 		return helper.InternalServerError(c, "Gagal update prestasi")
 	}
-
-	ref.UpdatedAt = time.Now()
-	if err := s.PgRepo.Update(c.Context(), ref); err != nil {
-		return helper.InternalServerError(c, "Gagal update reference prestasi")
-	}
+	s.syncFacts(c, ach)
 
 	return helper.Success(c, "Prestasi berhasil diperbarui", fiber.Map{
 		"id": mongoID,
 	})
 }
+
+// Achievement revision diff
+// @Summary      Diff revisi prestasi
+// @Description  Menampilkan perubahan isi prestasi sejak review (penolakan) terakhir
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id path string true "Achievement ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievements/{id}/diff [get]
+func (s *AchievementService) Diff(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	ref, err := s.authorize(c, id, policy.ReadAchievement, "Tidak dapat melihat prestasi milik pengguna lain")
+	if ref == nil {
+		return err
+	}
+
+	snap, err := s.SnapshotRepo.Latest(c.Context(), ref.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil snapshot review")
+	}
+	if snap == nil {
+		return helper.NotFound(c, "Prestasi belum pernah direview")
+	}
+
+	ach, err := s.MongoRepo.FindByID(c.Context(), ref.MongoAchievementID)
+	if err != nil || ach == nil {
+		return helper.NotFound(c, "Data prestasi tidak ditemukan")
+	}
+
+	return helper.Success(c, "Perubahan sejak review terakhir", fiber.Map{
+		"status":         ref.Status,
+		"reviewed_at":    snap.CreatedAt,
+		"reviewed_by":    snap.ReviewedBy,
+		"rejection_note": ref.RejectionNote,
+		"changes":        workflow.Diff(snap.Content, workflow.Snapshot(ach)),
+	})
+}
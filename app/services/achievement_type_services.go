@@ -0,0 +1,156 @@
+package services
+
+import (
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/schema"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AchievementTypeService struct {
+	repo repository.AchievementTypeRepository
+}
+
+func NewAchievementTypeService(repo repository.AchievementTypeRepository) *AchievementTypeService {
+	return &AchievementTypeService{repo: repo}
+}
+
+// List godoc
+// @Summary      Daftar tipe prestasi
+// @Description  Mengambil tipe prestasi beserta spesifikasi field details. Gunakan all=true untuk menyertakan tipe nonaktif.
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        all  query bool false "Sertakan tipe nonaktif"
+// @Success      200 {object} models.MetaInfo
+// @Router       /achievement-types [get]
+func (s *AchievementTypeService) List(c *fiber.Ctx) error {
+	list, err := s.repo.FindAll(c.Context(), !c.QueryBool("all"))
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil tipe prestasi")
+	}
+
+	return helper.Success(c, "Daftar tipe prestasi", list)
+}
+
+// Detail godoc
+// @Summary      Detail tipe prestasi
+// @Description  Mengambil spesifikasi field details untuk satu tipe prestasi
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        code  path string true "Kode tipe"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievement-types/{code} [get]
+func (s *AchievementTypeService) Detail(c *fiber.Ctx) error {
+	t, err := s.findType(c)
+	if t == nil {
+		return err
+	}
+
+	return helper.Success(c, "Detail tipe prestasi", t)
+}
+
+// Create godoc
+// @Summary      Tambah tipe prestasi
+// @Description  Mendaftarkan tipe prestasi baru beserta spesifikasi field details
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.AchievementTypeRequest  true  "Tipe prestasi"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Router       /achievement-types [post]
+func (s *AchievementTypeService) Create(c *fiber.Ctx) error {
+	var req models.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if errs := schema.ValidateType(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	existing, err := s.repo.GetByCode(c.Context(), req.Code)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa tipe prestasi")
+	}
+	if existing != nil {
+		return helper.BadRequest(c, "Tipe prestasi sudah terdaftar", fiber.Map{"code": "code sudah dipakai"})
+	}
+
+	t := &models.AchievementType{
+		Code:     req.Code,
+		Name:     req.Name,
+		Fields:   req.Fields,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	}
+	if t.Fields == nil {
+		t.Fields = []models.FieldSpec{}
+	}
+
+	if err := s.repo.Create(c.Context(), t); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan tipe prestasi")
+	}
+
+	return helper.Created(c, "Tipe prestasi berhasil dibuat", t)
+}
+
+// Update godoc
+// @Summary      Ubah tipe prestasi
+// @Description  Mengganti nama, spesifikasi field atau status aktif tipe prestasi. Kode tipe tidak dapat diubah.
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        code  path  string                        true  "Kode tipe"
+// @Param        body  body  models.AchievementTypeRequest  true  "Tipe prestasi"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /achievement-types/{code} [put]
+func (s *AchievementTypeService) Update(c *fiber.Ctx) error {
+	t, err := s.findType(c)
+	if t == nil {
+		return err
+	}
+
+	var req models.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	req.Code = t.Code
+	if errs := schema.ValidateType(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	t.Name = req.Name
+	if req.Fields != nil {
+		t.Fields = req.Fields
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.Update(c.Context(), t); err != nil {
+		return helper.InternalServerError(c, "Gagal update tipe prestasi")
+	}
+
+	return helper.Success(c, "Tipe prestasi berhasil diperbarui", t)
+}
+
+func (s *AchievementTypeService) findType(c *fiber.Ctx) (*models.AchievementType, error) {
+	t, err := s.repo.GetByCode(c.Context(), c.Params("code"))
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal mengambil tipe prestasi")
+	}
+	if t == nil {
+		return nil, helper.NotFound(c, "Tipe prestasi tidak ditemukan")
+	}
+	return t, nil
+}
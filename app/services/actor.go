@@ -0,0 +1,65 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentActor membangun policy.Actor dari locals AuthRequired beserta
+// profil mahasiswa / dosen milik user. Hasilnya di-cache per request.
+func currentActor(
+	c *fiber.Ctx,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+) (policy.Actor, error) {
+	if cached, ok := c.Locals("actor").(policy.Actor); ok {
+		return cached, nil
+	}
+
+	actor := policy.Actor{}
+	actor.UserID, _ = c.Locals("user_id").(string)
+	actor.Role, _ = c.Locals("role_id").(string)
+	actor.Permissions, _ = c.Locals("permissions").([]string)
+
+	if studentRepo != nil {
+		student, err := studentRepo.GetByUserID(c.Context(), actor.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return actor, err
+		}
+		if student != nil {
+			actor.StudentID = student.ID
+		}
+	}
+
+	if lecturerRepo != nil {
+		lecturer, err := lecturerRepo.GetByUserID(c.Context(), actor.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return actor, err
+		}
+		if lecturer != nil {
+			actor.LecturerID = lecturer.ID
+		}
+	}
+
+	c.Locals("actor", actor)
+	return actor, nil
+}
+
+// studentSubject membentuk policy.Subject dari profil mahasiswa.
+func studentSubject(st *models.Student, status string) policy.Subject {
+	return policy.Subject{
+		StudentID: st.ID,
+		AdvisorID: st.AdvisorID,
+		Status:    status,
+	}
+}
+
+// lecturerSubject membentuk policy.Subject dari profil dosen.
+func lecturerSubject(l *models.Lecturer) policy.Subject {
+	return policy.Subject{LecturerID: l.ID}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnalyticsService struct {
+	AnalyticsRepo repository.AnalyticsRepository
+	StudentRepo   repository.StudentRepository
+	LecturerRepo  repository.LecturerRepository
+}
+
+func NewAnalyticsService(
+	analyticsRepo repository.AnalyticsRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+) *AnalyticsService {
+	return &AnalyticsService{
+		AnalyticsRepo: analyticsRepo,
+		StudentRepo:   studentRepo,
+		LecturerRepo:  lecturerRepo,
+	}
+}
+
+var competitionLevelLabels = map[string]string{
+	"international": "Internasional",
+	"national":      "Nasional",
+	"regional":      "Regional",
+	"local":         "Lokal",
+}
+
+// Analytics aggregate
+// @Summary      Analitik prestasi
+// @Description  Jumlah dan total poin prestasi per bulan, semester, program studi, angkatan, tipe, tingkat kompetisi atau dosen wali
+// @Tags         Reports
+// @Security     BearerAuth
+// @Produce      json
+// @Param        dimension         path  string true  "month, semester, program_study, academic_year, achievement_type, competition_level atau advisor"
+// @Param        date_field        query string false "Tanggal acuan: verified_at (default), submitted_at atau created_at"
+// @Param        date_from         query string false "Awal rentang tanggal (YYYY-MM-DD)"
+// @Param        date_to           query string false "Akhir rentang tanggal (YYYY-MM-DD, inklusif)"
+// @Param        status            query string false "Status dipisah koma (default verified)"
+// @Param        program_study     query string false "Program studi"
+// @Param        academic_year     query string false "Angkatan"
+// @Param        achievement_type  query string false "Kode tipe prestasi"
+// @Param        competition_level query string false "Tingkat kompetisi"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /reports/analytics/{dimension} [get]
+func (s *AnalyticsService) Aggregate(c *fiber.Ctx) error {
+	dimension := c.Params("dimension")
+
+	filter, errs := parseAnalyticsFilter(c)
+	if !repository.IsAnalyticsDimension(dimension) {
+		errs["dimension"] = "dimension harus salah satu dari month, semester, program_study, academic_year, achievement_type, competition_level, advisor"
+	}
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Parameter analitik tidak valid", errs)
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+
+	switch policy.ListScope(actor) {
+	case policy.ScopeAll:
+	case policy.ScopeOwn:
+		filter.StudentIDs = []string{actor.StudentID}
+	case policy.ScopeAdvisees:
+		filter.AdvisorID = actor.LecturerID
+		// draft bersifat privat bagi mahasiswa
+		filter.Statuses = without(filter.Statuses, utils.AchievementStatusDraft)
+		if len(filter.Statuses) == 0 {
+			return helper.BadRequest(c, "Parameter analitik tidak valid", fiber.Map{
+				"status": "dosen wali tidak dapat melihat prestasi draft",
+			})
+		}
+	default:
+		return helper.Forbidden(c, "Role tidak memiliki akses")
+	}
+
+	buckets, err := s.AnalyticsRepo.Aggregate(c.Context(), dimension, filter)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil data analitik")
+	}
+
+	count, points := 0, 0
+	for i := range buckets {
+		buckets[i].Label = bucketLabel(dimension, buckets[i])
+		count += buckets[i].Count
+		points += buckets[i].Points
+	}
+
+	return helper.Success(c, "Data analitik berhasil diambil", fiber.Map{
+		"dimension":  dimension,
+		"date_field": filter.DateField,
+		"statuses":   filter.Statuses,
+		"buckets":    buckets,
+		"total":      fiber.Map{"count": count, "points": points},
+	})
+}
+
+// parseAnalyticsFilter membaca query parameter analitik.
+func parseAnalyticsFilter(c *fiber.Ctx) (models.AnalyticsFilter, map[string]string) {
+	errs := map[string]string{}
+
+	f := models.AnalyticsFilter{
+		DateField:        c.Query("date_field", "verified_at"),
+		ProgramStudy:     strings.TrimSpace(c.Query("program_study")),
+		AcademicYear:     strings.TrimSpace(c.Query("academic_year")),
+		AchievementType:  strings.TrimSpace(c.Query("achievement_type")),
+		CompetitionLevel: strings.ToLower(strings.TrimSpace(c.Query("competition_level"))),
+	}
+
+	if !repository.IsAnalyticsDateField(f.DateField) {
+		errs["date_field"] = "date_field harus salah satu dari verified_at, submitted_at, created_at"
+	}
+
+	for _, st := range splitQuery(c.Query("status")) {
+		if !filterableStatuses[st] {
+			errs["status"] = "status tidak dikenal: " + st
+			continue
+		}
+		f.Statuses = append(f.Statuses, st)
+	}
+	if len(f.Statuses) == 0 {
+		f.Statuses = []string{utils.AchievementStatusVerified}
+	}
+
+	f.From, f.To = parseDateRange(c, "date", errs)
+
+	return f, errs
+}
+
+// bucketLabel menentukan label tampilan untuk key hasil agregasi.
+func bucketLabel(dimension string, b models.AnalyticsBucket) string {
+	if b.Key == "" {
+		return "Tidak diketahui"
+	}
+
+	switch dimension {
+	case "semester":
+		return semesterLabel(b.Key)
+	case "competition_level":
+		if label, ok := competitionLevelLabels[b.Key]; ok {
+			return label
+		}
+	}
+
+	if b.Label != "" {
+		return b.Label
+	}
+	return b.Key
+}
+
+// semesterLabel mengubah key "2024-1" menjadi "2024/2025 Ganjil".
+func semesterLabel(key string) string {
+	year, term, ok := strings.Cut(key, "-")
+	start, err := strconv.Atoi(year)
+	if !ok || err != nil {
+		return key
+	}
+
+	name := "Ganjil"
+	if term == "2" {
+		name = "Genap"
+	}
+	return fmt.Sprintf("%d/%d %s", start, start+1, name)
+}
+
+func without(list []string, value string) []string {
+	out := []string{}
+	for _, v := range list {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// AttachmentPolicy membatasi jenis dan ukuran lampiran prestasi.
+type AttachmentPolicy struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+	// URLTTL adalah masa berlaku URL unduhan yang diterbitkan.
+	URLTTL time.Duration
+}
+
+// allowedAttachmentTypes memetakan MIME hasil sniffing ke ekstensi file
+// yang disimpan.
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+// AttachmentPolicyFromEnv membaca ATTACHMENT_MAX_FILE_MB (default 5),
+// ATTACHMENT_MAX_TOTAL_MB (default 20) dan ATTACHMENT_URL_TTL_MINUTES
+// (default 10).
+func AttachmentPolicyFromEnv() AttachmentPolicy {
+	envInt := func(key string, def int) int {
+		v, err := strconv.Atoi(os.Getenv(key))
+		if err != nil || v <= 0 {
+			return def
+		}
+		return v
+	}
+
+	return AttachmentPolicy{
+		MaxFileSize:  int64(envInt("ATTACHMENT_MAX_FILE_MB", 5)) << 20,
+		MaxTotalSize: int64(envInt("ATTACHMENT_MAX_TOTAL_MB", 20)) << 20,
+		URLTTL:       time.Duration(envInt("ATTACHMENT_URL_TTL_MINUTES", 10)) * time.Minute,
+	}
+}
+
+// multipartOverhead adalah ruang untuk boundary dan header setiap bagian
+// form multipart di atas isi file.
+const multipartOverhead = 1 << 20
+
+// BodyLimit adalah ukuran body request terbesar yang harus diterima server
+// agar upload hingga MaxTotalSize sampai ke pemeriksaan lampiran.
+func (p AttachmentPolicy) BodyLimit() int {
+	return int(p.MaxTotalSize + multipartOverhead)
+}
+
+// pendingFile adalah file upload yang sudah lolos validasi dan siap disimpan.
+type pendingFile struct {
+	name        string
+	contentType string
+	ext         string
+	checksum    string
+	data        []byte
+}
+
+// readAttachment membaca file upload lalu memeriksa ukuran dan jenisnya.
+// Jenis file ditentukan dari isi (sniffing), bukan dari header klien.
+func (p AttachmentPolicy) readAttachment(fh *multipart.FileHeader) (*pendingFile, string) {
+	if fh.Size > p.MaxFileSize {
+		return nil, fmt.Sprintf("Ukuran file melebihi batas %s", formatSize(p.MaxFileSize))
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "Gagal membaca file"
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, p.MaxFileSize+1))
+	if err != nil {
+		return nil, "Gagal membaca file"
+	}
+	if int64(len(data)) > p.MaxFileSize {
+		return nil, fmt.Sprintf("Ukuran file melebihi batas %s", formatSize(p.MaxFileSize))
+	}
+	if len(data) == 0 {
+		return nil, "File kosong"
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, "Jenis file tidak diizinkan (hanya PDF, JPEG, PNG, WebP)"
+	}
+
+	sum := sha256.Sum256(data)
+
+	return &pendingFile{
+		name:        sanitizeFileName(fh.Filename, ext),
+		contentType: contentType,
+		ext:         ext,
+		checksum:    hex.EncodeToString(sum[:]),
+		data:        data,
+	}, ""
+}
+
+// sanitizeFileName menyisakan nama dasar file untuk ditampilkan. Nama ini
+// tidak pernah dipakai sebagai path penyimpanan.
+func sanitizeFileName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == "/" {
+		return "lampiran" + ext
+	}
+
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func formatSize(n int64) string {
+	if n%(1<<20) == 0 {
+		return fmt.Sprintf("%d MB", n>>20)
+	}
+	return fmt.Sprintf("%d KB", n>>10)
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 	"uas/app/models"
 	"uas/app/repository"
 	"uas/helper"
@@ -10,16 +12,40 @@ import (
 	_ "uas/cmd/docs"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
-	
+	"github.com/google/uuid"
 )
 
 type AuthService struct {
-	repo repository.AuthRepository
+	repo        repository.AuthRepository
+	revocations repository.TokenRevocationRepository
+	sessions    repository.SessionRepository
+	resets      repository.PasswordResetRepository
+	mailer      utils.Mailer
+	guard       *loginGuard
+	mfa         repository.MFARepository
 }
 
-func NewAuthService(repo repository.AuthRepository) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(
+	repo repository.AuthRepository,
+	revocations repository.TokenRevocationRepository,
+	sessions repository.SessionRepository,
+	resets repository.PasswordResetRepository,
+	mailer utils.Mailer,
+	attempts repository.LoginAttemptRepository,
+	mfa repository.MFARepository,
+) *AuthService {
+	return &AuthService{
+		repo:        repo,
+		revocations: revocations,
+		sessions:    sessions,
+		resets:      resets,
+		mailer:      mailer,
+		guard: &loginGuard{
+			repo:   attempts,
+			policy: LoginPolicyFromEnv(),
+		},
+		mfa: mfa,
+	}
 }
 
 // Register godoc
@@ -72,7 +98,7 @@ func (s *AuthService) Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary      Login user
-// @Description  Login menggunakan email dan password
+// @Description  Login menggunakan email dan password, membuat session baru. Jika MFA aktif / diwajibkan role, mengembalikan challenge token untuk /auth/mfa/verify atau /auth/mfa/enroll.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -80,6 +106,7 @@ func (s *AuthService) Register(c *fiber.Ctx) error {
 // @Success      200   {object}  models.MetaInfo
 // @Failure      401   {object}  models.MetaInfo
 // @Failure      403   {object}  models.MetaInfo
+// @Failure      429   {object}  models.MetaInfo
 // @Router       /auth/login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
 	var req models.LoginReq
@@ -88,24 +115,57 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Format request tidak valid", err.Error())
 	}
 
-	user, err := s.repo.GetUserByEmail(req.Email)
+	wait, locked, err := s.guard.check(c.Context(), req.Email, c.IP())
 	if err != nil {
-		return helper.Unauthorized(c, "Email tidak ditemukan")
+		return helper.InternalServerError(c, "Gagal memeriksa percobaan login")
+	}
+	if locked {
+		return helper.TooManyRequests(c, "Akun atau IP dikunci sementara karena terlalu banyak percobaan login", wait)
+	}
+	if wait > 0 {
+		return helper.TooManyRequests(c, "Terlalu banyak percobaan login, coba lagi nanti", wait)
 	}
 
-	if !utils.CheckPassword(req.Password, user.PasswordHash) {
-		return helper.Unauthorized(c, "Password salah")
+	// Password tetap dibandingkan walau akun tidak ditemukan supaya waktu
+	// respon tidak membocorkan email mana yang terdaftar.
+	user, err := s.repo.GetUserByEmail(req.Email)
+	hash := utils.UnusablePasswordHash
+	if err == nil && user != nil {
+		hash = user.PasswordHash
+	}
+	if !utils.CheckPassword(req.Password, hash) {
+		if err := s.guard.fail(c.Context(), req.Email, c.IP()); err != nil {
+			helper.Log.Error().Err(err).Msg("gagal mencatat percobaan login")
+		}
+		return helper.Unauthorized(c, "Email atau password salah")
 	}
 
+	// Status akun baru diungkap setelah password terbukti benar.
 	if !user.IsActive {
 		return helper.Forbidden(c, "Akun tidak aktif")
 	}
 
-	permissions, _ := s.repo.GetPermissionsByUserID(user.ID)
+	challenge, err := s.mfaChallenge(c.Context(), user)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if challenge != nil {
+		return helper.Success(c, "Verifikasi MFA diperlukan", challenge)
+	}
+
+	return s.completeLogin(c, user, "Login berhasil", nil)
+}
+
+// completeLogin mereset counter login gagal, membuat session dan
+// mengembalikan token ke client.
+func (s *AuthService) completeLogin(c *fiber.Ctx, user *models.UserWithRole, message string, recoveryCodes []string) error {
+	if err := s.guard.succeed(c.Context(), user.Email); err != nil {
+		helper.Log.Error().Err(err).Msg("gagal mereset percobaan login")
+	}
 
-	token, err := utils.GenerateToken(user.ID, user.RoleName, permissions)
-	refreshToken, _ := utils.GenerateRefreshToken(user.ID)
+	permissions, _ := s.repo.GetPermissionsByUserID(user.ID)
 
+	token, refreshToken, err := s.startSession(c, user, permissions)
 	if err != nil {
 		return helper.InternalServerError(c, "Gagal membuat token")
 	}
@@ -121,15 +181,50 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		},
 		Token:         token,
 		RefreshToken:  refreshToken,
+		RecoveryCodes: recoveryCodes,
+	}
+
+	return helper.Success(c, message, response)
+}
+
+// startSession membuat session baru untuk user lalu menerbitkan
+// access token dan refresh token pertama dari session tersebut.
+func (s *AuthService) startSession(c *fiber.Ctx, user *models.UserWithRole, permissions []string) (string, string, error) {
+	sessionID := uuid.NewString()
+
+	refreshToken, rc, err := utils.GenerateRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+		ExpiresAt: rc.ExpiresAt.Time,
+	}
+
+	if err := s.sessions.Create(c.Context(), session, &models.RefreshToken{
+		JTI:       rc.ID,
+		SessionID: sessionID,
+		ExpiresAt: rc.ExpiresAt.Time,
+	}); err != nil {
+		return "", "", err
 	}
 
-	return helper.Success(c, "Login berhasil", response)
+	token, err := utils.GenerateToken(user.ID, user.RoleName, sessionID, permissions)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
 }
 
 
 // Refresh godoc
 // @Summary      Refresh access token
-// @Description  Generate access token baru menggunakan refresh token
+// @Description  Tukar refresh token dengan access token dan refresh token baru (rotasi). Pemakaian ulang refresh token lama mencabut seluruh session.
 // @Tags         Auth
 // @Security     BearerAuth
 // @Produce      json
@@ -144,39 +239,83 @@ func (s *AuthService) Refresh(c *fiber.Ctx) error {
 
 	refreshToken := strings.TrimPrefix(authHeader, "Bearer ")
 
-	if utils.TokenBlacklist[refreshToken] {
-		return helper.Unauthorized(c, "Token sudah logout")
+	claims, err := utils.ValidateRefreshToken(refreshToken)
+	if err != nil || claims.Subject == "" || claims.ID == "" || claims.SessionID == "" {
+		return helper.Unauthorized(c, "Refresh token tidak valid")
 	}
 
-	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_REFRESH_SECRET")), nil
-	})
+	revoked, err := s.revocations.IsRevoked(c.Context(), claims.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status token")
+	}
+	if revoked {
+		return helper.Unauthorized(c, "Token sudah logout")
+	}
 
-	if err != nil || !token.Valid {
+	stored, err := s.sessions.GetRefreshToken(c.Context(), claims.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa refresh token")
+	}
+	if stored == nil || stored.SessionID != claims.SessionID {
 		return helper.Unauthorized(c, "Refresh token tidak valid")
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	userID := claims["sub"].(string)
+	session, err := s.sessions.GetByID(c.Context(), claims.SessionID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa session")
+	}
+	if session == nil || session.RevokedAt != nil || session.UserID != claims.Subject {
+		return helper.Unauthorized(c, "Session sudah tidak berlaku")
+	}
+
+	if stored.UsedAt != nil {
+		_ = s.sessions.Revoke(c.Context(), session.ID, models.SessionRevokeReuse)
+		return helper.Unauthorized(c, "Refresh token sudah pernah dipakai, session dicabut")
+	}
+
+	userID := claims.Subject
 
 	user, err := s.repo.GetUserByID(userID)
-	if err != nil || !user.IsActive {
+	if err != nil || user == nil || !user.IsActive {
 		return helper.Forbidden(c, "Akun tidak valid atau tidak aktif")
 	}
 
+	newRefresh, rc, err := utils.GenerateRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat token")
+	}
+
+	rotated, err := s.sessions.Rotate(c.Context(), claims.ID, &models.RefreshToken{
+		JTI:       rc.ID,
+		SessionID: session.ID,
+		ExpiresAt: rc.ExpiresAt.Time,
+	})
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memperbarui session")
+	}
+	if !rotated {
+		// token lama dipakai bersamaan oleh pihak lain
+		_ = s.sessions.Revoke(c.Context(), session.ID, models.SessionRevokeReuse)
+		return helper.Unauthorized(c, "Refresh token sudah pernah dipakai, session dicabut")
+	}
+
 	perms, _ := s.repo.GetPermissionsByUserID(userID)
 
-	newToken, _ := utils.GenerateToken(user.ID, user.RoleName, perms)
+	newToken, err := utils.GenerateToken(user.ID, user.RoleName, session.ID, perms)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat token")
+	}
 
 	return helper.Success(c, "Token diperbarui", models.RefreshResp{
-		AccessToken: newToken,
+		AccessToken:  newToken,
+		RefreshToken: newRefresh,
 	})
 }
 
 
 // Logout godoc
 // @Summary      Logout user
-// @Description  Logout dan blacklist token
+// @Description  Logout, cabut access token dan session yang sedang dipakai
 // @Tags         Auth
 // @Security     BearerAuth
 // @Produce      json
@@ -190,11 +329,225 @@ func (s *AuthService) Logout(c *fiber.Ctx) error {
 	}
 
 	token := strings.TrimPrefix(authHeader, "Bearer ")
-	utils.TokenBlacklist[token] = true
+
+	claims, err := utils.ValidateToken(token)
+	if err != nil || claims.ID == "" {
+		return helper.Unauthorized(c, "Token tidak valid")
+	}
+
+	if err := s.revocations.Revoke(c.Context(), claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return helper.InternalServerError(c, "Gagal logout")
+	}
+
+	if claims.SessionID != "" {
+		if err := s.sessions.Revoke(c.Context(), claims.SessionID, models.SessionRevokeLogout); err != nil {
+			return helper.InternalServerError(c, "Gagal logout")
+		}
+	}
 
 	return helper.Success(c, "Logout berhasil", nil)
 }
 
+// Sessions godoc
+// @Summary      Daftar session aktif
+// @Description  Mengambil daftar session (device) aktif milik user yang sedang login
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200   {object}  models.MetaInfo
+// @Failure      401   {object}  models.MetaInfo
+// @Router       /auth/sessions [get]
+func (s *AuthService) Sessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	currentID, _ := c.Locals("session_id").(string)
+
+	sessions, err := s.sessions.ListActiveByUserID(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil session")
+	}
+
+	list := make([]models.SessionResponse, 0, len(sessions))
+	for _, ss := range sessions {
+		list = append(list, models.SessionResponse{
+			ID:         ss.ID,
+			UserAgent:  ss.UserAgent,
+			IPAddress:  ss.IPAddress,
+			CreatedAt:  ss.CreatedAt,
+			LastUsedAt: ss.LastUsedAt,
+			ExpiresAt:  ss.ExpiresAt,
+			Current:    ss.ID == currentID,
+		})
+	}
+
+	return helper.Success(c, "Daftar session aktif", list)
+}
+
+// RevokeSession godoc
+// @Summary      Cabut session
+// @Description  Mencabut satu session milik user yang sedang login
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  string  true  "Session ID"
+// @Success      200  {object}  models.MetaInfo
+// @Failure      404  {object}  models.MetaInfo
+// @Router       /auth/sessions/{id} [delete]
+func (s *AuthService) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	sessionID := c.Params("id")
+
+	if !utils.IsUUID(sessionID) {
+		return helper.NotFound(c, "Session tidak ditemukan")
+	}
+
+	session, err := s.sessions.GetByID(c.Context(), sessionID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil session")
+	}
+	if session == nil || session.UserID != userID {
+		return helper.NotFound(c, "Session tidak ditemukan")
+	}
+
+	if err := s.sessions.Revoke(c.Context(), session.ID, models.SessionRevokeUser); err != nil {
+		return helper.InternalServerError(c, "Gagal mencabut session")
+	}
+
+	return helper.Success(c, "Session berhasil dicabut", nil)
+}
+
+// RevokeOtherSessions godoc
+// @Summary      Cabut semua session lain
+// @Description  Mencabut semua session milik user kecuali session yang sedang dipakai
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  models.MetaInfo
+// @Router       /auth/sessions [delete]
+func (s *AuthService) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	currentID, _ := c.Locals("session_id").(string)
+
+	if err := s.sessions.RevokeAllByUserID(c.Context(), userID, models.SessionRevokeUser, currentID); err != nil {
+		return helper.InternalServerError(c, "Gagal mencabut session")
+	}
+
+	return helper.Success(c, "Session lain berhasil dicabut", nil)
+}
+
+// ForgotPassword godoc
+// @Summary      Lupa password
+// @Description  Mengirim link reset password ke email. Respon selalu sama walaupun email tidak terdaftar.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.ForgotPasswordReq  true  "Email akun"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Router       /auth/forgot-password [post]
+func (s *AuthService) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordReq
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if strings.TrimSpace(req.Email) == "" {
+		return helper.BadRequest(c, "Email wajib diisi", nil)
+	}
+
+	const message = "Jika email terdaftar, link reset password akan dikirim"
+
+	user, err := s.repo.GetUserByEmail(req.Email)
+	if err != nil || user == nil || !user.IsActive {
+		return helper.Success(c, message, nil)
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat token reset")
+	}
+
+	reset := &models.PasswordReset{
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		RequestedIP: c.IP(),
+		ExpiresAt:   time.Now().Add(passwordResetTTL()),
+	}
+
+	if err := s.resets.Create(c.Context(), reset); err != nil {
+		return helper.InternalServerError(c, "Gagal membuat token reset")
+	}
+
+	mail := utils.MailMessage{
+		To:      user.Email,
+		Subject: "Reset password akun prestasi",
+		Body: "Halo " + user.FullName + ",\n\n" +
+			"Gunakan link berikut untuk mengatur ulang password Anda:\n" +
+			os.Getenv("APP_URL") + "/reset-password?token=" + token + "\n\n" +
+			"Link berlaku sampai " + utils.FormatDateTime(reset.ExpiresAt) + " dan hanya dapat dipakai satu kali.\n" +
+			"Abaikan email ini jika Anda tidak meminta reset password.",
+	}
+
+	if err := s.mailer.Send(c.Context(), mail); err != nil {
+		helper.Log.Error().Err(err).Str("user_id", user.ID).Msg("gagal mengirim email reset password")
+	}
+
+	return helper.Success(c, message, nil)
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Mengganti password menggunakan token reset dan mencabut semua session user
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.ResetPasswordReq  true  "Token dan password baru"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Router       /auth/reset-password [post]
+func (s *AuthService) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordReq
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if req.Token == "" {
+		return helper.BadRequest(c, "Token wajib diisi", nil)
+	}
+
+	if len(req.Password) < 8 {
+		return helper.BadRequest(c, "Password minimal 8 karakter", nil)
+	}
+
+	hashed, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memproses password")
+	}
+
+	userID, err := s.resets.ResetPassword(c.Context(), utils.HashToken(req.Token), hashed)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal reset password")
+	}
+	if userID == "" {
+		return helper.BadRequest(c, "Token tidak valid atau sudah kedaluwarsa", nil)
+	}
+
+	if err := s.sessions.RevokeAllByUserID(c.Context(), userID, models.SessionRevokePasswordReset, ""); err != nil {
+		return helper.InternalServerError(c, "Gagal mencabut session")
+	}
+
+	return helper.Success(c, "Password berhasil direset, silakan login kembali", nil)
+}
+
+func passwordResetTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 // Profile godoc
 // @Summary      Ambil profil user
 // @Description  Mengambil data user yang sedang login
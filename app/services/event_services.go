@@ -0,0 +1,172 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"uas/app/events"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventService struct {
+	Repo         repository.EventRepository
+	Broker       events.Broker
+	StudentRepo  repository.StudentRepository
+	LecturerRepo repository.LecturerRepository
+
+	// Heartbeat menjaga koneksi tetap terbuka melewati proxy dan mendeteksi
+	// klien yang sudah pergi.
+	Heartbeat time.Duration
+	// ReplayBatch adalah jumlah event log yang diambil per query saat resume.
+	ReplayBatch int
+}
+
+func NewEventService(
+	repo repository.EventRepository,
+	broker events.Broker,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+) *EventService {
+	return &EventService{
+		Repo:         repo,
+		Broker:       broker,
+		StudentRepo:  studentRepo,
+		LecturerRepo: lecturerRepo,
+
+		Heartbeat:   20 * time.Second,
+		ReplayBatch: 200,
+	}
+}
+
+// Event stream
+// @Summary      Stream event real-time
+// @Description  Server-Sent Events berisi perubahan status prestasi dan notifikasi baru yang boleh dilihat pengguna. Kirim header Last-Event-ID (atau query last_event_id) untuk melanjutkan setelah terputus. Token dapat dikirim lewat query access_token untuk EventSource.
+// @Tags         Events
+// @Security     BearerAuth
+// @Produce      text/event-stream
+// @Param        last_event_id query int    false "ID event terakhir yang diterima"
+// @Param        access_token  query string false "Access token (pengganti header Authorization)"
+// @Success      200 {string} string "text/event-stream"
+// @Failure      400 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Router       /events/stream [get]
+func (s *EventService) Stream(c *fiber.Ctx) error {
+	lastID := int64(0)
+	raw := c.Get("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			return helper.BadRequest(c, "Last-Event-ID tidak valid", nil)
+		}
+		lastID = id
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+	audience := eventAudience(actor)
+
+	// berlangganan sebelum replay agar event baru di antaranya tidak terlewat
+	live, unsubscribe := s.Broker.Subscribe()
+
+	// tanpa Last-Event-ID stream dimulai dari event berikutnya. Batch
+	// pertama diambil di sini agar kegagalannya masih dapat dilaporkan.
+	var batch []models.Event
+	if raw != "" {
+		batch, err = s.Repo.Replay(c.Context(), audience, lastID, s.ReplayBatch)
+		if err != nil {
+			unsubscribe()
+			return helper.InternalServerError(c, "Gagal mengambil event")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+
+		// log dikirim per batch segera setelah dibaca sehingga backlog yang
+		// panjang tidak ditampung di memori
+		for len(batch) > 0 {
+			for i := range batch {
+				writeEvent(w, &batch[i])
+			}
+			lastID = batch[len(batch)-1].ID
+			if w.Flush() != nil || len(batch) < s.ReplayBatch {
+				break
+			}
+
+			batch, err = s.Repo.Replay(context.Background(), audience, lastID, s.ReplayBatch)
+			if err != nil {
+				// klien tersambung ulang dengan Last-Event-ID terakhir
+				helper.Log.Warn().Err(err).Int64("last_event_id", lastID).Msg("gagal mengambil event")
+				return
+			}
+		}
+		if w.Flush() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(s.Heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case ev, ok := <-live:
+				if !ok {
+					return
+				}
+				// event yang sudah dikirim dari log bisa juga datang dari broker
+				if ev.ID <= lastID || !audience.Sees(&ev) {
+					continue
+				}
+				writeEvent(w, &ev)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func eventAudience(actor policy.Actor) models.EventAudience {
+	a := models.EventAudience{UserID: actor.UserID}
+	switch policy.ListScope(actor) {
+	case policy.ScopeAll:
+		a.All = true
+	case policy.ScopeOwn:
+		a.StudentID = actor.StudentID
+	case policy.ScopeAdvisees:
+		a.LecturerID = actor.LecturerID
+	}
+	return a
+}
+
+func writeEvent(w *bufio.Writer, ev *models.Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"mime"
+	"path"
+
+	"uas/app/storage"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FileService melayani URL unduhan bertanda tangan untuk backend storage
+// lokal. Backend S3 menerbitkan presigned URL ke layanan object storage
+// sehingga endpoint ini tidak dipakai.
+type FileService struct {
+	local *storage.Local
+}
+
+func NewFileService(store storage.Storage) *FileService {
+	local, _ := store.(*storage.Local)
+	return &FileService{local: local}
+}
+
+// Download godoc
+// @Summary      Unduh file
+// @Description  Mengunduh file lampiran lewat URL bertanda tangan dari endpoint URL unduhan lampiran. Tidak memerlukan token login.
+// @Tags         Files
+// @Produce      octet-stream
+// @Param        key       path  string true "Key file"
+// @Param        expires   query int    true "Batas waktu (unix)"
+// @Param        signature query string true "Tanda tangan"
+// @Success      200 {file} file
+// @Failure      403 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /files/{key} [get]
+func (s *FileService) Download(c *fiber.Ctx) error {
+	if s.local == nil {
+		return helper.NotFound(c, "File tidak ditemukan")
+	}
+
+	key := c.Params("*")
+	if err := s.local.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		return helper.Forbidden(c, "Tautan unduhan tidak valid atau sudah kedaluwarsa")
+	}
+
+	file, err := s.local.Get(c.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return helper.NotFound(c, "File tidak ditemukan")
+	}
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membaca file")
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	return c.SendStream(file)
+}
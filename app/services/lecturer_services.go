@@ -1,8 +1,12 @@
 package services
 
 import (
+	"strings"
+	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
 	"uas/helper"
+	"uas/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -71,3 +75,87 @@ func (s *LecturerService) List(c *fiber.Ctx) error {
 		lecturers,
 	)
 }
+
+// UpdateProfile
+// @Summary      Update profil dosen
+// @Description  Admin dapat mengubah seluruh field (NIDN, departemen, kontak); dosen hanya dapat mengubah kontak (phone, office) miliknya sendiri. Field yang tidak dikirim tidak diubah, string kosong mengosongkan field opsional.
+// @Tags         Lecturers & Students
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "Lecturer ID"
+// @Param        body  body models.LecturerProfileUpdateRequest true "Data profil"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /lecturers/{id}/profile [put]
+func (s *LecturerService) UpdateProfile(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsUUID(id) {
+		return helper.NotFound(c, "Dosen tidak ditemukan")
+	}
+
+	lecturer, err := s.lecturerRepo.GetByID(c.Context(), id)
+	if err != nil || lecturer == nil {
+		return helper.NotFound(c, "Dosen tidak ditemukan")
+	}
+
+	actor, err := currentActor(c, s.studentRepo, s.lecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+	if !policy.Can(actor, policy.UpdateContact, lecturerSubject(lecturer)) {
+		return helper.Forbidden(c, "Tidak memiliki akses ke profil dosen ini")
+	}
+
+	var req models.LecturerProfileUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	academic := req.LecturerID != nil || req.Department != nil
+	if academic && !policy.Can(actor, policy.UpdateProfile, lecturerSubject(lecturer)) {
+		return helper.Forbidden(c, "Hanya admin yang dapat mengubah NIDN dan departemen")
+	}
+
+	updated := *lecturer
+	errs := map[string]string{}
+
+	if req.LecturerID != nil {
+		updated.LecturerID = strings.TrimSpace(*req.LecturerID)
+		if msg := validateLecturerID(updated.LecturerID); msg != "" {
+			errs["lecturer_id"] = msg
+		}
+	}
+	setProfileText(&updated.Department, req.Department, "department", maxDepartmentLen, errs)
+	setProfilePhone(&updated.Phone, req.Phone, errs)
+	setProfileText(&updated.Office, req.Office, "office", maxOfficeLen, errs)
+
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Data profil tidak valid", errs)
+	}
+
+	taken := map[string]string{"lecturer_id": "sudah terdaftar"}
+	if updated.LecturerID != lecturer.LecturerID {
+		ids, err := s.lecturerRepo.FindTakenLecturerIDs(c.Context(), []string{updated.LecturerID})
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa NIDN")
+		}
+		if ids[updated.LecturerID] {
+			return helper.BadRequest(c, "Data profil tidak valid", taken)
+		}
+	}
+
+	if err := s.lecturerRepo.UpdateProfile(c.Context(), &updated); err != nil {
+		// NIDN dapat direbut request lain di antara pengecekan dan update
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Data profil tidak valid", taken)
+		}
+		return helper.InternalServerError(c, "Gagal memperbarui profil dosen")
+	}
+
+	return helper.Success(c, "Profil dosen berhasil diperbarui", updated)
+}
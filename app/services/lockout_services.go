@@ -0,0 +1,63 @@
+package services
+
+import (
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LockoutService struct {
+	attemptRepo repository.LoginAttemptRepository
+}
+
+func NewLockoutService(attemptRepo repository.LoginAttemptRepository) *LockoutService {
+	return &LockoutService{attemptRepo: attemptRepo}
+}
+
+// List godoc
+// @Summary      Daftar lockout login
+// @Description  Menampilkan akun / IP yang terkunci atau memiliki percobaan login gagal (Admin only)
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Router       /lockouts [get]
+func (s *LockoutService) List(c *fiber.Ctx) error {
+	list, err := s.attemptRepo.FindActive(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil data lockout")
+	}
+
+	return helper.Success(c, "Daftar lockout login", list)
+}
+
+// Clear godoc
+// @Summary      Hapus lockout login
+// @Description  Menghapus counter gagal dan lockout untuk satu akun / IP (Admin only)
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path string true "Lockout ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /lockouts/{id} [delete]
+func (s *LockoutService) Clear(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsUUID(id) {
+		return helper.NotFound(c, "Lockout tidak ditemukan")
+	}
+
+	deleted, err := s.attemptRepo.DeleteByID(c.Context(), id)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal menghapus lockout")
+	}
+	if !deleted {
+		return helper.NotFound(c, "Lockout tidak ditemukan")
+	}
+
+	return helper.Success(c, "Lockout berhasil dihapus", nil)
+}
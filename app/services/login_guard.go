@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+)
+
+// LoginPolicy mengatur delay bertahap dan lockout sementara untuk login gagal.
+type LoginPolicy struct {
+	DelayAfter       int
+	MaxDelay         time.Duration
+	AccountThreshold int
+	IPThreshold      int
+	LockoutDuration  time.Duration
+	Window           time.Duration
+}
+
+func LoginPolicyFromEnv() LoginPolicy {
+	envInt := func(key string, def int) int {
+		v, err := strconv.Atoi(os.Getenv(key))
+		if err != nil || v <= 0 {
+			return def
+		}
+		return v
+	}
+
+	return LoginPolicy{
+		DelayAfter:       envInt("LOGIN_DELAY_AFTER", 3),
+		MaxDelay:         time.Duration(envInt("LOGIN_MAX_DELAY_SECONDS", 60)) * time.Second,
+		AccountThreshold: envInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		IPThreshold:      envInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockoutDuration:  time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		Window:           time.Duration(envInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+	}
+}
+
+// delayFor menghitung jeda minimum sebelum percobaan berikutnya
+// (1s, 2s, 4s, ... dibatasi MaxDelay).
+func (p LoginPolicy) delayFor(failed int) time.Duration {
+	if failed < p.DelayAfter {
+		return 0
+	}
+
+	d := time.Duration(math.Pow(2, float64(failed-p.DelayAfter))) * time.Second
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+func (p LoginPolicy) thresholdFor(scope string) int {
+	if scope == models.LoginScopeIP {
+		return p.IPThreshold
+	}
+	return p.AccountThreshold
+}
+
+type loginGuard struct {
+	repo   repository.LoginAttemptRepository
+	policy LoginPolicy
+}
+
+func loginKeys(email, ip string) map[string]string {
+	return map[string]string{
+		models.LoginScopeAccount: strings.ToLower(strings.TrimSpace(email)),
+		models.LoginScopeIP:      ip,
+	}
+}
+
+// check mengembalikan sisa waktu tunggu jika akun / IP sedang terkunci
+// atau masih dalam masa delay.
+func (g *loginGuard) check(ctx context.Context, email, ip string) (time.Duration, bool, error) {
+	now := time.Now()
+
+	var wait time.Duration
+	locked := false
+
+	for scope, identifier := range loginKeys(email, ip) {
+		a, err := g.repo.Get(ctx, scope, identifier)
+		if err != nil {
+			return 0, false, err
+		}
+		if a == nil {
+			continue
+		}
+
+		if a.LockedUntil != nil && a.LockedUntil.After(now) {
+			locked = true
+			if d := a.LockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+			continue
+		}
+
+		if a.LastFailedAt != nil && now.Sub(*a.LastFailedAt) < g.policy.Window {
+			next := a.LastFailedAt.Add(g.policy.delayFor(a.FailedCount))
+			if d := next.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+
+	return wait, locked, nil
+}
+
+func (g *loginGuard) fail(ctx context.Context, email, ip string) error {
+	for scope, identifier := range loginKeys(email, ip) {
+		a, err := g.repo.RegisterFailure(ctx, scope, identifier, g.policy.Window)
+		if err != nil {
+			return err
+		}
+
+		if a != nil && a.FailedCount >= g.policy.thresholdFor(scope) {
+			if err := g.repo.Lock(ctx, a.ID, time.Now().Add(g.policy.LockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *loginGuard) succeed(ctx context.Context, email string) error {
+	return g.repo.Reset(ctx, models.LoginScopeAccount, strings.ToLower(strings.TrimSpace(email)))
+}
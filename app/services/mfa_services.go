@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"os"
+	"time"
+	"uas/app/models"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	mfaChallengeTTL  = 5 * time.Minute
+	mfaRecoveryCodes = 10
+	defaultMFAIssuer = "Prestasi Mahasiswa"
+)
+
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultMFAIssuer
+}
+
+// mfaChallenge mengembalikan challenge jika user harus menyelesaikan MFA
+// (sudah aktif) atau mendaftarkan MFA (diwajibkan role tetapi belum aktif).
+func (s *AuthService) mfaChallenge(ctx context.Context, user *models.UserWithRole) (*models.MFAChallengeResponse, error) {
+	m, err := s.mfa.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := ""
+	switch {
+	case m != nil && m.Enabled:
+		purpose = models.MFAPurposeVerify
+	case user.MFARequired:
+		purpose = models.MFAPurposeEnroll
+	default:
+		return nil, nil
+	}
+
+	token, _, err := utils.GenerateMFAChallengeToken(user.ID, purpose, mfaChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: purpose == models.MFAPurposeEnroll,
+		ChallengeToken:     token,
+		ExpiresIn:          int(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+// challengeUser memvalidasi challenge token (sekali pakai) dan mengambil user-nya.
+func (s *AuthService) challengeUser(c *fiber.Ctx, token, purpose string) (*models.MFAChallengeClaims, *models.UserWithRole, error) {
+	claims, err := utils.ValidateMFAChallengeToken(token, purpose)
+	if err != nil {
+		return nil, nil, helper.Unauthorized(c, "Challenge token tidak valid atau kedaluwarsa")
+	}
+
+	revoked, err := s.revocations.IsRevoked(c.Context(), claims.ID)
+	if err != nil {
+		return nil, nil, helper.InternalServerError(c, "Gagal memeriksa status token")
+	}
+	if revoked {
+		return nil, nil, helper.Unauthorized(c, "Challenge token sudah dipakai")
+	}
+
+	user, err := s.repo.GetUserByID(claims.Subject)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, nil, helper.Forbidden(c, "Akun tidak valid atau tidak aktif")
+	}
+
+	wait, _, err := s.guard.check(c.Context(), user.Email, c.IP())
+	if err != nil {
+		return nil, nil, helper.InternalServerError(c, "Gagal memeriksa percobaan login")
+	}
+	if wait > 0 {
+		return nil, nil, helper.TooManyRequests(c, "Terlalu banyak percobaan verifikasi, coba lagi nanti", wait)
+	}
+
+	return claims, user, nil
+}
+
+// checkCode memvalidasi kode TOTP atau recovery code milik user.
+// Kode TOTP yang sudah dipakai tidak bisa dipakai ulang.
+func (s *AuthService) checkCode(ctx context.Context, m *models.UserMFA, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		normalized := utils.NormalizeRecoveryCode(recoveryCode)
+		ok, err := s.mfa.UseRecoveryCode(ctx, m.UserID, utils.HashToken(normalized))
+		if ok || err != nil || len(normalized) != 10 {
+			return ok, err
+		}
+		// recovery code lama di-hash beserta tanda hubungnya
+		return s.mfa.UseRecoveryCode(ctx, m.UserID, utils.HashToken(normalized[:5]+"-"+normalized[5:]))
+	}
+
+	step, ok := utils.ValidateTOTP(m.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return s.mfa.MarkStepUsed(ctx, m.UserID, step)
+}
+
+// enable mengaktifkan MFA setelah kode pertama dari authenticator
+// cocok, lalu mengembalikan recovery code dalam bentuk plain (sekali tampil).
+func (s *AuthService) enable(ctx context.Context, m *models.UserMFA, code string) ([]string, bool, error) {
+	step, ok := utils.ValidateTOTP(m.Secret, code, time.Now())
+	if !ok {
+		return nil, false, nil
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(mfaRecoveryCodes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, rc := range codes {
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(rc)))
+	}
+
+	if err := s.mfa.Enable(ctx, m.UserID, step, hashes); err != nil {
+		return nil, false, err
+	}
+
+	return codes, true, nil
+}
+
+func (s *AuthService) newSecret(ctx context.Context, user *models.UserWithRole) (*models.MFASetupResponse, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfa.SaveSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(mfaIssuer(), user.Email, secret),
+	}, nil
+}
+
+func (s *AuthService) consumeChallenge(c *fiber.Ctx, claims *models.MFAChallengeClaims) error {
+	return s.revocations.Revoke(c.Context(), claims.ID, claims.Subject, claims.ExpiresAt.Time)
+}
+
+// VerifyMFA godoc
+// @Summary      Verifikasi MFA saat login
+// @Description  Tukar challenge token dari /auth/login dan kode TOTP (atau recovery code) dengan access token dan refresh token
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.MFAVerifyReq  true  "Challenge token dan kode"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      401   {object}  models.MetaInfo
+// @Failure      429   {object}  models.MetaInfo
+// @Router       /auth/mfa/verify [post]
+func (s *AuthService) VerifyMFA(c *fiber.Ctx) error {
+	var req models.MFAVerifyReq
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if req.Code == "" && req.RecoveryCode == "" {
+		return helper.BadRequest(c, "Kode MFA atau recovery code wajib diisi", nil)
+	}
+
+	claims, user, err := s.challengeUser(c, req.ChallengeToken, models.MFAPurposeVerify)
+	if claims == nil {
+		return err
+	}
+
+	m, err := s.mfa.Get(c.Context(), user.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if m == nil || !m.Enabled {
+		return helper.Unauthorized(c, "MFA tidak aktif untuk akun ini")
+	}
+
+	ok, err := s.checkCode(c.Context(), m, req.Code, req.RecoveryCode)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memverifikasi kode MFA")
+	}
+	if !ok {
+		if err := s.guard.fail(c.Context(), user.Email, c.IP()); err != nil {
+			helper.Log.Error().Err(err).Msg("gagal mencatat percobaan login")
+		}
+		return helper.Unauthorized(c, "Kode MFA tidak valid")
+	}
+
+	if err := s.consumeChallenge(c, claims); err != nil {
+		return helper.InternalServerError(c, "Gagal memproses challenge token")
+	}
+
+	return s.completeLogin(c, user, "Login berhasil", nil)
+}
+
+// EnrollMFA godoc
+// @Summary      Mulai pendaftaran MFA wajib
+// @Description  Untuk role yang mewajibkan MFA: membuat secret TOTP baru menggunakan challenge token enrollment dari /auth/login
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.MFAEnrollReq  true  "Challenge token"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      401   {object}  models.MetaInfo
+// @Router       /auth/mfa/enroll [post]
+func (s *AuthService) EnrollMFA(c *fiber.Ctx) error {
+	var req models.MFAEnrollReq
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	claims, user, err := s.challengeUser(c, req.ChallengeToken, models.MFAPurposeEnroll)
+	if claims == nil {
+		return err
+	}
+
+	setup, err := s.newSecret(c.Context(), user)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat secret MFA")
+	}
+
+	return helper.Success(c, "Scan QR code lalu konfirmasi dengan kode dari aplikasi authenticator", setup)
+}
+
+// ConfirmEnrollMFA godoc
+// @Summary      Konfirmasi pendaftaran MFA wajib
+// @Description  Mengaktifkan MFA dengan kode TOTP pertama, lalu menyelesaikan login dan mengembalikan recovery code
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.MFAEnrollReq  true  "Challenge token dan kode"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Failure      401   {object}  models.MetaInfo
+// @Router       /auth/mfa/enroll/confirm [post]
+func (s *AuthService) ConfirmEnrollMFA(c *fiber.Ctx) error {
+	var req models.MFAEnrollReq
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	claims, user, err := s.challengeUser(c, req.ChallengeToken, models.MFAPurposeEnroll)
+	if claims == nil {
+		return err
+	}
+
+	m, err := s.mfa.Get(c.Context(), user.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if m == nil || m.Enabled {
+		return helper.BadRequest(c, "Jalankan /auth/mfa/enroll terlebih dahulu", nil)
+	}
+
+	codes, ok, err := s.enable(c.Context(), m, req.Code)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengaktifkan MFA")
+	}
+	if !ok {
+		if err := s.guard.fail(c.Context(), user.Email, c.IP()); err != nil {
+			helper.Log.Error().Err(err).Msg("gagal mencatat percobaan login")
+		}
+		return helper.BadRequest(c, "Kode MFA tidak valid", nil)
+	}
+
+	if err := s.consumeChallenge(c, claims); err != nil {
+		return helper.InternalServerError(c, "Gagal memproses challenge token")
+	}
+
+	return s.completeLogin(c, user, "MFA aktif, simpan recovery code di tempat aman", codes)
+}
+
+// MFAStatus godoc
+// @Summary      Status MFA
+// @Description  Menampilkan apakah MFA aktif dan apakah diwajibkan oleh role user
+// @Tags         MFA
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200   {object}  models.MetaInfo
+// @Router       /auth/mfa [get]
+func (s *AuthService) MFAStatus(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return helper.NotFound(c, "User tidak ditemukan")
+	}
+
+	m, err := s.mfa.Get(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+
+	status := models.MFAStatusResponse{Required: user.MFARequired}
+	if m != nil && m.Enabled {
+		status.Enabled = true
+		status.Since = m.EnabledAt
+	}
+
+	return helper.Success(c, "Status MFA", status)
+}
+
+// SetupMFA godoc
+// @Summary      Setup MFA
+// @Description  Membuat secret TOTP baru beserta provisioning URI untuk QR code. MFA belum aktif sampai dikonfirmasi lewat /auth/mfa/enable.
+// @Tags         MFA
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Router       /auth/mfa/setup [post]
+func (s *AuthService) SetupMFA(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return helper.NotFound(c, "User tidak ditemukan")
+	}
+
+	m, err := s.mfa.Get(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if m != nil && m.Enabled {
+		return helper.BadRequest(c, "MFA sudah aktif", nil)
+	}
+
+	setup, err := s.newSecret(c.Context(), user)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat secret MFA")
+	}
+
+	return helper.Success(c, "Scan QR code lalu konfirmasi dengan kode dari aplikasi authenticator", setup)
+}
+
+// EnableMFA godoc
+// @Summary      Aktifkan MFA
+// @Description  Mengaktifkan MFA dengan kode TOTP pertama dan mengembalikan recovery code (hanya ditampilkan sekali)
+// @Tags         MFA
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.MFACodeReq  true  "Kode TOTP"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Router       /auth/mfa/enable [post]
+func (s *AuthService) EnableMFA(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req models.MFACodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	m, err := s.mfa.Get(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if m == nil {
+		return helper.BadRequest(c, "Jalankan /auth/mfa/setup terlebih dahulu", nil)
+	}
+	if m.Enabled {
+		return helper.BadRequest(c, "MFA sudah aktif", nil)
+	}
+
+	codes, ok, err := s.enable(c.Context(), m, req.Code)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengaktifkan MFA")
+	}
+	if !ok {
+		return helper.BadRequest(c, "Kode MFA tidak valid", nil)
+	}
+
+	return helper.Success(c, "MFA aktif, simpan recovery code di tempat aman", models.MFAEnableResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableMFA godoc
+// @Summary      Nonaktifkan MFA
+// @Description  Menonaktifkan MFA dengan kode TOTP atau recovery code. Tidak diizinkan jika role mewajibkan MFA.
+// @Tags         MFA
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.MFACodeReq  true  "Kode TOTP atau recovery code"
+// @Success      200   {object}  models.MetaInfo
+// @Failure      400   {object}  models.MetaInfo
+// @Failure      403   {object}  models.MetaInfo
+// @Router       /auth/mfa/disable [post]
+func (s *AuthService) DisableMFA(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req models.MFACodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return helper.NotFound(c, "User tidak ditemukan")
+	}
+	if user.MFARequired {
+		return helper.Forbidden(c, "MFA wajib untuk role ini")
+	}
+
+	m, err := s.mfa.Get(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa status MFA")
+	}
+	if m == nil || !m.Enabled {
+		return helper.BadRequest(c, "MFA belum aktif", nil)
+	}
+
+	ok, err := s.checkCode(c.Context(), m, req.Code, req.RecoveryCode)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memverifikasi kode MFA")
+	}
+	if !ok {
+		return helper.BadRequest(c, "Kode MFA tidak valid", nil)
+	}
+
+	if err := s.mfa.Disable(c.Context(), userID); err != nil {
+		return helper.InternalServerError(c, "Gagal menonaktifkan MFA")
+	}
+
+	return helper.Success(c, "MFA berhasil dinonaktifkan", nil)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"uas/app/models"
+	"uas/app/notify"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationService struct {
+	Repo     repository.NotificationRepository
+	Notifier *notify.Notifier
+}
+
+func NewNotificationService(repo repository.NotificationRepository, notifier *notify.Notifier) *NotificationService {
+	return &NotificationService{
+		Repo:     repo,
+		Notifier: notifier,
+	}
+}
+
+// List notifications
+// @Summary      Daftar notifikasi
+// @Description  Notifikasi milik pengguna yang sedang login, terbaru lebih dulu, beserta jumlah yang belum dibaca
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Param        unread query bool false "Hanya yang belum dibaca"
+// @Param        page   query int  false "Halaman"
+// @Param        limit  query int  false "Jumlah per halaman"
+// @Success      200 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /notifications [get]
+func (s *NotificationService) List(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	items, total, err := s.Repo.FindByUser(c.Context(), userID, c.QueryBool("unread"), limit, (page-1)*limit)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil notifikasi")
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+
+	unread, err := s.Repo.CountUnread(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil notifikasi")
+	}
+
+	meta := models.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalData:  total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+
+	return helper.Paginated(c, "Notifikasi ditemukan", fiber.Map{
+		"items":  items,
+		"unread": unread,
+	}, meta)
+}
+
+// Mark notification as read
+// @Summary      Tandai notifikasi dibaca
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Param        id path string true "Notification ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /notifications/{id}/read [patch]
+func (s *NotificationService) MarkRead(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	found, err := s.Repo.MarkRead(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memperbarui notifikasi")
+	}
+	if !found {
+		return helper.NotFound(c, "Notifikasi tidak ditemukan")
+	}
+
+	return helper.Success(c, "Notifikasi ditandai sudah dibaca", nil)
+}
+
+// Mark all notifications as read
+// @Summary      Tandai semua notifikasi dibaca
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Success      200 {object} models.MetaInfo
+// @Router       /notifications/read-all [post]
+func (s *NotificationService) MarkAllRead(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	n, err := s.Repo.MarkAllRead(c.Context(), userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memperbarui notifikasi")
+	}
+
+	return helper.Success(c, "Semua notifikasi ditandai sudah dibaca", fiber.Map{
+		"updated": n,
+	})
+}
+
+// Get notification preferences
+// @Summary      Preferensi kanal notifikasi
+// @Description  Status aktif setiap kanal pengiriman; kanal yang belum diatur dianggap aktif
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Router       /notifications/preferences [get]
+func (s *NotificationService) GetPreferences(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	prefs, err := s.preferences(c, userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil preferensi notifikasi")
+	}
+
+	return helper.Success(c, "Preferensi notifikasi ditemukan", prefs)
+}
+
+// Update notification preferences
+// @Summary      Ubah preferensi kanal notifikasi
+// @Description  Mengaktifkan atau mematikan kanal pengiriman, mis. {"email": false}. Kanal in_app tidak dapat dimatikan.
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body object true "Kanal dan status aktifnya"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Router       /notifications/preferences [put]
+func (s *NotificationService) UpdatePreferences(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	var req map[string]bool
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	known := map[string]bool{}
+	for _, name := range s.Notifier.ChannelNames() {
+		known[name] = true
+	}
+
+	errs := map[string]string{}
+	for channel, enabled := range req {
+		switch {
+		case !known[channel]:
+			errs[channel] = "kanal tidak dikenal"
+		case channel == notify.ChannelInApp && !enabled:
+			errs[channel] = "kanal in_app tidak dapat dimatikan"
+		}
+	}
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Preferensi notifikasi tidak valid", errs)
+	}
+	// in_app selalu aktif sehingga tidak perlu disimpan
+	delete(req, notify.ChannelInApp)
+
+	if len(req) > 0 {
+		if err := s.Repo.SetPreferences(c.Context(), userID, req); err != nil {
+			return helper.InternalServerError(c, "Gagal menyimpan preferensi notifikasi")
+		}
+	}
+
+	prefs, err := s.preferences(c, userID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil preferensi notifikasi")
+	}
+
+	return helper.Success(c, "Preferensi notifikasi berhasil diperbarui", prefs)
+}
+
+// preferences melengkapi preferensi tersimpan dengan kanal yang belum
+// pernah diatur (aktif).
+func (s *NotificationService) preferences(c *fiber.Ctx, userID string) (map[string]bool, error) {
+	saved, err := s.Repo.Preferences(c.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := map[string]bool{}
+	for _, name := range s.Notifier.ChannelNames() {
+		enabled, ok := saved[name]
+		prefs[name] = !ok || enabled
+	}
+	prefs[notify.ChannelInApp] = true
+	return prefs, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var permissionPart = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+type PermissionService struct {
+	permissionRepo repository.PermissionRepository
+}
+
+func NewPermissionService(permissionRepo repository.PermissionRepository) *PermissionService {
+	return &PermissionService{permissionRepo: permissionRepo}
+}
+
+// validatePermissionRequest memastikan nama permission berformat resource:action.
+func validatePermissionRequest(req *models.PermissionRequest) map[string]string {
+	errs := map[string]string{}
+
+	req.Resource = strings.ToLower(strings.TrimSpace(req.Resource))
+	req.Action = strings.ToLower(strings.TrimSpace(req.Action))
+
+	if !permissionPart.MatchString(req.Resource) {
+		errs["resource"] = "resource wajib diisi (huruf kecil, angka, _ atau -)"
+	}
+	if !permissionPart.MatchString(req.Action) {
+		errs["action"] = "action wajib diisi (huruf kecil, angka, _ atau -)"
+	}
+
+	return errs
+}
+
+func (s *PermissionService) findPermission(c *fiber.Ctx) (*models.Permission, error) {
+	id := c.Params("id")
+	if !utils.IsUUID(id) {
+		return nil, helper.NotFound(c, "Permission tidak ditemukan")
+	}
+
+	p, err := s.permissionRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal mengambil permission")
+	}
+	if p == nil {
+		return nil, helper.NotFound(c, "Permission tidak ditemukan")
+	}
+
+	return p, nil
+}
+
+// List godoc
+// @Summary      Daftar permission
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Router       /permissions [get]
+func (s *PermissionService) List(c *fiber.Ctx) error {
+	list, err := s.permissionRepo.FindAll(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil permission")
+	}
+
+	return helper.Success(c, "Daftar permission", list)
+}
+
+// Create godoc
+// @Summary      Buat permission
+// @Description  Membuat permission baru dengan nama resource:action
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.PermissionRequest  true  "Permission"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Router       /permissions [post]
+func (s *PermissionService) Create(c *fiber.Ctx) error {
+	var req models.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if errs := validatePermissionRequest(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	p := &models.Permission{
+		Name:        req.Resource + ":" + req.Action,
+		Resource:    req.Resource,
+		Action:      req.Action,
+		Description: req.Description,
+	}
+
+	if err := s.permissionRepo.Create(c.Context(), p); err != nil {
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Permission sudah ada", nil)
+		}
+		return helper.InternalServerError(c, "Gagal membuat permission")
+	}
+
+	return helper.Created(c, "Permission berhasil dibuat", p)
+}
+
+// Update godoc
+// @Summary      Update permission
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string  true  "Permission ID"
+// @Param        body  body  models.PermissionRequest  true  "Permission"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /permissions/{id} [put]
+func (s *PermissionService) Update(c *fiber.Ctx) error {
+	p, err := s.findPermission(c)
+	if p == nil {
+		return err
+	}
+
+	if p.Name == utils.PermissionAll {
+		return helper.BadRequest(c, "Permission \"*\" tidak dapat diubah", nil)
+	}
+
+	var req models.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if errs := validatePermissionRequest(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	p.Name = req.Resource + ":" + req.Action
+	p.Resource = req.Resource
+	p.Action = req.Action
+	p.Description = req.Description
+
+	if err := s.permissionRepo.Update(c.Context(), p); err != nil {
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Permission sudah ada", nil)
+		}
+		return helper.InternalServerError(c, "Gagal memperbarui permission")
+	}
+
+	return helper.Success(c, "Permission berhasil diperbarui", p)
+}
+
+// Delete godoc
+// @Summary      Hapus permission
+// @Description  Menghapus permission beserta seluruh penugasannya ke role
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path string true "Permission ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /permissions/{id} [delete]
+func (s *PermissionService) Delete(c *fiber.Ctx) error {
+	p, err := s.findPermission(c)
+	if p == nil {
+		return err
+	}
+
+	if p.Name == utils.PermissionAll {
+		return helper.BadRequest(c, "Permission \"*\" tidak dapat dihapus", nil)
+	}
+
+	if err := s.permissionRepo.Delete(c.Context(), p.ID); err != nil {
+		return helper.InternalServerError(c, "Gagal menghapus permission")
+	}
+
+	return helper.Success(c, "Permission berhasil dihapus", nil)
+}
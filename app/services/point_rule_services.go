@@ -0,0 +1,155 @@
+package services
+
+import (
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/scoring"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PointRuleService struct {
+	repo repository.PointRuleRepository
+}
+
+func NewPointRuleService(repo repository.PointRuleRepository) *PointRuleService {
+	return &PointRuleService{repo: repo}
+}
+
+// Active godoc
+// @Summary      Aturan poin aktif
+// @Description  Mengambil tabel aturan poin yang sedang dipakai saat verifikasi
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /point-rules [get]
+func (s *PointRuleService) Active(c *fiber.Ctx) error {
+	set, err := s.repo.Active(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil aturan poin")
+	}
+	if set == nil {
+		return helper.NotFound(c, "Belum ada aturan poin aktif")
+	}
+
+	return helper.Success(c, "Aturan poin aktif", set)
+}
+
+// Versions godoc
+// @Summary      Riwayat versi aturan poin
+// @Description  Mengambil seluruh versi aturan poin (tanpa isi aturan)
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Router       /point-rules/versions [get]
+func (s *PointRuleService) Versions(c *fiber.Ctx) error {
+	list, err := s.repo.ListVersions(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil versi aturan poin")
+	}
+
+	return helper.Success(c, "Daftar versi aturan poin", list)
+}
+
+// Version godoc
+// @Summary      Detail versi aturan poin
+// @Description  Mengambil isi aturan poin pada versi tertentu
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        version  path int true "Versi"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /point-rules/versions/{version} [get]
+func (s *PointRuleService) Version(c *fiber.Ctx) error {
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 1 {
+		return helper.NotFound(c, "Versi aturan poin tidak ditemukan")
+	}
+
+	set, err := s.repo.GetByVersion(c.Context(), version)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil aturan poin")
+	}
+	if set == nil {
+		return helper.NotFound(c, "Versi aturan poin tidak ditemukan")
+	}
+
+	return helper.Success(c, "Detail aturan poin", set)
+}
+
+// Create godoc
+// @Summary      Buat versi aturan poin
+// @Description  Menyimpan tabel aturan poin lengkap sebagai versi baru. Secara default versi baru langsung aktif.
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.PointRuleSetRequest  true  "Aturan poin"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Router       /point-rules [post]
+func (s *PointRuleService) Create(c *fiber.Ctx) error {
+	var req models.PointRuleSetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	for i := range req.Rules {
+		req.Rules[i].AchievementType = strings.TrimSpace(req.Rules[i].AchievementType)
+		req.Rules[i].Field = strings.TrimSpace(req.Rules[i].Field)
+		req.Rules[i].Value = strings.TrimSpace(req.Rules[i].Value)
+	}
+
+	if errs := scoring.Validate(req.Rules); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	activate := req.Activate == nil || *req.Activate
+
+	set := &models.PointRuleSet{Rules: req.Rules}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		set.Note = &note
+	}
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		set.CreatedBy = &userID
+	}
+
+	if err := s.repo.Create(c.Context(), set, activate); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan aturan poin")
+	}
+
+	return helper.Created(c, "Versi aturan poin berhasil dibuat", set)
+}
+
+// Activate godoc
+// @Summary      Aktifkan versi aturan poin
+// @Description  Menjadikan versi tertentu sebagai aturan poin aktif
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        version  path int true "Versi"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /point-rules/versions/{version}/activate [post]
+func (s *PointRuleService) Activate(c *fiber.Ctx) error {
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 1 {
+		return helper.NotFound(c, "Versi aturan poin tidak ditemukan")
+	}
+
+	ok, err := s.repo.Activate(c.Context(), version)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengaktifkan aturan poin")
+	}
+	if !ok {
+		return helper.NotFound(c, "Versi aturan poin tidak ditemukan")
+	}
+
+	return helper.Success(c, "Versi aturan poin diaktifkan", fiber.Map{"version": version})
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"uas/app/export"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/portfolio"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PortfolioService struct {
+	PortfolioRepo repository.PortfolioRepository
+	RefRepo       repository.AchievementReferenceRepository
+	MongoRepo     repository.AchievementMongoRepository
+	StudentRepo   repository.StudentRepository
+	LecturerRepo  repository.LecturerRepository
+	UserRepo      repository.UserRepository
+	TypeRepo      repository.AchievementTypeRepository
+
+	Signer portfolio.Signer
+	Now    func() time.Time
+}
+
+func NewPortfolioService(
+	portfolioRepo repository.PortfolioRepository,
+	refRepo repository.AchievementReferenceRepository,
+	mongoRepo repository.AchievementMongoRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	userRepo repository.UserRepository,
+	typeRepo repository.AchievementTypeRepository,
+	signer portfolio.Signer,
+) *PortfolioService {
+	return &PortfolioService{
+		PortfolioRepo: portfolioRepo,
+		RefRepo:       refRepo,
+		MongoRepo:     mongoRepo,
+		StudentRepo:   studentRepo,
+		LecturerRepo:  lecturerRepo,
+		UserRepo:      userRepo,
+		TypeRepo:      typeRepo,
+
+		Signer: signer,
+		Now:    time.Now,
+	}
+}
+
+// Issue portfolio
+// @Summary      Terbitkan portofolio prestasi
+// @Description  Menyusun prestasi terverifikasi mahasiswa per tipe (SKPI), menandatanganinya dan memberi kode verifikasi
+// @Tags         Students
+// @Security     BearerAuth
+// @Produce      json
+// @Produce      application/pdf
+// @Param        id     path  string true  "Student ID"
+// @Param        format query string false "Format dokumen (json, pdf); dapat juga lewat header Accept"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /students/{id}/portfolio [post]
+func (s *PortfolioService) Issue(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	format, err := export.Negotiate(c.Query("format"), c.Get(fiber.HeaderAccept))
+	if err != nil || (format != "" && format != export.PDF) {
+		return helper.BadRequest(c, "Format portofolio harus json atau pdf", nil)
+	}
+
+	student, err := s.StudentRepo.FindByID(ctx, c.Params("id"))
+	if err != nil || student == nil {
+		return helper.NotFound(c, "Mahasiswa tidak ditemukan")
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+	if !policy.Can(actor, policy.ReadStudent, studentSubject(student, "")) {
+		return helper.Forbidden(c, "Tidak dapat menerbitkan portofolio mahasiswa ini")
+	}
+
+	doc, err := s.compile(ctx, student)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal menyusun portofolio")
+	}
+
+	code, err := portfolio.NewCode()
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal membuat kode verifikasi")
+	}
+	doc.VerificationCode = code
+
+	signature, err := s.Signer.Sign(doc)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal menandatangani portofolio")
+	}
+
+	issued := &models.Portfolio{
+		VerificationCode: code,
+		StudentID:        student.ID,
+		Document:         *doc,
+		Signature:        signature,
+		IssuedAt:         doc.IssuedAt,
+	}
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		issued.IssuedBy = &userID
+	}
+
+	if err := s.PortfolioRepo.Create(ctx, issued); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan portofolio")
+	}
+
+	if format == export.PDF {
+		meta := export.Document{
+			Institution: doc.Institution,
+			Title:       "Portofolio Prestasi Mahasiswa",
+			Subtitle:    portfolioSubtitle(doc, signature),
+			GeneratedAt: doc.IssuedAt,
+		}
+		return sendExport(c, format, "portofolio-"+doc.Student.StudentCode, meta, func(w export.Writer) error {
+			return writePortfolio(w, doc)
+		})
+	}
+
+	return helper.Created(c, "Portofolio berhasil diterbitkan", fiber.Map{
+		"document":   doc,
+		"signature":  signature,
+		"verify_url": portfolio.VerifyURL(code),
+	})
+}
+
+// Verify portfolio
+// @Summary      Verifikasi portofolio
+// @Description  Memeriksa keaslian portofolio prestasi berdasarkan kode verifikasi (publik)
+// @Tags         Portfolios
+// @Produce      json
+// @Param        code path string true "Kode verifikasi"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /portfolios/verify/{code} [get]
+func (s *PortfolioService) Verify(c *fiber.Ctx) error {
+	code := portfolio.NormalizeCode(c.Params("code"))
+	if code == "" {
+		return helper.BadRequest(c, "Format kode verifikasi tidak valid", nil)
+	}
+
+	issued, err := s.PortfolioRepo.GetByCode(c.Context(), code)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa kode verifikasi")
+	}
+	if issued == nil {
+		return helper.NotFound(c, "Kode verifikasi tidak ditemukan")
+	}
+
+	// dokumen yang diubah setelah diterbitkan tidak lagi cocok dengan tanda tangan
+	if issued.Document.VerificationCode != issued.VerificationCode || !s.Signer.Verify(&issued.Document, issued.Signature) {
+		return helper.Success(c, "Portofolio tidak valid", fiber.Map{
+			"valid":             false,
+			"verification_code": code,
+		})
+	}
+
+	return helper.Success(c, "Portofolio valid", fiber.Map{
+		"valid":             true,
+		"verification_code": code,
+		"issued_at":         issued.IssuedAt,
+		"document":          issued.Document,
+		"signature":         issued.Signature,
+	})
+}
+
+// compile menyusun isi portofolio dari prestasi terverifikasi mahasiswa.
+func (s *PortfolioService) compile(ctx context.Context, student *models.Student) (*models.PortfolioDocument, error) {
+	// dibulatkan ke detik dan UTC agar JSON yang disimpan identik saat diverifikasi
+	doc := &models.PortfolioDocument{
+		Institution: export.InstitutionFromEnv(),
+		IssuedAt:    s.Now().UTC().Truncate(time.Second),
+		Student: models.PortfolioStudent{
+			ID:          student.ID,
+			StudentCode: student.StudentID,
+		},
+		Groups: []models.PortfolioGroup{},
+	}
+	if student.ProgramStudy != nil {
+		doc.Student.ProgramStudy = *student.ProgramStudy
+	}
+	if student.AcademicYear != nil {
+		doc.Student.AcademicYear = *student.AcademicYear
+	}
+
+	names := map[string]string{}
+	userName := func(id string) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		if u, err := s.UserRepo.GetByID(id); err == nil && u != nil {
+			names[id] = u.FullName
+		} else {
+			names[id] = ""
+		}
+		return names[id]
+	}
+	doc.Student.Name = userName(student.UserID)
+
+	refs, err := s.RefRepo.FindByStudentID(ctx, student.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := make([]models.AchievementReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Status == utils.AchievementStatusVerified {
+			verified = append(verified, ref)
+		}
+	}
+
+	docs, dangling, err := stitchAchievements(ctx, s.MongoRepo, verified, "title", "achievementType", "points")
+	if err != nil {
+		return nil, err
+	}
+	if len(dangling) > 0 {
+		helper.Log.Warn().Strs("achievements", dangling).Str("student_id", student.ID).Msg("prestasi terverifikasi tanpa dokumen dilewati dari portofolio")
+	}
+
+	types, err := s.TypeRepo.FindAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	typeNames := make(map[string]string, len(types))
+	for _, t := range types {
+		typeNames[t.Code] = t.Name
+	}
+
+	groups := map[string]*models.PortfolioGroup{}
+	for i, ref := range verified {
+		ach := docs[i]
+		if ach == nil {
+			continue
+		}
+
+		g, ok := groups[ach.AchievementType]
+		if !ok {
+			g = &models.PortfolioGroup{
+				AchievementType: ach.AchievementType,
+				TypeName:        typeNames[ach.AchievementType],
+				Achievements:    []models.PortfolioEntry{},
+			}
+			if g.TypeName == "" {
+				g.TypeName = ach.AchievementType
+			}
+			groups[ach.AchievementType] = g
+		}
+
+		entry := models.PortfolioEntry{
+			ID:     ach.ID.Hex(),
+			Title:  ach.Title,
+			Points: ach.Points,
+		}
+		if ref.VerifiedAt != nil {
+			at := ref.VerifiedAt.UTC()
+			entry.VerifiedAt = &at
+		}
+		if ref.VerifiedBy != nil {
+			entry.VerifiedBy = userName(*ref.VerifiedBy)
+		}
+
+		g.Achievements = append(g.Achievements, entry)
+		g.Points += entry.Points
+		doc.TotalAchievements++
+		doc.TotalPoints += entry.Points
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.Achievements, func(i, j int) bool {
+			a, b := g.Achievements[i].VerifiedAt, g.Achievements[j].VerifiedAt
+			if a == nil || b == nil {
+				return b == nil && a != nil
+			}
+			return a.Before(*b)
+		})
+		doc.Groups = append(doc.Groups, *g)
+	}
+	sort.Slice(doc.Groups, func(i, j int) bool {
+		return doc.Groups[i].TypeName < doc.Groups[j].TypeName
+	})
+
+	return doc, nil
+}
+
+func portfolioSubtitle(doc *models.PortfolioDocument, signature string) []string {
+	lines := []string{fmt.Sprintf("Mahasiswa: %s (%s)", doc.Student.Name, doc.Student.StudentCode)}
+	if doc.Student.ProgramStudy != "" {
+		lines = append(lines, "Program studi: "+doc.Student.ProgramStudy)
+	}
+	if doc.Student.AcademicYear != "" {
+		lines = append(lines, "Angkatan: "+doc.Student.AcademicYear)
+	}
+	return append(lines,
+		"Kode verifikasi: "+doc.VerificationCode+" ("+portfolio.VerifyURL(doc.VerificationCode)+")",
+		"Tanda tangan: "+signature,
+	)
+}
+
+func writePortfolio(w export.Writer, doc *models.PortfolioDocument) error {
+	for _, g := range doc.Groups {
+		title := fmt.Sprintf("%s (%d prestasi, %d poin)", g.TypeName, len(g.Achievements), g.Points)
+		if err := w.Table(title, []string{"No", "Judul", "Diverifikasi", "Verifikator", "Poin"}); err != nil {
+			return err
+		}
+		for i, e := range g.Achievements {
+			if err := w.Row(i+1, e.Title, e.VerifiedAt, e.VerifiedBy, e.Points); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.Table("Ringkasan", []string{"Tipe", "Jumlah", "Poin"}); err != nil {
+		return err
+	}
+	for _, g := range doc.Groups {
+		if err := w.Row(g.TypeName, len(g.Achievements), g.Points); err != nil {
+			return err
+		}
+	}
+	return w.Row("Total", doc.TotalAchievements, doc.TotalPoints)
+}
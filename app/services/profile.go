@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"uas/utils"
+	"unicode/utf8"
+)
+
+// Batas panjang field profil, mengikuti ukuran kolom di database.
+const (
+	maxProgramStudyLen = 100
+	maxDepartmentLen   = 100
+	maxOfficeLen       = 100
+	maxAddressLen      = 500
+)
+
+// setProfileText menerapkan nilai field opsional dari request ke dst.
+// Nilai nil tidak mengubah dst dan string kosong mengosongkannya (NULL).
+func setProfileText(dst **string, v *string, field string, max int, errs map[string]string) {
+	if v == nil {
+		return
+	}
+
+	val := strings.TrimSpace(*v)
+	switch {
+	case val == "":
+		*dst = nil
+	case utf8.RuneCountInString(val) > max:
+		errs[field] = "maksimal " + strconv.Itoa(max) + " karakter"
+	default:
+		*dst = &val
+	}
+}
+
+// setProfilePhone seperti setProfileText dengan validasi format telepon;
+// spasi dan tanda hubung diabaikan.
+func setProfilePhone(dst **string, v *string, errs map[string]string) {
+	if v == nil {
+		return
+	}
+
+	val := strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(*v))
+	switch {
+	case val == "":
+		*dst = nil
+	case !utils.IsPhone(val):
+		errs["phone"] = "harus 8-15 digit angka, boleh diawali +"
+	default:
+		*dst = &val
+	}
+}
+
+// validateStudentID memeriksa NIM wajib dan formatnya.
+func validateStudentID(id string) string {
+	switch {
+	case id == "":
+		return "wajib diisi"
+	case !utils.IsNIM(id):
+		return "harus 5-20 digit angka"
+	}
+	return ""
+}
+
+// validateLecturerID memeriksa NIDN wajib dan formatnya.
+func validateLecturerID(id string) string {
+	switch {
+	case id == "":
+		return "wajib diisi"
+	case !utils.IsNIDN(id):
+		return "harus 10 digit angka"
+	}
+	return ""
+}
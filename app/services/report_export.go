@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bufio"
+	"sort"
+
+	"uas/app/export"
+	"uas/app/models"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendExport mengirim laporan sebagai file unduhan. Dokumen ditulis langsung
+// ke koneksi setelah handler selesai, sehingga render tidak boleh memakai
+// fiber.Ctx; kesalahan di tengah stream hanya dapat dicatat.
+func sendExport(c *fiber.Ctx, format export.Format, name string, doc export.Document, render func(export.Writer) error) error {
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+format.Filename(name, doc.GeneratedAt)+`"`)
+
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		w, err := export.NewWriter(format, bw, doc)
+		if err == nil {
+			err = render(w)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err != nil {
+			helper.Log.Error().Err(err).Str("format", string(format)).Str("file", name).Msg("gagal menulis ekspor laporan")
+		}
+	})
+
+	return nil
+}
+
+// statusLabels adalah label status untuk dokumen ekspor.
+var statusLabels = map[string]string{
+	"total":                          "Total",
+	utils.AchievementStatusDraft:     "Draft",
+	utils.AchievementStatusSubmitted: "Diajukan",
+	utils.AchievementStatusVerified:  "Terverifikasi",
+	utils.AchievementStatusRejected:  "Ditolak",
+	utils.AchievementStatusRevision:  "Revisi",
+}
+
+func statusLabel(status string) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// writeStatistics menulis ringkasan global lalu baris per mahasiswa yang
+// dialirkan eachStudent, sehingga baris tidak perlu ditampung di memori.
+func writeStatistics(w export.Writer, global map[string]int, eachStudent func(func(models.StudentStat) error) error) error {
+	if err := w.Table("Ringkasan", []string{"Status", "Jumlah"}); err != nil {
+		return err
+	}
+
+	order := []string{"total", utils.AchievementStatusDraft, utils.AchievementStatusSubmitted, utils.AchievementStatusVerified, utils.AchievementStatusRejected, utils.AchievementStatusRevision}
+	seen := map[string]bool{}
+	for _, key := range order {
+		seen[key] = true
+	}
+	// status lain yang belum punya urutan ditampilkan setelah status utama
+	extra := []string{}
+	for key := range global {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, key := range append(order, extra...) {
+		if err := w.Row(statusLabel(key), global[key]); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Table("Statistik per Mahasiswa", []string{"Mahasiswa", "Total", "Draft", "Diajukan", "Terverifikasi", "Ditolak", "Revisi"}); err != nil {
+		return err
+	}
+	return eachStudent(func(st models.StudentStat) error {
+		return w.Row(st.StudentName, st.Total, st.Draft, st.Submitted, st.Verified, st.Rejected, st.Revision)
+	})
+}
+
+func writeStudentReport(w export.Writer, refs []models.AchievementReference, docs []*models.AchievementMongo) error {
+	columns := []string{"No", "Judul", "Tipe", "Status", "Poin", "Diajukan", "Diverifikasi"}
+	if err := w.Table("Daftar Prestasi", columns); err != nil {
+		return err
+	}
+
+	for i, ref := range refs {
+		title, kind, points := "(data prestasi tidak ditemukan)", "", any("")
+		if ach := docs[i]; ach != nil {
+			title, kind, points = ach.Title, ach.AchievementType, ach.Points
+		}
+
+		if err := w.Row(i+1, title, kind, statusLabel(ref.Status), points, ref.SubmittedAt, ref.VerifiedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// studentSubtitle menyusun identitas mahasiswa untuk kop laporan.
+func studentSubtitle(student *models.Student, refs []models.AchievementReference) []string {
+	name := student.StudentID
+	if len(refs) > 0 && refs[0].StudentName != "" {
+		name = refs[0].StudentName + " (" + student.StudentID + ")"
+	}
+
+	lines := []string{"Mahasiswa: " + name}
+	if student.ProgramStudy != nil {
+		lines = append(lines, "Program studi: "+*student.ProgramStudy)
+	}
+	if student.AcademicYear != nil {
+		lines = append(lines, "Angkatan: "+*student.AcademicYear)
+	}
+	return lines
+}
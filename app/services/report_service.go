@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"time"
+	"uas/app/export"
 	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
 	"uas/helper"
 
@@ -12,15 +15,21 @@ import (
 type ReportService struct {
 	AchievementRefRepo   repository.AchievementReferenceRepository
 	AchievementMongoRepo repository.AchievementMongoRepository
+	StudentRepo          repository.StudentRepository
+	LecturerRepo         repository.LecturerRepository
 }
 
 func NewReportService(
 	refRepo repository.AchievementReferenceRepository,
 	mongoRepo repository.AchievementMongoRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
 ) *ReportService {
 	return &ReportService{
 		AchievementRefRepo:   refRepo,
 		AchievementMongoRepo: mongoRepo,
+		StudentRepo:          studentRepo,
+		LecturerRepo:         lecturerRepo,
 	}
 }
 
@@ -31,7 +40,12 @@ func NewReportService(
 // @Tags         Reports
 // @Security     BearerAuth
 // @Produce      json
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/pdf
+// @Param        format query string false "Format ekspor (json, csv, xlsx, pdf); dapat juga lewat header Accept"
 // @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
 // @Failure      401 {object} models.MetaInfo
 // @Failure      403 {object} models.MetaInfo
 // @Failure      500 {object} models.MetaInfo
@@ -39,7 +53,36 @@ func NewReportService(
 func (s *ReportService) Statistics(c *fiber.Ctx) error {
 	ctx := c.Context()
 
-	refs, err := s.AchievementRefRepo.FindAll(ctx)
+	format, err := export.Negotiate(c.Query("format"), c.Get(fiber.HeaderAccept))
+	if err != nil {
+		return helper.BadRequest(c, "unsupported export format", nil)
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "failed to check access")
+	}
+
+	// cakupan policy diterapkan di SQL, seperti pada list dan analitik
+	var filter models.AchievementFilter
+	switch policy.ListScope(actor) {
+	case policy.ScopeAll:
+	case policy.ScopeOwn:
+		filter.StudentIDs = []string{actor.StudentID}
+	case policy.ScopeAdvisees:
+		advisees, err := s.StudentRepo.FindByAdvisorID(ctx, actor.LecturerID)
+		if err != nil {
+			return helper.InternalServerError(c, "failed to load report statistics")
+		}
+		filter.StudentIDs = make([]string, 0, len(advisees))
+		for _, st := range advisees {
+			filter.StudentIDs = append(filter.StudentIDs, st.ID)
+		}
+	default:
+		filter.StudentIDs = []string{}
+	}
+
+	counts, err := s.AchievementRefRepo.CountByStatus(ctx, filter)
 	if err != nil {
 		return helper.InternalServerError(c, "failed to load report statistics")
 	}
@@ -50,39 +93,34 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 		"submitted": 0,
 		"verified":  0,
 		"rejected":  0,
+		"revision":  0,
+	}
+	for status, n := range counts {
+		global["total"] += n
+		global[status] += n
 	}
 
-	studentMap := map[string]*models.StudentStat{}
-
-	for _, ref := range refs {
-		global["total"]++
-		global[ref.Status]++
-
-		if _, ok := studentMap[ref.StudentID]; !ok {
-			studentMap[ref.StudentID] = &models.StudentStat{
-				StudentID:   ref.StudentID,
-				StudentName: ref.StudentName,
-			}
-		}
-
-		st := studentMap[ref.StudentID]
-		st.Total++
-
-		switch ref.Status {
-		case "draft":
-			st.Draft++
-		case "submitted":
-			st.Submitted++
-		case "verified":
-			st.Verified++
-		case "rejected":
-			st.Rejected++
+	if format != "" {
+		doc := export.Document{
+			Institution: export.InstitutionFromEnv(),
+			Title:       "Laporan Statistik Prestasi Mahasiswa",
+			GeneratedAt: time.Now(),
 		}
+		// baris per mahasiswa ditulis langsung dari cursor query
+		return sendExport(c, format, "statistik-prestasi", doc, func(w export.Writer) error {
+			return writeStatistics(w, global, func(fn func(models.StudentStat) error) error {
+				return s.AchievementRefRepo.EachStudentStat(context.Background(), filter, fn)
+			})
+		})
 	}
 
-	var perStudent []models.StudentStat
-	for _, v := range studentMap {
-		perStudent = append(perStudent, *v)
+	perStudent := []models.StudentStat{}
+	err = s.AchievementRefRepo.EachStudentStat(ctx, filter, func(st models.StudentStat) error {
+		perStudent = append(perStudent, st)
+		return nil
+	})
+	if err != nil {
+		return helper.InternalServerError(c, "failed to load report statistics")
 	}
 
 	return helper.Success(c, "report statistics retrieved", fiber.Map{
@@ -98,8 +136,13 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 // @Tags         Reports
 // @Security     BearerAuth
 // @Produce      json
-// @Param        id   path string true "Student ID"
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/pdf
+// @Param        id     path  string true  "Student ID"
+// @Param        format query string false "Format ekspor (json, csv, xlsx, pdf); dapat juga lewat header Accept"
 // @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
 // @Failure      401 {object} models.MetaInfo
 // @Failure      403 {object} models.MetaInfo
 // @Failure      404 {object} models.MetaInfo
@@ -109,29 +152,64 @@ func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 	ctx := c.Context()
 	studentID := c.Params("id")
 
+	format, err := export.Negotiate(c.Query("format"), c.Get(fiber.HeaderAccept))
+	if err != nil {
+		return helper.BadRequest(c, "unsupported export format", nil)
+	}
+
+	student, err := s.StudentRepo.FindByID(ctx, studentID)
+	if err != nil || student == nil {
+		return helper.NotFound(c, "student not found")
+	}
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "failed to check access")
+	}
+
+	if !policy.Can(actor, policy.ReadStudent, studentSubject(student, "")) {
+		return helper.Forbidden(c, "not allowed to view this student report")
+	}
+
 	refs, err := s.AchievementRefRepo.FindByStudentID(ctx, studentID)
 	if err != nil {
 		return helper.InternalServerError(c, "failed to load student report")
 	}
 
-	// local wrapper → ONLY for this endpoint
-	type Item struct {
-		Reference   models.ReportReference   `json:"reference"`
-		Achievement models.ReportAchievement `json:"achievement"`
+	visible := make([]models.AchievementReference, 0, len(refs))
+	for _, ref := range refs {
+		if policy.Can(actor, policy.ReadAchievement, studentSubject(student, ref.Status)) {
+			visible = append(visible, ref)
+		}
 	}
 
-	var items []Item
+	docs, dangling, err := stitchAchievements(ctx, s.AchievementMongoRepo, visible, listFields...)
+	if err != nil {
+		return helper.InternalServerError(c, "failed to load student report")
+	}
 
-	for _, ref := range refs {
-		ach, err := s.AchievementMongoRepo.FindByID(
-			context.Background(),
-			ref.MongoAchievementID,
-		)
-		if err != nil {
-			continue
+	if format != "" {
+		doc := export.Document{
+			Institution: export.InstitutionFromEnv(),
+			Title:       "Laporan Prestasi Mahasiswa",
+			Subtitle:    studentSubtitle(student, visible),
+			GeneratedAt: time.Now(),
 		}
+		return sendExport(c, format, "prestasi-"+student.StudentID, doc, func(w export.Writer) error {
+			return writeStudentReport(w, visible, docs)
+		})
+	}
 
-		items = append(items, Item{
+	// local wrapper → ONLY for this endpoint
+	type Item struct {
+		Reference   models.ReportReference    `json:"reference"`
+		Achievement *models.ReportAchievement `json:"achievement"`
+	}
+
+	items := make([]Item, 0, len(visible))
+
+	for i, ref := range visible {
+		item := Item{
 			Reference: models.ReportReference{
 				ID:          ref.ID,
 				Status:      ref.Status,
@@ -141,21 +219,24 @@ func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 				SubmittedAt: ref.SubmittedAt,
 				VerifiedAt:  ref.VerifiedAt,
 			},
-			Achievement: models.ReportAchievement{
+		}
+
+		if ach := docs[i]; ach != nil {
+			item.Achievement = &models.ReportAchievement{
 				ID:    ach.ID.Hex(),
 				Title: ach.Title,
 				Type:  ach.AchievementType,
 				Point: ach.Points,
-			},
-		})
+			}
+		}
+
+		items = append(items, item)
 	}
 
 	return helper.Success(c, "student report retrieved", fiber.Map{
 		"student_id": studentID,
 		"total":      len(items),
 		"items":      items,
+		"dangling":   dangling,
 	})
 }
-
-
-
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"math"
+	"time"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/app/workflow"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewService struct {
+	RefRepo      repository.AchievementReferenceRepository
+	ReviewRepo   repository.ReviewRepository
+	MongoRepo    repository.AchievementMongoRepository
+	StudentRepo  repository.StudentRepository
+	LecturerRepo repository.LecturerRepository
+
+	// SLA adalah batas waktu prestasi submitted harus diputuskan.
+	SLA time.Duration
+	Now func() time.Time
+}
+
+func NewReviewService(
+	refRepo repository.AchievementReferenceRepository,
+	reviewRepo repository.ReviewRepository,
+	mongoRepo repository.AchievementMongoRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+) *ReviewService {
+	return &ReviewService{
+		RefRepo:      refRepo,
+		ReviewRepo:   reviewRepo,
+		MongoRepo:    mongoRepo,
+		StudentRepo:  studentRepo,
+		LecturerRepo: lecturerRepo,
+
+		SLA: workflow.ReviewSLAFromEnv(),
+		Now: time.Now,
+	}
+}
+
+// Review queue
+// @Summary      Antrian review dosen wali
+// @Description  Prestasi submitted mahasiswa bimbingan, yang paling lama menunggu lebih dulu, beserta jumlah per mahasiswa dan penanda SLA
+// @Tags         Lecturers & Students
+// @Security     BearerAuth
+// @Produce      json
+// @Param        lecturer_id query string false "ID dosen (khusus admin)"
+// @Param        page        query int    false "Halaman"
+// @Param        limit       query int    false "Jumlah per halaman"
+// @Success      200 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /lecturers/review-queue [get]
+func (s *ReviewService) Queue(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	actor, err := currentActor(c, s.StudentRepo, s.LecturerRepo)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+	}
+
+	lecturerID := actor.LecturerID
+	if other := c.Query("lecturer_id"); other != "" && other != lecturerID {
+		if policy.ListScope(actor) != policy.ScopeAll {
+			return helper.Forbidden(c, "Tidak dapat melihat antrian review dosen lain")
+		}
+		lecturerID = other
+	}
+	if lecturerID == "" {
+		return helper.Forbidden(c, "Hanya dosen wali yang memiliki antrian review")
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	now := s.Now()
+	breachBefore := now.Add(-s.SLA)
+
+	advisees, err := s.StudentRepo.FindByAdvisorID(ctx, lecturerID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil data mahasiswa bimbingan")
+	}
+
+	ids := make([]string, 0, len(advisees))
+	for _, st := range advisees {
+		ids = append(ids, st.ID)
+	}
+
+	items := []fiber.Map{}
+	stats := []models.AdviseeQueueStat{}
+	total := 0
+
+	if len(ids) > 0 {
+		refs, n, err := s.RefRepo.FindForAdvisorPaginated(ctx, ids, limit, offset)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal mengambil antrian review")
+		}
+		total = n
+
+		stats, err = s.ReviewRepo.PendingByStudent(ctx, ids, breachBefore)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal mengambil antrian review")
+		}
+
+		docs, _, err := stitchAchievements(ctx, s.MongoRepo, refs, listFields...)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal mengambil data prestasi")
+		}
+
+		for i, ref := range refs {
+			item := fiber.Map{
+				"id":           ref.MongoAchievementID,
+				"reference_id": ref.ID,
+				"student_id":   ref.StudentID,
+				"student_code": ref.StudentCode,
+				"student_name": ref.StudentName,
+				"submitted_at": ref.SubmittedAt,
+			}
+			if ref.SubmittedAt != nil {
+				item["waiting_hours"] = hours(now.Sub(*ref.SubmittedAt))
+				item["sla_breached"] = ref.SubmittedAt.Before(breachBefore)
+			}
+			if ach := docs[i]; ach != nil {
+				item["title"] = ach.Title
+				item["type"] = ach.AchievementType
+			} else {
+				item["missing"] = true
+			}
+			items = append(items, item)
+		}
+	}
+
+	pending, breached := 0, 0
+	var oldest *time.Time
+	for _, st := range stats {
+		pending += st.Pending
+		breached += st.Breached
+		if st.OldestAt != nil && (oldest == nil || st.OldestAt.Before(*oldest)) {
+			oldest = st.OldestAt
+		}
+	}
+
+	summary := fiber.Map{
+		"lecturer_id":         lecturerID,
+		"advisees":            len(advisees),
+		"pending":             pending,
+		"breached":            breached,
+		"oldest_submitted_at": oldest,
+		"sla_hours":           s.SLA.Hours(),
+	}
+
+	meta := models.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalData:  total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+
+	return helper.Paginated(c, "Antrian review ditemukan", fiber.Map{
+		"items":       items,
+		"per_advisee": stats,
+		"summary":     summary,
+	}, meta)
+}
+
+// Review metrics
+// @Summary      Metrik review per dosen
+// @Description  Beban antrian, rata-rata waktu review dan tingkat persetujuan setiap dosen wali
+// @Tags         Lecturers & Students
+// @Security     BearerAuth
+// @Produce      json
+// @Param        date_from query string false "Awal rentang waktu review (YYYY-MM-DD)"
+// @Param        date_to   query string false "Akhir rentang waktu review (YYYY-MM-DD, inklusif)"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /lecturers/review-metrics [get]
+func (s *ReviewService) Metrics(c *fiber.Ctx) error {
+	errs := map[string]string{}
+	from, to := parseDateRange(c, "date", errs)
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Parameter tidak valid", errs)
+	}
+
+	list, err := s.ReviewRepo.Metrics(c.Context(), from, to, s.Now().Add(-s.SLA))
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil metrik review")
+	}
+
+	var pending, breached, approved, rejected, timed int
+	var totalHours float64
+	for _, m := range list {
+		pending += m.Pending
+		breached += m.Breached
+		approved += m.Approved
+		rejected += m.Rejected
+		if m.AvgReviewHours != nil {
+			totalHours += *m.AvgReviewHours * float64(m.Reviewed)
+			timed += m.Reviewed
+		}
+	}
+
+	overall := fiber.Map{
+		"pending":          pending,
+		"breached":         breached,
+		"reviewed":         approved + rejected,
+		"approved":         approved,
+		"rejected":         rejected,
+		"approval_rate":    nil,
+		"avg_review_hours": nil,
+		"sla_hours":        s.SLA.Hours(),
+	}
+	if approved+rejected > 0 {
+		overall["approval_rate"] = float64(approved) / float64(approved+rejected)
+	}
+	if timed > 0 {
+		overall["avg_review_hours"] = hours(time.Duration(totalHours / float64(timed) * float64(time.Hour)))
+	}
+
+	return helper.Success(c, "Metrik review dosen ditemukan", fiber.Map{
+		"lecturers": list,
+		"overall":   overall,
+	})
+}
+
+// hours membulatkan durasi ke satu desimal jam.
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*10) / 10
+}
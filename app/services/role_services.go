@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleService struct {
+	roleRepo       repository.RoleRepository
+	permissionRepo repository.PermissionRepository
+}
+
+func NewRoleService(
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+) *RoleService {
+	return &RoleService{
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+	}
+}
+
+// adminPermissions adalah permission yang membuka pengelolaan role.
+// Minimal satu role harus tetap memegang salah satunya agar akses admin
+// tidak terkunci.
+var adminPermissions = []string{"*", "role:manage"}
+
+// keepsAdminRole memeriksa apakah setelah role roleID hanya memegang
+// permission remaining masih ada role yang memegang adminPermissions.
+func (s *RoleService) keepsAdminRole(ctx context.Context, roleID string, remaining []string) (bool, error) {
+	for _, name := range remaining {
+		for _, admin := range adminPermissions {
+			if name == admin {
+				return true, nil
+			}
+		}
+	}
+
+	n, err := s.roleRepo.CountRolesWithPermission(ctx, adminPermissions, roleID)
+	return n > 0, err
+}
+
+func validateRoleRequest(req *models.RoleRequest) map[string]string {
+	errs := map[string]string{}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		errs["name"] = "Nama role wajib diisi"
+	}
+
+	if req.ProfileType == "" {
+		req.ProfileType = models.ProfileNone
+	}
+	switch req.ProfileType {
+	case models.ProfileStudent, models.ProfileLecturer, models.ProfileNone:
+	default:
+		errs["profile_type"] = "profile_type harus student, lecturer atau none"
+	}
+
+	return errs
+}
+
+// findRole mengambil role dari path param :id, mengirim 404 jika tidak ada.
+func (s *RoleService) findRole(c *fiber.Ctx) (*models.Role, error) {
+	id := c.Params("id")
+	if !utils.IsUUID(id) {
+		return nil, helper.NotFound(c, "Role tidak ditemukan")
+	}
+
+	role, err := s.roleRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal mengambil role")
+	}
+	if role == nil {
+		return nil, helper.NotFound(c, "Role tidak ditemukan")
+	}
+
+	return role, nil
+}
+
+// List godoc
+// @Summary      Daftar role
+// @Description  Mengambil seluruh role beserta profile_type dan kebijakan MFA
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Router       /roles [get]
+func (s *RoleService) List(c *fiber.Ctx) error {
+	roles, err := s.roleRepo.FindAll(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil role")
+	}
+
+	return helper.Success(c, "Daftar role", roles)
+}
+
+// Detail godoc
+// @Summary      Detail role
+// @Description  Mengambil role beserta daftar permission-nya
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path string true "Role ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id} [get]
+func (s *RoleService) Detail(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	perms, err := s.roleRepo.ListPermissions(c.Context(), role.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil permission role")
+	}
+	role.Permissions = perms
+
+	return helper.Success(c, "Detail role", role)
+}
+
+// Create godoc
+// @Summary      Buat role
+// @Description  Membuat role baru. profile_type menentukan profil yang dibuat otomatis (student / lecturer / none).
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.RoleRequest  true  "Role"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Router       /roles [post]
+func (s *RoleService) Create(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if errs := validateRoleRequest(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		ProfileType: req.ProfileType,
+		MFARequired: req.MFARequired,
+	}
+
+	if err := s.roleRepo.Create(c.Context(), role); err != nil {
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Nama role sudah dipakai", nil)
+		}
+		return helper.InternalServerError(c, "Gagal membuat role")
+	}
+
+	return helper.Created(c, "Role berhasil dibuat", role)
+}
+
+// Update godoc
+// @Summary      Update role
+// @Description  Mengubah nama, deskripsi, profile_type dan kebijakan MFA role. profile_type hanya dapat diubah selama role belum dipakai user.
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string  true  "Role ID"
+// @Param        body  body  models.RoleRequest  true  "Role"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id} [put]
+func (s *RoleService) Update(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	if errs := validateRoleRequest(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Validasi gagal", errs)
+	}
+
+	if req.ProfileType != role.ProfileType {
+		n, err := s.roleRepo.CountUsers(c.Context(), role.ID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa pemakaian role")
+		}
+		if n > 0 {
+			return helper.BadRequest(c, "profile_type tidak dapat diubah selama role dipakai user", fiber.Map{"users": n})
+		}
+	}
+
+	role.Name = req.Name
+	role.Description = req.Description
+	role.ProfileType = req.ProfileType
+	role.MFARequired = req.MFARequired
+
+	if err := s.roleRepo.Update(c.Context(), role); err != nil {
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Nama role sudah dipakai", nil)
+		}
+		return helper.InternalServerError(c, "Gagal memperbarui role")
+	}
+
+	return helper.Success(c, "Role berhasil diperbarui", role)
+}
+
+// Delete godoc
+// @Summary      Hapus role
+// @Description  Menghapus role yang tidak lagi dipakai user manapun
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path string true "Role ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id} [delete]
+func (s *RoleService) Delete(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	n, err := s.roleRepo.CountUsers(c.Context(), role.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa pemakaian role")
+	}
+	if n > 0 {
+		return helper.BadRequest(c, "Role masih dipakai oleh user", fiber.Map{"users": n})
+	}
+
+	if err := s.roleRepo.Delete(c.Context(), role.ID); err != nil {
+		return helper.InternalServerError(c, "Gagal menghapus role")
+	}
+
+	return helper.Success(c, "Role berhasil dihapus", nil)
+}
+
+// Permissions godoc
+// @Summary      Permission milik role
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path string true "Role ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id}/permissions [get]
+func (s *RoleService) Permissions(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	perms, err := s.roleRepo.ListPermissions(c.Context(), role.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil permission role")
+	}
+
+	return helper.Success(c, "Permission role", perms)
+}
+
+// SetPermissions godoc
+// @Summary      Ganti permission role
+// @Description  Mengganti seluruh permission role. Berlaku pada request berikutnya tanpa perlu login ulang.
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string  true  "Role ID"
+// @Param        body  body  models.RolePermissionsRequest  true  "Daftar permission ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id}/permissions [put]
+func (s *RoleService) SetPermissions(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	var req models.RolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	names := make([]string, 0, len(req.PermissionIDs))
+	for _, pid := range req.PermissionIDs {
+		if !utils.IsUUID(pid) {
+			return helper.BadRequest(c, "Permission ID tidak valid", pid)
+		}
+		p, err := s.permissionRepo.GetByID(c.Context(), pid)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa permission")
+		}
+		if p == nil {
+			return helper.BadRequest(c, "Permission tidak ditemukan", pid)
+		}
+		names = append(names, p.Name)
+	}
+
+	ok, err := s.keepsAdminRole(c.Context(), role.ID, names)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa role admin")
+	}
+	if !ok {
+		return helper.BadRequest(c, "Harus ada role lain yang memegang permission pengelolaan role", nil)
+	}
+
+	if err := s.roleRepo.SetPermissions(c.Context(), role.ID, req.PermissionIDs); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan permission role")
+	}
+
+	return helper.Success(c, "Permission role berhasil diperbarui", nil)
+}
+
+// AddPermission godoc
+// @Summary      Tambah permission ke role
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id            path string true "Role ID"
+// @Param        permissionId  path string true "Permission ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id}/permissions/{permissionId} [post]
+func (s *RoleService) AddPermission(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	pid := c.Params("permissionId")
+	if !utils.IsUUID(pid) {
+		return helper.NotFound(c, "Permission tidak ditemukan")
+	}
+
+	p, err := s.permissionRepo.GetByID(c.Context(), pid)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa permission")
+	}
+	if p == nil {
+		return helper.NotFound(c, "Permission tidak ditemukan")
+	}
+
+	if err := s.roleRepo.AddPermission(c.Context(), role.ID, p.ID); err != nil {
+		return helper.InternalServerError(c, "Gagal menambah permission role")
+	}
+
+	return helper.Success(c, "Permission berhasil ditambahkan ke role", nil)
+}
+
+// RemovePermission godoc
+// @Summary      Cabut permission dari role
+// @Tags         Roles & Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id            path string true "Role ID"
+// @Param        permissionId  path string true "Permission ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) RemovePermission(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	pid := c.Params("permissionId")
+	if !utils.IsUUID(pid) {
+		return helper.NotFound(c, "Permission tidak ditemukan")
+	}
+
+	current, err := s.roleRepo.ListPermissions(c.Context(), role.ID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil permission role")
+	}
+	remaining := make([]string, 0, len(current))
+	for _, p := range current {
+		if p.ID != pid {
+			remaining = append(remaining, p.Name)
+		}
+	}
+
+	ok, err := s.keepsAdminRole(c.Context(), role.ID, remaining)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa role admin")
+	}
+	if !ok {
+		return helper.BadRequest(c, "Harus ada role lain yang memegang permission pengelolaan role", nil)
+	}
+
+	removed, err := s.roleRepo.RemovePermission(c.Context(), role.ID, pid)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mencabut permission role")
+	}
+	if !removed {
+		return helper.NotFound(c, "Permission tidak dimiliki role ini")
+	}
+
+	return helper.Success(c, "Permission berhasil dicabut dari role", nil)
+}
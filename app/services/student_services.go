@@ -3,7 +3,11 @@ package services
 import (
     "database/sql"
     "strconv"
+    "strings"
     "uas/app/models"
+    "uas/app/notify"
+    "uas/app/outbox"
+    "uas/app/policy"
     "uas/app/repository"
     "uas/helper"
 	"uas/utils"
@@ -17,16 +21,20 @@ type StudentService struct {
     lecturerRepo repository.LecturerRepository
     AchRefRepo      repository.AchievementReferenceRepository
 	MongoAchRepo    repository.AchievementMongoRepository
+	Notifier        *notify.Notifier
+	OutboxRepo      repository.OutboxRepository
 }
 
 func NewStudentService(db *sql.DB, sRepo repository.StudentRepository, lRepo repository.LecturerRepository, achRefRepo repository.AchievementReferenceRepository,
-	mongoRepo repository.AchievementMongoRepository) *StudentService {
+	mongoRepo repository.AchievementMongoRepository, notifier *notify.Notifier, outboxRepo repository.OutboxRepository) *StudentService {
     return &StudentService{
         DB:           db,
         studentRepo:  sRepo,
         lecturerRepo: lRepo,
         AchRefRepo:  achRefRepo,
 		MongoAchRepo: mongoRepo,
+		Notifier:     notifier,
+		OutboxRepo:   outboxRepo,
     }
 }
 
@@ -48,6 +56,31 @@ func (s *StudentService) resolveStudentID(id string) (string, error) {
     return "", fiber.NewError(fiber.StatusBadRequest, "Format ID mahasiswa tidak valid")
 }
 
+// findAuthorized me-resolve ID mahasiswa lalu memeriksa policy untuk action.
+// Jika gagal, respon 404/403 sudah dikirim dan student bernilai nil.
+func (s *StudentService) findAuthorized(c *fiber.Ctx, action policy.Action) (*models.Student, error) {
+    resolvedID, err := s.resolveStudentID(c.Params("id"))
+    if err != nil {
+        return nil, helper.NotFound(c, "Mahasiswa tidak ditemukan")
+    }
+
+    student, err := s.studentRepo.FindByID(c.Context(), resolvedID)
+    if err != nil || student == nil {
+        return nil, helper.NotFound(c, "Mahasiswa tidak ditemukan")
+    }
+
+    actor, err := currentActor(c, s.studentRepo, s.lecturerRepo)
+    if err != nil {
+        return nil, helper.InternalServerError(c, "Gagal memeriksa hak akses")
+    }
+
+    if !policy.Can(actor, action, studentSubject(student, "")) {
+        return nil, helper.Forbidden(c, "Tidak memiliki akses ke data mahasiswa ini")
+    }
+
+    return student, nil
+}
+
 // GetAll
 // @Summary      Ambil semua mahasiswa
 // @Description  Menampilkan daftar seluruh mahasiswa
@@ -60,7 +93,21 @@ func (s *StudentService) resolveStudentID(id string) (string, error) {
 // @Failure      500 {object} models.MetaInfo
 // @Router       /students [get]
 func (s *StudentService) GetAll(c *fiber.Ctx) error {
-    list, err := s.studentRepo.FindAll(c.Context())
+    actor, err := currentActor(c, s.studentRepo, s.lecturerRepo)
+    if err != nil {
+        return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+    }
+
+    var list []models.Student
+
+    switch {
+    case policy.Can(actor, policy.ManageStudent, policy.Subject{}):
+        list, err = s.studentRepo.FindAll(c.Context())
+    case policy.ListScope(actor) == policy.ScopeAdvisees:
+        list, err = s.studentRepo.FindByAdvisorID(c.Context(), actor.LecturerID)
+    default:
+        return helper.Forbidden(c, "Tidak memiliki akses ke daftar mahasiswa")
+    }
     if err != nil {
         return helper.InternalServerError(c, err.Error())
     }
@@ -82,16 +129,9 @@ func (s *StudentService) GetAll(c *fiber.Ctx) error {
 // @Failure      500 {object} models.MetaInfo
 // @Router       /students/{id} [get]
 func (s *StudentService) GetByID(c *fiber.Ctx) error {
-    idParam := c.Params("id")
-
-    resolvedID, err := s.resolveStudentID(idParam)
-    if err != nil {
-        return helper.NotFound(c, "Mahasiswa tidak ditemukan")
-    }
-
-    student, err := s.studentRepo.FindByID(c.Context(), resolvedID)
-    if err != nil || student == nil {
-        return helper.NotFound(c, "Mahasiswa tidak ditemukan")
+    student, err := s.findAuthorized(c, policy.ReadStudent)
+    if student == nil {
+        return err
     }
 
     return helper.Success(c, "Data mahasiswa ditemukan", student)
@@ -114,12 +154,11 @@ func (s *StudentService) GetByID(c *fiber.Ctx) error {
 // @Failure      500 {object} models.MetaInfo
 // @Router       /students/{id}/advisor [put]
 func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
-    idParam := c.Params("id")
-
-    resolvedID, err := s.resolveStudentID(idParam)
-    if err != nil {
-        return helper.NotFound(c, "Mahasiswa tidak ditemukan")
+    student, err := s.findAuthorized(c, policy.ManageStudent)
+    if student == nil {
+        return err
     }
+    resolvedID := student.ID
 
     var req models.UpdateAdvisorRequest
     if err := c.BodyParser(&req); err != nil {
@@ -132,13 +171,6 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
         return helper.InternalServerError(c, "Gagal memulai transaksi")
     }
 
-    // cek student ada
-    student, err := s.studentRepo.FindByID(c.Context(), resolvedID)
-    if err != nil {
-        tx.Rollback()
-        return helper.NotFound(c, "Mahasiswa tidak ditemukan")
-    }
-
     // jika set advisor
     var lecID *string = nil
 
@@ -162,12 +194,116 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
         return helper.InternalServerError(c, "Gagal commit transaksi")
     }
 
+	// notifikasi hanya dikirim jika dosen wali benar-benar berganti
+	if !sameAdvisor(student.AdvisorID, lecID) {
+		advisorUserID := ""
+		if req.AdvisorID != nil {
+			advisorUserID = *req.AdvisorID
+			s.Notifier.Send(c.Context(), notify.AdviseeAssigned(advisorUserID, student))
+		}
+		s.Notifier.Send(c.Context(), notify.AdvisorAssigned(student.UserID, student, advisorUserID))
+	}
+
     return helper.Success(c, "Advisor mahasiswa berhasil diperbarui", fiber.Map{
         "student_id": student.ID,
         "advisor_id": req.AdvisorID,
     })
 }
 
+// UpdateProfile
+// @Summary      Update profil mahasiswa
+// @Description  Admin dapat mengubah seluruh field (NIM, program studi, angkatan, kontak); mahasiswa hanya dapat mengubah kontak (phone, address) miliknya sendiri. Field yang tidak dikirim tidak diubah, string kosong mengosongkan field opsional.
+// @Tags         Lecturers & Students
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path string true "Student ID atau index"
+// @Param        body  body models.StudentProfileUpdateRequest true "Data profil"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      401 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Failure      500 {object} models.MetaInfo
+// @Router       /students/{id}/profile [put]
+func (s *StudentService) UpdateProfile(c *fiber.Ctx) error {
+	student, err := s.findAuthorized(c, policy.UpdateContact)
+	if student == nil {
+		return err
+	}
+
+	var req models.StudentProfileUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+
+	actor, _ := currentActor(c, s.studentRepo, s.lecturerRepo)
+	academic := req.StudentID != nil || req.ProgramStudy != nil || req.AcademicYear != nil
+	if academic && !policy.Can(actor, policy.UpdateProfile, studentSubject(student, "")) {
+		return helper.Forbidden(c, "Hanya admin yang dapat mengubah NIM, program studi dan angkatan")
+	}
+
+	updated := *student
+	errs := map[string]string{}
+
+	if req.StudentID != nil {
+		updated.StudentID = strings.TrimSpace(*req.StudentID)
+		if msg := validateStudentID(updated.StudentID); msg != "" {
+			errs["student_id"] = msg
+		}
+	}
+	setProfileText(&updated.ProgramStudy, req.ProgramStudy, "program_study", maxProgramStudyLen, errs)
+	if req.AcademicYear != nil {
+		year := strings.TrimSpace(*req.AcademicYear)
+		switch {
+		case year == "":
+			updated.AcademicYear = nil
+		case !utils.IsAcademicYear(year):
+			errs["academic_year"] = "harus tahun angkatan 4 digit, mis. 2024"
+		default:
+			updated.AcademicYear = &year
+		}
+	}
+	setProfilePhone(&updated.Phone, req.Phone, errs)
+	setProfileText(&updated.Address, req.Address, "address", maxAddressLen, errs)
+
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Data profil tidak valid", errs)
+	}
+
+	taken := map[string]string{"student_id": "sudah terdaftar"}
+	if updated.StudentID != student.StudentID {
+		ids, err := s.studentRepo.FindTakenStudentIDs(c.Context(), []string{updated.StudentID})
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa NIM")
+		}
+		if ids[updated.StudentID] {
+			return helper.BadRequest(c, "Data profil tidak valid", taken)
+		}
+	}
+
+	// dokumen prestasi menyimpan NIM sebagai studentId; penggantiannya
+	// dicatat di outbox dalam transaksi yang sama dan diulang worker jika gagal
+	var msg *models.OutboxMessage
+	if updated.StudentID != student.StudentID {
+		msg = outbox.NewRenameStudent(student.ID, student.StudentID, updated.StudentID)
+	}
+
+	if err := s.studentRepo.UpdateProfileWithOutbox(c.Context(), &updated, msg); err != nil {
+		// NIM dapat direbut request lain di antara pengecekan dan update
+		if utils.IsUniqueViolation(err) {
+			return helper.BadRequest(c, "Data profil tidak valid", taken)
+		}
+		return helper.InternalServerError(c, "Gagal memperbarui profil mahasiswa")
+	}
+
+	if msg != nil {
+		_ = outbox.Dispatch(c.Context(), s.OutboxRepo, s.MongoAchRepo, msg)
+	}
+
+	return helper.Success(c, "Profil mahasiswa berhasil diperbarui", updated)
+}
+
 // GetAchievements
 // @Summary      Daftar prestasi mahasiswa
 // @Description  Menampilkan daftar prestasi milik mahasiswa
@@ -182,19 +318,14 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
 // @Failure      500 {object} models.MetaInfo
 // @Router       /students/{id}/achievements [get]
 func (s *StudentService) GetAchievements(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-
-	resolvedID, err := s.resolveStudentID(idParam)
-	if err != nil {
-		return helper.NotFound(c, "Mahasiswa tidak ditemukan")
+	student, err := s.findAuthorized(c, policy.ReadStudent)
+	if student == nil {
+		return err
 	}
 
-	student, err := s.studentRepo.FindByID(c.Context(), resolvedID)
-	if err != nil || student == nil {
-		return helper.NotFound(c, "Mahasiswa tidak ditemukan")
-	}
+	actor, _ := currentActor(c, s.studentRepo, s.lecturerRepo)
 
-	refs, err := s.AchRefRepo.FindByStudentID(c.Context(), resolvedID)
+	refs, err := s.AchRefRepo.FindByStudentID(c.Context(), student.ID)
 	if err != nil {
 		return helper.InternalServerError(c, "Gagal mengambil prestasi")
 	}
@@ -203,24 +334,44 @@ func (s *StudentService) GetAchievements(c *fiber.Ctx) error {
 		return helper.Success(c, "Belum ada prestasi", []fiber.Map{})
 	}
 
-	result := []fiber.Map{}
-
+	visible := make([]models.AchievementReference, 0, len(refs))
 	for _, ref := range refs {
-		ach, err := s.MongoAchRepo.FindByID(c.Context(), ref.MongoAchievementID)
-		if err != nil || ach == nil {
-			continue
+		if policy.Can(actor, policy.ReadAchievement, studentSubject(student, ref.Status)) {
+			visible = append(visible, ref)
+		}
+	}
+
+	docs, _, err := stitchAchievements(c.Context(), s.MongoAchRepo, visible, listFields...)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil prestasi")
+	}
+
+	result := make([]fiber.Map, 0, len(visible))
+
+	for i, ref := range visible {
+		item := fiber.Map{
+			"id":     ref.MongoAchievementID,
+			"status": ref.Status,
 		}
 
-		result = append(result, fiber.Map{
-			"id":      ach.ID.Hex(),
-			"title":   ach.Title,
-			"type":    ach.AchievementType,
-			"status":  ref.Status,
-			"updated": ach.UpdatedAt,
-		})
+		if ach := docs[i]; ach != nil {
+			item["title"] = ach.Title
+			item["type"] = ach.AchievementType
+			item["updated"] = ach.UpdatedAt
+		} else {
+			item["missing"] = true
+			item["updated"] = ref.UpdatedAt
+		}
+
+		result = append(result, item)
 	}
 
 	return helper.Success(c, "Daftar prestasi mahasiswa ditemukan", result)
 }
 
-
+func sameAdvisor(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
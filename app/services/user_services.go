@@ -1,7 +1,12 @@
 package services
 
 import (
+	"errors"
+	"io"
+	"context"
 	"strconv"
+	"strings"
+	"uas/app/importer"
 	"uas/app/models"
 	"uas/app/repository"
 	"uas/helper"
@@ -17,6 +22,7 @@ type UserService struct {
     userRepo     repository.UserRepository
     studentRepo  repository.StudentRepository
     lecturerRepo repository.LecturerRepository
+    roleRepo     repository.RoleRepository
 }
 
 
@@ -25,12 +31,14 @@ func NewUserService(
     userRepo repository.UserRepository,
     studentRepo repository.StudentRepository,
     lecturerRepo repository.LecturerRepository,
+    roleRepo repository.RoleRepository,
 ) *UserService {
     return &UserService{
         DB:           db,
         userRepo:     userRepo,
         studentRepo:  studentRepo,
         lecturerRepo: lecturerRepo,
+        roleRepo:     roleRepo,
     }
 }
 
@@ -102,7 +110,7 @@ func (s *UserService) GetByID(c *fiber.Ctx) error {
 
 // Create godoc
 // @Summary      Buat user baru
-// @Description  Membuat user beserta profil sesuai role
+// @Description  Membuat user beserta profil sesuai role. student_id (NIM, 5-20 digit) atau lecturer_id (NIDN, 10 digit) opsional; jika kosong dibangkitkan otomatis
 // @Tags         Users
 // @Security     BearerAuth
 // @Accept       json
@@ -119,6 +127,21 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Input tidak valid", err.Error())
 	}
 
+	role, err := s.roleRepo.GetByID(c.Context(), body.RoleID)
+	if err != nil || role == nil {
+		return helper.BadRequest(c, "Role tidak ditemukan", nil)
+	}
+
+	body.StudentID = strings.TrimSpace(body.StudentID)
+	body.LecturerID = strings.TrimSpace(body.LecturerID)
+	errs, err := s.validateProfileIDs(c.Context(), role.ProfileType, body.StudentID, body.LecturerID)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal memeriksa NIM/NIDN")
+	}
+	if len(errs) > 0 {
+		return helper.BadRequest(c, "Data profil tidak valid", errs)
+	}
+
 	hashed, _ := utils.HashPassword(body.Password)
 
 	u := models.Users{
@@ -147,19 +170,31 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 	}
 
 	// CREATE PROFILE BASED ON ROLE
-	switch body.RoleID {
+	switch role.ProfileType {
 
-	case utils.ROLE_MAHASISWA:
-		studentID := genShort("STD-")
+	case models.ProfileStudent:
+		studentID := body.StudentID
+		if studentID == "" {
+			studentID = genShort("STD-")
+		}
 		if err := s.studentRepo.Create(tx, newUserID, studentID); err != nil {
 			tx.Rollback()
+			if utils.IsUniqueViolation(err) {
+				return helper.BadRequest(c, "Data profil tidak valid", map[string]string{"student_id": "sudah terdaftar"})
+			}
 			return helper.InternalServerError(c, "Gagal membuat profil mahasiswa")
 		}
 
-	case utils.ROLE_DOSEN:
-		lecID := genShort("DSN-")
+	case models.ProfileLecturer:
+		lecID := body.LecturerID
+		if lecID == "" {
+			lecID = genShort("DSN-")
+		}
 		if err := s.lecturerRepo.Create(tx, newUserID, lecID); err != nil {
 			tx.Rollback()
+			if utils.IsUniqueViolation(err) {
+				return helper.BadRequest(c, "Data profil tidak valid", map[string]string{"lecturer_id": "sudah terdaftar"})
+			}
 			return helper.InternalServerError(c, "Gagal membuat profil dosen")
 		}
 	}
@@ -174,6 +209,47 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 	})
 }
 
+// validateProfileIDs memeriksa NIM/NIDN opsional pada pembuatan user:
+// identifier hanya boleh diisi sesuai profil role, formatnya valid dan
+// belum terdaftar.
+func (s *UserService) validateProfileIDs(ctx context.Context, profile, studentID, lecturerID string) (map[string]string, error) {
+	errs := map[string]string{}
+
+	if studentID != "" {
+		if profile != models.ProfileStudent {
+			errs["student_id"] = "hanya untuk role mahasiswa"
+		} else if msg := validateStudentID(studentID); msg != "" {
+			errs["student_id"] = msg
+		} else {
+			taken, err := s.studentRepo.FindTakenStudentIDs(ctx, []string{studentID})
+			if err != nil {
+				return nil, err
+			}
+			if taken[studentID] {
+				errs["student_id"] = "sudah terdaftar"
+			}
+		}
+	}
+
+	if lecturerID != "" {
+		if profile != models.ProfileLecturer {
+			errs["lecturer_id"] = "hanya untuk role dosen"
+		} else if msg := validateLecturerID(lecturerID); msg != "" {
+			errs["lecturer_id"] = msg
+		} else {
+			taken, err := s.lecturerRepo.FindTakenLecturerIDs(ctx, []string{lecturerID})
+			if err != nil {
+				return nil, err
+			}
+			if taken[lecturerID] {
+				errs["lecturer_id"] = "sudah terdaftar"
+			}
+		}
+	}
+
+	return errs, nil
+}
+
 // Update godoc
 // @Summary      Update user
 // @Description  Memperbarui data user
@@ -284,6 +360,11 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Format request tidak valid", err.Error())
 	}
 
+	role, err := s.roleRepo.GetByID(c.Context(), req.RoleID)
+	if err != nil || role == nil {
+		return helper.BadRequest(c, "Role tidak ditemukan", nil)
+	}
+
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return helper.InternalServerError(c, "Gagal memulai transaksi")
@@ -301,16 +382,16 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 		return prefix + uuid.New().String()[:8]
 	}
 
-	switch req.RoleID {
+	switch role.ProfileType {
 
-	case utils.ROLE_MAHASISWA:
+	case models.ProfileStudent:
 		studentID := genShort("STD-")
 		if err := s.studentRepo.Create(tx, resolvedID, studentID); err != nil {
 			tx.Rollback()
 			return helper.InternalServerError(c, "Gagal membuat profil mahasiswa baru")
 		}
 
-	case utils.ROLE_DOSEN:
+	case models.ProfileLecturer:
 		lecturerID := genShort("DSN-")
 		if err := s.lecturerRepo.Create(tx, resolvedID, lecturerID); err != nil {
 			tx.Rollback()
@@ -324,3 +405,73 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 
 	return helper.Success(c, "Role user berhasil diperbarui", nil)
 }
+
+// Import godoc
+// @Summary      Import mahasiswa massal
+// @Description  Membuat akun mahasiswa dari berkas CSV atau XLSX (sheet pertama). Kolom header: username, email, full_name, nim, program_study, academic_year, advisor_lecturer_id dan password (opsional; jika kosong mahasiswa mengatur password lewat lupa password). Mode all_or_nothing (default) tidak menyimpan apa pun jika ada baris yang bermasalah; best_effort menyimpan baris yang valid saja. Dengan dry_run=true hanya validasi yang dijalankan.
+// @Tags         Users
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true   "Berkas CSV atau XLSX"
+// @Param        mode     formData  string  false  "all_or_nothing atau best_effort"
+// @Param        dry_run  formData  bool    false  "Hanya validasi tanpa menyimpan"
+// @Success      200   {object} models.MetaInfo
+// @Success      201   {object} models.MetaInfo
+// @Failure      400   {object} models.MetaInfo
+// @Failure      403   {object} models.MetaInfo
+// @Router       /users/import [post]
+func (s *UserService) Import(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return helper.BadRequest(c, "Tidak ada file yang diupload", nil)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return helper.BadRequest(c, "File tidak dapat dibaca", nil)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return helper.BadRequest(c, "File tidak dapat dibaca", nil)
+	}
+
+	table, err := importer.ReadTable(fh.Filename, data)
+	if err != nil {
+		return helper.BadRequest(c, "File import tidak valid", map[string]string{"file": err.Error()})
+	}
+	rows, err := importer.ParseStudents(table)
+	if err != nil {
+		return helper.BadRequest(c, "File import tidak valid", map[string]string{"file": err.Error()})
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run", c.Query("dry_run")))
+	opt := importer.Options{
+		Mode:   c.FormValue("mode", c.Query("mode")),
+		DryRun: dryRun,
+	}
+
+	im := importer.NewStudentImporter(s.DB, s.userRepo, s.studentRepo, s.lecturerRepo, s.roleRepo)
+	report, err := im.Run(c.Context(), rows, opt)
+	switch {
+	case errors.Is(err, importer.ErrInvalidMode):
+		return helper.BadRequest(c, "Mode import tidak valid", map[string]string{"mode": err.Error()})
+	case errors.Is(err, importer.ErrTooManyRows):
+		return helper.BadRequest(c, "File import tidak valid", map[string]string{"file": err.Error()})
+	case errors.Is(err, importer.ErrNoStudentRole):
+		return helper.BadRequest(c, "Role mahasiswa belum tersedia", nil)
+	case err != nil:
+		return helper.InternalServerError(c, "Gagal mengimpor mahasiswa")
+	}
+
+	switch {
+	case report.DryRun:
+		return helper.Success(c, "Validasi import selesai", report)
+	case report.Mode == models.ImportAllOrNothing && report.Created == 0 && report.Total > 0:
+		return helper.BadRequest(c, "Import dibatalkan, tidak ada data yang disimpan", report)
+	case report.Created > 0:
+		return helper.Created(c, "Import mahasiswa selesai", report)
+	}
+	return helper.Success(c, "Import mahasiswa selesai", report)
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/webhook"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type WebhookService struct {
+	Repo       repository.WebhookRepository
+	Dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookService(repo repository.WebhookRepository, dispatcher *webhook.Dispatcher) *WebhookService {
+	return &WebhookService{
+		Repo:       repo,
+		Dispatcher: dispatcher,
+	}
+}
+
+// List webhooks
+// @Summary      Daftar langganan webhook
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Router       /webhooks [get]
+func (s *WebhookService) List(c *fiber.Ctx) error {
+	list, err := s.Repo.ListSubscriptions(c.Context())
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil langganan webhook")
+	}
+
+	return helper.Success(c, "Daftar langganan webhook", list)
+}
+
+// Create webhook
+// @Summary      Daftarkan webhook
+// @Description  Mendaftarkan URL penerima untuk event achievement.created, achievement.submitted, achievement.verified, achievement.rejected dan achievement.deleted. Secret untuk memverifikasi header X-Webhook-Signature hanya ditampilkan pada respon ini; kosongkan agar dibuatkan server.
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body models.WebhookSubscriptionRequest true "Langganan webhook"
+// @Success      201 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      403 {object} models.MetaInfo
+// @Router       /webhooks [post]
+func (s *WebhookService) Create(c *fiber.Ctx) error {
+	var req models.WebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+	if errs := validateWebhook(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Data webhook tidak valid", errs)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			return helper.InternalServerError(c, "Gagal membuat secret webhook")
+		}
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		sub.CreatedBy = &userID
+	}
+
+	if err := s.Repo.CreateSubscription(c.Context(), sub); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan webhook")
+	}
+
+	return helper.Created(c, "Webhook berhasil didaftarkan", fiber.Map{
+		"subscription": sub,
+		"secret":       secret,
+	})
+}
+
+// Webhook detail
+// @Summary      Detail langganan webhook
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Webhook ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /webhooks/{id} [get]
+func (s *WebhookService) Detail(c *fiber.Ctx) error {
+	sub, err := s.findSubscription(c)
+	if sub == nil {
+		return err
+	}
+
+	return helper.Success(c, "Detail webhook", sub)
+}
+
+// Update webhook
+// @Summary      Ubah langganan webhook
+// @Description  Mengganti URL, event, deskripsi dan status aktif. Secret hanya diganti jika diisi.
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path string                             true "Webhook ID"
+// @Param        body body models.WebhookSubscriptionRequest true "Langganan webhook"
+// @Success      200 {object} models.MetaInfo
+// @Failure      400 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /webhooks/{id} [put]
+func (s *WebhookService) Update(c *fiber.Ctx) error {
+	sub, err := s.findSubscription(c)
+	if sub == nil {
+		return err
+	}
+
+	var req models.WebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Format request tidak valid", err.Error())
+	}
+	if errs := validateWebhook(&req); len(errs) > 0 {
+		return helper.BadRequest(c, "Data webhook tidak valid", errs)
+	}
+
+	sub.URL = req.URL
+	sub.Events = req.Events
+	sub.Description = req.Description
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+
+	if err := s.Repo.UpdateSubscription(c.Context(), sub); err != nil {
+		return helper.InternalServerError(c, "Gagal menyimpan webhook")
+	}
+
+	return helper.Success(c, "Webhook berhasil diperbarui", sub)
+}
+
+// Delete webhook
+// @Summary      Hapus langganan webhook
+// @Description  Menghapus langganan beserta log pengirimannya
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        id path string true "Webhook ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /webhooks/{id} [delete]
+func (s *WebhookService) Delete(c *fiber.Ctx) error {
+	sub, err := s.findSubscription(c)
+	if sub == nil {
+		return err
+	}
+
+	if err := s.Repo.DeleteSubscription(c.Context(), sub.ID); err != nil {
+		return helper.InternalServerError(c, "Gagal menghapus webhook")
+	}
+
+	return helper.Success(c, "Webhook berhasil dihapus", nil)
+}
+
+// Webhook deliveries
+// @Summary      Log pengiriman webhook
+// @Description  Riwayat pengiriman terbaru lebih dulu, beserta jumlah percobaan, kode respon dan error terakhir
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id    path  string true  "Webhook ID"
+// @Param        page  query int    false "Halaman"
+// @Param        limit query int    false "Jumlah per halaman"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /webhooks/{id}/deliveries [get]
+func (s *WebhookService) Deliveries(c *fiber.Ctx) error {
+	sub, err := s.findSubscription(c)
+	if sub == nil {
+		return err
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	list, total, err := s.Repo.ListDeliveries(c.Context(), sub.ID, limit, (page-1)*limit)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil log pengiriman")
+	}
+	if list == nil {
+		list = []models.WebhookDelivery{}
+	}
+
+	meta := models.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalData:  total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+
+	return helper.Paginated(c, "Log pengiriman webhook", list, meta)
+}
+
+// Redeliver webhook
+// @Summary      Kirim ulang webhook
+// @Description  Mengirim ulang payload sebuah pengiriman sebagai pengiriman baru dengan X-Webhook-Event-Id yang sama. Jika gagal, pengiriman baru diulang otomatis.
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Delivery ID"
+// @Success      200 {object} models.MetaInfo
+// @Failure      404 {object} models.MetaInfo
+// @Router       /webhooks/deliveries/{id}/redeliver [post]
+func (s *WebhookService) Redeliver(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return helper.NotFound(c, "Pengiriman tidak ditemukan")
+	}
+
+	original, err := s.Repo.GetDelivery(c.Context(), id)
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengambil pengiriman")
+	}
+	if original == nil {
+		return helper.NotFound(c, "Pengiriman tidak ditemukan")
+	}
+
+	delivery, err := s.Dispatcher.Redeliver(c.Context(), original)
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		return helper.NotFound(c, "Webhook tidak ditemukan")
+	}
+	if err != nil {
+		return helper.InternalServerError(c, "Gagal mengirim ulang webhook")
+	}
+
+	return helper.Success(c, "Webhook dikirim ulang", delivery)
+}
+
+// findSubscription menulis respon 404/500 dan mengembalikan nil jika
+// langganan pada parameter id tidak dapat diambil.
+func (s *WebhookService) findSubscription(c *fiber.Ctx) (*models.WebhookSubscription, error) {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, helper.NotFound(c, "Webhook tidak ditemukan")
+	}
+
+	sub, err := s.Repo.GetSubscription(c.Context(), id)
+	if err != nil {
+		return nil, helper.InternalServerError(c, "Gagal mengambil webhook")
+	}
+	if sub == nil {
+		return nil, helper.NotFound(c, "Webhook tidak ditemukan")
+	}
+	return sub, nil
+}
+
+func validateWebhook(req *models.WebhookSubscriptionRequest) map[string]string {
+	errs := map[string]string{}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs["url"] = "harus berupa URL http atau https"
+	}
+
+	if len(req.Events) == 0 {
+		errs["events"] = "minimal satu event"
+	}
+	seen := map[string]bool{}
+	unique := req.Events[:0]
+	for _, e := range req.Events {
+		if !webhook.IsEventType(e) {
+			errs["events"] = "event " + e + " tidak dikenal"
+			continue
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+	req.Events = unique
+
+	if req.Secret != "" && len(req.Secret) < 16 {
+		errs["secret"] = "minimal 16 karakter"
+	}
+
+	return errs
+}
@@ -0,0 +1,94 @@
+// Package storage menyimpan file lampiran pada disk lokal atau object
+// storage S3-compatible (AWS S3, MinIO, dsb). Pemilihan backend dilakukan
+// lewat environment sehingga service tidak bergantung pada lokasi file.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound dikembalikan jika objek dengan key tersebut tidak ada.
+var ErrNotFound = errors.New("storage: objek tidak ditemukan")
+
+// ErrInvalidKey dikembalikan untuk key yang berpotensi keluar dari root
+// penyimpanan atau mengandung karakter yang tidak diizinkan.
+var ErrInvalidKey = errors.New("storage: key tidak valid")
+
+// Storage adalah abstraksi penyimpanan objek berbasis key.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL mengembalikan URL unduhan yang hanya berlaku selama ttl.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// List mengembalikan seluruh objek yang key-nya diawali prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Object adalah metadata objek yang tersimpan.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+var keyPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*(/[a-zA-Z0-9][a-zA-Z0-9._-]*)*$`)
+
+// ValidKey memastikan key hanya terdiri dari segmen aman yang dipisah "/".
+func ValidKey(key string) error {
+	if len(key) > 512 || !keyPattern.MatchString(key) || strings.Contains(key, "..") {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// FromEnv memilih backend berdasarkan STORAGE_DRIVER ("local" atau "s3",
+// default "local").
+func FromEnv() (Storage, error) {
+	secret := os.Getenv("STORAGE_SIGNING_SECRET")
+
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "", "local":
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = "uploads"
+		}
+		baseURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if baseURL == "" {
+			baseURL = "/api/v1/files"
+		}
+		// URL unduhan ditandatangani dengan kunci tersendiri, bukan JWT_SECRET
+		if secret == "" {
+			return nil, errors.New("storage: STORAGE_SIGNING_SECRET belum diatur")
+		}
+		if secret == os.Getenv("JWT_SECRET") {
+			return nil, errors.New("storage: STORAGE_SIGNING_SECRET tidak boleh sama dengan JWT_SECRET")
+		}
+		return NewLocal(root, baseURL, []byte(secret)), nil
+
+	case "s3":
+		s3 := &S3{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: strings.ToLower(os.Getenv("S3_PATH_STYLE")) != "false",
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			return nil, errors.New("storage: S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+		}
+		return s3, nil
+	}
+
+	return nil, errors.New("storage: STORAGE_DRIVER tidak dikenal")
+}
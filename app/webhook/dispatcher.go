@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+)
+
+// ErrSubscriptionNotFound dikembalikan Redeliver jika langganan sudah dihapus.
+var ErrSubscriptionNotFound = errors.New("webhook: langganan tidak ditemukan")
+
+// responseLimit membatasi isi respon penerima yang disimpan di log.
+const responseLimit = 2048
+
+// Dispatcher membuat pengiriman untuk setiap langganan yang cocok lalu
+// mengirimnya. Dispatcher memenuhi events.Sink sehingga cukup didaftarkan
+// pada events.Publisher.
+type Dispatcher struct {
+	Repo   repository.WebhookRepository
+	Client *http.Client
+
+	// MaxAttempts adalah batas percobaan sebelum pengiriman dinyatakan gagal.
+	MaxAttempts int
+	// BaseDelay adalah jeda sebelum percobaan kedua; setiap kegagalan
+	// berikutnya melipatgandakannya hingga MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Now       func() time.Time
+
+	wake chan struct{}
+}
+
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		Repo:        repo,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    6 * time.Hour,
+		Now:         time.Now,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Backoff mengembalikan jeda setelah percobaan ke-attempt gagal.
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < attempt && delay < d.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxDelay {
+		delay = d.MaxDelay
+	}
+	return delay
+}
+
+// Woken memberi sinyal saat ada pengiriman baru untuk worker.
+func (d *Dispatcher) Woken() <-chan struct{} {
+	return d.wake
+}
+
+// Handle membuat pengiriman untuk event prestasi ev. Kegagalan hanya dicatat
+// agar tidak membatalkan aksi pemicunya.
+func (d *Dispatcher) Handle(ctx context.Context, ev *models.Event) {
+	eventType := eventTypeOf(ev)
+	if eventType == "" {
+		return
+	}
+
+	subs, err := d.Repo.ActiveSubscriptionsFor(ctx, eventType)
+	if err != nil {
+		helper.Log.Warn().Err(err).Str("event", eventType).Msg("gagal mengambil langganan webhook")
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	eventID := strconv.FormatInt(ev.ID, 10)
+	payload, err := json.Marshal(map[string]any{
+		"id":         eventID,
+		"type":       eventType,
+		"created_at": ev.CreatedAt,
+		"data":       ev.Data,
+	})
+	if err != nil {
+		helper.Log.Warn().Err(err).Str("event", eventType).Msg("gagal menyusun payload webhook")
+		return
+	}
+
+	now := d.Now()
+	for _, sub := range subs {
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.WebhookPending,
+			NextAttemptAt:  &now,
+		}
+		if err := d.Repo.CreateDelivery(ctx, delivery); err != nil {
+			helper.Log.Warn().Err(err).Str("subscription_id", sub.ID).Msg("gagal mencatat pengiriman webhook")
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessDue mengirim hingga limit pengiriman yang sudah jatuh tempo.
+func (d *Dispatcher) ProcessDue(ctx context.Context, limit int) (done, failed int, err error) {
+	due, err := d.Repo.ClaimDue(ctx, limit, 5*time.Minute)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	subs := map[string]*models.WebhookSubscription{}
+	for i := range due {
+		delivery := &due[i]
+
+		sub, ok := subs[delivery.SubscriptionID]
+		if !ok {
+			sub, err = d.Repo.GetSubscription(ctx, delivery.SubscriptionID)
+			if err != nil {
+				return done, failed, err
+			}
+			subs[delivery.SubscriptionID] = sub
+		}
+
+		if sub == nil || !sub.IsActive {
+			d.abandon(ctx, delivery, "langganan sudah dinonaktifkan")
+			failed++
+			continue
+		}
+
+		if d.Attempt(ctx, delivery, sub) != nil {
+			failed++
+			continue
+		}
+		done++
+	}
+
+	return done, failed, nil
+}
+
+// Redeliver mengirim ulang payload original sebagai pengiriman baru dan
+// langsung mencobanya sekali; jika gagal, pengiriman baru diulang seperti
+// biasa.
+func (d *Dispatcher) Redeliver(ctx context.Context, original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	sub, err := d.Repo.GetSubscription(ctx, original.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	// tanpa next_attempt_at, worker tidak mengambilnya selama dicoba di sini
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookPending,
+	}
+	if err := d.Repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	_ = d.Attempt(ctx, delivery, sub)
+	return delivery, nil
+}
+
+// Attempt mengirim delivery ke sub satu kali lalu menyimpan hasilnya.
+// Error dikembalikan jika penerima tidak merespon 2xx.
+func (d *Dispatcher) Attempt(ctx context.Context, delivery *models.WebhookDelivery, sub *models.WebhookSubscription) error {
+	now := d.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = nil
+	delivery.ResponseBody = nil
+
+	sendErr := d.send(ctx, delivery, sub, now)
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookSucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+	} else {
+		msg := sendErr.Error()
+		delivery.LastError = &msg
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.Status = models.WebhookFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(d.Backoff(delivery.Attempts))
+			delivery.Status = models.WebhookPending
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := d.Repo.SaveAttempt(ctx, delivery); err != nil {
+		helper.Log.Error().Err(err).Str("delivery_id", delivery.ID).Msg("gagal menyimpan hasil pengiriman webhook")
+	}
+	return sendErr
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery, sub *models.WebhookSubscription, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "uas-webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, responseLimit))
+	code, text := resp.StatusCode, string(body)
+	delivery.ResponseCode = &code
+	delivery.ResponseBody = &text
+
+	if code < 200 || code > 299 {
+		return fmt.Errorf("penerima merespon HTTP %d", code)
+	}
+	return nil
+}
+
+func (d *Dispatcher) abandon(ctx context.Context, delivery *models.WebhookDelivery, reason string) {
+	delivery.Status = models.WebhookFailed
+	delivery.NextAttemptAt = nil
+	delivery.LastError = &reason
+	if err := d.Repo.SaveAttempt(ctx, delivery); err != nil {
+		helper.Log.Error().Err(err).Str("delivery_id", delivery.ID).Msg("gagal menyimpan hasil pengiriman webhook")
+	}
+}
@@ -0,0 +1,102 @@
+// Package webhook mengirim event siklus hidup prestasi ke sistem luar yang
+// berlangganan. Setiap pengiriman ditandatangani HMAC-SHA256, dicatat di
+// webhook_deliveries dan diulang dengan backoff eksponensial jika gagal.
+//
+// Penerima memverifikasi header X-Webhook-Signature, yaitu
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)) dengan
+// timestamp dari header X-Webhook-Timestamp (detik Unix). X-Webhook-Event-Id
+// sama untuk setiap pengiriman ulang event yang sama sehingga dapat dipakai
+// untuk deduplikasi.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"uas/app/events"
+	"uas/app/models"
+	"uas/utils"
+)
+
+// Jenis event webhook.
+const (
+	EventCreated   = "achievement.created"
+	EventSubmitted = "achievement.submitted"
+	EventVerified  = "achievement.verified"
+	EventRejected  = "achievement.rejected"
+	EventDeleted   = "achievement.deleted"
+)
+
+// EventTypes adalah seluruh event yang dapat dilanggan.
+var EventTypes = []string{EventCreated, EventSubmitted, EventVerified, EventRejected, EventDeleted}
+
+func IsEventType(t string) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Header pengiriman.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Event-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign menghasilkan nilai header X-Webhook-Signature.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa signature seperti yang dilakukan penerima.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// NewSecret membuat secret acak untuk langganan baru.
+func NewSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// eventTypeOf memetakan perubahan status prestasi ke event webhook; string
+// kosong untuk perubahan yang tidak dikirim (mis. masuk revisi).
+func eventTypeOf(ev *models.Event) string {
+	if ev.Type != events.TypeStatusChanged {
+		return ""
+	}
+
+	from, _ := ev.Data["from_status"].(string)
+	status, _ := ev.Data["status"].(string)
+
+	switch status {
+	case utils.AchievementStatusDraft:
+		if from == "" {
+			return EventCreated
+		}
+	case utils.AchievementStatusSubmitted:
+		return EventSubmitted
+	case utils.AchievementStatusVerified:
+		return EventVerified
+	case utils.AchievementStatusRejected:
+		return EventRejected
+	case utils.AchievementStatusDeleted:
+		return EventDeleted
+	}
+	return ""
+}
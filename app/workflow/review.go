@@ -0,0 +1,17 @@
+package workflow
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// ReviewSLAFromEnv membaca ACHIEVEMENT_REVIEW_SLA_HOURS, batas waktu
+// prestasi yang diajukan harus diverifikasi atau ditolak (default 72 jam).
+func ReviewSLAFromEnv() time.Duration {
+	v, err := strconv.Atoi(os.Getenv("ACHIEVEMENT_REVIEW_SLA_HOURS"))
+	if err != nil || v <= 0 {
+		return 72 * time.Hour
+	}
+	return time.Duration(v) * time.Hour
+}
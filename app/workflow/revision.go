@@ -0,0 +1,112 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"uas/app/models"
+	"uas/utils"
+)
+
+// MaxResubmissionsFromEnv membaca ACHIEVEMENT_MAX_RESUBMISSIONS
+// (default 3, 0 berarti tidak dibatasi).
+func MaxResubmissionsFromEnv() int {
+	v, err := strconv.Atoi(os.Getenv("ACHIEVEMENT_MAX_RESUBMISSIONS"))
+	if err != nil || v < 0 {
+		return 3
+	}
+	return v
+}
+
+// Resubmissions menghitung berapa kali prestasi sudah dikirim ulang
+// berdasarkan history (submit pertama tidak dihitung).
+func Resubmissions(history []models.AchievementStatusHistory) int {
+	n := 0
+	for _, h := range history {
+		if h.ToStatus == utils.AchievementStatusSubmitted {
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return n - 1
+}
+
+// Snapshot mengambil bagian isi prestasi yang dapat direvisi mahasiswa.
+func Snapshot(a *models.AchievementMongo) models.AchievementSnapshot {
+	files := make([]string, 0, len(a.Attachments))
+	for _, f := range a.Attachments {
+		files = append(files, f.FileName)
+	}
+
+	return models.AchievementSnapshot{
+		AchievementType: a.AchievementType,
+		Title:           a.Title,
+		Description:     a.Description,
+		Details:         a.Details,
+		Tags:            a.Tags,
+		Attachments:     files,
+	}
+}
+
+// Diff membandingkan dua snapshot per field; field details dibandingkan per
+// key ("details.<key>").
+func Diff(before, after models.AchievementSnapshot) []models.FieldChange {
+	b, a := normalize(before), normalize(after)
+
+	changes := []models.FieldChange{}
+	for _, field := range []string{"achievement_type", "title", "description", "tags", "attachments"} {
+		if !reflect.DeepEqual(b[field], a[field]) {
+			changes = append(changes, models.FieldChange{Field: field, Before: b[field], After: a[field]})
+		}
+	}
+
+	bd, _ := b["details"].(map[string]interface{})
+	ad, _ := a["details"].(map[string]interface{})
+
+	keys := map[string]struct{}{}
+	for k := range bd {
+		keys[k] = struct{}{}
+	}
+	for k := range ad {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		if !reflect.DeepEqual(bd[k], ad[k]) {
+			changes = append(changes, models.FieldChange{Field: "details." + k, Before: bd[k], After: ad[k]})
+		}
+	}
+
+	return changes
+}
+
+// normalize menyamakan tipe nilai (angka, tanggal, slice) lewat JSON agar
+// snapshot dari database dan dari MongoDB dapat dibandingkan langsung.
+func normalize(s models.AchievementSnapshot) map[string]interface{} {
+	out := map[string]interface{}{}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return out
+	}
+	_ = json.Unmarshal(raw, &out)
+
+	// nil dan slice kosong dianggap sama
+	for _, field := range []string{"tags", "attachments"} {
+		if list, ok := out[field].([]interface{}); ok && len(list) == 0 {
+			out[field] = nil
+		}
+	}
+	return out
+}
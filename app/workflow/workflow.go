@@ -0,0 +1,108 @@
+// Package workflow mendefinisikan state machine status prestasi. Semua
+// perubahan status (submit, verify, reject, revisi, hapus) harus melalui
+// Apply agar aturan transisi dan audit trail konsisten.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"uas/app/models"
+	"uas/utils"
+)
+
+type Event string
+
+const (
+	Submit Event = "submit"
+	Verify Event = "verify"
+	Reject Event = "reject"
+	Revise Event = "revise"
+	Delete Event = "delete"
+)
+
+var ErrInvalidTransition = errors.New("transisi status tidak valid")
+
+// transitions: status asal -> event -> status tujuan.
+var transitions = map[string]map[Event]string{
+	utils.AchievementStatusDraft: {
+		Submit: utils.AchievementStatusSubmitted,
+		Delete: utils.AchievementStatusDeleted,
+	},
+	utils.AchievementStatusSubmitted: {
+		Verify: utils.AchievementStatusVerified,
+		Reject: utils.AchievementStatusRejected,
+	},
+	utils.AchievementStatusRejected: {
+		Revise: utils.AchievementStatusRevision,
+	},
+	utils.AchievementStatusRevision: {
+		Submit: utils.AchievementStatusSubmitted,
+		Delete: utils.AchievementStatusDeleted,
+	},
+}
+
+// Next mengembalikan status tujuan untuk event dari status from.
+func Next(from string, e Event) (string, error) {
+	to, ok := transitions[from][e]
+	if !ok {
+		return "", fmt.Errorf("%w: %s tidak dapat %s", ErrInvalidTransition, from, e)
+	}
+	return to, nil
+}
+
+// Can mengembalikan true jika event diizinkan dari status from.
+func Can(from string, e Event) bool {
+	_, err := Next(from, e)
+	return err == nil
+}
+
+// Editable mengembalikan true jika isi prestasi masih boleh diubah pemiliknya.
+func Editable(status string) bool {
+	return status == utils.AchievementStatusDraft || status == utils.AchievementStatusRevision
+}
+
+// Apply menjalankan event pada ref: mengubah status beserta kolom timestamp
+// terkait, lalu mengembalikan entry history yang harus disimpan bersama ref.
+func Apply(ref *models.AchievementReference, e Event, actorID string, note string, now time.Time) (*models.AchievementStatusHistory, error) {
+	from := ref.Status
+
+	to, err := Next(from, e)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e {
+	case Submit:
+		ref.SubmittedAt = &now
+		ref.VerifiedAt = nil
+		ref.VerifiedBy = nil
+	case Verify:
+		ref.VerifiedAt = &now
+		ref.VerifiedBy = &actorID
+		ref.RejectionNote = nil
+	case Reject:
+		ref.VerifiedAt = &now
+		ref.VerifiedBy = &actorID
+		ref.RejectionNote = &note
+	}
+
+	ref.Status = to
+	ref.UpdatedAt = now
+
+	entry := &models.AchievementStatusHistory{
+		AchievementRefID: ref.ID,
+		FromStatus:       &from,
+		ToStatus:         to,
+		CreatedAt:        now,
+	}
+	if actorID != "" {
+		entry.ActorID = &actorID
+	}
+	if note != "" {
+		entry.Note = &note
+	}
+
+	return entry, nil
+}
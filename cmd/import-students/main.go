@@ -0,0 +1,91 @@
+// Command import-students membuat akun mahasiswa massal dari berkas CSV atau
+// XLSX, sama seperti endpoint POST /users/import.
+//
+//	go run ./cmd/import-students -file mahasiswa.xlsx -dry-run
+//	go run ./cmd/import-students -file mahasiswa.csv -mode best_effort
+//	go run ./cmd/import-students -file mahasiswa.csv -report laporan.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"uas/app/importer"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "berkas CSV atau XLSX")
+	mode := flag.String("mode", models.ImportAllOrNothing, "all_or_nothing atau best_effort")
+	dryRun := flag.Bool("dry-run", false, "validasi tanpa menyimpan")
+	reportPath := flag.String("report", "", "simpan laporan import (JSON) ke berkas ini")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal("Gagal membaca berkas:", err)
+	}
+	table, err := importer.ReadTable(*file, data)
+	if err != nil {
+		log.Fatal("Berkas import tidak valid: ", err)
+	}
+	rows, err := importer.ParseStudents(table)
+	if err != nil {
+		log.Fatal("Berkas import tidak valid: ", err)
+	}
+
+	envPath, _ := filepath.Abs(".env")
+	if err := godotenv.Load(envPath); err != nil {
+		log.Fatal("Gagal load .env:", err)
+	}
+
+	db := database.PostgresConnections()
+	defer db.Close()
+
+	im := importer.NewStudentImporter(
+		db,
+		repository.NewUserRepo(db),
+		repository.NewStudentRepo(db),
+		repository.NewLecturerRepo(db),
+		repository.NewRoleRepo(db),
+	)
+	// tidak dibatasi seperti upload lewat HTTP
+	im.MaxRows = 0
+
+	report, err := im.Run(context.Background(), rows, importer.Options{Mode: *mode, DryRun: *dryRun})
+	if err != nil {
+		log.Fatal("Gagal mengimpor mahasiswa: ", err)
+	}
+
+	for _, r := range report.Rows {
+		if len(r.Errors) > 0 {
+			log.Printf("baris %d (%s): %s %v", r.Row, r.Username, r.Status, r.Errors)
+		}
+	}
+	log.Printf("%d baris: %d valid, %d tidak valid, %d dibuat, %d gagal (mode=%s, dry-run=%v)",
+		report.Total, report.Valid, report.Invalid, report.Created, report.Failed, report.Mode, report.DryRun)
+
+	if *reportPath != "" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*reportPath, out, 0o644); err != nil {
+			log.Fatal("Gagal menyimpan laporan:", err)
+		}
+	}
+
+	if report.Invalid > 0 || report.Failed > 0 {
+		os.Exit(1)
+	}
+}
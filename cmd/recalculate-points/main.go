@@ -0,0 +1,69 @@
+// Command recalculate-points menghitung ulang poin prestasi terverifikasi.
+//
+//	go run ./cmd/recalculate-points                # pakai aturan aktif
+//	go run ./cmd/recalculate-points -version 2     # pakai versi tertentu
+//	go run ./cmd/recalculate-points -dry-run       # hanya hitung perubahan
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"path/filepath"
+
+	"uas/app/jobs"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	version := flag.Int("version", 0, "versi aturan poin (0 = versi aktif)")
+	dryRun := flag.Bool("dry-run", false, "hitung tanpa menyimpan")
+	flag.Parse()
+
+	envPath, _ := filepath.Abs(".env")
+	if err := godotenv.Load(envPath); err != nil {
+		log.Fatal("Gagal load .env:", err)
+	}
+
+	db := database.PostgresConnections()
+	defer db.Close()
+	mongoDB := database.MongoConnections()
+
+	ctx := context.Background()
+	rules := repository.NewPointRuleRepo(db)
+
+	var (
+		set *models.PointRuleSet
+		err error
+	)
+	if *version > 0 {
+		set, err = rules.GetByVersion(ctx, *version)
+	} else {
+		set, err = rules.Active(ctx)
+	}
+	if err != nil {
+		log.Fatal("Gagal mengambil aturan poin:", err)
+	}
+	if set == nil {
+		log.Fatal("Aturan poin tidak ditemukan")
+	}
+
+	res, err := jobs.RecalculatePoints(
+		ctx,
+		repository.NewAchievementReferenceRepository(db),
+		repository.NewAchievementMongoRepository(mongoDB.Collection("achievements")),
+		repository.NewOutboxRepo(db),
+		set,
+		*dryRun,
+	)
+	if err != nil {
+		log.Fatal("Gagal menghitung ulang poin:", err)
+	}
+
+	log.Printf("versi %d: %d diperiksa, %d berubah, %d gagal (dry-run=%v)",
+		set.Version, res.Checked, res.Changed, res.Failed, *dryRun)
+}
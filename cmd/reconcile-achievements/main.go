@@ -0,0 +1,68 @@
+// Command reconcile-achievements memeriksa konsistensi achievement_references
+// (PostgreSQL) dengan koleksi achievements (MongoDB).
+//
+//	go run ./cmd/reconcile-achievements            # hanya laporan
+//	go run ./cmd/reconcile-achievements -repair    # perbaiki ketidaksesuaian
+//	go run ./cmd/reconcile-achievements -json      # laporan dalam JSON
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"uas/app/jobs"
+	"uas/app/outbox"
+	"uas/app/repository"
+	"uas/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "perbaiki ketidaksesuaian yang ditemukan")
+	asJSON := flag.Bool("json", false, "cetak laporan lengkap dalam JSON")
+	flag.Parse()
+
+	envPath, _ := filepath.Abs(".env")
+	if err := godotenv.Load(envPath); err != nil {
+		log.Fatal("Gagal load .env:", err)
+	}
+
+	db := database.PostgresConnections()
+	defer db.Close()
+	mongoDB := database.MongoConnections()
+
+	ctx := context.Background()
+	refs := repository.NewAchievementReferenceRepository(db)
+	docs := repository.NewAchievementMongoRepository(mongoDB.Collection("achievements"))
+	outboxRepo := repository.NewOutboxRepo(db)
+
+	// terapkan dulu operasi yang masih tertunda agar tidak terbaca sebagai selisih
+	done, failed, err := outbox.Process(ctx, outboxRepo, docs, 1000, outbox.DefaultMaxAttempts)
+	if err != nil {
+		log.Fatal("Gagal memproses outbox:", err)
+	}
+	log.Printf("outbox: %d diterapkan, %d gagal", done, failed)
+
+	report, err := jobs.ReconcileAchievements(ctx, refs, docs, outboxRepo, *repair)
+	if err != nil {
+		log.Fatal("Gagal rekonsiliasi:", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	}
+
+	log.Printf("%d reference, %d dokumen diperiksa", report.CheckedRefs, report.CheckedDocs)
+	log.Printf("orphan=%d missing=%d deleted=%d stale=%d skipped=%d",
+		len(report.OrphanDocs), len(report.MissingDocs), len(report.DeletedDocs), len(report.StaleDocs), len(report.Skipped))
+	if *repair {
+		log.Printf("%d diperbaiki, %d gagal", report.Repaired, report.Failed)
+	}
+}
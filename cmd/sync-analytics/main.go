@@ -0,0 +1,39 @@
+// Command sync-analytics menyalin tipe, tingkat kompetisi dan poin prestasi
+// dari MongoDB ke achievement_references untuk endpoint analitik.
+//
+//	go run ./cmd/sync-analytics
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+
+	"uas/app/jobs"
+	"uas/app/repository"
+	"uas/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	envPath, _ := filepath.Abs(".env")
+	if err := godotenv.Load(envPath); err != nil {
+		log.Fatal("Gagal load .env:", err)
+	}
+
+	db := database.PostgresConnections()
+	defer db.Close()
+	mongoDB := database.MongoConnections()
+
+	res, err := jobs.SyncAchievementFacts(
+		context.Background(),
+		repository.NewAchievementReferenceRepository(db),
+		repository.NewAchievementMongoRepository(mongoDB.Collection("achievements")),
+	)
+	if err != nil {
+		log.Fatal("Gagal sinkronisasi data analitik:", err)
+	}
+
+	log.Printf("%d diperiksa, %d diperbarui, %d gagal", res.Checked, res.Updated, res.Failed)
+}
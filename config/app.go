@@ -1,20 +1,28 @@
 package config
 
 import (
+	"uas/app/services"
 	"uas/helper"
 
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2"
 )
 
-func InitApp() *fiber.App {
-	helper.InitLogger()
-
-	app := fiber.New(fiber.Config{
+// AppConfig adalah konfigurasi Fiber aplikasi. BodyLimit mengikuti batas
+// lampiran agar kuota upload dapat dicapai.
+func AppConfig() fiber.Config {
+	return fiber.Config{
+		BodyLimit: services.AttachmentPolicyFromEnv().BodyLimit(),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			return helper.InternalServerError(c, err.Error())
 		},
-	})
+	}
+}
+
+func InitApp() *fiber.App {
+	helper.InitLogger()
+
+	app := fiber.New(AppConfig())
 
 	app.Use(cors.New())
 
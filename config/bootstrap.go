@@ -1,6 +1,11 @@
 package config
 
 import (
+	"context"
+	"time"
+
+	"uas/app/jobs"
+	"uas/middleware"
 	"uas/routes"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +17,17 @@ func Bootstrap() *fiber.App {
 
 	container := BuildContainer(db.Postgres, db.Mongo)
 
+	middleware.InitAuth(container.RevocationRepo, container.SessionRepo, container.RoleRepo)
+
+	ctx := context.Background()
+	jobs.StartRevocationCleanup(ctx, container.RevocationRepo, time.Hour)
+	jobs.StartSessionCleanup(ctx, container.SessionRepo, 6*time.Hour, 30*24*time.Hour)
+	jobs.StartOutboxWorker(ctx, container.OutboxRepo, container.AchievementMongoRepo, 30*time.Second)
+	jobs.StartAttachmentSweeper(ctx, container.Storage, container.AchievementMongoRepo, 6*time.Hour, 24*time.Hour)
+	jobs.StartEventLogCleanup(ctx, container.EventRepo, 6*time.Hour, 7*24*time.Hour)
+	jobs.StartWebhookWorker(ctx, container.WebhookDispatcher, 30*time.Second)
+	jobs.StartNotificationWorker(ctx, container.Notifier)
+
 	routes.RegisterRoutes(app, &routes.RouteContainer{
 		AuthService: container.AuthService,
 		UserService: container.UserService,
@@ -19,6 +35,18 @@ func Bootstrap() *fiber.App {
 		AchievementService: container.AchievementService,
 		LecturerService: container.LecturerService,
 		ReportService: container.ReportService,
+		LockoutService: container.LockoutService,
+		RoleService: container.RoleService,
+		PermissionService: container.PermissionService,
+		PointRuleService: container.PointRuleService,
+		AchievementTypeService: container.AchievementTypeService,
+		FileService: container.FileService,
+		AnalyticsService: container.AnalyticsService,
+		PortfolioService: container.PortfolioService,
+		ReviewService: container.ReviewService,
+		NotificationService: container.NotificationService,
+		EventService: container.EventService,
+		WebhookService: container.WebhookService,
 	})
 
 	return app
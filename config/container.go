@@ -1,11 +1,20 @@
 package config
 
 import (
+	"context"
+	"log"
 	repo "uas/app/repository"
 	"database/sql"
 	mgodriver "go.mongodb.org/mongo-driver/mongo"
 
+	"uas/app/events"
+	"uas/app/notify"
+	"uas/app/portfolio"
 	"uas/app/services"
+	"uas/app/storage"
+	"uas/app/webhook"
+	"uas/database"
+	"uas/utils"
 )
 
 
@@ -16,6 +25,28 @@ type Container struct {
 	AchievementService	*services.AchievementService
 	LecturerService 	*services.LecturerService
 	ReportService 		*services.ReportService
+	LockoutService 		*services.LockoutService
+	RoleService 		*services.RoleService
+	PermissionService 	*services.PermissionService
+	PointRuleService 	*services.PointRuleService
+	AchievementTypeService 	*services.AchievementTypeService
+	FileService 		*services.FileService
+	AnalyticsService 	*services.AnalyticsService
+	PortfolioService 	*services.PortfolioService
+	ReviewService 		*services.ReviewService
+	NotificationService 	*services.NotificationService
+	EventService 		*services.EventService
+	WebhookService 		*services.WebhookService
+
+	RevocationRepo 		repo.TokenRevocationRepository
+	SessionRepo 		repo.SessionRepository
+	RoleRepo 			repo.RoleRepository
+	OutboxRepo 			repo.OutboxRepository
+	AchievementMongoRepo 	repo.AchievementMongoRepository
+	EventRepo 			repo.EventRepository
+	WebhookDispatcher 	*webhook.Dispatcher
+	Notifier 			*notify.Notifier
+	Storage 			storage.Storage
 }
 
 // Dependency Injection Container
@@ -27,21 +58,77 @@ func BuildContainer(db *sql.DB, mongoDB *mgodriver.Database) *Container {
 	studentRepo := repo.NewStudentRepo(db)
     lecturerRepo := repo.NewLecturerRepo(db)
 	achievementRefRepo := repo.NewAchievementReferenceRepository(db)
+	revocationRepo := repo.NewTokenRevocationRepo(db)
+	sessionRepo := repo.NewSessionRepo(db)
+	passwordResetRepo := repo.NewPasswordResetRepo(db)
+	loginAttemptRepo := repo.NewLoginAttemptRepo(db)
+	mfaRepo := repo.NewMFARepo(db)
+	roleRepo := repo.NewRoleRepo(db)
+	permissionRepo := repo.NewPermissionRepo(db)
+	achievementHistoryRepo := repo.NewAchievementHistoryRepo(db)
+	reviewSnapshotRepo := repo.NewReviewSnapshotRepo(db)
+	pointRuleRepo := repo.NewPointRuleRepo(db)
+	achievementTypeRepo := repo.NewAchievementTypeRepo(db)
+	outboxRepo := repo.NewOutboxRepo(db)
+	analyticsRepo := repo.NewAnalyticsRepository(db)
+	portfolioRepo := repo.NewPortfolioRepo(db)
+	reviewRepo := repo.NewReviewRepo(db)
+	notificationRepo := repo.NewNotificationRepo(db)
+	eventRepo := repo.NewEventRepo(db)
+	webhookRepo := repo.NewWebhookRepo(db)
 
 
-	achievementMongoRepo := repo.NewAchievementMongoRepository(
-		mongoDB.Collection("achievements"),
-	)
+	achievementCol := mongoDB.Collection("achievements")
+	if err := repo.EnsureAchievementIndexes(context.Background(), achievementCol); err != nil {
+		log.Println("gagal membuat index achievements:", err)
+	}
+
+	achievementMongoRepo := repo.NewAchievementMongoRepository(achievementCol)
+
+	mailer := utils.NewMailerFromEnv()
+
+	store, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalln("konfigurasi storage tidak valid:", err)
+	}
+
+	signer, err := portfolio.SignerFromEnv()
+	if err != nil {
+		log.Fatalln("konfigurasi portofolio tidak valid:", err)
+	}
+
+	channels, err := notify.ChannelsFromEnv(mailer)
+	if err != nil {
+		log.Fatalln("konfigurasi notifikasi tidak valid:", err)
+	}
+	broker, err := events.BrokerFromEnv(context.Background(), database.PostgresDSN(), db, eventRepo)
+	if err != nil {
+		log.Fatalln("konfigurasi event broker tidak valid:", err)
+	}
+	dispatcher := webhook.NewDispatcher(webhookRepo)
+	publisher := events.NewPublisher(eventRepo, broker, dispatcher)
+
+	notifier := notify.New(notificationRepo, userRepo, publisher, channels...)
 
 	// SERVICES
-	authService := services.NewAuthService(authRepo)
-    userService := services.NewUserService(db, userRepo, studentRepo, lecturerRepo)
+	authService := services.NewAuthService(
+		authRepo,
+		revocationRepo,
+		sessionRepo,
+		passwordResetRepo,
+		mailer,
+		loginAttemptRepo,
+		mfaRepo,
+	)
+    userService := services.NewUserService(db, userRepo, studentRepo, lecturerRepo, roleRepo)
 	studentService := services.NewStudentService(
 		db,
 		studentRepo,
 		lecturerRepo,
 		achievementRefRepo,
 		achievementMongoRepo,
+		notifier,
+		outboxRepo,
 	)
 
 	achievementService := services.NewAchievementService(
@@ -50,6 +137,14 @@ func BuildContainer(db *sql.DB, mongoDB *mgodriver.Database) *Container {
 		achievementRefRepo,
 		lecturerRepo,
 		userRepo,
+		achievementHistoryRepo,
+		reviewSnapshotRepo,
+		pointRuleRepo,
+		achievementTypeRepo,
+		outboxRepo,
+		store,
+		notifier,
+		publisher,
 	)
 
 	lecturerService := services.NewLecturerService(
@@ -60,9 +155,39 @@ func BuildContainer(db *sql.DB, mongoDB *mgodriver.Database) *Container {
 	reportService := services.NewReportService(
 		achievementRefRepo,
 		achievementMongoRepo,
+		studentRepo,
+		lecturerRepo,
 	)
 
 
+	lockoutService := services.NewLockoutService(loginAttemptRepo)
+	roleService := services.NewRoleService(roleRepo, permissionRepo)
+	permissionService := services.NewPermissionService(permissionRepo)
+	pointRuleService := services.NewPointRuleService(pointRuleRepo)
+	achievementTypeService := services.NewAchievementTypeService(achievementTypeRepo)
+	fileService := services.NewFileService(store)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, studentRepo, lecturerRepo)
+	reviewService := services.NewReviewService(
+		achievementRefRepo,
+		reviewRepo,
+		achievementMongoRepo,
+		studentRepo,
+		lecturerRepo,
+	)
+	notificationService := services.NewNotificationService(notificationRepo, notifier)
+	eventService := services.NewEventService(eventRepo, broker, studentRepo, lecturerRepo)
+	webhookService := services.NewWebhookService(webhookRepo, dispatcher)
+	portfolioService := services.NewPortfolioService(
+		portfolioRepo,
+		achievementRefRepo,
+		achievementMongoRepo,
+		studentRepo,
+		lecturerRepo,
+		userRepo,
+		achievementTypeRepo,
+		signer,
+	)
+
 	return &Container{
 		AuthService: authService,
 		UserService: userService,
@@ -70,5 +195,27 @@ func BuildContainer(db *sql.DB, mongoDB *mgodriver.Database) *Container {
 		AchievementService: achievementService,
 		LecturerService: lecturerService,
 		ReportService: reportService,
+		LockoutService: lockoutService,
+		RoleService: roleService,
+		PermissionService: permissionService,
+		PointRuleService: pointRuleService,
+		AchievementTypeService: achievementTypeService,
+		FileService: fileService,
+		AnalyticsService: analyticsService,
+		PortfolioService: portfolioService,
+		ReviewService: reviewService,
+		NotificationService: notificationService,
+		EventService: eventService,
+		WebhookService: webhookService,
+
+		RevocationRepo: revocationRepo,
+		SessionRepo: sessionRepo,
+		RoleRepo: roleRepo,
+		OutboxRepo: outboxRepo,
+		AchievementMongoRepo: achievementMongoRepo,
+		EventRepo: eventRepo,
+		WebhookDispatcher: dispatcher,
+		Notifier: notifier,
+		Storage: store,
 	}
 }
@@ -0,0 +1,12 @@
+-- LOGIN ATTEMPTS (counter gagal login per akun dan per IP)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(10) NOT NULL,
+    identifier VARCHAR(255) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    UNIQUE (scope, identifier)
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_locked_until ON login_attempts (locked_until);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+
+ALTER TABLE roles DROP COLUMN IF EXISTS mfa_required;
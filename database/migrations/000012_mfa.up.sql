@@ -0,0 +1,22 @@
+-- Kebijakan MFA per role
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS mfa_required BOOLEAN NOT NULL DEFAULT false;
+
+-- TOTP (RFC 6238) per user
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    last_used_step BIGINT,
+    enabled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- RECOVERY CODES (disimpan dalam bentuk hash, sekali pakai)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
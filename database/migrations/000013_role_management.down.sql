@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name IN ('*', 'role:manage');
+
+ALTER TABLE roles DROP COLUMN IF EXISTS profile_type;
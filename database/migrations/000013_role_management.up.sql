@@ -0,0 +1,21 @@
+-- Profil yang wajib dimiliki user dengan role ini (menggantikan UUID role hardcoded)
+ALTER TABLE roles
+ADD COLUMN IF NOT EXISTS profile_type VARCHAR(20) NOT NULL DEFAULT 'none'
+CHECK (profile_type IN ('student', 'lecturer', 'none'));
+
+UPDATE roles SET profile_type = 'student' WHERE name = 'Mahasiswa';
+UPDATE roles SET profile_type = 'lecturer' WHERE name = 'Dosen Wali';
+
+-- Permission baru: wildcard dan manajemen role
+INSERT INTO permissions (name, resource, action, description) VALUES
+('*', '*', '*', 'Akses penuh ke seluruh resource'),
+('role:manage', 'role', 'manage', 'Kelola role dan permission')
+ON CONFLICT (name) DO NOTHING;
+
+-- Admin tidak lagi di-bypass berdasarkan nama role, tetapi lewat permission "*"
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'Admin'
+AND p.name = '*'
+ON CONFLICT DO NOTHING;
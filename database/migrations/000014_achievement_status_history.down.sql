@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS achievement_status_history;
+
+-- nilai enum 'revision' tidak dapat dihapus dari achievement_status
+UPDATE achievement_references SET status = 'rejected' WHERE status = 'revision';
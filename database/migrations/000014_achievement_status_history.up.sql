@@ -0,0 +1,29 @@
+-- status revision: prestasi yang ditolak dan sedang diperbaiki mahasiswa
+ALTER TYPE achievement_status
+ADD VALUE IF NOT EXISTS 'revision';
+
+-- ACHIEVEMENT STATUS HISTORY (audit trail setiap transisi status)
+CREATE TABLE IF NOT EXISTS achievement_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_status_history_ref
+    ON achievement_status_history (achievement_ref_id, created_at);
+
+-- backfill dari kolom timestamp yang sudah ada
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, created_at)
+SELECT id, 'draft', 'submitted', submitted_at
+FROM achievement_references
+WHERE submitted_at IS NOT NULL;
+
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, actor_id, note, created_at)
+SELECT id, 'submitted', status::text, verified_by, rejection_note, verified_at
+FROM achievement_references
+WHERE verified_at IS NOT NULL
+  AND status IN ('verified', 'rejected');
@@ -0,0 +1,12 @@
+-- REVIEW SNAPSHOTS (isi prestasi saat terakhir direview dosen wali,
+-- dipakai untuk diff ketika mahasiswa mengirim ulang hasil revisi)
+CREATE TABLE IF NOT EXISTS achievement_review_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    content JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_review_snapshots_ref
+    ON achievement_review_snapshots (achievement_ref_id, created_at DESC);
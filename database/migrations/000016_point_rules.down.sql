@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'point:manage';
+
+DROP TABLE IF EXISTS point_rules;
+DROP TABLE IF EXISTS point_rule_sets;
@@ -0,0 +1,62 @@
+-- POINT RULE SETS (tabel aturan poin yang berversi; hanya satu yang aktif)
+CREATE TABLE IF NOT EXISTS point_rule_sets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version INT NOT NULL UNIQUE,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    note TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_point_rule_sets_active
+    ON point_rule_sets (is_active) WHERE is_active;
+
+-- field NULL = poin dasar untuk achievement_type tersebut,
+-- selain itu poin ditambahkan jika details[field] = value
+CREATE TABLE IF NOT EXISTS point_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_set_id UUID NOT NULL REFERENCES point_rule_sets(id) ON DELETE CASCADE,
+    achievement_type VARCHAR(50) NOT NULL,
+    field VARCHAR(50),
+    value VARCHAR(100),
+    points INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_point_rules_set ON point_rules (rule_set_id);
+
+-- versi awal
+INSERT INTO point_rule_sets (version, is_active, note)
+VALUES (1, TRUE, 'Aturan poin awal');
+
+INSERT INTO point_rules (rule_set_id, achievement_type, field, value, points)
+SELECT s.id, r.achievement_type, r.field, r.value, r.points
+FROM point_rule_sets s,
+(VALUES
+    ('competition', NULL, NULL, 10),
+    ('competition', 'competition_level', 'international', 50),
+    ('competition', 'competition_level', 'national', 30),
+    ('competition', 'competition_level', 'regional', 20),
+    ('competition', 'competition_level', 'local', 10),
+    ('competition', 'rank', '1', 30),
+    ('competition', 'rank', '2', 20),
+    ('competition', 'rank', '3', 10),
+    ('competition', 'medalType', 'gold', 15),
+    ('competition', 'medalType', 'silver', 10),
+    ('competition', 'medalType', 'bronze', 5),
+    ('publication', NULL, NULL, 20),
+    ('publication', 'publication_type', 'journal', 40),
+    ('publication', 'publication_type', 'conference', 25),
+    ('publication', 'publication_type', 'book', 30),
+    ('organization', NULL, NULL, 5),
+    ('organization', 'position', 'ketua', 25),
+    ('organization', 'position', 'wakil', 15),
+    ('organization', 'position', 'sekretaris', 10),
+    ('organization', 'position', 'bendahara', 10),
+    ('organization', 'position', 'anggota', 5),
+    ('certification', NULL, NULL, 15)
+) AS r(achievement_type, field, value, points)
+WHERE s.version = 1;
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('point:manage', 'point', 'manage', 'Kelola aturan poin prestasi')
+ON CONFLICT (name) DO NOTHING;
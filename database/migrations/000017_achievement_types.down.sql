@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'achievement_type:manage';
+
+DROP TABLE IF EXISTS achievement_types;
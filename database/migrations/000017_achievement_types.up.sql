@@ -0,0 +1,44 @@
+-- ACHIEVEMENT TYPES (registry tipe prestasi beserta spesifikasi field details)
+CREATE TABLE IF NOT EXISTS achievement_types (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    fields JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO achievement_types (code, name, fields) VALUES
+('competition', 'Kompetisi', '[
+    {"name": "competition_name", "type": "string", "required": true},
+    {"name": "competition_level", "type": "string", "required": true, "enum": ["international", "national", "regional", "local"]},
+    {"name": "rank", "type": "integer", "min": 1},
+    {"name": "medalType", "type": "string", "enum": ["gold", "silver", "bronze"]},
+    {"name": "eventDate", "type": "date"},
+    {"name": "location", "type": "string"},
+    {"name": "organizer", "type": "string"}
+]'),
+('publication', 'Publikasi', '[
+    {"name": "publication_type", "type": "string", "required": true, "enum": ["journal", "conference", "book"]},
+    {"name": "publication_title", "type": "string", "required": true},
+    {"name": "authors", "type": "array", "items": "string"},
+    {"name": "publisher", "type": "string"},
+    {"name": "issn", "type": "string", "pattern": "^[0-9]{4}-[0-9]{3}[0-9Xx]$"}
+]'),
+('organization', 'Organisasi', '[
+    {"name": "organization_name", "type": "string", "required": true},
+    {"name": "position", "type": "string", "required": true, "enum": ["ketua", "wakil", "sekretaris", "bendahara", "anggota"]},
+    {"name": "period", "type": "object"}
+]'),
+('certification', 'Sertifikasi', '[
+    {"name": "certification_name", "type": "string", "required": true},
+    {"name": "issuedBy", "type": "string", "required": true},
+    {"name": "certification_number", "type": "string"},
+    {"name": "validUntil", "type": "date"}
+]')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('achievement_type:manage', 'achievement_type', 'manage', 'Kelola tipe prestasi dan spesifikasi field')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_students_program_year;
+DROP INDEX IF EXISTS idx_achievement_references_mongo_id;
+DROP INDEX IF EXISTS idx_achievement_references_verified_at;
+DROP INDEX IF EXISTS idx_achievement_references_submitted_at;
+DROP INDEX IF EXISTS idx_achievement_references_updated_at;
+DROP INDEX IF EXISTS idx_achievement_references_student_status;
@@ -0,0 +1,18 @@
+-- index untuk filter & sort list prestasi
+CREATE INDEX IF NOT EXISTS idx_achievement_references_student_status
+    ON achievement_references (student_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_updated_at
+    ON achievement_references (updated_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_submitted_at
+    ON achievement_references (submitted_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_verified_at
+    ON achievement_references (verified_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_mongo_id
+    ON achievement_references (mongo_achievement_id);
+
+CREATE INDEX IF NOT EXISTS idx_students_program_year
+    ON students (program_study, academic_year);
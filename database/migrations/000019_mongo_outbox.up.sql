@@ -0,0 +1,15 @@
+-- OUTBOX operasi MongoDB yang ditulis dalam transaksi PostgreSQL
+CREATE TABLE IF NOT EXISTS mongo_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(24) NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    payload JSONB,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mongo_outbox_pending
+    ON mongo_outbox (id)
+    WHERE processed_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_students_advisor;
+DROP INDEX IF EXISTS idx_achievement_references_type;
+
+ALTER TABLE achievement_references
+    DROP COLUMN IF EXISTS points,
+    DROP COLUMN IF EXISTS competition_level,
+    DROP COLUMN IF EXISTS achievement_type;
@@ -0,0 +1,12 @@
+-- salinan ringkas data MongoDB untuk agregasi analitik di PostgreSQL;
+-- isi data lama dengan `make sync-analytics`
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS achievement_type VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS competition_level VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS points INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_type
+    ON achievement_references (achievement_type);
+
+CREATE INDEX IF NOT EXISTS idx_students_advisor
+    ON students (advisor_id);
@@ -0,0 +1,13 @@
+-- PORTOFOLIO prestasi yang diterbitkan beserta tanda tangan server
+CREATE TABLE IF NOT EXISTS portfolios (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    verification_code VARCHAR(20) UNIQUE NOT NULL,
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    document JSONB NOT NULL,
+    signature VARCHAR(128) NOT NULL,
+    issued_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_student
+    ON portfolios (student_id, issued_at DESC);
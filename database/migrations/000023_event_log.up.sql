@@ -0,0 +1,14 @@
+-- LOG EVENT real-time untuk resume stream SSE lewat Last-Event-ID.
+-- Kolom audiens menentukan siapa yang boleh menerima event.
+CREATE TABLE IF NOT EXISTS event_log (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    user_id UUID,
+    student_id UUID,
+    advisor_id UUID,
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_log_created
+    ON event_log (created_at);
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'profile:update';
+
+ALTER TABLE lecturers
+DROP COLUMN IF EXISTS office,
+DROP COLUMN IF EXISTS phone;
+
+ALTER TABLE students
+DROP COLUMN IF EXISTS address,
+DROP COLUMN IF EXISTS phone;
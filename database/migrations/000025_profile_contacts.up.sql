@@ -0,0 +1,19 @@
+-- KONTAK pada profil akademik; dapat diubah sendiri oleh mahasiswa dan dosen
+ALTER TABLE students
+ADD COLUMN IF NOT EXISTS phone VARCHAR(20),
+ADD COLUMN IF NOT EXISTS address TEXT;
+
+ALTER TABLE lecturers
+ADD COLUMN IF NOT EXISTS phone VARCHAR(20),
+ADD COLUMN IF NOT EXISTS office VARCHAR(100);
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('profile:update', 'profile', 'update', 'Ubah kontak pada profil mahasiswa/dosen milik sendiri')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.profile_type IN ('student', 'lecturer')
+AND p.name = 'profile:update'
+ON CONFLICT DO NOTHING;
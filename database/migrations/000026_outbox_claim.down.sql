@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_mongo_outbox_aggregate_pending;
+
+ALTER TABLE mongo_outbox
+    DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,8 @@
+-- LEASE pesan outbox yang sedang diterapkan agar tidak diproses ganda oleh
+-- beberapa replika worker
+ALTER TABLE mongo_outbox
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_mongo_outbox_aggregate_pending
+    ON mongo_outbox (aggregate_id, id)
+    WHERE processed_at IS NULL;
@@ -0,0 +1,2 @@
+-- Audiens dosen wali yang sudah dihapus tidak dikembalikan.
+SELECT 1;
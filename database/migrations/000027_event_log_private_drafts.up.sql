@@ -0,0 +1,6 @@
+-- Event draft dan prestasi terhapus bersifat privat bagi mahasiswa; hapus
+-- audiens dosen wali dari event yang sudah tercatat.
+UPDATE event_log
+SET advisor_id = NULL
+WHERE type = 'achievement.status_changed'
+  AND data->>'status' IN ('draft', 'deleted');
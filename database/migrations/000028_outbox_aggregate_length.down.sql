@@ -0,0 +1,2 @@
+ALTER TABLE mongo_outbox
+    ALTER COLUMN aggregate_id TYPE VARCHAR(24);
@@ -0,0 +1,4 @@
+-- aggregate_id juga dipakai untuk ID mahasiswa (UUID) pada operasi yang
+-- mengubah banyak dokumen sekaligus
+ALTER TABLE mongo_outbox
+    ALTER COLUMN aggregate_id TYPE VARCHAR(64);
@@ -9,9 +9,10 @@ import (
     _ "github.com/lib/pq"
 )
 
-func PostgresConnections() *sql.DB {
-
-	connStr := fmt.Sprintf(
+// PostgresDSN menyusun connection string dari variabel DB_*. Dipakai juga
+// oleh koneksi LISTEN/NOTIFY yang tidak bisa memakai pool database/sql.
+func PostgresDSN() string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_PORT"),
@@ -19,8 +20,11 @@ func PostgresConnections() *sql.DB {
 		os.Getenv("DB_PASS"),
 		os.Getenv("DB_NAME"),
 	)
+}
+
+func PostgresConnections() *sql.DB {
 
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", PostgresDSN())
 	if err != nil {
 		log.Fatalf("Can't Connect: %v", err)
 	}
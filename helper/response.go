@@ -2,6 +2,9 @@ package helper
 
 import (
 	"encoding/json"
+	"math"
+	"strconv"
+	"time"
 	"uas/app/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -104,6 +107,37 @@ func NotFound(c *fiber.Ctx, message string) error {
 }
 
 
+func Conflict(c *fiber.Ctx, message string) error {
+	response := models.MetaInfo{
+		Status:  "error",
+		Message: message,
+	}
+
+	logResponse(c, fiber.StatusConflict, response)
+
+	return c.Status(fiber.StatusConflict).JSON(response)
+}
+
+
+
+func TooManyRequests(c *fiber.Ctx, message string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	response := models.MetaInfo{
+		Status: "error",
+		Message: message,
+		Meta: fiber.Map{"retry_after": seconds},
+	}
+
+	logResponse(c, fiber.StatusTooManyRequests, response)
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(response)
+}
+
 
 func InternalServerError(c *fiber.Ctx, message string) error {
 	response := models.MetaInfo{
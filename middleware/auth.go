@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+
+	"uas/app/models"
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	revocationStore repository.TokenRevocationRepository
+	sessionStore    repository.SessionRepository
+	accessStore     repository.RoleRepository
+)
+
+// InitAuth mendaftarkan store pencabutan token, session dan role yang dipakai
+// AuthRequired dan JWTProtected. Dipanggil sekali saat bootstrap.
+func InitAuth(revocations repository.TokenRevocationRepository, sessions repository.SessionRepository, roles repository.RoleRepository) {
+	revocationStore = revocations
+	sessionStore = sessions
+	accessStore = roles
+}
+
+func isTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if revocationStore == nil {
+		return false, nil
+	}
+	return revocationStore.IsRevoked(ctx, jti)
+}
+
+func isSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionStore == nil {
+		return true, nil
+	}
+	return sessionStore.IsActive(ctx, sessionID)
+}
+
+// setAccessLocals mengisi role dan permission user. Jika role store tersedia,
+// keduanya dibaca ulang dari database sehingga perubahan role / permission
+// langsung berlaku tanpa menunggu token expired.
+func setAccessLocals(c *fiber.Ctx, claims *models.JWTClaims) (bool, error) {
+	role, perms := claims.RoleID, claims.Permissions
+
+	if accessStore != nil {
+		access, err := accessStore.GetAccess(c.Context(), claims.UserID)
+		if err != nil {
+			return false, err
+		}
+		if access == nil || !access.IsActive {
+			return false, nil
+		}
+		role, perms = access.RoleName, access.Permissions
+	}
+
+	c.Locals("user_id", claims.UserID)
+	c.Locals("role_id", role)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("permissions", perms)
+
+	return true, nil
+}
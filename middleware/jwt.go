@@ -24,9 +24,29 @@ func JWTProtected() fiber.Handler {
 			return helper.Unauthorized(c, "Token tidak valid")
 		}
 
-		c.Locals("user_id", claims.UserID)
-		c.Locals("role_id", claims.RoleID)
-		c.Locals("permissions", claims.Permissions)
+		revoked, err := isTokenRevoked(c.Context(), claims.ID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa status token")
+		}
+		if revoked {
+			return helper.Unauthorized(c, "Token sudah tidak berlaku")
+		}
+
+		active, err := isSessionActive(c.Context(), claims.SessionID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa session")
+		}
+		if !active {
+			return helper.Unauthorized(c, "Session sudah tidak berlaku")
+		}
+
+		allowed, err := setAccessLocals(c, claims)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+		}
+		if !allowed {
+			return helper.Forbidden(c, "Akun tidak valid atau tidak aktif")
+		}
 
 		return c.Next()
 	}
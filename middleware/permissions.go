@@ -18,18 +18,34 @@ func AuthRequired() fiber.Handler {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		if utils.TokenBlacklist[tokenString] {
+		claims, err := utils.ValidateToken(tokenString)
+		if err != nil || claims.UserID == "" || claims.ID == "" || claims.SessionID == "" {
+			return helper.Unauthorized(c, "Token tidak valid atau expired")
+		}
+
+		revoked, err := isTokenRevoked(c.Context(), claims.ID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa status token")
+		}
+		if revoked {
 			return helper.Unauthorized(c, "Token sudah tidak berlaku")
 		}
 
-		claims, err := utils.ValidateToken(tokenString)
-		if err != nil || claims.UserID == "" {
-			return helper.Unauthorized(c, "Token tidak valid atau expired")
+		active, err := isSessionActive(c.Context(), claims.SessionID)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa session")
+		}
+		if !active {
+			return helper.Unauthorized(c, "Session sudah tidak berlaku")
 		}
 
-		c.Locals("user_id", claims.UserID)
-		c.Locals("role_id", claims.RoleID)
-		c.Locals("permissions", claims.Permissions)
+		allowed, err := setAccessLocals(c, claims)
+		if err != nil {
+			return helper.InternalServerError(c, "Gagal memeriksa hak akses")
+		}
+		if !allowed {
+			return helper.Forbidden(c, "Akun tidak valid atau tidak aktif")
+		}
 
 		return c.Next()
 	}
@@ -38,11 +54,6 @@ func AuthRequired() fiber.Handler {
 	
 func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		roleAny := c.Locals("role_id")
-		role, ok := roleAny.(string)
-		if ok && role == "Admin" {
-			return c.Next()
-		}
 		permsAny := c.Locals("permissions")
 		if permsAny == nil {
             return helper.Forbidden(c, "Permissions tidak ditemukan")
@@ -53,14 +64,10 @@ func RequirePermission(permission string) fiber.Handler {
 			return helper.Forbidden(c, "Permissions tidak ditemukan")
 		}
 
-		for _, p := range perms {
-			if p == permission {
-				return c.Next()
-			}
+		if utils.HasPermission(perms, permission) {
+			return c.Next()
 		}
 
 		return helper.Forbidden(c, "Anda tidak memiliki izin untuk aksi ini")
 	}
 }
-
-
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// QueryToken memindahkan query access_token ke header Authorization jika
+// header belum ada. EventSource di browser tidak dapat mengirim header
+// sendiri, jadi middleware ini hanya dipasang pada route stream sebelum
+// AuthRequired.
+func QueryToken() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
@@ -20,5 +20,9 @@ func AchievementRoutes(r fiber.Router, achievementService *services.AchievementS
 	achievement.Post("/:id/verify", middleware.RequirePermission("achievement:verify"), achievementService.Verify,)
 	achievement.Post("/:id/reject", middleware.RequirePermission("achievement:verify"), achievementService.Reject,)
 	achievement.Post("/:id/attachments", middleware.RequirePermission("achievement:update"), achievementService.UploadAttachments)
+	achievement.Get("/:id/attachments/:attachmentId", middleware.RequirePermission("achievement:read"), achievementService.AttachmentURL)
+	achievement.Put("/:id/attachments/:attachmentId", middleware.RequirePermission("achievement:update"), achievementService.ReplaceAttachment)
+	achievement.Delete("/:id/attachments/:attachmentId", middleware.RequirePermission("achievement:update"), achievementService.DeleteAttachment)
 	achievement.Get("/:id/history", middleware.RequirePermission("achievement:read"), achievementService.History)
+	achievement.Get("/:id/diff", middleware.RequirePermission("achievement:read"), achievementService.Diff)
 }
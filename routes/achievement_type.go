@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func AchievementTypeRoutes(r fiber.Router, achievementTypeService *services.AchievementTypeService) {
+	types := r.Group("/achievement-types")
+
+	types.Use(middleware.AuthRequired())
+
+	types.Get("/", achievementTypeService.List)
+	types.Get("/:code", achievementTypeService.Detail)
+	types.Post("/", middleware.RequirePermission("achievement_type:manage"), achievementTypeService.Create)
+	types.Put("/:code", middleware.RequirePermission("achievement_type:manage"), achievementTypeService.Update)
+}
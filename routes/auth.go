@@ -11,6 +11,20 @@ func AuthRoutes(r fiber.Router, authService *services.AuthService) {
 	r.Post("/auth/register", authService.Register)
 	r.Post("/auth/refresh", authService.Refresh)
 	r.Post("/auth/logout", authService.Logout)
+	r.Post("/auth/forgot-password", authService.ForgotPassword)
+	r.Post("/auth/reset-password", authService.ResetPassword)
+
+	r.Post("/auth/mfa/verify", authService.VerifyMFA)
+	r.Post("/auth/mfa/enroll", authService.EnrollMFA)
+	r.Post("/auth/mfa/enroll/confirm", authService.ConfirmEnrollMFA)
+	r.Get("/auth/mfa", middleware.AuthRequired(), authService.MFAStatus)
+	r.Post("/auth/mfa/setup", middleware.AuthRequired(), authService.SetupMFA)
+	r.Post("/auth/mfa/enable", middleware.AuthRequired(), authService.EnableMFA)
+	r.Post("/auth/mfa/disable", middleware.AuthRequired(), authService.DisableMFA)
 
 	r.Get("/auth/profile", middleware.AuthRequired(), authService.Profile)
+
+	r.Get("/auth/sessions", middleware.AuthRequired(), authService.Sessions)
+	r.Delete("/auth/sessions", middleware.AuthRequired(), authService.RevokeOtherSessions)
+	r.Delete("/auth/sessions/:id", middleware.AuthRequired(), authService.RevokeSession)
 }
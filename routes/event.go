@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func EventRoutes(r fiber.Router, eventService *services.EventService) {
+	events := r.Group("/events")
+
+	events.Get("/stream", middleware.QueryToken(), middleware.AuthRequired(), eventService.Stream)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+)
+
+// FileRoutes tidak memakai AuthRequired; akses dijamin oleh tanda tangan URL.
+func FileRoutes(r fiber.Router, fileService *services.FileService) {
+	r.Get("/files/*", fileService.Download)
+}
@@ -12,6 +12,18 @@ type RouteContainer struct {
 	AchievementService 	*services.AchievementService
 	LecturerService 	*services.LecturerService
 	ReportService 		*services.ReportService
+	LockoutService 		*services.LockoutService
+	RoleService 		*services.RoleService
+	PermissionService 	*services.PermissionService
+	PointRuleService 	*services.PointRuleService
+	AchievementTypeService 	*services.AchievementTypeService
+	FileService 		*services.FileService
+	AnalyticsService 	*services.AnalyticsService
+	PortfolioService 	*services.PortfolioService
+	ReviewService 		*services.ReviewService
+	NotificationService 	*services.NotificationService
+	EventService 		*services.EventService
+	WebhookService 		*services.WebhookService
 }
 
 func RegisterRoutes(app *fiber.App, c *RouteContainer) {
@@ -21,8 +33,17 @@ func RegisterRoutes(app *fiber.App, c *RouteContainer) {
 	// Daftarkan masing-masing router
 	AuthRoutes(api, c.AuthService)
 	UserRoutes(api, c.UserService)
-	StudentRoutes(api, c.StudentService)
+	StudentRoutes(api, c.StudentService, c.PortfolioService)
 	AchievementRoutes(api, c.AchievementService)
-	LecturerRoutes(api, c.LecturerService)
-	ReportRoutes(api, c.ReportService)
+	LecturerRoutes(api, c.LecturerService, c.ReviewService)
+	ReportRoutes(api, c.ReportService, c.AnalyticsService)
+	LockoutRoutes(api, c.LockoutService)
+	RoleRoutes(api, c.RoleService, c.PermissionService)
+	PointRuleRoutes(api, c.PointRuleService)
+	AchievementTypeRoutes(api, c.AchievementTypeService)
+	FileRoutes(api, c.FileService)
+	PortfolioRoutes(api, c.PortfolioService)
+	NotificationRoutes(api, c.NotificationService)
+	EventRoutes(api, c.EventService)
+	WebhookRoutes(api, c.WebhookService)
 }
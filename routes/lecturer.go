@@ -6,11 +6,14 @@ import (
 	"uas/middleware"
 )
 
-func LecturerRoutes(r fiber.Router, lecturerService *services.LecturerService) {
+func LecturerRoutes(r fiber.Router, lecturerService *services.LecturerService, reviewService *services.ReviewService) {
 	lecturers := r.Group("/lecturers")
 
 	lecturers.Use(middleware.AuthRequired())
 
 	lecturers.Get("/", middleware.RequirePermission("achievement:verify"), lecturerService.List)
+	lecturers.Get("/review-queue", middleware.RequirePermission("achievement:verify"), reviewService.Queue)
+	lecturers.Get("/review-metrics", middleware.RequirePermission("user:manage"), reviewService.Metrics)
+	lecturers.Put("/:id/profile", lecturerService.UpdateProfile)
 	lecturers.Get("/:id/advisees", middleware.RequirePermission("achievement:verify"), lecturerService.GetMyAdvisees)
 }
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func LockoutRoutes(r fiber.Router, lockoutService *services.LockoutService) {
+	lockouts := r.Group("/lockouts")
+
+	lockouts.Use(middleware.AuthRequired())
+	lockouts.Use(middleware.RequirePermission("user:manage"))
+
+	lockouts.Get("/", lockoutService.List)
+	lockouts.Delete("/:id", lockoutService.Clear)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func NotificationRoutes(r fiber.Router, notificationService *services.NotificationService) {
+	notifications := r.Group("/notifications")
+
+	notifications.Use(middleware.AuthRequired())
+
+	notifications.Get("/", notificationService.List)
+	notifications.Post("/read-all", notificationService.MarkAllRead)
+	notifications.Get("/preferences", notificationService.GetPreferences)
+	notifications.Put("/preferences", notificationService.UpdatePreferences)
+	notifications.Patch("/:id/read", notificationService.MarkRead)
+}
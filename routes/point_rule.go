@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func PointRuleRoutes(r fiber.Router, pointRuleService *services.PointRuleService) {
+	rules := r.Group("/point-rules")
+
+	rules.Use(middleware.AuthRequired())
+
+	rules.Get("/", middleware.RequirePermission("achievement:read"), pointRuleService.Active)
+	rules.Post("/", middleware.RequirePermission("point:manage"), pointRuleService.Create)
+	rules.Get("/versions", middleware.RequirePermission("point:manage"), pointRuleService.Versions)
+	rules.Get("/versions/:version", middleware.RequirePermission("point:manage"), pointRuleService.Version)
+	rules.Post("/versions/:version/activate", middleware.RequirePermission("point:manage"), pointRuleService.Activate)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+)
+
+// PortfolioRoutes bersifat publik agar pihak luar dapat memeriksa keaslian
+// portofolio; penerbitan ada di StudentRoutes.
+func PortfolioRoutes(r fiber.Router, portfolioService *services.PortfolioService) {
+	portfolios := r.Group("/portfolios")
+
+	portfolios.Get("/verify/:code", portfolioService.Verify)
+}
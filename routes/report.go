@@ -6,7 +6,7 @@ import (
 	"uas/middleware"
 )
 
-func ReportRoutes(r fiber.Router, reportService *services.ReportService) {
+func ReportRoutes(r fiber.Router, reportService *services.ReportService, analyticsService *services.AnalyticsService) {
 	reports := r.Group("/reports")
 
 	reports.Use(middleware.AuthRequired())
@@ -21,4 +21,10 @@ func ReportRoutes(r fiber.Router, reportService *services.ReportService) {
 		middleware.RequirePermission("achievement:read"),
 		reportService.StudentReport,
 	)
+
+	reports.Get(
+		"/analytics/:dimension",
+		middleware.RequirePermission("achievement:read"),
+		analyticsService.Aggregate,
+	)
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func RoleRoutes(r fiber.Router, roleService *services.RoleService, permissionService *services.PermissionService) {
+	roles := r.Group("/roles")
+
+	roles.Use(middleware.AuthRequired())
+	roles.Use(middleware.RequirePermission("role:manage"))
+
+	roles.Get("/", roleService.List)
+	roles.Get("/:id", roleService.Detail)
+	roles.Post("/", roleService.Create)
+	roles.Put("/:id", roleService.Update)
+	roles.Delete("/:id", roleService.Delete)
+
+	roles.Get("/:id/permissions", roleService.Permissions)
+	roles.Put("/:id/permissions", roleService.SetPermissions)
+	roles.Post("/:id/permissions/:permissionId", roleService.AddPermission)
+	roles.Delete("/:id/permissions/:permissionId", roleService.RemovePermission)
+
+	permissions := r.Group("/permissions")
+
+	permissions.Use(middleware.AuthRequired())
+	permissions.Use(middleware.RequirePermission("role:manage"))
+
+	permissions.Get("/", permissionService.List)
+	permissions.Post("/", permissionService.Create)
+	permissions.Put("/:id", permissionService.Update)
+	permissions.Delete("/:id", permissionService.Delete)
+}
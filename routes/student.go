@@ -7,14 +7,15 @@ import (
     "github.com/gofiber/fiber/v2"
 )
 
-func StudentRoutes(r fiber.Router, studentServices *services.StudentService) {
+func StudentRoutes(r fiber.Router, studentServices *services.StudentService, portfolioService *services.PortfolioService) {
 	students := r.Group("/students")
 
 	students.Use(middleware.AuthRequired())
-	students.Use(middleware.RequirePermission("user:manage"))
 
     students.Get("/", studentServices.GetAll)
     students.Get("/:id", studentServices.GetByID)
-    students.Put("/:id/advisor", studentServices.UpdateAdvisor)
+    students.Put("/:id/advisor", middleware.RequirePermission("user:manage"), studentServices.UpdateAdvisor)
+    students.Put("/:id/profile", studentServices.UpdateProfile)
     students.Get("/:id/achievements", studentServices.GetAchievements)
+    students.Post("/:id/portfolio", middleware.RequirePermission("achievement:read"), portfolioService.Issue)
 }
@@ -15,6 +15,7 @@ func UserRoutes(r fiber.Router, userService *services.UserService) {
 	users.Get("/", userService.GetAll)
 	users.Get("/:id", userService.GetByID)
 	users.Post("/", userService.Create)
+	users.Post("/import", userService.Import)
 	users.Put("/:id", userService.Update)
 	users.Delete("/:id", userService.Delete)
 	users.Put("/:id/role", userService.UpdateRole)
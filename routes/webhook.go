@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"uas/app/services"
+	"uas/middleware"
+)
+
+func WebhookRoutes(r fiber.Router, webhookService *services.WebhookService) {
+	webhooks := r.Group("/webhooks")
+
+	webhooks.Use(middleware.AuthRequired(), middleware.RequirePermission("webhook:manage"))
+
+	webhooks.Get("/", webhookService.List)
+	webhooks.Post("/", webhookService.Create)
+	webhooks.Post("/deliveries/:id/redeliver", webhookService.Redeliver)
+	webhooks.Get("/:id", webhookService.Detail)
+	webhooks.Put("/:id", webhookService.Update)
+	webhooks.Delete("/:id", webhookService.Delete)
+	webhooks.Get("/:id/deliveries", webhookService.Deliveries)
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type AchievementHistoryMockRepo struct {
+	FindByReferenceIDFn func(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error)
+}
+
+func (m *AchievementHistoryMockRepo) FindByReferenceID(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error) {
+	if m.FindByReferenceIDFn == nil {
+		return nil, nil
+	}
+	return m.FindByReferenceIDFn(ctx, refID)
+}
@@ -2,14 +2,23 @@ package repo
 
 import (
 	"context"
+	"time"
 	"uas/app/models"
 )
 
 type AchievementMongoMockRepo struct {
-	CreateFn     func(ctx context.Context, data *models.AchievementMongo) (string, error)
-	FindByIDFn   func(ctx context.Context, id string) (*models.AchievementMongo, error)
-	SoftDeleteFn func(ctx context.Context, id string) error
-	UpdateFn     func(ctx context.Context, a *models.AchievementMongo) error
+	CreateFn            func(ctx context.Context, data *models.AchievementMongo) (string, error)
+	FindByIDFn          func(ctx context.Context, id string) (*models.AchievementMongo, error)
+	FindByIDsFn         func(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error)
+	SoftDeleteFn        func(ctx context.Context, id string) error
+	UpdateFn            func(ctx context.Context, a *models.AchievementMongo) error
+	SetPointsFn         func(ctx context.Context, id string, points, version int) error
+	UpdateStudentCodeFn func(ctx context.Context, oldCode, newCode string) error
+	FindIDsFn           func(ctx context.Context, f models.AchievementFilter, limit int) ([]string, error)
+	StatesFn            func(ctx context.Context) (map[string]bool, error)
+	RestoreFn           func(ctx context.Context, id string) error
+
+	AttachmentKeysFn func(ctx context.Context) (map[string]*time.Time, error)
 }
 
 func (m *AchievementMongoMockRepo) Create(ctx context.Context, data *models.AchievementMongo) (string, error) {
@@ -26,6 +35,13 @@ func (m *AchievementMongoMockRepo) FindByID(ctx context.Context, id string) (*mo
 	return m.FindByIDFn(ctx, id)
 }
 
+func (m *AchievementMongoMockRepo) FindByIDs(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error) {
+	if m.FindByIDsFn == nil {
+		return nil, nil
+	}
+	return m.FindByIDsFn(ctx, ids, fields...)
+}
+
 func (m *AchievementMongoMockRepo) SoftDelete(ctx context.Context, id string) error {
 	if m.SoftDeleteFn == nil {
 		return nil
@@ -38,4 +54,45 @@ func (m *AchievementMongoMockRepo) Update(ctx context.Context, a *models.Achieve
 		return nil
 	}
 	return m.UpdateFn(ctx, a)
-}
\ No newline at end of file
+}
+func (m *AchievementMongoMockRepo) SetPoints(ctx context.Context, id string, points, version int) error {
+	if m.SetPointsFn == nil {
+		return nil
+	}
+	return m.SetPointsFn(ctx, id, points, version)
+}
+
+func (m *AchievementMongoMockRepo) UpdateStudentCode(ctx context.Context, oldCode, newCode string) error {
+	if m.UpdateStudentCodeFn == nil {
+		return nil
+	}
+	return m.UpdateStudentCodeFn(ctx, oldCode, newCode)
+}
+
+func (m *AchievementMongoMockRepo) FindIDs(ctx context.Context, f models.AchievementFilter, limit int) ([]string, error) {
+	if m.FindIDsFn == nil {
+		return nil, nil
+	}
+	return m.FindIDsFn(ctx, f, limit)
+}
+
+func (m *AchievementMongoMockRepo) States(ctx context.Context) (map[string]bool, error) {
+	if m.StatesFn == nil {
+		return nil, nil
+	}
+	return m.StatesFn(ctx)
+}
+
+func (m *AchievementMongoMockRepo) Restore(ctx context.Context, id string) error {
+	if m.RestoreFn == nil {
+		return nil
+	}
+	return m.RestoreFn(ctx, id)
+}
+
+func (m *AchievementMongoMockRepo) AttachmentKeys(ctx context.Context) (map[string]*time.Time, error) {
+	if m.AttachmentKeysFn == nil {
+		return nil, nil
+	}
+	return m.AttachmentKeysFn(ctx)
+}
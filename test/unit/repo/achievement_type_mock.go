@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type AchievementTypeMockRepo struct {
+	FindAllFn   func(ctx context.Context, activeOnly bool) ([]models.AchievementType, error)
+	GetByCodeFn func(ctx context.Context, code string) (*models.AchievementType, error)
+	CreateFn    func(ctx context.Context, t *models.AchievementType) error
+	UpdateFn    func(ctx context.Context, t *models.AchievementType) error
+}
+
+func (m *AchievementTypeMockRepo) FindAll(ctx context.Context, activeOnly bool) ([]models.AchievementType, error) {
+	if m.FindAllFn == nil {
+		return nil, nil
+	}
+	return m.FindAllFn(ctx, activeOnly)
+}
+
+func (m *AchievementTypeMockRepo) GetByCode(ctx context.Context, code string) (*models.AchievementType, error) {
+	if m.GetByCodeFn == nil {
+		return nil, nil
+	}
+	return m.GetByCodeFn(ctx, code)
+}
+
+func (m *AchievementTypeMockRepo) Create(ctx context.Context, t *models.AchievementType) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, t)
+}
+
+func (m *AchievementTypeMockRepo) Update(ctx context.Context, t *models.AchievementType) error {
+	if m.UpdateFn == nil {
+		return nil
+	}
+	return m.UpdateFn(ctx, t)
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type AnalyticsMockRepo struct {
+	AggregateFn func(ctx context.Context, dimension string, f models.AnalyticsFilter) ([]models.AnalyticsBucket, error)
+}
+
+func (m *AnalyticsMockRepo) Aggregate(ctx context.Context, dimension string, f models.AnalyticsFilter) ([]models.AnalyticsBucket, error) {
+	if m.AggregateFn == nil {
+		return nil, nil
+	}
+	return m.AggregateFn(ctx, dimension, f)
+}
@@ -4,6 +4,7 @@ import "uas/app/models"
 
 type AuthMockRepo struct {
 	GetUserByEmailFn         func(email string) (*models.UserWithRole, error)
+	GetUserByIDFn            func(userID string) (*models.UserWithRole, error)
 	GetPermissionsByUserIDFn func(userID string) ([]string, error)
 }
 
@@ -16,7 +17,10 @@ func (m *AuthMockRepo) GetUserByEmail(email string) (*models.UserWithRole, error
 }
 
 func (m *AuthMockRepo) GetUserByID(userID string) (*models.UserWithRole, error) {
-	return nil, nil
+	if m.GetUserByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetUserByIDFn(userID)
 }
 
 func (m *AuthMockRepo) GetPermissionsByUserID(userID string) ([]string, error) {
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"context"
+	"time"
+	"uas/app/models"
+)
+
+type EventMockRepo struct {
+	AppendFn      func(ctx context.Context, ev *models.Event) error
+	GetByIDFn     func(ctx context.Context, id int64) (*models.Event, error)
+	ReplayFn      func(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error)
+	PurgeBeforeFn func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *EventMockRepo) Append(ctx context.Context, ev *models.Event) error {
+	if m.AppendFn == nil {
+		return nil
+	}
+	return m.AppendFn(ctx, ev)
+}
+
+func (m *EventMockRepo) GetByID(ctx context.Context, id int64) (*models.Event, error) {
+	if m.GetByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByIDFn(ctx, id)
+}
+
+func (m *EventMockRepo) Replay(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error) {
+	if m.ReplayFn == nil {
+		return nil, nil
+	}
+	return m.ReplayFn(ctx, a, afterID, limit)
+}
+
+func (m *EventMockRepo) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.PurgeBeforeFn == nil {
+		return 0, nil
+	}
+	return m.PurgeBeforeFn(ctx, before)
+}
@@ -7,7 +7,13 @@ import (
 )
 
 type LecturerMockRepo struct {
-	CreateFn func(tx *sql.Tx, userID, lecturerID string) error
+	CreateFn      func(tx *sql.Tx, userID, lecturerID string) error
+	GetByUserIDFn func(ctx context.Context, userID string) (*models.Lecturer, error)
+	GetByIDFn     func(ctx context.Context, id string) (*models.Lecturer, error)
+	FindAllFn     func(ctx context.Context) ([]models.Lecturer, error)
+
+	UpdateProfileFn        func(ctx context.Context, l *models.Lecturer) error
+	FindTakenLecturerIDsFn func(ctx context.Context, ids []string) (map[string]bool, error)
 }
 
 func (m *LecturerMockRepo) Create(tx *sql.Tx, userID, lecturerID string) error {
@@ -23,9 +29,36 @@ func (m *LecturerMockRepo) GetIDByUserID(userID string) (string, error) {
 }
 
 func (m *LecturerMockRepo) GetByUserID(ctx context.Context, userID string) (*models.Lecturer, error) {
-	return nil, nil
+	if m.GetByUserIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByUserIDFn(ctx, userID)
+}
+
+func (m *LecturerMockRepo) GetByID(ctx context.Context, id string) (*models.Lecturer, error) {
+	if m.GetByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByIDFn(ctx, id)
 }
 
 func (m *LecturerMockRepo) FindAll(ctx context.Context) ([]models.Lecturer, error) {
-	return nil, nil
+	if m.FindAllFn == nil {
+		return nil, nil
+	}
+	return m.FindAllFn(ctx)
+}
+
+func (m *LecturerMockRepo) UpdateProfile(ctx context.Context, l *models.Lecturer) error {
+	if m.UpdateProfileFn == nil {
+		return nil
+	}
+	return m.UpdateProfileFn(ctx, l)
+}
+
+func (m *LecturerMockRepo) FindTakenLecturerIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	if m.FindTakenLecturerIDsFn == nil {
+		return nil, nil
+	}
+	return m.FindTakenLecturerIDsFn(ctx, ids)
 }
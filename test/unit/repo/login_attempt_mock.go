@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"time"
+	"uas/app/models"
+)
+
+type LoginAttemptMockRepo struct {
+	GetFn             func(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error)
+	RegisterFailureFn func(ctx context.Context, scope, identifier string, window time.Duration) (*models.LoginAttempt, error)
+	LockFn            func(ctx context.Context, id string, until time.Time) error
+	ResetFn           func(ctx context.Context, scope, identifier string) error
+	FindActiveFn      func(ctx context.Context) ([]models.LoginAttempt, error)
+	DeleteByIDFn      func(ctx context.Context, id string) (bool, error)
+}
+
+func (m *LoginAttemptMockRepo) Get(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error) {
+	if m.GetFn == nil {
+		return nil, nil
+	}
+	return m.GetFn(ctx, scope, identifier)
+}
+
+func (m *LoginAttemptMockRepo) RegisterFailure(ctx context.Context, scope, identifier string, window time.Duration) (*models.LoginAttempt, error) {
+	if m.RegisterFailureFn == nil {
+		return nil, nil
+	}
+	return m.RegisterFailureFn(ctx, scope, identifier, window)
+}
+
+func (m *LoginAttemptMockRepo) Lock(ctx context.Context, id string, until time.Time) error {
+	if m.LockFn == nil {
+		return nil
+	}
+	return m.LockFn(ctx, id, until)
+}
+
+func (m *LoginAttemptMockRepo) Reset(ctx context.Context, scope, identifier string) error {
+	if m.ResetFn == nil {
+		return nil
+	}
+	return m.ResetFn(ctx, scope, identifier)
+}
+
+func (m *LoginAttemptMockRepo) FindActive(ctx context.Context) ([]models.LoginAttempt, error) {
+	if m.FindActiveFn == nil {
+		return nil, nil
+	}
+	return m.FindActiveFn(ctx)
+}
+
+func (m *LoginAttemptMockRepo) DeleteByID(ctx context.Context, id string) (bool, error) {
+	if m.DeleteByIDFn == nil {
+		return false, nil
+	}
+	return m.DeleteByIDFn(ctx, id)
+}
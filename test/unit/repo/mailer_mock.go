@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"uas/utils"
+)
+
+// MailerMock menampung email yang dikirim agar bisa diperiksa di test.
+type MailerMock struct {
+	mu   sync.Mutex
+	Sent []utils.MailMessage
+}
+
+func (m *MailerMock) Send(ctx context.Context, msg utils.MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Sent = append(m.Sent, msg)
+	return nil
+}
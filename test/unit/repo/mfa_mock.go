@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type MFAMockRepo struct {
+	GetFn             func(ctx context.Context, userID string) (*models.UserMFA, error)
+	SaveSecretFn      func(ctx context.Context, userID string, secret string) error
+	EnableFn          func(ctx context.Context, userID string, step int64, recoveryHashes []string) error
+	DisableFn         func(ctx context.Context, userID string) error
+	MarkStepUsedFn    func(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCodeFn func(ctx context.Context, userID string, codeHash string) (bool, error)
+}
+
+func (m *MFAMockRepo) Get(ctx context.Context, userID string) (*models.UserMFA, error) {
+	if m.GetFn == nil {
+		return nil, nil
+	}
+	return m.GetFn(ctx, userID)
+}
+
+func (m *MFAMockRepo) SaveSecret(ctx context.Context, userID string, secret string) error {
+	if m.SaveSecretFn == nil {
+		return nil
+	}
+	return m.SaveSecretFn(ctx, userID, secret)
+}
+
+func (m *MFAMockRepo) Enable(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+	if m.EnableFn == nil {
+		return nil
+	}
+	return m.EnableFn(ctx, userID, step, recoveryHashes)
+}
+
+func (m *MFAMockRepo) Disable(ctx context.Context, userID string) error {
+	if m.DisableFn == nil {
+		return nil
+	}
+	return m.DisableFn(ctx, userID)
+}
+
+func (m *MFAMockRepo) MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	if m.MarkStepUsedFn == nil {
+		return true, nil
+	}
+	return m.MarkStepUsedFn(ctx, userID, step)
+}
+
+func (m *MFAMockRepo) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	if m.UseRecoveryCodeFn == nil {
+		return false, nil
+	}
+	return m.UseRecoveryCodeFn(ctx, userID, codeHash)
+}
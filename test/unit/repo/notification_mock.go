@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type NotificationMockRepo struct {
+	CreateFn         func(ctx context.Context, n *models.Notification) error
+	FindByUserFn     func(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	CountUnreadFn    func(ctx context.Context, userID string) (int, error)
+	MarkReadFn       func(ctx context.Context, userID, id string) (bool, error)
+	MarkAllReadFn    func(ctx context.Context, userID string) (int, error)
+	PreferencesFn    func(ctx context.Context, userID string) (map[string]bool, error)
+	SetPreferencesFn func(ctx context.Context, userID string, prefs map[string]bool) error
+}
+
+func (m *NotificationMockRepo) Create(ctx context.Context, n *models.Notification) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, n)
+}
+
+func (m *NotificationMockRepo) FindByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+	if m.FindByUserFn == nil {
+		return nil, 0, nil
+	}
+	return m.FindByUserFn(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (m *NotificationMockRepo) CountUnread(ctx context.Context, userID string) (int, error) {
+	if m.CountUnreadFn == nil {
+		return 0, nil
+	}
+	return m.CountUnreadFn(ctx, userID)
+}
+
+func (m *NotificationMockRepo) MarkRead(ctx context.Context, userID, id string) (bool, error) {
+	if m.MarkReadFn == nil {
+		return false, nil
+	}
+	return m.MarkReadFn(ctx, userID, id)
+}
+
+func (m *NotificationMockRepo) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	if m.MarkAllReadFn == nil {
+		return 0, nil
+	}
+	return m.MarkAllReadFn(ctx, userID)
+}
+
+func (m *NotificationMockRepo) Preferences(ctx context.Context, userID string) (map[string]bool, error) {
+	if m.PreferencesFn == nil {
+		return nil, nil
+	}
+	return m.PreferencesFn(ctx, userID)
+}
+
+func (m *NotificationMockRepo) SetPreferences(ctx context.Context, userID string, prefs map[string]bool) error {
+	if m.SetPreferencesFn == nil {
+		return nil
+	}
+	return m.SetPreferencesFn(ctx, userID, prefs)
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type OutboxMockRepo struct {
+	PendingFn      func(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error)
+	ClaimPendingFn func(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error)
+	ClaimFn        func(ctx context.Context, id int64) error
+	MarkDoneFn     func(ctx context.Context, id int64) error
+	MarkFailedFn   func(ctx context.Context, id int64, reason string) error
+}
+
+func (m *OutboxMockRepo) Pending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+	if m.PendingFn == nil {
+		return nil, nil
+	}
+	return m.PendingFn(ctx, limit, maxAttempts)
+}
+
+func (m *OutboxMockRepo) ClaimPending(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+	if m.ClaimPendingFn == nil {
+		return nil, nil
+	}
+	return m.ClaimPendingFn(ctx, limit, maxAttempts)
+}
+
+func (m *OutboxMockRepo) Claim(ctx context.Context, id int64) error {
+	if m.ClaimFn == nil {
+		return nil
+	}
+	return m.ClaimFn(ctx, id)
+}
+
+func (m *OutboxMockRepo) MarkDone(ctx context.Context, id int64) error {
+	if m.MarkDoneFn == nil {
+		return nil
+	}
+	return m.MarkDoneFn(ctx, id)
+}
+
+func (m *OutboxMockRepo) MarkFailed(ctx context.Context, id int64, reason string) error {
+	if m.MarkFailedFn == nil {
+		return nil
+	}
+	return m.MarkFailedFn(ctx, id, reason)
+}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type PasswordResetMockRepo struct {
+	CreateFn        func(ctx context.Context, reset *models.PasswordReset) error
+	ResetPasswordFn func(ctx context.Context, tokenHash string, passwordHash string) (string, error)
+}
+
+func (m *PasswordResetMockRepo) Create(ctx context.Context, reset *models.PasswordReset) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, reset)
+}
+
+func (m *PasswordResetMockRepo) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (string, error) {
+	if m.ResetPasswordFn == nil {
+		return "", nil
+	}
+	return m.ResetPasswordFn(ctx, tokenHash, passwordHash)
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type PermissionMockRepo struct {
+	FindAllFn func(ctx context.Context) ([]models.Permission, error)
+	GetByIDFn func(ctx context.Context, id string) (*models.Permission, error)
+	CreateFn  func(ctx context.Context, p *models.Permission) error
+	UpdateFn  func(ctx context.Context, p *models.Permission) error
+	DeleteFn  func(ctx context.Context, id string) error
+}
+
+func (m *PermissionMockRepo) FindAll(ctx context.Context) ([]models.Permission, error) {
+	if m.FindAllFn == nil {
+		return nil, nil
+	}
+	return m.FindAllFn(ctx)
+}
+
+func (m *PermissionMockRepo) GetByID(ctx context.Context, id string) (*models.Permission, error) {
+	if m.GetByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByIDFn(ctx, id)
+}
+
+func (m *PermissionMockRepo) Create(ctx context.Context, p *models.Permission) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, p)
+}
+
+func (m *PermissionMockRepo) Update(ctx context.Context, p *models.Permission) error {
+	if m.UpdateFn == nil {
+		return nil
+	}
+	return m.UpdateFn(ctx, p)
+}
+
+func (m *PermissionMockRepo) Delete(ctx context.Context, id string) error {
+	if m.DeleteFn == nil {
+		return nil
+	}
+	return m.DeleteFn(ctx, id)
+}
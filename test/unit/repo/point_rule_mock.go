@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type PointRuleMockRepo struct {
+	ActiveFn       func(ctx context.Context) (*models.PointRuleSet, error)
+	GetByVersionFn func(ctx context.Context, version int) (*models.PointRuleSet, error)
+	ListVersionsFn func(ctx context.Context) ([]models.PointRuleSet, error)
+	CreateFn       func(ctx context.Context, set *models.PointRuleSet, activate bool) error
+	ActivateFn     func(ctx context.Context, version int) (bool, error)
+}
+
+func (m *PointRuleMockRepo) Active(ctx context.Context) (*models.PointRuleSet, error) {
+	if m.ActiveFn == nil {
+		return nil, nil
+	}
+	return m.ActiveFn(ctx)
+}
+
+func (m *PointRuleMockRepo) GetByVersion(ctx context.Context, version int) (*models.PointRuleSet, error) {
+	if m.GetByVersionFn == nil {
+		return nil, nil
+	}
+	return m.GetByVersionFn(ctx, version)
+}
+
+func (m *PointRuleMockRepo) ListVersions(ctx context.Context) ([]models.PointRuleSet, error) {
+	if m.ListVersionsFn == nil {
+		return nil, nil
+	}
+	return m.ListVersionsFn(ctx)
+}
+
+func (m *PointRuleMockRepo) Create(ctx context.Context, set *models.PointRuleSet, activate bool) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, set, activate)
+}
+
+func (m *PointRuleMockRepo) Activate(ctx context.Context, version int) (bool, error) {
+	if m.ActivateFn == nil {
+		return false, nil
+	}
+	return m.ActivateFn(ctx, version)
+}
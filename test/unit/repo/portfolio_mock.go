@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type PortfolioMockRepo struct {
+	CreateFn    func(ctx context.Context, p *models.Portfolio) error
+	GetByCodeFn func(ctx context.Context, code string) (*models.Portfolio, error)
+}
+
+func (m *PortfolioMockRepo) Create(ctx context.Context, p *models.Portfolio) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, p)
+}
+
+func (m *PortfolioMockRepo) GetByCode(ctx context.Context, code string) (*models.Portfolio, error) {
+	if m.GetByCodeFn == nil {
+		return nil, nil
+	}
+	return m.GetByCodeFn(ctx, code)
+}
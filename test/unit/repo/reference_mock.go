@@ -9,6 +9,16 @@ type AchievementReferenceMockRepo struct {
 	CreateFn                   func(ctx context.Context, ref *models.AchievementReference) error
 	GetByMongoIDFn             func(ctx context.Context, mongoID string) (*models.AchievementReference, error)
 	UpdateFn                   func(ctx context.Context, ref *models.AchievementReference) error
+	TouchFn                    func(ctx context.Context, id, expectedStatus string) error
+	TransitionFn               func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error
+	CreateWithOutboxFn         func(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error
+	TransitionWithOutboxFn     func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, msg *models.OutboxMessage) error
+	TransitionWithSnapshotFn   func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error
+	FindAllWithDeletedFn       func(ctx context.Context) ([]models.AchievementReference, error)
+	UpdateFactsFn              func(ctx context.Context, mongoID string, facts models.AchievementFacts) error
+	UpdateFactsWithOutboxFn    func(ctx context.Context, mongoID string, facts models.AchievementFacts, msg *models.OutboxMessage) error
+	CountByStatusFn            func(ctx context.Context, f models.AchievementFilter) (map[string]int, error)
+	EachStudentStatFn          func(ctx context.Context, f models.AchievementFilter, fn func(models.StudentStat) error) error
 	FindByStudentIDFn          func(ctx context.Context, studentID string) ([]models.AchievementReference, error)
 	FindAllFn                  func(ctx context.Context) ([]models.AchievementReference, error)
 	FindByStudentIDsFn         func(ctx context.Context, ids []string) ([]models.AchievementReference, error)
@@ -16,6 +26,7 @@ type AchievementReferenceMockRepo struct {
 	FindAllPaginatedFn         func(ctx context.Context, limit, offset int) ([]models.AchievementReference, int, error)
 	FindByStudentIDPaginatedFn func(ctx context.Context, studentID string, limit, offset int) ([]models.AchievementReference, int, error)
 	FindForAdvisorPaginatedFn  func(ctx context.Context, ids []string, limit, offset int) ([]models.AchievementReference, int, error)
+	SearchFn                   func(ctx context.Context, f models.AchievementFilter, limit, offset int) ([]models.AchievementReference, int, error)
 }
 
 func (m *AchievementReferenceMockRepo) Create(ctx context.Context, ref *models.AchievementReference) error {
@@ -39,6 +50,20 @@ func (m *AchievementReferenceMockRepo) Update(ctx context.Context, ref *models.A
 	return m.UpdateFn(ctx, ref)
 }
 
+func (m *AchievementReferenceMockRepo) Touch(ctx context.Context, id, expectedStatus string) error {
+	if m.TouchFn == nil {
+		return nil
+	}
+	return m.TouchFn(ctx, id, expectedStatus)
+}
+
+func (m *AchievementReferenceMockRepo) Transition(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+	if m.TransitionFn == nil {
+		return nil
+	}
+	return m.TransitionFn(ctx, ref, entry)
+}
+
 func (m *AchievementReferenceMockRepo) FindByStudentID(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
 	if m.FindByStudentIDFn == nil {
 		return nil, nil
@@ -86,4 +111,71 @@ func (m *AchievementReferenceMockRepo) FindForAdvisorPaginated(ctx context.Conte
 		return nil, 0, nil
 	}
 	return m.FindForAdvisorPaginatedFn(ctx, ids, limit, offset)
-}
\ No newline at end of file
+}
+
+func (m *AchievementReferenceMockRepo) Search(ctx context.Context, f models.AchievementFilter, limit, offset int) ([]models.AchievementReference, int, error) {
+	if m.SearchFn == nil {
+		return nil, 0, nil
+	}
+	return m.SearchFn(ctx, f, limit, offset)
+}
+
+func (m *AchievementReferenceMockRepo) CreateWithOutbox(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error {
+	if m.CreateWithOutboxFn == nil {
+		return nil
+	}
+	return m.CreateWithOutboxFn(ctx, ref, msg)
+}
+
+func (m *AchievementReferenceMockRepo) TransitionWithOutbox(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, msg *models.OutboxMessage) error {
+	if m.TransitionWithOutboxFn == nil {
+		return nil
+	}
+	return m.TransitionWithOutboxFn(ctx, ref, entry, msg)
+}
+
+// TransitionWithSnapshot memakai TransitionFn jika TransitionWithSnapshotFn
+// tidak diisi, agar test transisi reject tetap dapat mencatat entry.
+func (m *AchievementReferenceMockRepo) TransitionWithSnapshot(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error {
+	if m.TransitionWithSnapshotFn == nil {
+		return m.Transition(ctx, ref, entry)
+	}
+	return m.TransitionWithSnapshotFn(ctx, ref, entry, snap)
+}
+
+func (m *AchievementReferenceMockRepo) FindAllWithDeleted(ctx context.Context) ([]models.AchievementReference, error) {
+	if m.FindAllWithDeletedFn == nil {
+		return nil, nil
+	}
+	return m.FindAllWithDeletedFn(ctx)
+}
+
+func (m *AchievementReferenceMockRepo) UpdateFacts(ctx context.Context, mongoID string, facts models.AchievementFacts) error {
+	if m.UpdateFactsFn == nil {
+		return nil
+	}
+	return m.UpdateFactsFn(ctx, mongoID, facts)
+}
+
+// UpdateFactsWithOutbox memakai UpdateFactsFn jika UpdateFactsWithOutboxFn
+// tidak diisi.
+func (m *AchievementReferenceMockRepo) UpdateFactsWithOutbox(ctx context.Context, mongoID string, facts models.AchievementFacts, msg *models.OutboxMessage) error {
+	if m.UpdateFactsWithOutboxFn == nil {
+		return m.UpdateFacts(ctx, mongoID, facts)
+	}
+	return m.UpdateFactsWithOutboxFn(ctx, mongoID, facts, msg)
+}
+
+func (m *AchievementReferenceMockRepo) CountByStatus(ctx context.Context, f models.AchievementFilter) (map[string]int, error) {
+	if m.CountByStatusFn == nil {
+		return map[string]int{}, nil
+	}
+	return m.CountByStatusFn(ctx, f)
+}
+
+func (m *AchievementReferenceMockRepo) EachStudentStat(ctx context.Context, f models.AchievementFilter, fn func(models.StudentStat) error) error {
+	if m.EachStudentStatFn == nil {
+		return nil
+	}
+	return m.EachStudentStatFn(ctx, f, fn)
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+	"time"
+	"uas/app/models"
+)
+
+type ReviewMockRepo struct {
+	PendingByStudentFn func(ctx context.Context, ids []string, breachBefore time.Time) ([]models.AdviseeQueueStat, error)
+	MetricsFn          func(ctx context.Context, from, to *time.Time, breachBefore time.Time) ([]models.ReviewerMetrics, error)
+}
+
+func (m *ReviewMockRepo) PendingByStudent(ctx context.Context, ids []string, breachBefore time.Time) ([]models.AdviseeQueueStat, error) {
+	if m.PendingByStudentFn == nil {
+		return nil, nil
+	}
+	return m.PendingByStudentFn(ctx, ids, breachBefore)
+}
+
+func (m *ReviewMockRepo) Metrics(ctx context.Context, from, to *time.Time, breachBefore time.Time) ([]models.ReviewerMetrics, error) {
+	if m.MetricsFn == nil {
+		return nil, nil
+	}
+	return m.MetricsFn(ctx, from, to, breachBefore)
+}
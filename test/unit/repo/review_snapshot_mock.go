@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type ReviewSnapshotMockRepo struct {
+	CreateFn func(ctx context.Context, snap *models.AchievementReviewSnapshot) error
+	LatestFn func(ctx context.Context, refID string) (*models.AchievementReviewSnapshot, error)
+}
+
+func (m *ReviewSnapshotMockRepo) Create(ctx context.Context, snap *models.AchievementReviewSnapshot) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, snap)
+}
+
+func (m *ReviewSnapshotMockRepo) Latest(ctx context.Context, refID string) (*models.AchievementReviewSnapshot, error) {
+	if m.LatestFn == nil {
+		return nil, nil
+	}
+	return m.LatestFn(ctx, refID)
+}
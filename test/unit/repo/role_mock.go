@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"uas/app/models"
+)
+
+type RoleMockRepo struct {
+	FindAllFn                  func(ctx context.Context) ([]models.Role, error)
+	GetByIDFn                  func(ctx context.Context, id string) (*models.Role, error)
+	CreateFn                   func(ctx context.Context, role *models.Role) error
+	UpdateFn                   func(ctx context.Context, role *models.Role) error
+	DeleteFn                   func(ctx context.Context, id string) error
+	CountUsersFn               func(ctx context.Context, id string) (int, error)
+	CountRolesWithPermissionFn func(ctx context.Context, names []string, exceptRoleID string) (int, error)
+	ListPermissionsFn          func(ctx context.Context, roleID string) ([]models.Permission, error)
+	SetPermissionsFn           func(ctx context.Context, roleID string, permissionIDs []string) error
+	AddPermissionFn            func(ctx context.Context, roleID string, permissionID string) error
+	RemovePermissionFn         func(ctx context.Context, roleID string, permissionID string) (bool, error)
+	GetAccessFn                func(ctx context.Context, userID string) (*models.UserAccess, error)
+}
+
+func (m *RoleMockRepo) FindAll(ctx context.Context) ([]models.Role, error) {
+	if m.FindAllFn == nil {
+		return nil, nil
+	}
+	return m.FindAllFn(ctx)
+}
+
+func (m *RoleMockRepo) GetByID(ctx context.Context, id string) (*models.Role, error) {
+	if m.GetByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByIDFn(ctx, id)
+}
+
+func (m *RoleMockRepo) Create(ctx context.Context, role *models.Role) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, role)
+}
+
+func (m *RoleMockRepo) Update(ctx context.Context, role *models.Role) error {
+	if m.UpdateFn == nil {
+		return nil
+	}
+	return m.UpdateFn(ctx, role)
+}
+
+func (m *RoleMockRepo) Delete(ctx context.Context, id string) error {
+	if m.DeleteFn == nil {
+		return nil
+	}
+	return m.DeleteFn(ctx, id)
+}
+
+func (m *RoleMockRepo) CountUsers(ctx context.Context, id string) (int, error) {
+	if m.CountUsersFn == nil {
+		return 0, nil
+	}
+	return m.CountUsersFn(ctx, id)
+}
+
+func (m *RoleMockRepo) CountRolesWithPermission(ctx context.Context, names []string, exceptRoleID string) (int, error) {
+	if m.CountRolesWithPermissionFn == nil {
+		return 0, nil
+	}
+	return m.CountRolesWithPermissionFn(ctx, names, exceptRoleID)
+}
+
+func (m *RoleMockRepo) ListPermissions(ctx context.Context, roleID string) ([]models.Permission, error) {
+	if m.ListPermissionsFn == nil {
+		return nil, nil
+	}
+	return m.ListPermissionsFn(ctx, roleID)
+}
+
+func (m *RoleMockRepo) SetPermissions(ctx context.Context, roleID string, permissionIDs []string) error {
+	if m.SetPermissionsFn == nil {
+		return nil
+	}
+	return m.SetPermissionsFn(ctx, roleID, permissionIDs)
+}
+
+func (m *RoleMockRepo) AddPermission(ctx context.Context, roleID string, permissionID string) error {
+	if m.AddPermissionFn == nil {
+		return nil
+	}
+	return m.AddPermissionFn(ctx, roleID, permissionID)
+}
+
+func (m *RoleMockRepo) RemovePermission(ctx context.Context, roleID string, permissionID string) (bool, error) {
+	if m.RemovePermissionFn == nil {
+		return false, nil
+	}
+	return m.RemovePermissionFn(ctx, roleID, permissionID)
+}
+
+func (m *RoleMockRepo) GetAccess(ctx context.Context, userID string) (*models.UserAccess, error) {
+	if m.GetAccessFn == nil {
+		return nil, nil
+	}
+	return m.GetAccessFn(ctx, userID)
+}
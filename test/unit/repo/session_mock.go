@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"context"
+	"time"
+	"uas/app/models"
+)
+
+type SessionMockRepo struct {
+	CreateFn             func(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	GetByIDFn            func(ctx context.Context, id string) (*models.Session, error)
+	ListActiveByUserIDFn func(ctx context.Context, userID string) ([]models.Session, error)
+	IsActiveFn           func(ctx context.Context, id string) (bool, error)
+	RevokeFn             func(ctx context.Context, id string, reason string) error
+	RevokeAllByUserIDFn  func(ctx context.Context, userID string, reason string, exceptID string) error
+	GetRefreshTokenFn    func(ctx context.Context, jti string) (*models.RefreshToken, error)
+	RotateFn             func(ctx context.Context, oldJTI string, next *models.RefreshToken) (bool, error)
+	PurgeExpiredFn       func(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+func (m *SessionMockRepo) Create(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	if m.CreateFn == nil {
+		return nil
+	}
+	return m.CreateFn(ctx, session, token)
+}
+
+func (m *SessionMockRepo) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	if m.GetByIDFn == nil {
+		return nil, nil
+	}
+	return m.GetByIDFn(ctx, id)
+}
+
+func (m *SessionMockRepo) ListActiveByUserID(ctx context.Context, userID string) ([]models.Session, error) {
+	if m.ListActiveByUserIDFn == nil {
+		return nil, nil
+	}
+	return m.ListActiveByUserIDFn(ctx, userID)
+}
+
+func (m *SessionMockRepo) IsActive(ctx context.Context, id string) (bool, error) {
+	if m.IsActiveFn == nil {
+		return true, nil
+	}
+	return m.IsActiveFn(ctx, id)
+}
+
+func (m *SessionMockRepo) Revoke(ctx context.Context, id string, reason string) error {
+	if m.RevokeFn == nil {
+		return nil
+	}
+	return m.RevokeFn(ctx, id, reason)
+}
+
+func (m *SessionMockRepo) RevokeAllByUserID(ctx context.Context, userID string, reason string, exceptID string) error {
+	if m.RevokeAllByUserIDFn == nil {
+		return nil
+	}
+	return m.RevokeAllByUserIDFn(ctx, userID, reason, exceptID)
+}
+
+func (m *SessionMockRepo) GetRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	if m.GetRefreshTokenFn == nil {
+		return nil, nil
+	}
+	return m.GetRefreshTokenFn(ctx, jti)
+}
+
+func (m *SessionMockRepo) Rotate(ctx context.Context, oldJTI string, next *models.RefreshToken) (bool, error) {
+	if m.RotateFn == nil {
+		return true, nil
+	}
+	return m.RotateFn(ctx, oldJTI, next)
+}
+
+func (m *SessionMockRepo) PurgeExpired(ctx context.Context, retention time.Duration) (int64, error) {
+	if m.PurgeExpiredFn == nil {
+		return 0, nil
+	}
+	return m.PurgeExpiredFn(ctx, retention)
+}
@@ -7,16 +7,20 @@ import (
 )
 
 type StudentMockRepo struct {
-	CreateFn          func(tx *sql.Tx, userID string, studentID string) error
-	DeleteByUserIDFn  func(tx *sql.Tx, userID string) error
-	RemoveAdvisorFn   func(tx *sql.Tx, lecturerID string) error
-	GetByUserIDFn     func(ctx context.Context, userID string) (*models.Student, error)
-	UpdateAdvisorFn   func(tx *sql.Tx, studentID string, advisorID *string) error
-	GetIDByIndexFn    func(idx int) (string, error)
-	FindAllFn         func(ctx context.Context) ([]models.Student, error)
-	FindByIDFn        func(ctx context.Context, id string) (*models.Student, error)
-	FindByAdvisorIDFn func(ctx context.Context, advisorID string) ([]models.Student, error)
-	FindAdviseesIDFn  func(ctx context.Context, advisorID string) ([]models.AdviseeResponse, error)
+	CreateFn                  func(tx *sql.Tx, userID string, studentID string) error
+	CreateProfileFn           func(tx *sql.Tx, userID string, s *models.Student) error
+	FindTakenStudentIDsFn     func(ctx context.Context, ids []string) (map[string]bool, error)
+	DeleteByUserIDFn          func(tx *sql.Tx, userID string) error
+	RemoveAdvisorFn           func(tx *sql.Tx, lecturerID string) error
+	GetByUserIDFn             func(ctx context.Context, userID string) (*models.Student, error)
+	UpdateAdvisorFn           func(tx *sql.Tx, studentID string, advisorID *string) error
+	UpdateProfileFn           func(ctx context.Context, s *models.Student) error
+	UpdateProfileWithOutboxFn func(ctx context.Context, s *models.Student, msg *models.OutboxMessage) error
+	GetIDByIndexFn            func(idx int) (string, error)
+	FindAllFn                 func(ctx context.Context) ([]models.Student, error)
+	FindByIDFn                func(ctx context.Context, id string) (*models.Student, error)
+	FindByAdvisorIDFn         func(ctx context.Context, advisorID string) ([]models.Student, error)
+	FindAdviseesIDFn          func(ctx context.Context, advisorID string) ([]models.AdviseeResponse, error)
 }
 
 func (m *StudentMockRepo) Create(tx *sql.Tx, userID string, studentID string) error {
@@ -26,6 +30,20 @@ func (m *StudentMockRepo) Create(tx *sql.Tx, userID string, studentID string) er
 	return m.CreateFn(tx, userID, studentID)
 }
 
+func (m *StudentMockRepo) CreateProfile(tx *sql.Tx, userID string, s *models.Student) error {
+	if m.CreateProfileFn == nil {
+		return nil
+	}
+	return m.CreateProfileFn(tx, userID, s)
+}
+
+func (m *StudentMockRepo) FindTakenStudentIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	if m.FindTakenStudentIDsFn == nil {
+		return nil, nil
+	}
+	return m.FindTakenStudentIDsFn(ctx, ids)
+}
+
 func (m *StudentMockRepo) DeleteByUserID(tx *sql.Tx, userID string) error {
 	if m.DeleteByUserIDFn == nil {
 		return nil
@@ -54,6 +72,20 @@ func (m *StudentMockRepo) UpdateAdvisor(tx *sql.Tx, studentID string, advisorID
 	return m.UpdateAdvisorFn(tx, studentID, advisorID)
 }
 
+func (m *StudentMockRepo) UpdateProfile(ctx context.Context, s *models.Student) error {
+	if m.UpdateProfileFn == nil {
+		return nil
+	}
+	return m.UpdateProfileFn(ctx, s)
+}
+
+func (m *StudentMockRepo) UpdateProfileWithOutbox(ctx context.Context, s *models.Student, msg *models.OutboxMessage) error {
+	if m.UpdateProfileWithOutboxFn == nil {
+		return m.UpdateProfile(ctx, s)
+	}
+	return m.UpdateProfileWithOutboxFn(ctx, s, msg)
+}
+
 func (m *StudentMockRepo) GetIDByIndex(idx int) (string, error) {
 	if m.GetIDByIndexFn == nil {
 		return "", nil
@@ -87,4 +119,4 @@ func (m *StudentMockRepo) FindAdviseesID(ctx context.Context, advisorID string)
 		return nil, nil
 	}
 	return m.FindAdviseesIDFn(ctx, advisorID)
-}
\ No newline at end of file
+}
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"uas/app/models"
 )
@@ -13,6 +14,7 @@ type UserMockRepo struct {
 	UpdateRoleFn   func(tx *sql.Tx, userID string, roleID string) error
 	DeleteFn       func(tx *sql.Tx, id string) error
 	GetIDByIndexFn func(idx int) (string, error)
+	FindTakenFn    func(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error)
 }
 
 func (m *UserMockRepo) GetAll() ([]models.UserWithRole, error) {
@@ -62,4 +64,11 @@ func (m *UserMockRepo) GetIDByIndex(idx int) (string, error) {
 		return "", nil
 	}
 	return m.GetIDByIndexFn(idx)
-}
\ No newline at end of file
+}
+
+func (m *UserMockRepo) FindTaken(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error) {
+	if m.FindTakenFn == nil {
+		return nil, nil, nil
+	}
+	return m.FindTakenFn(ctx, usernames, emails)
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"time"
+	"uas/app/models"
+)
+
+type WebhookMockRepo struct {
+	CreateSubscriptionFn     func(ctx context.Context, sub *models.WebhookSubscription) error
+	UpdateSubscriptionFn     func(ctx context.Context, sub *models.WebhookSubscription) error
+	DeleteSubscriptionFn     func(ctx context.Context, id string) error
+	GetSubscriptionFn        func(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptionsFn      func(ctx context.Context) ([]models.WebhookSubscription, error)
+	ActiveSubscriptionsForFn func(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+	CreateDeliveryFn         func(ctx context.Context, d *models.WebhookDelivery) error
+	GetDeliveryFn            func(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	ListDeliveriesFn         func(ctx context.Context, subscriptionID string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	ClaimDueFn               func(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	SaveAttemptFn            func(ctx context.Context, d *models.WebhookDelivery) error
+}
+
+func (m *WebhookMockRepo) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	if m.CreateSubscriptionFn == nil {
+		return nil
+	}
+	return m.CreateSubscriptionFn(ctx, sub)
+}
+
+func (m *WebhookMockRepo) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	if m.UpdateSubscriptionFn == nil {
+		return nil
+	}
+	return m.UpdateSubscriptionFn(ctx, sub)
+}
+
+func (m *WebhookMockRepo) DeleteSubscription(ctx context.Context, id string) error {
+	if m.DeleteSubscriptionFn == nil {
+		return nil
+	}
+	return m.DeleteSubscriptionFn(ctx, id)
+}
+
+func (m *WebhookMockRepo) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	if m.GetSubscriptionFn == nil {
+		return nil, nil
+	}
+	return m.GetSubscriptionFn(ctx, id)
+}
+
+func (m *WebhookMockRepo) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	if m.ListSubscriptionsFn == nil {
+		return nil, nil
+	}
+	return m.ListSubscriptionsFn(ctx)
+}
+
+func (m *WebhookMockRepo) ActiveSubscriptionsFor(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	if m.ActiveSubscriptionsForFn == nil {
+		return nil, nil
+	}
+	return m.ActiveSubscriptionsForFn(ctx, eventType)
+}
+
+func (m *WebhookMockRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	if m.CreateDeliveryFn == nil {
+		return nil
+	}
+	return m.CreateDeliveryFn(ctx, d)
+}
+
+func (m *WebhookMockRepo) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	if m.GetDeliveryFn == nil {
+		return nil, nil
+	}
+	return m.GetDeliveryFn(ctx, id)
+}
+
+func (m *WebhookMockRepo) ListDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	if m.ListDeliveriesFn == nil {
+		return nil, 0, nil
+	}
+	return m.ListDeliveriesFn(ctx, subscriptionID, limit, offset)
+}
+
+func (m *WebhookMockRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	if m.ClaimDueFn == nil {
+		return nil, nil
+	}
+	return m.ClaimDueFn(ctx, limit, lease)
+}
+
+func (m *WebhookMockRepo) SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	if m.SaveAttemptFn == nil {
+		return nil
+	}
+	return m.SaveAttemptFn(ctx, d)
+}
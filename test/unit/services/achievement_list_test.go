@@ -0,0 +1,266 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// listCalls mencatat filter yang diteruskan List ke MongoDB dan PostgreSQL.
+type listCalls struct {
+	mongo *models.AchievementFilter
+	pg    *models.AchievementFilter
+}
+
+func listService(mongoIDs []string) (*services.AchievementService, *listCalls) {
+	students, lecturers, refs := policyRepos("submitted")
+	students.FindByAdvisorIDFn = func(ctx context.Context, advisorID string) ([]models.Student, error) {
+		return []models.Student{{ID: "s1", StudentID: "2101"}, {ID: "s3", StudentID: "2103"}}, nil
+	}
+	students.FindByIDFn = func(ctx context.Context, id string) (*models.Student, error) {
+		return &models.Student{ID: id, StudentID: "2101"}, nil
+	}
+
+	calls := &listCalls{}
+	refs.SearchFn = func(ctx context.Context, f models.AchievementFilter, limit, offset int) ([]models.AchievementReference, int, error) {
+		calls.pg = &f
+		return nil, 0, nil
+	}
+	mongo := &repo.AchievementMongoMockRepo{
+		FindIDsFn: func(ctx context.Context, f models.AchievementFilter, limit int) ([]string, error) {
+			calls.mongo = &f
+			if len(mongoIDs) > limit {
+				return mongoIDs[:limit], nil
+			}
+			return mongoIDs, nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+	return svc, calls
+}
+
+func listRequest(t *testing.T, svc *services.AchievementService, userID string, perms []string, query string) int {
+	app := fiber.New()
+	app.Get("/achievements", asUser(userID, perms), svc.List)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements"+query, nil))
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAchievement_List_InvalidFilter(t *testing.T) {
+	for _, query := range []string{
+		"?status=approved",
+		"?event_from=17-08-2025",
+		"?submitted_from=2025-02-01&submitted_to=2025-01-01",
+		"?sort=title",
+		"?order=up",
+	} {
+		t.Run(query, func(t *testing.T) {
+			svc, calls := listService(nil)
+
+			assert.Equal(t, fiber.StatusBadRequest, listRequest(t, svc, "u5", adminPerms, query))
+			assert.Nil(t, calls.pg)
+		})
+	}
+}
+
+func TestAchievement_List_StudentScope(t *testing.T) {
+	svc, calls := listService(nil)
+
+	status := listRequest(t, svc, "u1", studentPerms, "?status=verified,rejected&sort=verified_at&order=asc")
+	require.Equal(t, fiber.StatusOK, status)
+
+	require.NotNil(t, calls.pg)
+	assert.Equal(t, []string{"s1"}, calls.pg.StudentIDs)
+	assert.Equal(t, []string{"verified", "rejected"}, calls.pg.Statuses)
+	assert.Equal(t, "verified_at", calls.pg.Sort)
+	assert.Equal(t, "asc", calls.pg.Order)
+	assert.Nil(t, calls.mongo, "tanpa filter isi, MongoDB tidak perlu dicari")
+}
+
+func TestAchievement_List_AdvisorScope(t *testing.T) {
+	t.Run("default verification queue", func(t *testing.T) {
+		svc, calls := listService(nil)
+
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u3", lecturerPerms, ""))
+		assert.Equal(t, []string{"s1", "s3"}, calls.pg.StudentIDs)
+		assert.Equal(t, []string{"submitted"}, calls.pg.Statuses)
+		assert.Equal(t, []string{"draft"}, calls.pg.ExcludeStatuses)
+		assert.Equal(t, "submitted_at", calls.pg.Sort)
+	})
+
+	t.Run("explicit status", func(t *testing.T) {
+		svc, calls := listService(nil)
+
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u3", lecturerPerms, "?status=verified"))
+		assert.Equal(t, []string{"verified"}, calls.pg.Statuses)
+		assert.Equal(t, []string{"draft"}, calls.pg.ExcludeStatuses)
+	})
+}
+
+func TestAchievement_List_MongoFilter(t *testing.T) {
+	t.Run("ids passed to postgres", func(t *testing.T) {
+		svc, calls := listService([]string{"m1", "m2"})
+
+		query := "?achievement_type=competition&tags=ai,web&q=gemastik&event_from=2025-01-01&event_to=2025-12-31&program_study=Informatika"
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u5", adminPerms, query))
+
+		require.NotNil(t, calls.mongo)
+		assert.Equal(t, "competition", calls.mongo.AchievementType)
+		assert.Equal(t, []string{"ai", "web"}, calls.mongo.Tags)
+		assert.Equal(t, "gemastik", calls.mongo.Query)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *calls.mongo.EventFrom)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *calls.mongo.EventTo)
+
+		require.NotNil(t, calls.pg)
+		assert.Nil(t, calls.pg.StudentIDs)
+		assert.Equal(t, []string{"m1", "m2"}, calls.pg.MongoIDs)
+		assert.Equal(t, "Informatika", calls.pg.ProgramStudy)
+	})
+
+	t.Run("scoped to student codes", func(t *testing.T) {
+		svc, calls := listService([]string{"m1"})
+
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u1", studentPerms, "?q=gemastik"))
+		require.NotNil(t, calls.mongo)
+		assert.Equal(t, []string{"2101"}, calls.mongo.StudentCodes)
+
+		svc, calls = listService([]string{"m1"})
+
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u3", lecturerPerms, "?q=gemastik"))
+		require.NotNil(t, calls.mongo)
+		assert.Equal(t, []string{"2101", "2103"}, calls.mongo.StudentCodes)
+	})
+
+	t.Run("too many matches rejected", func(t *testing.T) {
+		ids := make([]string, 2001)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("m%d", i)
+		}
+		svc, calls := listService(ids)
+
+		assert.Equal(t, fiber.StatusBadRequest, listRequest(t, svc, "u5", adminPerms, "?tags=ai"))
+		assert.Nil(t, calls.pg)
+	})
+
+	t.Run("no match skips postgres", func(t *testing.T) {
+		svc, calls := listService([]string{})
+
+		require.Equal(t, fiber.StatusOK, listRequest(t, svc, "u5", adminPerms, "?q=tidak-ada"))
+		assert.NotNil(t, calls.mongo)
+		assert.Nil(t, calls.pg)
+	})
+}
+
+func batchMongo(calls *int, docs ...string) *repo.AchievementMongoMockRepo {
+	return &repo.AchievementMongoMockRepo{
+		FindByIDsFn: func(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error) {
+			*calls++
+			out := map[string]*models.AchievementMongo{}
+			for _, id := range docs {
+				oid, _ := primitive.ObjectIDFromHex(id)
+				out[id] = &models.AchievementMongo{ID: oid, Title: "Prestasi " + id[len(id)-1:]}
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestAchievement_List_BatchPreservesOrder(t *testing.T) {
+	const (
+		id1 = "000000000000000000000001"
+		id2 = "000000000000000000000002"
+		id3 = "000000000000000000000003"
+	)
+
+	students, lecturers, refs := policyRepos("submitted")
+	refs.SearchFn = func(ctx context.Context, f models.AchievementFilter, limit, offset int) ([]models.AchievementReference, int, error) {
+		return []models.AchievementReference{
+			{ID: "ref-3", MongoAchievementID: id3},
+			{ID: "ref-1", MongoAchievementID: id1},
+			{ID: "ref-2", MongoAchievementID: id2},
+		}, 3, nil
+	}
+
+	calls := 0
+	mongo := batchMongo(&calls, id1, id3)
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/achievements", asUser("u5", adminPerms), svc.List)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Title   string `json:"title"`
+			Missing bool   `json:"missing"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, 1, calls)
+	require.Len(t, body.Data, 3)
+	assert.Equal(t, []string{id3, id1, id2}, []string{body.Data[0].ID, body.Data[1].ID, body.Data[2].ID})
+	assert.Equal(t, "Prestasi 3", body.Data[0].Title)
+	assert.False(t, body.Data[1].Missing)
+	assert.True(t, body.Data[2].Missing)
+}
+
+func TestReport_StudentReport_ReportsDangling(t *testing.T) {
+	const id1 = "000000000000000000000001"
+
+	students, lecturers, refs := policyRepos("verified")
+	refs.FindByStudentIDFn = func(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
+		return []models.AchievementReference{
+			{ID: "ref-1", StudentID: "s1", MongoAchievementID: id1, Status: "verified"},
+			{ID: "ref-2", StudentID: "s1", MongoAchievementID: "000000000000000000000009", Status: "verified"},
+		}, nil
+	}
+
+	calls := 0
+	svc := services.NewReportService(refs, batchMongo(&calls, id1), students, lecturers)
+
+	app := fiber.New()
+	app.Get("/reports/student/:id", asUser("u1", studentPerms), svc.StudentReport)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/reports/student/s1", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data struct {
+			Total    int      `json:"total"`
+			Dangling []string `json:"dangling"`
+			Items    []struct {
+				Achievement *models.ReportAchievement `json:"achievement"`
+			} `json:"items"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, body.Data.Total)
+	assert.Equal(t, []string{"ref-2"}, body.Data.Dangling)
+	require.Len(t, body.Data.Items, 2)
+	assert.NotNil(t, body.Data.Items[0].Achievement)
+	assert.Nil(t, body.Data.Items[1].Achievement)
+}
@@ -0,0 +1,249 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/schema"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+var competitionType = &models.AchievementType{
+	Code:     "competition",
+	Name:     "Kompetisi",
+	IsActive: true,
+	Fields: []models.FieldSpec{
+		{Name: "competition_name", Type: models.FieldString, Required: true},
+		{Name: "competition_level", Type: models.FieldString, Required: true, Enum: []string{"international", "national", "regional", "local"}},
+		{Name: "rank", Type: models.FieldInteger, Min: floatPtr(1)},
+		{Name: "eventDate", Type: models.FieldDate},
+		{Name: "authors", Type: models.FieldArray, Items: models.FieldString},
+		{Name: "issn", Type: models.FieldString, Pattern: `^[0-9]{4}-[0-9]{3}[0-9Xx]$`},
+	},
+}
+
+// typeRegistry mengembalikan registry berisi tipe competition dan tipe
+// organization yang sudah dinonaktifkan.
+func typeRegistry() *repo.AchievementTypeMockRepo {
+	return &repo.AchievementTypeMockRepo{
+		GetByCodeFn: func(ctx context.Context, code string) (*models.AchievementType, error) {
+			switch code {
+			case "competition":
+				return competitionType, nil
+			case "organization":
+				return &models.AchievementType{Code: code, IsActive: false}, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	cases := []struct {
+		name     string
+		details  map[string]interface{}
+		wantErrs []string
+	}{
+		{"valid", map[string]interface{}{"competition_name": "Gemastik", "competition_level": "National", "rank": float64(1), "eventDate": "2024-08-17"}, nil},
+		{"missing required", map[string]interface{}{"competition_level": "national"}, []string{"details.competition_name"}},
+		{"rank not a number", map[string]interface{}{"competition_name": "A", "competition_level": "local", "rank": "abc"}, []string{"details.rank"}},
+		{"rank fraction", map[string]interface{}{"competition_name": "A", "competition_level": "local", "rank": 1.5}, []string{"details.rank"}},
+		{"rank below min", map[string]interface{}{"competition_name": "A", "competition_level": "local", "rank": float64(0)}, []string{"details.rank"}},
+		{"bad enum", map[string]interface{}{"competition_name": "A", "competition_level": "galaxy"}, []string{"details.competition_level"}},
+		{"bad date", map[string]interface{}{"competition_name": "A", "competition_level": "local", "eventDate": "tomorrow"}, []string{"details.eventDate"}},
+		{"bad array item", map[string]interface{}{"competition_name": "A", "competition_level": "local", "authors": []interface{}{"Budi", float64(3)}}, []string{"details.authors"}},
+		{"bad pattern", map[string]interface{}{"competition_name": "A", "competition_level": "local", "issn": "12-34"}, []string{"details.issn"}},
+		{"unknown key", map[string]interface{}{"competition_name": "A", "competition_level": "local", "hack": true}, []string{"details.hack"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := schema.Validate(competitionType, tc.details)
+
+			keys := []string{}
+			for k := range errs {
+				keys = append(keys, k)
+			}
+			assert.ElementsMatch(t, tc.wantErrs, keys)
+		})
+	}
+}
+
+func TestSchema_Validate_Normalizes(t *testing.T) {
+	clean, errs := schema.Validate(competitionType, map[string]interface{}{
+		"competition_name":  "Gemastik",
+		"competition_level": "National",
+		"rank":              float64(2),
+		"eventDate":         "2024-08-17T09:00:00+07:00",
+	})
+	require.Empty(t, errs)
+
+	assert.Equal(t, "national", clean["competition_level"])
+	assert.Equal(t, 2, clean["rank"])
+	assert.Equal(t, "2024-08-17", clean["eventDate"])
+}
+
+func TestSchema_ValidateType(t *testing.T) {
+	req := &models.AchievementTypeRequest{
+		Code: "Hackathon",
+		Name: "Hackathon",
+		Fields: []models.FieldSpec{
+			{Name: "team", Type: models.FieldString},
+			{Name: "team", Type: models.FieldString},
+			{Name: "prize", Type: "money"},
+			{Name: "score", Type: models.FieldNumber, Min: floatPtr(10), Max: floatPtr(1)},
+			{Name: "code", Type: models.FieldString, Pattern: "(["},
+		},
+	}
+
+	errs := schema.ValidateType(req)
+
+	assert.Equal(t, "hackathon", req.Code)
+	assert.NotContains(t, errs, "code")
+	assert.NotContains(t, errs, "fields[0]")
+	assert.Contains(t, errs, "fields[1]")
+	assert.Contains(t, errs, "fields[2]")
+	assert.Contains(t, errs, "fields[3]")
+	assert.Contains(t, errs, "fields[4]")
+}
+
+func TestAchievement_Create_DetailsValidation(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantErrKey string
+	}{
+		{"unknown type", `{"achievement_type":"esport","title":"T"}`, fiber.StatusBadRequest, "achievement_type"},
+		{"inactive type", `{"achievement_type":"organization","title":"T"}`, fiber.StatusBadRequest, "achievement_type"},
+		{"invalid rank", `{"achievement_type":"competition","title":"T","details":{"competition_name":"A","competition_level":"local","rank":"abc"}}`, fiber.StatusBadRequest, "details.rank"},
+		{"valid", `{"achievement_type":"competition","title":"T","details":{"competition_name":"A","competition_level":"local","rank":1}}`, fiber.StatusCreated, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stored *models.AchievementMongo
+			mongo := &repo.AchievementMongoMockRepo{
+				CreateFn: func(ctx context.Context, data *models.AchievementMongo) (string, error) {
+					stored = data
+					return "m1", nil
+				},
+			}
+			users := &repo.UserMockRepo{
+				GetByIDFn: func(id string) (*models.UserWithRole, error) {
+					return &models.UserWithRole{FullName: "Budi"}, nil
+				},
+			}
+			students, _ := ownerRepos("m1")
+
+			svc := services.NewAchievementService(students, mongo, &repo.AchievementReferenceMockRepo{}, &repo.LecturerMockRepo{}, users, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, typeRegistry(), &repo.OutboxMockRepo{}, nil, nil, nil)
+
+			app := fiber.New()
+			app.Post("/achievements", asUser("u1", studentPerms), svc.Create)
+
+			req := httptest.NewRequest("POST", "/achievements", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			if tc.wantErrKey == "" {
+				require.NotNil(t, stored)
+				assert.EqualValues(t, 1, stored.Details["rank"])
+				return
+			}
+
+			var body struct {
+				Errors map[string]string `json:"errors"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Contains(t, body.Errors, tc.wantErrKey)
+			assert.Nil(t, stored)
+		})
+	}
+}
+
+func TestAchievementType_Create(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"new type", `{"code":"hackathon","name":"Hackathon","fields":[{"name":"team","type":"string","required":true}]}`, fiber.StatusCreated},
+		{"duplicate code", `{"code":"competition","name":"Kompetisi"}`, fiber.StatusBadRequest},
+		{"invalid field type", `{"code":"hackathon","name":"Hackathon","fields":[{"name":"team","type":"text"}]}`, fiber.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created *models.AchievementType
+			types := typeRegistry()
+			types.CreateFn = func(ctx context.Context, at *models.AchievementType) error {
+				created = at
+				return nil
+			}
+
+			svc := services.NewAchievementTypeService(types)
+
+			app := fiber.New()
+			app.Post("/achievement-types", svc.Create)
+
+			req := httptest.NewRequest("POST", "/achievement-types", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			if tc.wantStatus == fiber.StatusCreated {
+				require.NotNil(t, created)
+				assert.True(t, created.IsActive)
+				assert.Len(t, created.Fields, 1)
+			} else {
+				assert.Nil(t, created)
+			}
+		})
+	}
+}
+
+func TestAchievementType_Update_Deactivate(t *testing.T) {
+	var updated *models.AchievementType
+	types := &repo.AchievementTypeMockRepo{
+		GetByCodeFn: func(ctx context.Context, code string) (*models.AchievementType, error) {
+			ct := *competitionType
+			return &ct, nil
+		},
+		UpdateFn: func(ctx context.Context, at *models.AchievementType) error {
+			updated = at
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementTypeService(types)
+
+	app := fiber.New()
+	app.Put("/achievement-types/:code", svc.Update)
+
+	req := httptest.NewRequest("PUT", "/achievement-types/competition", strings.NewReader(`{"name":"Kompetisi","is_active":false}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, updated)
+	assert.False(t, updated.IsActive)
+	assert.Equal(t, competitionType.Fields, updated.Fields)
+}
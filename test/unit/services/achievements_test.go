@@ -8,11 +8,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"uas/app/models"
 	"uas/app/services"
+	"uas/app/storage"
 	"uas/test/unit/repo"
 
 	"github.com/gofiber/fiber/v2"
@@ -32,9 +35,47 @@ func setupAchievementService(
 		UserRepo:    userRepo,
 		MongoRepo:   mongoRepo,
 		PgRepo:      pgRepo,
+		OutboxRepo:  &repo.OutboxMockRepo{},
+		Attachments: services.AttachmentPolicy{MaxFileSize: 1 << 20, MaxTotalSize: 2 << 20, URLTTL: time.Minute},
+		TypeRepo: &repo.AchievementTypeMockRepo{
+			GetByCodeFn: func(ctx context.Context, code string) (*models.AchievementType, error) {
+				return &models.AchievementType{
+					Code:     code,
+					IsActive: true,
+					Fields:   []models.FieldSpec{{Name: "rank", Type: models.FieldString}},
+				}, nil
+			},
+		},
 	}
 }
 
+// ownerRepos mengembalikan repo mahasiswa & reference dimana user "u1"
+// adalah pemilik prestasi mongoID (status draft).
+func ownerRepos(mongoID string) (*repo.StudentMockRepo, *repo.AchievementReferenceMockRepo) {
+	studentRepo := &repo.StudentMockRepo{
+		GetByUserIDFn: func(ctx context.Context, uid string) (*models.Student, error) {
+			return &models.Student{ID: "s1", UserID: "u1"}, nil
+		},
+		FindByIDFn: func(ctx context.Context, id string) (*models.Student, error) {
+			return &models.Student{ID: "s1", UserID: "u1"}, nil
+		},
+	}
+	pgRepo := &repo.AchievementReferenceMockRepo{
+		GetByMongoIDFn: func(ctx context.Context, id string) (*models.AchievementReference, error) {
+			if id != mongoID {
+				return nil, nil
+			}
+			return &models.AchievementReference{ID: "ref-1", StudentID: "s1", MongoAchievementID: id, Status: "draft"}, nil
+		},
+	}
+	return studentRepo, pgRepo
+}
+
+func asOwner(c *fiber.Ctx) {
+	c.Locals("user_id", "u1")
+	c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update"})
+}
+
 func createMultipartRequest(url string, fieldName, fileName string, fileContent []byte) (*http.Request, error) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
@@ -73,18 +114,22 @@ func TestAchievement_Create_Success(t *testing.T) {
 		},
 	}
 
+	var refMongoID string
+
 	mockMongoRepo := &repo.AchievementMongoMockRepo{
 		CreateFn: func(ctx context.Context, data *models.AchievementMongo) (string, error) {
 			assert.Equal(t, "NIM123", data.StudentID)
 			assert.Equal(t, "Kompetisi", data.AchievementType)
-			
-			return "mongo-obj-id-123", nil
+			assert.Equal(t, refMongoID, data.ID.Hex())
+
+			return data.ID.Hex(), nil
 		},
 	}
 
 	mockPgRepo := &repo.AchievementReferenceMockRepo{
-		CreateFn: func(ctx context.Context, ref *models.AchievementReference) error {
-			assert.Equal(t, "mongo-obj-id-123", ref.MongoAchievementID)
+		CreateWithOutboxFn: func(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error {
+			refMongoID = ref.MongoAchievementID
+			assert.Equal(t, ref.MongoAchievementID, msg.AggregateID)
 			assert.Equal(t, "Panji Mahasiswa", ref.StudentName)
 			return nil
 		},
@@ -176,7 +221,7 @@ func TestAchievement_Create_ValidationError(t *testing.T) {
 	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 }
 
-func TestAchievement_Create_MongoError(t *testing.T) {
+func TestAchievement_Create_MongoError_LeftInOutbox(t *testing.T) {
 	app := fiber.New()
 
 	mockStudentRepo := &repo.StudentMockRepo{
@@ -199,9 +244,22 @@ func TestAchievement_Create_MongoError(t *testing.T) {
 		mockStudentRepo,
 		mockUserRepo,
 		mockMongoRepo,
-		&repo.AchievementReferenceMockRepo{},
+		&repo.AchievementReferenceMockRepo{
+			CreateWithOutboxFn: func(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error {
+				msg.ID = 42
+				return nil
+			},
+		},
 	)
 
+	var failedID int64
+	svc.OutboxRepo = &repo.OutboxMockRepo{
+		MarkFailedFn: func(ctx context.Context, id int64, reason string) error {
+			failedID = id
+			return nil
+		},
+	}
+
 	app.Post("/achievements", func(c *fiber.Ctx) error {
 		c.Locals("user_id", "u1")
 		return svc.Create(c)
@@ -213,12 +271,13 @@ func TestAchievement_Create_MongoError(t *testing.T) {
 
 	resp, _ := app.Test(req)
 
-	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	// reference sudah tersimpan; dokumen dibuat ulang oleh worker outbox
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int64(42), failedID, "operasi outbox harus ditandai gagal agar diulang")
 }
 
-func TestAchievement_Create_Rollback_On_PgError(t *testing.T) {
+func TestAchievement_Create_PgError_NoMongoWrite(t *testing.T) {
 	app := fiber.New()
-	mockMongoID := "mongo-id-to-delete"
 
 	mockStudentRepo := &repo.StudentMockRepo{
 		GetByUserIDFn: func(ctx context.Context, uid string) (*models.Student, error) {
@@ -231,21 +290,17 @@ func TestAchievement_Create_Rollback_On_PgError(t *testing.T) {
 		},
 	}
 
-	softDeleteCalled := false
+	mongoCalled := false
 
 	mockMongoRepo := &repo.AchievementMongoMockRepo{
 		CreateFn: func(ctx context.Context, data *models.AchievementMongo) (string, error) {
-			return mockMongoID, nil
-		},
-		SoftDeleteFn: func(ctx context.Context, id string) error {
-			softDeleteCalled = true
-			assert.Equal(t, mockMongoID, id)
-			return nil
+			mongoCalled = true
+			return data.ID.Hex(), nil
 		},
 	}
 
 	mockPgRepo := &repo.AchievementReferenceMockRepo{
-		CreateFn: func(ctx context.Context, ref *models.AchievementReference) error {
+		CreateWithOutboxFn: func(ctx context.Context, ref *models.AchievementReference, msg *models.OutboxMessage) error {
 			return errors.New("postgres connection error")
 		},
 	}
@@ -264,13 +319,10 @@ func TestAchievement_Create_Rollback_On_PgError(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
-	assert.True(t, softDeleteCalled, "SoftDelete harus dipanggil jika simpan ke Postgres gagal")
+	assert.False(t, mongoCalled, "MongoDB tidak boleh ditulis jika transaksi Postgres gagal")
 }
 
 func TestAchievement_UploadAttachments_Success(t *testing.T) {
-	_ = os.MkdirAll("./uploads", 0755)
-	defer os.RemoveAll("./uploads")
-
 	app := fiber.New()
 	
 
@@ -290,18 +342,24 @@ func TestAchievement_UploadAttachments_Success(t *testing.T) {
 			assert.Equal(t, oid, a.ID) 
 			assert.Len(t, a.Attachments, 1)
 			assert.Equal(t, "test.pdf", a.Attachments[0].FileName)
+			assert.Equal(t, "application/pdf", a.Attachments[0].FileType)
 			return nil
 		},
 	}
 
-	svc := setupAchievementService(nil, nil, mockMongoRepo, nil)
-	app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+	studentRepo, pgRepo := ownerRepos(targetID)
+	svc := setupAchievementService(studentRepo, nil, mockMongoRepo, pgRepo)
+	svc.Storage = storage.NewLocal(t.TempDir(), "/files", []byte("secret"))
+	app.Post("/achievements/:id/attachments", func(c *fiber.Ctx) error {
+		asOwner(c)
+		return svc.UploadAttachments(c)
+	})
 
 	req, err := createMultipartRequest(
 		"/achievements/"+targetID+"/attachments", 
 		"files",
 		"test.pdf",
-		[]byte("dummy content pdf"),
+		[]byte("%PDF-1.4 dummy content pdf"),
 	)
 	require.NoError(t, err)
 
@@ -320,8 +378,11 @@ func TestAchievement_UploadAttachments_NotFound(t *testing.T) {
 		},
 	}
 
-	svc := setupAchievementService(nil, nil, mockMongoRepo, nil)
-	app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+	svc := setupAchievementService(&repo.StudentMockRepo{}, nil, mockMongoRepo, &repo.AchievementReferenceMockRepo{})
+	app.Post("/achievements/:id/attachments", func(c *fiber.Ctx) error {
+		asOwner(c)
+		return svc.UploadAttachments(c)
+	})
 
 	req := httptest.NewRequest("POST", "/achievements/"+targetID+"/attachments", nil)
 
@@ -342,8 +403,12 @@ func TestAchievement_UploadAttachments_NoFileUploaded(t *testing.T) {
 		},
 	}
 
-	svc := setupAchievementService(nil, nil, mockMongoRepo, nil)
-	app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+	studentRepo, pgRepo := ownerRepos(targetID)
+	svc := setupAchievementService(studentRepo, nil, mockMongoRepo, pgRepo)
+	app.Post("/achievements/:id/attachments", func(c *fiber.Ctx) error {
+		asOwner(c)
+		return svc.UploadAttachments(c)
+	})
 
 	req, _ := createMultipartRequest(
 		"/achievements/"+targetID+"/attachments",
@@ -358,9 +423,6 @@ func TestAchievement_UploadAttachments_NoFileUploaded(t *testing.T) {
 }
 
 func TestAchievement_UploadAttachments_UpdateError(t *testing.T) {
-	_ = os.MkdirAll("./uploads", 0755)
-	defer os.RemoveAll("./uploads")
-
 	app := fiber.New()
 	oid := primitive.NewObjectID()
 	targetID := "mongo-id-123"
@@ -374,17 +436,27 @@ func TestAchievement_UploadAttachments_UpdateError(t *testing.T) {
 		},
 	}
 
-	svc := setupAchievementService(nil, nil, mockMongoRepo, nil)
-	app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+	root := t.TempDir()
+	studentRepo, pgRepo := ownerRepos(targetID)
+	svc := setupAchievementService(studentRepo, nil, mockMongoRepo, pgRepo)
+	svc.Storage = storage.NewLocal(root, "/files", []byte("secret"))
+	app.Post("/achievements/:id/attachments", func(c *fiber.Ctx) error {
+		asOwner(c)
+		return svc.UploadAttachments(c)
+	})
 
 	req, _ := createMultipartRequest(
 		"/achievements/"+targetID+"/attachments",
 		"files",
-		"data.txt",
-		[]byte("content"),
+		"data.png",
+		append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...),
 	)
 
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	// file yang terlanjur disimpan harus dibersihkan
+	stored, _ := os.ReadDir(filepath.Join(root, "achievements", oid.Hex()))
+	assert.Empty(t, stored)
 }
\ No newline at end of file
@@ -0,0 +1,168 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas/app/jobs"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type analyticsCall struct {
+	dimension string
+	filter    models.AnalyticsFilter
+}
+
+func analyticsRequest(t *testing.T, userID string, perms []string, url string, buckets []models.AnalyticsBucket) (int, map[string]any, *analyticsCall) {
+	students, lecturers, _ := policyRepos("verified")
+
+	var call *analyticsCall
+	analytics := &repo.AnalyticsMockRepo{
+		AggregateFn: func(ctx context.Context, dimension string, f models.AnalyticsFilter) ([]models.AnalyticsBucket, error) {
+			call = &analyticsCall{dimension: dimension, filter: f}
+			return buckets, nil
+		},
+	}
+	svc := services.NewAnalyticsService(analytics, students, lecturers)
+
+	app := fiber.New()
+	app.Get("/reports/analytics/:dimension", asUser(userID, perms), svc.Aggregate)
+
+	resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(body, &out))
+
+	data, _ := out["data"].(map[string]any)
+	return resp.StatusCode, data, call
+}
+
+func TestAnalytics_Semester(t *testing.T) {
+	status, data, call := analyticsRequest(t, "u5", adminPerms,
+		"/reports/analytics/semester?date_from=2024-08-01&date_to=2025-07-31&competition_level=National",
+		[]models.AnalyticsBucket{
+			{Key: "2024-1", Count: 3, Points: 120},
+			{Key: "2024-2", Count: 2, Points: 40},
+		})
+	require.Equal(t, fiber.StatusOK, status)
+	require.NotNil(t, call)
+
+	assert.Equal(t, "semester", call.dimension)
+	assert.Equal(t, "verified_at", call.filter.DateField)
+	assert.Equal(t, []string{"verified"}, call.filter.Statuses)
+	assert.Equal(t, "national", call.filter.CompetitionLevel)
+	assert.Empty(t, call.filter.StudentIDs)
+	assert.Empty(t, call.filter.AdvisorID)
+	require.NotNil(t, call.filter.From)
+	require.NotNil(t, call.filter.To)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), *call.filter.To)
+
+	buckets := data["buckets"].([]any)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, "2024/2025 Ganjil", buckets[0].(map[string]any)["label"])
+	assert.Equal(t, "2024/2025 Genap", buckets[1].(map[string]any)["label"])
+	assert.Equal(t, map[string]any{"count": float64(5), "points": float64(160)}, data["total"])
+}
+
+func TestAnalytics_Labels(t *testing.T) {
+	_, data, _ := analyticsRequest(t, "u5", adminPerms, "/reports/analytics/competition_level",
+		[]models.AnalyticsBucket{
+			{Key: "national", Count: 2},
+			{Key: "", Count: 1},
+		})
+
+	buckets := data["buckets"].([]any)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, "Nasional", buckets[0].(map[string]any)["label"])
+	assert.Equal(t, "Tidak diketahui", buckets[1].(map[string]any)["label"])
+}
+
+func TestAnalytics_Scope(t *testing.T) {
+	// mahasiswa hanya melihat prestasinya sendiri
+	status, _, call := analyticsRequest(t, "u1", studentPerms, "/reports/analytics/month?status=draft,verified", nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, []string{"s1"}, call.filter.StudentIDs)
+	assert.Equal(t, []string{"draft", "verified"}, call.filter.Statuses)
+
+	// dosen wali dibatasi pada mahasiswa bimbingan tanpa draft
+	status, _, call = analyticsRequest(t, "u3", lecturerPerms, "/reports/analytics/program_study?status=draft,submitted", nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "l1", call.filter.AdvisorID)
+	assert.Equal(t, []string{"submitted"}, call.filter.Statuses)
+
+	status, _, call = analyticsRequest(t, "u3", lecturerPerms, "/reports/analytics/program_study?status=draft", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Nil(t, call)
+
+	status, _, call = analyticsRequest(t, "u9", []string{}, "/reports/analytics/month", nil)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Nil(t, call)
+}
+
+func TestAnalytics_InvalidParams(t *testing.T) {
+	for _, url := range []string{
+		"/reports/analytics/student",
+		"/reports/analytics/month?date_field=updated_at",
+		"/reports/analytics/month?date_from=01-01-2025",
+		"/reports/analytics/month?status=unknown",
+	} {
+		status, _, call := analyticsRequest(t, "u5", adminPerms, url, nil)
+		assert.Equal(t, fiber.StatusBadRequest, status, url)
+		assert.Nil(t, call, url)
+	}
+}
+
+func TestJobs_SyncAchievementFacts(t *testing.T) {
+	id1 := primitive.NewObjectID()
+	id2 := primitive.NewObjectID()
+
+	synced := map[string]models.AchievementFacts{}
+	refs := &repo.AchievementReferenceMockRepo{
+		FindAllFn: func(ctx context.Context) ([]models.AchievementReference, error) {
+			return []models.AchievementReference{
+				{MongoAchievementID: id1.Hex(), Status: "verified"},
+				{MongoAchievementID: id2.Hex(), Status: "draft"},
+			}, nil
+		},
+		UpdateFactsFn: func(ctx context.Context, mongoID string, f models.AchievementFacts) error {
+			synced[mongoID] = f
+			return nil
+		},
+	}
+
+	var fields []string
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDsFn: func(ctx context.Context, ids []string, f ...string) (map[string]*models.AchievementMongo, error) {
+			fields = f
+			return map[string]*models.AchievementMongo{
+				id1.Hex(): {
+					ID:              id1,
+					AchievementType: "competition",
+					Details:         map[string]interface{}{"competition_level": " International "},
+					Points:          80,
+				},
+			}, nil
+		},
+	}
+
+	res, err := jobs.SyncAchievementFacts(context.Background(), refs, mongo)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.SyncFactsResult{Checked: 2, Updated: 1, Failed: 1}, res)
+	assert.ElementsMatch(t, []string{"achievementType", "details", "points"}, fields)
+	assert.Equal(t, map[string]models.AchievementFacts{
+		id1.Hex(): {AchievementType: "competition", CompetitionLevel: "international", Points: 80},
+	}, synced)
+}
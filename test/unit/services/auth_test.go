@@ -1,12 +1,16 @@
 package services_test
 
 import (
+	"context"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"uas/app/models"
+	"uas/app/repository"
 	"uas/app/services"
+	"uas/middleware"
 	"uas/test/unit/repo"
 	"uas/utils"
 
@@ -40,7 +44,7 @@ func TestAuth_Login_Success(t *testing.T) {
 		},
 	}
 
-	authService := services.NewAuthService(mockRepo)
+	authService := services.NewAuthService(mockRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
 	app.Post("/auth/login", authService.Login)
 
 	reqBody := `{
@@ -78,7 +82,7 @@ func TestAuth_Login_WrongPassword(t *testing.T) {
 		},
 	}
 
-	authService := services.NewAuthService(mockRepo)
+	authService := services.NewAuthService(mockRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
 	app.Post("/auth/login", authService.Login)
 
 	reqBody := `{
@@ -113,7 +117,7 @@ func TestAuth_Login_InactiveUser(t *testing.T) {
 		},
 	}
 
-	authService := services.NewAuthService(mockRepo)
+	authService := services.NewAuthService(mockRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
 	app.Post("/auth/login", authService.Login)
 
 	reqBody := `{
@@ -128,3 +132,168 @@ func TestAuth_Login_InactiveUser(t *testing.T) {
 
 	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
 }
+
+func setupRefreshSession(t *testing.T) (string, *models.RefreshClaims, *repo.SessionMockRepo, *repo.AuthMockRepo) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+	t.Setenv("JWT_EXPIRED", "1")
+	t.Setenv("JWT_REFRESH_EXPIRED", "24")
+
+	refreshToken, rc, err := utils.GenerateRefreshToken("user-1", "session-1")
+	require.NoError(t, err)
+
+	sessions := &repo.SessionMockRepo{
+		GetRefreshTokenFn: func(ctx context.Context, jti string) (*models.RefreshToken, error) {
+			return &models.RefreshToken{JTI: jti, SessionID: "session-1", ExpiresAt: rc.ExpiresAt.Time}, nil
+		},
+		GetByIDFn: func(ctx context.Context, id string) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: "user-1"}, nil
+		},
+	}
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByIDFn: func(userID string) (*models.UserWithRole, error) {
+			return &models.UserWithRole{ID: userID, RoleName: "Mahasiswa", IsActive: true}, nil
+		},
+		GetPermissionsByUserIDFn: func(string) ([]string, error) {
+			return []string{"achievement:read"}, nil
+		},
+	}
+
+	return refreshToken, rc, sessions, authRepo
+}
+
+func TestAuth_Refresh_RevokedToken(t *testing.T) {
+	refreshToken, rc, sessions, authRepo := setupRefreshSession(t)
+
+	app := fiber.New()
+
+	revocations := repository.NewMemoryRevocationRepo()
+	authService := services.NewAuthService(authRepo, revocations, sessions, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/refresh", authService.Refresh)
+
+	require.NoError(t, revocations.Revoke(context.Background(), rc.ID, "user-1", rc.ExpiresAt.Time))
+
+	req := httptest.NewRequest("POST", "/auth/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+refreshToken)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuth_Refresh_RotatesToken(t *testing.T) {
+	refreshToken, rc, sessions, authRepo := setupRefreshSession(t)
+
+	var rotatedFrom string
+	var next *models.RefreshToken
+	sessions.RotateFn = func(ctx context.Context, oldJTI string, n *models.RefreshToken) (bool, error) {
+		rotatedFrom = oldJTI
+		next = n
+		return true, nil
+	}
+
+	app := fiber.New()
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), sessions, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/refresh", authService.Refresh)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+refreshToken)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, rc.ID, rotatedFrom)
+	require.NotNil(t, next)
+	assert.NotEqual(t, rc.ID, next.JTI)
+	assert.Equal(t, "session-1", next.SessionID)
+}
+
+func TestAuth_Refresh_ReuseRevokesSession(t *testing.T) {
+	refreshToken, rc, sessions, authRepo := setupRefreshSession(t)
+
+	usedAt := time.Now().Add(-time.Minute)
+	sessions.GetRefreshTokenFn = func(ctx context.Context, jti string) (*models.RefreshToken, error) {
+		return &models.RefreshToken{JTI: jti, SessionID: "session-1", ExpiresAt: rc.ExpiresAt.Time, UsedAt: &usedAt}, nil
+	}
+
+	var revokedSession, revokeReason string
+	sessions.RevokeFn = func(ctx context.Context, id string, reason string) error {
+		revokedSession = id
+		revokeReason = reason
+		return nil
+	}
+	sessions.RotateFn = func(ctx context.Context, oldJTI string, n *models.RefreshToken) (bool, error) {
+		t.Fatal("refresh token yang sudah dipakai tidak boleh dirotasi")
+		return false, nil
+	}
+
+	app := fiber.New()
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), sessions, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/refresh", authService.Refresh)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+refreshToken)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "session-1", revokedSession)
+	assert.Equal(t, models.SessionRevokeReuse, revokeReason)
+}
+
+func TestAuth_Logout_RevokesAccessToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_EXPIRED", "1")
+
+	revocations := repository.NewMemoryRevocationRepo()
+	sessions := &repo.SessionMockRepo{}
+	middleware.InitAuth(revocations, sessions, nil)
+	defer middleware.InitAuth(nil, nil, nil)
+
+	authService := services.NewAuthService(&repo.AuthMockRepo{}, revocations, sessions, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+
+	app := fiber.New()
+	app.Post("/auth/logout", authService.Logout)
+	app.Get("/protected", middleware.AuthRequired(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	token, err := utils.GenerateToken("user-1", "Mahasiswa", "session-1", []string{"achievement:read"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuth_RevocationRepo_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	revocations := repository.NewMemoryRevocationRepo()
+
+	require.NoError(t, revocations.Revoke(ctx, "expired", "u1", time.Now().Add(-time.Minute)))
+	require.NoError(t, revocations.Revoke(ctx, "active", "u1", time.Now().Add(time.Hour)))
+
+	revoked, _ := revocations.IsRevoked(ctx, "expired")
+	assert.False(t, revoked)
+
+	n, err := revocations.PurgeExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	revoked, _ = revocations.IsRevoked(ctx, "active")
+	assert.True(t, revoked)
+}
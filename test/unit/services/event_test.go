@@ -0,0 +1,284 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/events"
+	"uas/app/models"
+	"uas/app/notify"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventAudience_Sees(t *testing.T) {
+	status := &models.Event{Type: events.TypeStatusChanged, StudentID: "s1", AdvisorID: "l1"}
+	notif := &models.Event{Type: events.TypeNotificationCreated, UserID: "u3"}
+
+	cases := []struct {
+		name     string
+		audience models.EventAudience
+		status   bool
+		notif    bool
+	}{
+		{"mahasiswa pemilik", models.EventAudience{UserID: "u1", StudentID: "s1"}, true, false},
+		{"mahasiswa lain", models.EventAudience{UserID: "u2", StudentID: "s2"}, false, false},
+		{"dosen wali", models.EventAudience{UserID: "u3", LecturerID: "l1"}, true, true},
+		{"dosen lain", models.EventAudience{UserID: "u4", LecturerID: "l2"}, false, false},
+		// notifikasi pribadi tidak ikut terlihat oleh admin
+		{"admin", models.EventAudience{UserID: "u5", All: true}, true, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, tc.audience.Sees(status))
+			assert.Equal(t, tc.notif, tc.audience.Sees(notif))
+		})
+	}
+}
+
+func TestEvents_StatusChanged_PrivateStatuses(t *testing.T) {
+	advisor := models.EventAudience{UserID: "u3", LecturerID: "l1"}
+	owner := models.EventAudience{UserID: "u1", StudentID: "s1"}
+
+	for _, tc := range []struct {
+		from, to   string
+		advisorSee bool
+	}{
+		{"", "draft", false},
+		{"draft", "deleted", false},
+		{"draft", "submitted", true},
+	} {
+		t.Run(tc.to, func(t *testing.T) {
+			ref := &models.AchievementReference{ID: "ref-1", StudentID: "s1", MongoAchievementID: "m1", Status: tc.to}
+			ev := events.StatusChanged(ref, tc.from, "l1")
+
+			assert.Equal(t, tc.advisorSee, advisor.Sees(ev))
+			assert.True(t, owner.Sees(ev))
+		})
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	b := events.NewMemoryBroker()
+	b.Buffer = 1
+
+	fast, stopFast := b.Subscribe()
+	defer stopFast()
+	slow, stopSlow := b.Subscribe()
+	defer stopSlow()
+
+	require.NoError(t, b.Publish(context.Background(), &models.Event{ID: 1}))
+	assert.Equal(t, int64(1), (<-fast).ID)
+
+	// slow belum membaca event 1 sehingga diputus saat event 2 datang
+	require.NoError(t, b.Publish(context.Background(), &models.Event{ID: 2}))
+	assert.Equal(t, int64(2), (<-fast).ID)
+
+	assert.Equal(t, int64(1), (<-slow).ID)
+	_, ok := <-slow
+	assert.False(t, ok)
+}
+
+// eventLog menyimpan event di memori dan memberi ID berurutan.
+func eventLog(log *[]models.Event) *repo.EventMockRepo {
+	return &repo.EventMockRepo{
+		AppendFn: func(ctx context.Context, ev *models.Event) error {
+			ev.ID = int64(len(*log) + 1)
+			*log = append(*log, *ev)
+			return nil
+		},
+	}
+}
+
+func TestAchievement_Submit_PublishesEvent(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+
+	var log []models.Event
+	broker := events.NewMemoryBroker()
+	live, stop := broker.Subscribe()
+	defer stop()
+
+	publisher := events.NewPublisher(eventLog(&log), broker)
+	notifications := notify.New(&repo.NotificationMockRepo{}, &repo.UserMockRepo{}, publisher)
+	lecturers.GetByIDFn = func(ctx context.Context, id string) (*models.Lecturer, error) {
+		return &models.Lecturer{ID: id, UserID: "u3"}, nil
+	}
+
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, notifications, publisher)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/submit", asUser("u1", studentPerms), svc.Submit)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/submit", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// perubahan status lalu notifikasi untuk dosen wali
+	require.Len(t, log, 2)
+	ev := <-live
+	assert.Equal(t, events.TypeStatusChanged, ev.Type)
+	assert.Equal(t, "s1", ev.StudentID)
+	assert.Equal(t, "l1", ev.AdvisorID)
+	assert.Equal(t, "draft", ev.Data["from_status"])
+	assert.Equal(t, "submitted", ev.Data["status"])
+
+	ev = <-live
+	assert.Equal(t, events.TypeNotificationCreated, ev.Type)
+	assert.Equal(t, "u3", ev.UserID)
+}
+
+// readFrame membaca satu frame SSE dan mengembalikan field-fieldnya.
+func readFrame(t *testing.T, r *bufio.Reader) map[string]string {
+	frame := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(frame) > 0 {
+				return frame
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		frame[key] = value
+	}
+}
+
+func TestEvent_Stream(t *testing.T) {
+	students, lecturers, _ := policyRepos("submitted")
+
+	var replayedFor models.EventAudience
+	var replayedAfter int64
+	eventRepo := &repo.EventMockRepo{
+		ReplayFn: func(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error) {
+			replayedFor, replayedAfter = a, afterID
+			return []models.Event{
+				{ID: 6, Type: events.TypeStatusChanged, StudentID: "s1", AdvisorID: "l1", Data: map[string]any{"status": "submitted"}},
+			}, nil
+		},
+	}
+	broker := events.NewMemoryBroker()
+
+	svc := services.NewEventService(eventRepo, broker, students, lecturers)
+	svc.Heartbeat = 50 * time.Millisecond
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/events/stream", asUser("u3", lecturerPerms), svc.Stream)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	defer app.ShutdownWithTimeout(time.Second)
+
+	req, err := http.NewRequest("GET", "http://"+ln.Addr().String()+"/events/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, models.EventAudience{UserID: "u3", LecturerID: "l1"}, replayedFor)
+	assert.Equal(t, int64(5), replayedAfter)
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "3000", readFrame(t, r)["retry"])
+
+	frame := readFrame(t, r)
+	assert.Equal(t, "6", frame["id"])
+	assert.Equal(t, events.TypeStatusChanged, frame["event"])
+	assert.Contains(t, frame["data"], `"status":"submitted"`)
+
+	ctx := context.Background()
+	// sudah dikirim dari log, bukan untuk dosen ini, lalu notifikasi miliknya
+	require.NoError(t, broker.Publish(ctx, &models.Event{ID: 6, Type: events.TypeStatusChanged, StudentID: "s1", AdvisorID: "l1"}))
+	require.NoError(t, broker.Publish(ctx, &models.Event{ID: 7, Type: events.TypeStatusChanged, StudentID: "s2", AdvisorID: "l2"}))
+	require.NoError(t, broker.Publish(ctx, &models.Event{ID: 8, Type: events.TypeNotificationCreated, UserID: "u3"}))
+
+	frame = readFrame(t, r)
+	assert.Equal(t, "8", frame["id"])
+	assert.Equal(t, events.TypeNotificationCreated, frame["event"])
+}
+
+func TestEvent_Stream_ReplaysInBatches(t *testing.T) {
+	students, lecturers, _ := policyRepos("submitted")
+
+	var log []models.Event
+	for id := int64(1); id <= 5; id++ {
+		log = append(log, models.Event{ID: id, Type: events.TypeNotificationCreated, UserID: "u3"})
+	}
+	limits := make(chan int, 10)
+	eventRepo := &repo.EventMockRepo{
+		ReplayFn: func(ctx context.Context, a models.EventAudience, afterID int64, limit int) ([]models.Event, error) {
+			limits <- limit
+			out := []models.Event{}
+			for _, ev := range log {
+				if ev.ID > afterID && len(out) < limit {
+					out = append(out, ev)
+				}
+			}
+			return out, nil
+		},
+	}
+
+	svc := services.NewEventService(eventRepo, events.NewMemoryBroker(), students, lecturers)
+	svc.Heartbeat = 50 * time.Millisecond
+	svc.ReplayBatch = 2
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/events/stream", asUser("u3", lecturerPerms), svc.Stream)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	defer app.ShutdownWithTimeout(time.Second)
+
+	req, err := http.NewRequest("GET", "http://"+ln.Addr().String()+"/events/stream?last_event_id=0", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "3000", readFrame(t, r)["retry"])
+	for _, want := range []string{"1", "2", "3", "4", "5"} {
+		assert.Equal(t, want, readFrame(t, r)["id"])
+	}
+
+	// 2 + 2 + 1: batch terakhir yang tidak penuh mengakhiri replay
+	assert.Len(t, limits, 3)
+}
+
+func TestEvent_Stream_InvalidLastEventID(t *testing.T) {
+	students, lecturers, _ := policyRepos("submitted")
+	svc := services.NewEventService(&repo.EventMockRepo{}, events.NewMemoryBroker(), students, lecturers)
+
+	app := fiber.New()
+	app.Get("/events/stream", asUser("u1", studentPerms), svc.Stream)
+
+	req := httptest.NewRequest("GET", "/events/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
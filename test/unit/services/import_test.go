@@ -0,0 +1,366 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas/app/export"
+	"uas/app/importer"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/test/unit/repo"
+	"uas/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importHeader = "username,email,full_name,nim,program_study,academic_year,advisor_lecturer_id\n"
+
+func TestImporter_ReadTable_CSV(t *testing.T) {
+	data := []byte("\xef\xbb\xbfUsername;Email;Nama Lengkap;NIM;Prodi;Angkatan;Dosen Wali\n" +
+		"budi;budi@kampus.ac.id;Budi Santoso;210101001;Informatika;2021;DSN-001\n" +
+		";;;;;;\n")
+
+	table, err := importer.ReadTable("mahasiswa.csv", data)
+	require.NoError(t, err)
+
+	rows, err := importer.ParseStudents(table)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, importer.StudentRow{
+		Line:         2,
+		Username:     "budi",
+		Email:        "budi@kampus.ac.id",
+		FullName:     "Budi Santoso",
+		NIM:          "210101001",
+		ProgramStudy: "Informatika",
+		AcademicYear: "2021",
+		Advisor:      "DSN-001",
+	}, rows[0])
+}
+
+func TestImporter_ReadTable_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.XLSX, &buf, export.Document{Title: "Mahasiswa", GeneratedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, w.Table("Mahasiswa", []string{"username", "email", "full_name", "nim", "academic_year"}))
+	require.NoError(t, w.Row("budi", "budi@kampus.ac.id", "Budi & Ani", 210101001, 2021))
+	require.NoError(t, w.Close())
+
+	// tanpa ekstensi, format dikenali dari isi berkas
+	table, err := importer.ReadTable("upload", buf.Bytes())
+	require.NoError(t, err)
+
+	rows, err := importer.ParseStudents(table)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Budi & Ani", rows[0].FullName)
+	assert.Equal(t, "210101001", rows[0].NIM)
+	assert.Equal(t, "2021", rows[0].AcademicYear)
+}
+
+func TestImporter_ParseStudents_MissingColumns(t *testing.T) {
+	_, err := importer.ParseStudents([][]string{{"username", "email"}})
+
+	var headerErr *importer.HeaderError
+	require.ErrorAs(t, err, &headerErr)
+	assert.Equal(t, []string{"full_name", "nim"}, headerErr.Missing)
+
+	_, err = importer.ReadTable("mahasiswa.xls", []byte("bukan xlsx"))
+	assert.ErrorIs(t, err, importer.ErrUnsupportedFormat)
+}
+
+// importRepos menyiapkan repo dengan data yang sudah terdaftar: username
+// "lama", email "lama@kampus.ac.id", NIM 200101001 dan dosen DSN-001 (l1).
+func importRepos() (*repo.UserMockRepo, *repo.StudentMockRepo, *repo.LecturerMockRepo, *repo.RoleMockRepo) {
+	users := &repo.UserMockRepo{
+		FindTakenFn: func(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error) {
+			return map[string]bool{"lama": true}, map[string]bool{"lama@kampus.ac.id": true}, nil
+		},
+	}
+	students := &repo.StudentMockRepo{
+		FindTakenStudentIDsFn: func(ctx context.Context, ids []string) (map[string]bool, error) {
+			return map[string]bool{"200101001": true}, nil
+		},
+	}
+	lecturers := &repo.LecturerMockRepo{
+		FindAllFn: func(ctx context.Context) ([]models.Lecturer, error) {
+			return []models.Lecturer{{ID: "l1", LecturerID: "DSN-001"}}, nil
+		},
+	}
+	roles := &repo.RoleMockRepo{
+		FindAllFn: func(ctx context.Context) ([]models.Role, error) {
+			return []models.Role{
+				{ID: "r-admin", Name: "Admin", ProfileType: models.ProfileNone},
+				{ID: "r-mhs", Name: "Mahasiswa", ProfileType: models.ProfileStudent},
+			}, nil
+		},
+	}
+	return users, students, lecturers, roles
+}
+
+func importRequest(t *testing.T, svc *services.UserService, csv string, fields map[string]string) (int, models.MetaInfo, models.ImportReport) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "mahasiswa.csv")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(csv))
+	require.NoError(t, err)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+	require.NoError(t, mw.Close())
+
+	app := fiber.New()
+	app.Post("/users/import", svc.Import)
+
+	req := httptest.NewRequest("POST", "/users/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := app.Test(req, 30000)
+	require.NoError(t, err)
+
+	var meta models.MetaInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&meta))
+
+	// laporan ada di data, atau di errors saat import dibatalkan
+	var report models.ImportReport
+	payload := meta.Data
+	if payload == nil {
+		payload = meta.Errors
+	}
+	raw, _ := json.Marshal(payload)
+	_ = json.Unmarshal(raw, &report)
+
+	return resp.StatusCode, meta, report
+}
+
+func TestUser_Import_DryRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	users, students, lecturers, roles := importRepos()
+	users.CreateFn = func(tx *sql.Tx, user *models.Users) (string, error) {
+		t.Fatal("dry-run tidak boleh menyimpan")
+		return "", nil
+	}
+	svc := services.NewUserService(db, users, students, lecturers, roles)
+
+	csv := importHeader +
+		"budi,budi@kampus.ac.id,Budi,210101001,Informatika,2021,DSN-001\n" +
+		"ani,bukan-email,Ani,210101002,Informatika,21,DSN-009\n" +
+		"lama,Lama@Kampus.ac.id,Lama,200101001,,,\n" +
+		"budi,budi2@kampus.ac.id,Budi Dua,210101001,,,\n" +
+		"cici,cici@kampus.ac.id,,NIM-1,,,\n"
+
+	status, _, report := importRequest(t, svc, csv, map[string]string{"dry_run": "true"})
+	require.Equal(t, fiber.StatusOK, status)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, models.ImportAllOrNothing, report.Mode)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 4, report.Invalid)
+	assert.Equal(t, 0, report.Created)
+
+	rows := report.Rows
+	assert.Equal(t, models.ImportRowValid, rows[0].Status)
+	assert.Equal(t, 2, rows[0].Row)
+
+	assert.Equal(t, models.ImportRowInvalid, rows[1].Status)
+	assert.Equal(t, 3, rows[1].Row)
+	assert.Contains(t, rows[1].Errors, "email")
+	assert.Contains(t, rows[1].Errors, "academic_year")
+	assert.Equal(t, "dosen wali DSN-009 tidak ditemukan", rows[1].Errors["advisor_lecturer_id"])
+
+	assert.Equal(t, map[string]string{
+		"username": "sudah terdaftar",
+		"email":    "sudah terdaftar",
+		"nim":      "sudah terdaftar",
+	}, rows[2].Errors)
+
+	assert.Equal(t, map[string]string{
+		"username": "sama dengan baris 2",
+		"nim":      "sama dengan baris 2",
+	}, rows[3].Errors)
+
+	assert.Equal(t, "wajib diisi", rows[4].Errors["full_name"])
+	assert.Equal(t, "harus 5-20 digit angka", rows[4].Errors["nim"])
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_Import_AllOrNothing(t *testing.T) {
+	t.Run("dibatalkan jika ada baris tidak valid", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		users, students, lecturers, roles := importRepos()
+		created := 0
+		users.CreateFn = func(tx *sql.Tx, user *models.Users) (string, error) {
+			created++
+			return "u-new", nil
+		}
+		svc := services.NewUserService(db, users, students, lecturers, roles)
+
+		csv := importHeader +
+			"budi,budi@kampus.ac.id,Budi,210101001,,,\n" +
+			"ani,ani@kampus.ac.id,Ani,123,,,\n"
+
+		status, _, report := importRequest(t, svc, csv, nil)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, 0, created)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Equal(t, models.ImportRowValid, report.Rows[0].Status)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("semua baris dalam satu transaksi", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		users, students, lecturers, roles := importRepos()
+		var createdUsers []models.Users
+		users.CreateFn = func(tx *sql.Tx, user *models.Users) (string, error) {
+			require.NotNil(t, tx)
+			createdUsers = append(createdUsers, *user)
+			return "u-" + user.Username, nil
+		}
+		var profiles []models.Student
+		students.CreateProfileFn = func(tx *sql.Tx, userID string, s *models.Student) error {
+			s.ID = "s-" + userID
+			s.UserID = userID
+			profiles = append(profiles, *s)
+			return nil
+		}
+		svc := services.NewUserService(db, users, students, lecturers, roles)
+
+		csv := "username,email,full_name,nim,program_study,academic_year,advisor_lecturer_id,password\n" +
+			"budi,budi@kampus.ac.id,Budi,210101001,Informatika,2021,DSN-001,rahasia123\n" +
+			"ani,ani@kampus.ac.id,Ani,210101002,,,,\n"
+
+		status, _, report := importRequest(t, svc, csv, map[string]string{"mode": "all_or_nothing"})
+		require.Equal(t, fiber.StatusCreated, status)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, "u-budi", report.Rows[0].UserID)
+		assert.Equal(t, "s-u-budi", report.Rows[0].StudentID)
+
+		require.Len(t, createdUsers, 2)
+		assert.Equal(t, "r-mhs", createdUsers[0].RoleID)
+		assert.True(t, utils.CheckPassword("rahasia123", createdUsers[0].PasswordHash))
+		// tanpa password akun belum dapat login sampai password diatur
+		assert.Equal(t, utils.UnusablePasswordHash, createdUsers[1].PasswordHash)
+		assert.False(t, utils.CheckPassword("", createdUsers[1].PasswordHash))
+
+		require.Len(t, profiles, 2)
+		assert.Equal(t, "210101001", profiles[0].StudentID)
+		assert.Equal(t, "Informatika", *profiles[0].ProgramStudy)
+		assert.Equal(t, "2021", *profiles[0].AcademicYear)
+		assert.Equal(t, "l1", *profiles[0].AdvisorID)
+		assert.Nil(t, profiles[1].AdvisorID)
+		assert.Nil(t, profiles[1].ProgramStudy)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback jika satu baris gagal disimpan", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		users, students, lecturers, roles := importRepos()
+		users.CreateFn = func(tx *sql.Tx, user *models.Users) (string, error) {
+			if user.Username == "ani" {
+				return "", &pq.Error{Code: "23505"}
+			}
+			return "u-" + user.Username, nil
+		}
+		svc := services.NewUserService(db, users, students, lecturers, roles)
+
+		csv := importHeader +
+			"budi,budi@kampus.ac.id,Budi,210101001,,,\n" +
+			"ani,ani@kampus.ac.id,Ani,210101002,,,\n"
+
+		status, _, report := importRequest(t, svc, csv, nil)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, models.ImportRowValid, report.Rows[0].Status)
+		assert.Empty(t, report.Rows[0].UserID)
+		assert.Equal(t, models.ImportRowFailed, report.Rows[1].Status)
+		assert.Contains(t, report.Rows[1].Errors["row"], "sudah terdaftar")
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_Import_BestEffort(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	users, students, lecturers, roles := importRepos()
+	users.CreateFn = func(tx *sql.Tx, user *models.Users) (string, error) {
+		return "u-" + user.Username, nil
+	}
+	students.CreateProfileFn = func(tx *sql.Tx, userID string, s *models.Student) error {
+		if userID == "u-ani" {
+			return errors.New("koneksi terputus")
+		}
+		s.ID = "s-" + userID
+		return nil
+	}
+	svc := services.NewUserService(db, users, students, lecturers, roles)
+
+	csv := importHeader +
+		"budi,budi@kampus.ac.id,Budi,210101001,,,\n" +
+		"lama,lama2@kampus.ac.id,Lama,210101003,,,\n" +
+		"ani,ani@kampus.ac.id,Ani,210101002,,,\n"
+
+	status, _, report := importRequest(t, svc, csv, map[string]string{"mode": "best_effort"})
+	require.Equal(t, fiber.StatusCreated, status)
+
+	assert.Equal(t, models.ImportBestEffort, report.Mode)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, models.ImportRowCreated, report.Rows[0].Status)
+	assert.Equal(t, models.ImportRowInvalid, report.Rows[1].Status)
+	assert.Equal(t, models.ImportRowFailed, report.Rows[2].Status)
+	assert.Equal(t, "gagal disimpan", report.Rows[2].Errors["row"])
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_Import_InvalidRequest(t *testing.T) {
+	users, students, lecturers, roles := importRepos()
+	svc := services.NewUserService(nil, users, students, lecturers, roles)
+
+	status, meta, _ := importRequest(t, svc, "username,email\nbudi,budi@kampus.ac.id\n", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Contains(t, meta.Errors, "file")
+
+	status, _, _ = importRequest(t, svc, importHeader+"budi,budi@kampus.ac.id,Budi,210101001,,,\n", map[string]string{"mode": "sebagian"})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
@@ -0,0 +1,219 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/test/unit/repo"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoginApp(authRepo *repo.AuthMockRepo, attempts *repo.LoginAttemptMockRepo) *fiber.App {
+	app := fiber.New()
+
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, attempts, &repo.MFAMockRepo{})
+	app.Post("/auth/login", authService.Login)
+
+	return app
+}
+
+func doLogin(t *testing.T, app *fiber.App, email, password string) (int, models.MetaInfo, string) {
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+
+	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var meta models.MetaInfo
+	json.NewDecoder(resp.Body).Decode(&meta)
+
+	return resp.StatusCode, meta, resp.Header.Get(fiber.HeaderRetryAfter)
+}
+
+func TestAuth_Login_UniformErrorMessage(t *testing.T) {
+	hashed, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			if email != "panji@test.com" {
+				return nil, errors.New("not found")
+			}
+			return &models.UserWithRole{ID: "user-1", Email: email, PasswordHash: hashed, IsActive: true}, nil
+		},
+	}
+
+	app := newLoginApp(authRepo, &repo.LoginAttemptMockRepo{})
+
+	code1, unknown, _ := doLogin(t, app, "siapa@test.com", "password123")
+	code2, wrong, _ := doLogin(t, app, "panji@test.com", "salah")
+
+	assert.Equal(t, fiber.StatusUnauthorized, code1)
+	assert.Equal(t, fiber.StatusUnauthorized, code2)
+	assert.Equal(t, "Email atau password salah", unknown.Message)
+	assert.Equal(t, unknown.Message, wrong.Message)
+}
+
+func TestAuth_Login_UnknownEmailStillComparesHash(t *testing.T) {
+	hashed, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			if email != "panji@test.com" {
+				return nil, errors.New("not found")
+			}
+			return &models.UserWithRole{ID: "user-1", Email: email, PasswordHash: hashed, IsActive: true}, nil
+		},
+	}
+	app := newLoginApp(authRepo, &repo.LoginAttemptMockRepo{})
+
+	start := time.Now()
+	doLogin(t, app, "panji@test.com", "salah")
+	known := time.Since(start)
+
+	start = time.Now()
+	doLogin(t, app, "siapa@test.com", "salah")
+	unknown := time.Since(start)
+
+	// email yang tidak terdaftar tetap melewati perbandingan bcrypt
+	assert.Greater(t, unknown, known/4)
+}
+
+func TestAuth_Login_InactiveAccountHiddenUntilPasswordMatches(t *testing.T) {
+	hashed, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			return &models.UserWithRole{ID: "user-1", Email: email, PasswordHash: hashed, IsActive: false}, nil
+		},
+	}
+	app := newLoginApp(authRepo, &repo.LoginAttemptMockRepo{})
+
+	code, meta, _ := doLogin(t, app, "panji@test.com", "salah")
+	assert.Equal(t, fiber.StatusUnauthorized, code)
+	assert.Equal(t, "Email atau password salah", meta.Message)
+
+	code, _, _ = doLogin(t, app, "panji@test.com", "password123")
+	assert.Equal(t, fiber.StatusForbidden, code)
+}
+
+func TestAuth_Login_FailureLocksAtThreshold(t *testing.T) {
+	t.Setenv("LOGIN_LOCKOUT_THRESHOLD", "5")
+
+	locked := map[string]bool{}
+	attempts := &repo.LoginAttemptMockRepo{
+		RegisterFailureFn: func(ctx context.Context, scope, identifier string, window time.Duration) (*models.LoginAttempt, error) {
+			if scope == models.LoginScopeAccount {
+				return &models.LoginAttempt{ID: "acc-1", Scope: scope, Identifier: identifier, FailedCount: 5}, nil
+			}
+			return &models.LoginAttempt{ID: "ip-1", Scope: scope, Identifier: identifier, FailedCount: 5}, nil
+		},
+		LockFn: func(ctx context.Context, id string, until time.Time) error {
+			locked[id] = true
+			return nil
+		},
+	}
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(string) (*models.UserWithRole, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	code, _, _ := doLogin(t, newLoginApp(authRepo, attempts), "Panji@Test.com", "salah")
+
+	assert.Equal(t, fiber.StatusUnauthorized, code)
+	assert.True(t, locked["acc-1"])
+	assert.False(t, locked["ip-1"], "IP threshold jauh lebih tinggi dari akun")
+}
+
+func TestAuth_Login_LockedAccountRejected(t *testing.T) {
+	until := time.Now().Add(10 * time.Minute)
+	attempts := &repo.LoginAttemptMockRepo{
+		GetFn: func(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error) {
+			if scope == models.LoginScopeAccount && identifier == "panji@test.com" {
+				return &models.LoginAttempt{ID: "acc-1", FailedCount: 10, LockedUntil: &until}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	called := false
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(string) (*models.UserWithRole, error) {
+			called = true
+			return nil, errors.New("not found")
+		},
+	}
+
+	code, _, retryAfter := doLogin(t, newLoginApp(authRepo, attempts), "panji@test.com", "password123")
+
+	assert.Equal(t, fiber.StatusTooManyRequests, code)
+	assert.NotEmpty(t, retryAfter)
+	assert.False(t, called, "kredensial tidak boleh diperiksa saat akun terkunci")
+}
+
+func TestAuth_Login_ProgressiveDelay(t *testing.T) {
+	t.Setenv("LOGIN_DELAY_AFTER", "3")
+
+	last := time.Now()
+	attempts := &repo.LoginAttemptMockRepo{
+		GetFn: func(ctx context.Context, scope, identifier string) (*models.LoginAttempt, error) {
+			if scope == models.LoginScopeIP {
+				return &models.LoginAttempt{ID: "ip-1", FailedCount: 5, LastFailedAt: &last}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	code, _, retryAfter := doLogin(t, newLoginApp(&repo.AuthMockRepo{}, attempts), "panji@test.com", "password123")
+
+	assert.Equal(t, fiber.StatusTooManyRequests, code)
+	assert.Equal(t, "4", retryAfter)
+}
+
+func TestAuth_Login_SuccessResetsCounter(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+
+	hashed, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	var reset string
+	attempts := &repo.LoginAttemptMockRepo{
+		ResetFn: func(ctx context.Context, scope, identifier string) error {
+			reset = scope + ":" + identifier
+			return nil
+		},
+	}
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			return &models.UserWithRole{ID: "user-1", Email: email, PasswordHash: hashed, RoleName: "Mahasiswa", IsActive: true}, nil
+		},
+		GetPermissionsByUserIDFn: func(string) ([]string, error) {
+			return []string{"achievement:read"}, nil
+		},
+	}
+
+	code, _, _ := doLogin(t, newLoginApp(authRepo, attempts), "panji@test.com", "password123")
+
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "account:panji@test.com", reset)
+}
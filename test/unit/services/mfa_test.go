@@ -0,0 +1,259 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/test/unit/repo"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secret RFC 6238 ("12345678901234567890") dalam base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range cases {
+		got, err := utils.TOTPCode(rfcSecret, utils.TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "t=%d", unix)
+	}
+
+	step, ok := utils.ValidateTOTP(rfcSecret, "287082", time.Unix(59+30, 0))
+	assert.True(t, ok, "step sebelumnya masih diterima")
+	assert.Equal(t, int64(1), step)
+
+	_, ok = utils.ValidateTOTP(rfcSecret, "287082", time.Unix(59+90, 0))
+	assert.False(t, ok)
+}
+
+type mfaFixture struct {
+	app      *fiber.App
+	mfa      *repo.MFAMockRepo
+	sessions *repo.SessionMockRepo
+}
+
+func newMFAFixture(t *testing.T, mfaRequired bool, mfa *models.UserMFA) *mfaFixture {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+
+	hashed, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	user := &models.UserWithRole{
+		ID:           "user-1",
+		Email:        "dosen@test.com",
+		PasswordHash: hashed,
+		RoleName:     "Dosen Wali",
+		IsActive:     true,
+		MFARequired:  mfaRequired,
+	}
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(string) (*models.UserWithRole, error) { return user, nil },
+		GetUserByIDFn:    func(string) (*models.UserWithRole, error) { return user, nil },
+		GetPermissionsByUserIDFn: func(string) ([]string, error) {
+			return []string{"achievement:verify"}, nil
+		},
+	}
+
+	mfaRepo := &repo.MFAMockRepo{
+		GetFn: func(ctx context.Context, userID string) (*models.UserMFA, error) {
+			return mfa, nil
+		},
+	}
+
+	sessions := &repo.SessionMockRepo{}
+
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), sessions, &repo.PasswordResetMockRepo{}, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, mfaRepo)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	app.Post("/auth/mfa/verify", authService.VerifyMFA)
+	app.Post("/auth/mfa/enroll", authService.EnrollMFA)
+	app.Post("/auth/mfa/enroll/confirm", authService.ConfirmEnrollMFA)
+
+	return &mfaFixture{app: app, mfa: mfaRepo, sessions: sessions}
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body any) (int, map[string]any) {
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", path, strings.NewReader(string(raw)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var out map[string]any
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	return resp.StatusCode, out
+}
+
+func challengeFrom(t *testing.T, out map[string]any) (string, bool) {
+	data, ok := out["data"].(map[string]any)
+	require.True(t, ok)
+
+	token, _ := data["challenge_token"].(string)
+	enroll, _ := data["enrollment_required"].(bool)
+	return token, enroll
+}
+
+func TestMFA_LoginReturnsChallenge(t *testing.T) {
+	f := newMFAFixture(t, false, &models.UserMFA{UserID: "user-1", Secret: rfcSecret, Enabled: true})
+
+	code, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+
+	assert.Equal(t, fiber.StatusOK, code)
+	token, enroll := challengeFrom(t, out)
+	assert.NotEmpty(t, token)
+	assert.False(t, enroll)
+
+	claims, err := utils.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Empty(t, claims.SessionID, "challenge token tidak boleh dipakai sebagai access token")
+}
+
+func TestMFA_VerifyIssuesTokensOnce(t *testing.T) {
+	f := newMFAFixture(t, false, &models.UserMFA{UserID: "user-1", Secret: rfcSecret, Enabled: true})
+
+	_, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+	challenge, _ := challengeFrom(t, out)
+
+	totp, err := utils.TOTPCode(rfcSecret, utils.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	code, out := postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, Code: totp})
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.NotEmpty(t, out["data"].(map[string]any)["token"])
+
+	code, _ = postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, Code: totp})
+	assert.Equal(t, fiber.StatusUnauthorized, code, "challenge token hanya sekali pakai")
+}
+
+func TestMFA_VerifyRejectsWrongAndReplayedCode(t *testing.T) {
+	f := newMFAFixture(t, false, &models.UserMFA{UserID: "user-1", Secret: rfcSecret, Enabled: true})
+
+	_, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+	challenge, _ := challengeFrom(t, out)
+
+	code, _ := postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, Code: "000000"})
+	assert.Equal(t, fiber.StatusUnauthorized, code)
+
+	f.mfa.MarkStepUsedFn = func(ctx context.Context, userID string, step int64) (bool, error) {
+		return false, nil
+	}
+	totp, _ := utils.TOTPCode(rfcSecret, utils.TOTPStep(time.Now()))
+
+	code, _ = postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, Code: totp})
+	assert.Equal(t, fiber.StatusUnauthorized, code)
+}
+
+func TestMFA_VerifyWithRecoveryCode(t *testing.T) {
+	f := newMFAFixture(t, false, &models.UserMFA{UserID: "user-1", Secret: rfcSecret, Enabled: true})
+
+	var usedHash string
+	f.mfa.UseRecoveryCodeFn = func(ctx context.Context, userID string, codeHash string) (bool, error) {
+		usedHash = codeHash
+		return true, nil
+	}
+
+	_, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+	challenge, _ := challengeFrom(t, out)
+
+	code, _ := postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, RecoveryCode: " ABCDE-12345 "})
+
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, utils.HashToken("abcde12345"), usedHash)
+}
+
+func TestMFA_VerifyWithRecoveryCode_Formats(t *testing.T) {
+	stored := map[string]bool{
+		utils.HashToken("abcde12345"):  true,
+		utils.HashToken("fghij-67890"): true, // dibuat sebelum normalisasi tanda hubung
+	}
+
+	for _, input := range []string{"abcde12345", "ABCDE-12345", "abcde 12345", "fghij-67890", "FGHIJ67890"} {
+		t.Run(input, func(t *testing.T) {
+			f := newMFAFixture(t, false, &models.UserMFA{UserID: "user-1", Secret: rfcSecret, Enabled: true})
+			f.mfa.UseRecoveryCodeFn = func(ctx context.Context, userID string, codeHash string) (bool, error) {
+				return stored[codeHash], nil
+			}
+
+			_, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+			challenge, _ := challengeFrom(t, out)
+
+			code, _ := postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, RecoveryCode: input})
+			assert.Equal(t, fiber.StatusOK, code)
+		})
+	}
+}
+
+func TestMFA_RequiredRoleMustEnroll(t *testing.T) {
+	pending := &models.UserMFA{UserID: "user-1"}
+	f := newMFAFixture(t, true, nil)
+
+	_, out := postJSON(t, f.app, "/auth/login", map[string]string{"email": "dosen@test.com", "password": "password123"})
+	challenge, enroll := challengeFrom(t, out)
+	require.True(t, enroll)
+
+	code, _ := postJSON(t, f.app, "/auth/mfa/verify", models.MFAVerifyReq{ChallengeToken: challenge, Code: "123456"})
+	assert.Equal(t, fiber.StatusUnauthorized, code, "challenge enrollment tidak bisa dipakai untuk verify")
+
+	f.mfa.SaveSecretFn = func(ctx context.Context, userID string, secret string) error {
+		pending.Secret = secret
+		return nil
+	}
+	f.mfa.GetFn = func(ctx context.Context, userID string) (*models.UserMFA, error) {
+		if pending.Secret == "" {
+			return nil, nil
+		}
+		return pending, nil
+	}
+
+	var hashes []string
+	f.mfa.EnableFn = func(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+		hashes = recoveryHashes
+		return nil
+	}
+
+	code, out = postJSON(t, f.app, "/auth/mfa/enroll", models.MFAEnrollReq{ChallengeToken: challenge})
+	require.Equal(t, fiber.StatusOK, code)
+	uri := out["data"].(map[string]any)["provisioning_uri"].(string)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/"))
+
+	totp, err := utils.TOTPCode(pending.Secret, utils.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	code, out = postJSON(t, f.app, "/auth/mfa/enroll/confirm", models.MFAEnrollReq{ChallengeToken: challenge, Code: totp})
+	require.Equal(t, fiber.StatusOK, code)
+
+	data := out["data"].(map[string]any)
+	assert.NotEmpty(t, data["token"])
+	assert.Len(t, data["recovery_codes"], 10)
+	require.Len(t, hashes, 10)
+
+	// hash disimpan dari bentuk normal, tanpa tanda hubung
+	first := data["recovery_codes"].([]any)[0].(string)
+	assert.Contains(t, first, "-")
+	assert.Equal(t, utils.HashToken(strings.ReplaceAll(first, "-", "")), hashes[0])
+}
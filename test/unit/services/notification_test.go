@@ -0,0 +1,297 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/notify"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notifier menyiapkan notifier dengan kanal email yang menampung pesan di
+// mailer; prefs adalah preferensi tersimpan setiap pengguna.
+func notifier(sent *[]models.Notification, mailer *repo.MailerMock, prefs map[string]map[string]bool) *notify.Notifier {
+	notifications := &repo.NotificationMockRepo{
+		CreateFn: func(ctx context.Context, n *models.Notification) error {
+			*sent = append(*sent, *n)
+			return nil
+		},
+		PreferencesFn: func(ctx context.Context, userID string) (map[string]bool, error) {
+			return prefs[userID], nil
+		},
+	}
+	users := &repo.UserMockRepo{
+		GetByIDFn: func(id string) (*models.UserWithRole, error) {
+			return &models.UserWithRole{ID: id, FullName: "Pengguna " + id, Email: id + "@kampus.ac.id"}, nil
+		},
+	}
+	return notify.New(notifications, users, nil, &notify.EmailChannel{Mailer: mailer})
+}
+
+// deliverQueued menjalankan pekerjaan worker notifikasi untuk seluruh pesan
+// yang sudah diantrekan.
+func deliverQueued(n *notify.Notifier) {
+	for {
+		select {
+		case msg := <-n.Queue():
+			n.Deliver(context.Background(), msg)
+		default:
+			return
+		}
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	var sent []models.Notification
+	mailer := &repo.MailerMock{}
+	n := notifier(&sent, mailer, map[string]map[string]bool{"u2": {"email": false}})
+
+	n.Send(context.Background(), &models.Notification{UserID: "u1", Type: notify.TypeAchievementVerified, Title: "Prestasi terverifikasi", Body: "isi"})
+	n.Send(context.Background(), &models.Notification{UserID: "u2", Type: notify.TypeAchievementVerified, Title: "Prestasi terverifikasi", Body: "isi"})
+
+	// keduanya tersimpan di kotak masuk; email dikirim worker, bukan Send
+	assert.Len(t, sent, 2)
+	assert.Empty(t, mailer.Sent)
+
+	// email hanya untuk yang tidak mematikannya
+	deliverQueued(n)
+	require.Len(t, mailer.Sent, 1)
+	assert.Equal(t, "u1@kampus.ac.id", mailer.Sent[0].To)
+	assert.Equal(t, "Prestasi terverifikasi", mailer.Sent[0].Subject)
+	assert.Contains(t, mailer.Sent[0].Body, "Halo Pengguna u1")
+
+	// notifier nil tidak melakukan apa pun
+	var none *notify.Notifier
+	none.Send(context.Background(), &models.Notification{UserID: "u1"})
+}
+
+func TestNotifier_Send_StoreFailure(t *testing.T) {
+	mailer := &repo.MailerMock{}
+	n := notify.New(&repo.NotificationMockRepo{
+		CreateFn: func(ctx context.Context, n *models.Notification) error {
+			return errors.New("db down")
+		},
+	}, &repo.UserMockRepo{}, nil, &notify.EmailChannel{Mailer: mailer})
+
+	n.Send(context.Background(), &models.Notification{UserID: "u1"})
+	assert.Empty(t, mailer.Sent)
+}
+
+func TestAchievement_Submit_NotifiesAdvisor(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+	lecturers.GetByIDFn = func(ctx context.Context, id string) (*models.Lecturer, error) {
+		return &models.Lecturer{ID: id, UserID: "u3"}, nil
+	}
+	refs.GetByMongoIDFn = func(ctx context.Context, id string) (*models.AchievementReference, error) {
+		return &models.AchievementReference{ID: "ref-1", StudentID: "s1", StudentName: "Siti", MongoAchievementID: id, Status: "draft"}, nil
+	}
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara 1 Gemastik"}, nil
+		},
+	}
+
+	var sent []models.Notification
+	mailer := &repo.MailerMock{}
+	n := notifier(&sent, mailer, nil)
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, n, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/submit", asUser("u1", studentPerms), svc.Submit)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/submit", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, "u3", sent[0].UserID)
+	assert.Equal(t, notify.TypeAchievementSubmitted, sent[0].Type)
+	assert.Equal(t, "m1", sent[0].Data["achievement_id"])
+	assert.Contains(t, sent[0].Body, `Siti mengajukan prestasi "Juara 1 Gemastik"`)
+
+	deliverQueued(n)
+	require.Len(t, mailer.Sent, 1)
+	assert.Equal(t, "u3@kampus.ac.id", mailer.Sent[0].To)
+}
+
+func TestAchievement_Reject_NotifiesStudent(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara"}, nil
+		},
+	}
+
+	var sent []models.Notification
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, notifier(&sent, &repo.MailerMock{}, nil), nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/reject", asUser("u3", lecturerPerms), svc.Reject)
+
+	req := httptest.NewRequest("POST", "/achievements/m1/reject", strings.NewReader(`{"note":"lampiran kurang"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, "u1", sent[0].UserID)
+	assert.Equal(t, notify.TypeAchievementRejected, sent[0].Type)
+	assert.Equal(t, "lampiran kurang", sent[0].Data["rejection_note"])
+	assert.Equal(t, "rejected", sent[0].Data["status"])
+}
+
+func TestStudent_UpdateAdvisor_Notifies(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		users []string
+		types []string
+	}{
+		{"dosen baru", `{"advisor_id":"u4"}`, []string{"u4", "u1"}, []string{notify.TypeAdviseeAssigned, notify.TypeAdvisorAssigned}},
+		{"dosen dilepas", `{"advisor_id":null}`, []string{"u1"}, []string{notify.TypeAdvisorAssigned}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			students, lecturers, refs := policyRepos("verified")
+
+			var sent []models.Notification
+			svc := services.NewStudentService(db, students, lecturers, refs, &repo.AchievementMongoMockRepo{}, notifier(&sent, &repo.MailerMock{}, nil), &repo.OutboxMockRepo{})
+
+			app := fiber.New()
+			app.Put("/students/:id/advisor", asUser("u5", adminPerms), svc.UpdateAdvisor)
+
+			req := httptest.NewRequest("PUT", "/students/1/advisor", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var users, types []string
+			for _, n := range sent {
+				users = append(users, n.UserID)
+				types = append(types, n.Type)
+			}
+			assert.Equal(t, tc.users, users)
+			assert.Equal(t, tc.types, types)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestNotification_Preferences(t *testing.T) {
+	saved := map[string]bool{}
+	notifications := &repo.NotificationMockRepo{
+		PreferencesFn: func(ctx context.Context, userID string) (map[string]bool, error) {
+			return saved, nil
+		},
+		SetPreferencesFn: func(ctx context.Context, userID string, prefs map[string]bool) error {
+			for k, v := range prefs {
+				saved[k] = v
+			}
+			return nil
+		},
+	}
+	var sent []models.Notification
+	svc := services.NewNotificationService(notifications, notifier(&sent, &repo.MailerMock{}, nil))
+
+	app := fiber.New()
+	app.Get("/notifications/preferences", asUser("u1", studentPerms), svc.GetPreferences)
+	app.Put("/notifications/preferences", asUser("u1", studentPerms), svc.UpdatePreferences)
+
+	send := func(method, body string) (int, map[string]any) {
+		req := httptest.NewRequest(method, "/notifications/preferences", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var out struct {
+			Data map[string]any `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return resp.StatusCode, out.Data
+	}
+
+	// kanal yang belum diatur dianggap aktif
+	status, data := send("GET", "")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, map[string]any{"in_app": true, "email": true}, data)
+
+	status, data = send("PUT", `{"email": false}`)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, map[string]any{"in_app": true, "email": false}, data)
+
+	status, _ = send("PUT", `{"in_app": false}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = send("PUT", `{"sms": true}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, map[string]bool{"email": false}, saved)
+}
+
+func TestNotification_List_And_MarkRead(t *testing.T) {
+	var gotUser string
+	var gotUnread bool
+	notifications := &repo.NotificationMockRepo{
+		FindByUserFn: func(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+			gotUser, gotUnread = userID, unreadOnly
+			return []models.Notification{{ID: "n1", UserID: userID, Title: "Prestasi ditolak"}}, 1, nil
+		},
+		CountUnreadFn: func(ctx context.Context, userID string) (int, error) {
+			return 1, nil
+		},
+		MarkReadFn: func(ctx context.Context, userID, id string) (bool, error) {
+			return userID == "u1" && id == "n1", nil
+		},
+	}
+	svc := services.NewNotificationService(notifications, nil)
+
+	app := fiber.New()
+	app.Get("/notifications", asUser("u1", studentPerms), svc.List)
+	app.Patch("/notifications/:id/read", asUser("u1", studentPerms), svc.MarkRead)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/notifications?unread=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "u1", gotUser)
+	assert.True(t, gotUnread)
+
+	var out struct {
+		Data struct {
+			Items  []models.Notification `json:"items"`
+			Unread int                   `json:"unread"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Len(t, out.Data.Items, 1)
+	assert.Equal(t, 1, out.Data.Unread)
+
+	resp, err = app.Test(httptest.NewRequest("PATCH", "/notifications/n1/read", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// notifikasi milik pengguna lain tidak ditemukan
+	resp, err = app.Test(httptest.NewRequest("PATCH", "/notifications/n2/read", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
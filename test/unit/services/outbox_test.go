@@ -0,0 +1,254 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas/app/jobs"
+	"uas/app/models"
+	"uas/app/outbox"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestOutbox_Apply_CreateIsIdempotent(t *testing.T) {
+	doc := &models.AchievementMongo{
+		ID:      primitive.NewObjectID(),
+		Title:   "Juara 1",
+		Details: map[string]interface{}{"rank": 1},
+	}
+	msg, err := outbox.NewCreate(doc)
+	require.NoError(t, err)
+
+	var inserted *models.AchievementMongo
+	mongoRepo := &repo.AchievementMongoMockRepo{
+		CreateFn: func(ctx context.Context, data *models.AchievementMongo) (string, error) {
+			if inserted != nil {
+				return "", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}
+			}
+			inserted = data
+			return data.ID.Hex(), nil
+		},
+	}
+
+	require.NoError(t, outbox.Apply(context.Background(), mongoRepo, msg))
+	require.NoError(t, outbox.Apply(context.Background(), mongoRepo, msg), "dokumen yang sudah ada tidak dianggap gagal")
+
+	require.NotNil(t, inserted)
+	assert.Equal(t, doc.ID, inserted.ID)
+	assert.Equal(t, "Juara 1", inserted.Title)
+	assert.EqualValues(t, 1, inserted.Details["rank"])
+}
+
+func TestOutbox_NewCreate_RequiresID(t *testing.T) {
+	_, err := outbox.NewCreate(&models.AchievementMongo{Title: "T"})
+	assert.Error(t, err)
+}
+
+func TestOutbox_Process(t *testing.T) {
+	var done, failed []int64
+	outboxRepo := &repo.OutboxMockRepo{
+		ClaimPendingFn: func(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+			return []models.OutboxMessage{
+				{ID: 1, AggregateID: "a1", Operation: outbox.OpSoftDelete},
+				{ID: 2, AggregateID: "a2", Operation: outbox.OpSoftDelete},
+				{ID: 3, AggregateID: "a3", Operation: "unknown"},
+			}, nil
+		},
+		MarkDoneFn: func(ctx context.Context, id int64) error {
+			done = append(done, id)
+			return nil
+		},
+		MarkFailedFn: func(ctx context.Context, id int64, reason string) error {
+			failed = append(failed, id)
+			return nil
+		},
+	}
+	mongoRepo := &repo.AchievementMongoMockRepo{
+		SoftDeleteFn: func(ctx context.Context, id string) error {
+			if id == "a2" {
+				return errors.New("timeout")
+			}
+			return nil
+		},
+	}
+
+	nDone, nFailed, err := outbox.Process(context.Background(), outboxRepo, mongoRepo, 100, outbox.DefaultMaxAttempts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, nDone)
+	assert.Equal(t, 2, nFailed)
+	assert.Equal(t, []int64{1}, done)
+	assert.Equal(t, []int64{2, 3}, failed)
+}
+
+func TestOutbox_Dispatch_BusyLeftForWorker(t *testing.T) {
+	var marked bool
+	outboxRepo := &repo.OutboxMockRepo{
+		ClaimFn: func(ctx context.Context, id int64) error {
+			return repository.ErrOutboxBusy
+		},
+		MarkDoneFn: func(ctx context.Context, id int64) error {
+			marked = true
+			return nil
+		},
+		MarkFailedFn: func(ctx context.Context, id int64, reason string) error {
+			marked = true
+			return nil
+		},
+	}
+	applied := false
+	mongoRepo := &repo.AchievementMongoMockRepo{
+		SoftDeleteFn: func(ctx context.Context, id string) error {
+			applied = true
+			return nil
+		},
+	}
+
+	// pesan create yang lebih lama belum diterapkan: soft delete menunggu
+	err := outbox.Dispatch(context.Background(), outboxRepo, mongoRepo, &models.OutboxMessage{ID: 2, AggregateID: "a1", Operation: outbox.OpSoftDelete})
+
+	assert.ErrorIs(t, err, repository.ErrOutboxBusy)
+	assert.False(t, applied)
+	assert.False(t, marked, "pesan tetap tertunda tanpa menambah attempts")
+}
+
+func TestOutboxRepo_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := repository.NewOutboxRepo(db)
+
+	mock.ExpectExec("UPDATE mongo_outbox o").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, outboxRepo.Claim(context.Background(), 1))
+
+	mock.ExpectExec("UPDATE mongo_outbox o").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, outboxRepo.Claim(context.Background(), 2), repository.ErrOutboxBusy)
+
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").WithArgs(outbox.DefaultMaxAttempts, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "aggregate_id", "operation", "payload", "attempts", "last_error", "created_at"}).
+			AddRow(int64(3), "a1", outbox.OpSoftDelete, nil, 0, nil, time.Now()))
+	claimed, err := outboxRepo.ClaimPending(context.Background(), 100, outbox.DefaultMaxAttempts)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "a1", claimed[0].AggregateID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievement_Delete_WritesOutboxInTransition(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+
+	var msg *models.OutboxMessage
+	refs.TransitionWithOutboxFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, m *models.OutboxMessage) error {
+		assert.Equal(t, "deleted", entry.ToStatus)
+		msg = m
+		return nil
+	}
+
+	var softDeleted string
+	mongoRepo := &repo.AchievementMongoMockRepo{
+		SoftDeleteFn: func(ctx context.Context, id string) error {
+			softDeleted = id
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongoRepo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Delete("/achievements/:id", asUser("u1", studentPerms), svc.Delete)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/achievements/m1", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, msg)
+	assert.Equal(t, outbox.OpSoftDelete, msg.Operation)
+	assert.Equal(t, "m1", msg.AggregateID)
+	assert.Equal(t, "m1", softDeleted)
+}
+
+func TestJobs_ReconcileAchievements(t *testing.T) {
+	refs := &repo.AchievementReferenceMockRepo{
+		FindAllWithDeletedFn: func(ctx context.Context) ([]models.AchievementReference, error) {
+			return []models.AchievementReference{
+				{ID: "ok", MongoAchievementID: "d-ok", Status: "verified"},
+				{ID: "missing", MongoAchievementID: "d-missing", Status: "submitted"},
+				{ID: "soft", MongoAchievementID: "d-soft", Status: "draft"},
+				{ID: "stale", MongoAchievementID: "d-stale", Status: "deleted"},
+				{ID: "pending", MongoAchievementID: "d-pending", Status: "draft"},
+			}, nil
+		},
+	}
+
+	var transitioned []string
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		transitioned = append(transitioned, ref.ID+":"+*entry.FromStatus+"->"+entry.ToStatus)
+		return nil
+	}
+
+	var restored, softDeleted []string
+	docs := &repo.AchievementMongoMockRepo{
+		StatesFn: func(ctx context.Context) (map[string]bool, error) {
+			return map[string]bool{
+				"d-ok":     false,
+				"d-soft":   true,
+				"d-stale":  false,
+				"d-orphan": false,
+				"d-gone":   true,
+			}, nil
+		},
+		RestoreFn: func(ctx context.Context, id string) error {
+			restored = append(restored, id)
+			return nil
+		},
+		SoftDeleteFn: func(ctx context.Context, id string) error {
+			softDeleted = append(softDeleted, id)
+			return nil
+		},
+	}
+
+	outboxRepo := &repo.OutboxMockRepo{
+		PendingFn: func(ctx context.Context, limit, maxAttempts int) ([]models.OutboxMessage, error) {
+			return []models.OutboxMessage{{AggregateID: "d-pending", Operation: outbox.OpCreate}}, nil
+		},
+	}
+
+	t.Run("report only", func(t *testing.T) {
+		report, err := jobs.ReconcileAchievements(context.Background(), refs, docs, outboxRepo, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"d-orphan"}, report.OrphanDocs)
+		assert.Equal(t, []string{"missing"}, report.MissingDocs)
+		assert.Equal(t, []string{"soft"}, report.DeletedDocs)
+		assert.Equal(t, []string{"d-stale"}, report.StaleDocs)
+		assert.Equal(t, []string{"d-pending"}, report.Skipped)
+		assert.Zero(t, report.Repaired)
+		assert.Empty(t, restored)
+		assert.Empty(t, softDeleted)
+		assert.Empty(t, transitioned)
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		report, err := jobs.ReconcileAchievements(context.Background(), refs, docs, outboxRepo, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, report.Repaired)
+		assert.Equal(t, []string{"d-soft"}, restored)
+		assert.ElementsMatch(t, []string{"d-stale", "d-orphan"}, softDeleted)
+		assert.Equal(t, []string{"missing:submitted->deleted"}, transitioned)
+	})
+}
@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/test/unit/repo"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_ForgotPassword_UnknownEmail(t *testing.T) {
+	app := fiber.New()
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+	resets := &repo.PasswordResetMockRepo{
+		CreateFn: func(ctx context.Context, reset *models.PasswordReset) error {
+			t.Fatal("token reset tidak boleh dibuat untuk email yang tidak terdaftar")
+			return nil
+		},
+	}
+	mailer := &repo.MailerMock{}
+
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, resets, mailer, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/forgot-password", authService.ForgotPassword)
+
+	req := httptest.NewRequest("POST", "/auth/forgot-password", strings.NewReader(`{"email":"ghost@test.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Empty(t, mailer.Sent)
+}
+
+func TestAuth_ForgotPassword_SendsHashedToken(t *testing.T) {
+	app := fiber.New()
+
+	authRepo := &repo.AuthMockRepo{
+		GetUserByEmailFn: func(email string) (*models.UserWithRole, error) {
+			return &models.UserWithRole{ID: "user-1", Email: email, FullName: "Panji", IsActive: true}, nil
+		},
+	}
+
+	var stored *models.PasswordReset
+	resets := &repo.PasswordResetMockRepo{
+		CreateFn: func(ctx context.Context, reset *models.PasswordReset) error {
+			stored = reset
+			return nil
+		},
+	}
+	mailer := &repo.MailerMock{}
+
+	authService := services.NewAuthService(authRepo, repository.NewMemoryRevocationRepo(), &repo.SessionMockRepo{}, resets, mailer, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/forgot-password", authService.ForgotPassword)
+
+	req := httptest.NewRequest("POST", "/auth/forgot-password", strings.NewReader(`{"email":"panji@test.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, stored)
+	require.Len(t, mailer.Sent, 1)
+	assert.Equal(t, "panji@test.com", mailer.Sent[0].To)
+	assert.True(t, stored.ExpiresAt.After(time.Now()))
+
+	// email berisi token asli, database hanya menyimpan hash-nya
+	idx := strings.Index(mailer.Sent[0].Body, "token=")
+	require.NotEqual(t, -1, idx)
+	token := strings.Fields(mailer.Sent[0].Body[idx+len("token="):])[0]
+	assert.Equal(t, utils.HashToken(token), stored.TokenHash)
+	assert.NotContains(t, mailer.Sent[0].Body, stored.TokenHash)
+}
+
+func TestAuth_ResetPassword_RevokesSessions(t *testing.T) {
+	app := fiber.New()
+
+	resets := &repo.PasswordResetMockRepo{
+		ResetPasswordFn: func(ctx context.Context, tokenHash string, passwordHash string) (string, error) {
+			assert.Equal(t, utils.HashToken("raw-token"), tokenHash)
+			assert.True(t, utils.CheckPassword("passwordBaru1", passwordHash))
+			return "user-1", nil
+		},
+	}
+
+	var revokedUser, reason string
+	sessions := &repo.SessionMockRepo{
+		RevokeAllByUserIDFn: func(ctx context.Context, userID string, r string, exceptID string) error {
+			revokedUser = userID
+			reason = r
+			assert.Empty(t, exceptID)
+			return nil
+		},
+	}
+
+	authService := services.NewAuthService(&repo.AuthMockRepo{}, repository.NewMemoryRevocationRepo(), sessions, resets, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/reset-password", authService.ResetPassword)
+
+	req := httptest.NewRequest("POST", "/auth/reset-password", strings.NewReader(`{"token":"raw-token","password":"passwordBaru1"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "user-1", revokedUser)
+	assert.Equal(t, models.SessionRevokePasswordReset, reason)
+}
+
+func TestAuth_ResetPassword_InvalidToken(t *testing.T) {
+	app := fiber.New()
+
+	resets := &repo.PasswordResetMockRepo{
+		ResetPasswordFn: func(ctx context.Context, tokenHash string, passwordHash string) (string, error) {
+			return "", nil
+		},
+	}
+	sessions := &repo.SessionMockRepo{
+		RevokeAllByUserIDFn: func(ctx context.Context, userID string, r string, exceptID string) error {
+			t.Fatal("session tidak boleh dicabut jika token tidak valid")
+			return nil
+		},
+	}
+
+	authService := services.NewAuthService(&repo.AuthMockRepo{}, repository.NewMemoryRevocationRepo(), sessions, resets, &repo.MailerMock{}, &repo.LoginAttemptMockRepo{}, &repo.MFAMockRepo{})
+	app.Post("/auth/reset-password", authService.ResetPassword)
+
+	req := httptest.NewRequest("POST", "/auth/reset-password", strings.NewReader(`{"token":"expired","password":"passwordBaru1"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,283 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	studentPerms  = []string{"achievement:create", "achievement:read", "achievement:update"}
+	lecturerPerms = []string{"achievement:read", "achievement:verify"}
+	adminPerms    = []string{"*"}
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestPolicy_Can(t *testing.T) {
+	owned := policy.Subject{StudentID: "s1", AdvisorID: strPtr("l1"), Status: "submitted"}
+	draft := policy.Subject{StudentID: "s1", AdvisorID: strPtr("l1"), Status: "draft"}
+
+	owner := policy.Actor{UserID: "u1", Permissions: studentPerms, StudentID: "s1"}
+	otherStudent := policy.Actor{UserID: "u2", Permissions: studentPerms, StudentID: "s2"}
+	advisor := policy.Actor{UserID: "u3", Permissions: lecturerPerms, LecturerID: "l1"}
+	otherLecturer := policy.Actor{UserID: "u4", Permissions: lecturerPerms, LecturerID: "l2"}
+	admin := policy.Actor{UserID: "u5", Permissions: adminPerms}
+	manager := policy.Actor{UserID: "u6", Permissions: []string{"user:manage"}}
+
+	cases := []struct {
+		name    string
+		actor   policy.Actor
+		action  policy.Action
+		subject policy.Subject
+		want    bool
+	}{
+		{"owner reads", owner, policy.ReadAchievement, owned, true},
+		{"owner updates", owner, policy.UpdateAchievement, draft, true},
+		{"owner cannot verify", owner, policy.VerifyAchievement, owned, false},
+		{"other student reads", otherStudent, policy.ReadAchievement, owned, false},
+		{"other student deletes", otherStudent, policy.DeleteAchievement, draft, false},
+		{"advisor reads submitted", advisor, policy.ReadAchievement, owned, true},
+		{"advisor reads draft", advisor, policy.ReadAchievement, draft, false},
+		{"advisor verifies", advisor, policy.VerifyAchievement, owned, true},
+		{"advisor cannot update", advisor, policy.UpdateAchievement, owned, false},
+		{"other lecturer verifies", otherLecturer, policy.VerifyAchievement, owned, false},
+		{"other lecturer reads student", otherLecturer, policy.ReadStudent, owned, false},
+		{"admin wildcard", admin, policy.VerifyAchievement, owned, true},
+		{"user manager reads student", manager, policy.ReadStudent, owned, true},
+		{"user manager cannot verify", manager, policy.VerifyAchievement, owned, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, policy.Can(tc.actor, tc.action, tc.subject))
+		})
+	}
+}
+
+func TestPolicy_ListScope(t *testing.T) {
+	assert.Equal(t, policy.ScopeOwn, policy.ListScope(policy.Actor{Permissions: studentPerms, StudentID: "s1"}))
+	assert.Equal(t, policy.ScopeAdvisees, policy.ListScope(policy.Actor{Permissions: lecturerPerms, LecturerID: "l1"}))
+	assert.Equal(t, policy.ScopeAll, policy.ListScope(policy.Actor{Permissions: adminPerms}))
+	assert.Equal(t, policy.ScopeNone, policy.ListScope(policy.Actor{Permissions: []string{"user:manage"}}))
+}
+
+// policyRepos menyiapkan mahasiswa s1 (user u1) dengan dosen wali l1
+// (user u3), dosen lain l2 (user u4) dan mahasiswa lain s2 (user u2).
+func policyRepos(status string) (*repo.StudentMockRepo, *repo.LecturerMockRepo, *repo.AchievementReferenceMockRepo) {
+	s1 := &models.Student{ID: "s1", UserID: "u1", AdvisorID: strPtr("l1")}
+	s2 := &models.Student{ID: "s2", UserID: "u2"}
+
+	students := &repo.StudentMockRepo{
+		GetByUserIDFn: func(ctx context.Context, uid string) (*models.Student, error) {
+			switch uid {
+			case "u1":
+				return s1, nil
+			case "u2":
+				return s2, nil
+			}
+			return nil, nil
+		},
+		FindByIDFn: func(ctx context.Context, id string) (*models.Student, error) {
+			if id == "s1" {
+				return s1, nil
+			}
+			return nil, nil
+		},
+		GetIDByIndexFn: func(idx int) (string, error) {
+			return "s1", nil
+		},
+		FindAllFn: func(ctx context.Context) ([]models.Student, error) {
+			return []models.Student{*s1, *s2}, nil
+		},
+	}
+
+	lecturers := &repo.LecturerMockRepo{
+		GetByUserIDFn: func(ctx context.Context, uid string) (*models.Lecturer, error) {
+			switch uid {
+			case "u3":
+				return &models.Lecturer{ID: "l1", UserID: "u3"}, nil
+			case "u4":
+				return &models.Lecturer{ID: "l2", UserID: "u4"}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	refs := &repo.AchievementReferenceMockRepo{
+		GetByMongoIDFn: func(ctx context.Context, id string) (*models.AchievementReference, error) {
+			return &models.AchievementReference{ID: "ref-1", StudentID: "s1", MongoAchievementID: id, Status: status}, nil
+		},
+		FindAllFn: func(ctx context.Context) ([]models.AchievementReference, error) {
+			return []models.AchievementReference{
+				{ID: "ref-1", StudentID: "s1", Status: "verified"},
+				{ID: "ref-2", StudentID: "s2", Status: "submitted"},
+				{ID: "ref-3", StudentID: "s2", Status: "draft"},
+			}, nil
+		},
+	}
+
+	return students, lecturers, refs
+}
+
+func asUser(userID string, perms []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("permissions", perms)
+		return c.Next()
+	}
+}
+
+func TestAchievement_Verify_PerRole(t *testing.T) {
+	cases := []struct {
+		name   string
+		userID string
+		perms  []string
+		want   int
+	}{
+		{"advisor", "u3", lecturerPerms, fiber.StatusOK},
+		{"other lecturer", "u4", lecturerPerms, fiber.StatusForbidden},
+		{"owner student", "u1", studentPerms, fiber.StatusForbidden},
+		{"admin", "u5", adminPerms, fiber.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos("submitted")
+			mongo := &repo.AchievementMongoMockRepo{
+				FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+					return &models.AchievementMongo{Title: "Juara"}, nil
+				},
+			}
+			svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+			app := fiber.New()
+			app.Post("/achievements/:id/verify", asUser(tc.userID, tc.perms), svc.Verify)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/verify", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestAchievement_Detail_PerRole(t *testing.T) {
+	cases := []struct {
+		name   string
+		status string
+		userID string
+		perms  []string
+		want   int
+	}{
+		{"owner reads draft", "draft", "u1", studentPerms, fiber.StatusOK},
+		{"other student", "submitted", "u2", studentPerms, fiber.StatusForbidden},
+		{"advisor reads submitted", "submitted", "u3", lecturerPerms, fiber.StatusOK},
+		{"advisor reads draft", "draft", "u3", lecturerPerms, fiber.StatusForbidden},
+		{"other lecturer", "submitted", "u4", lecturerPerms, fiber.StatusForbidden},
+		{"admin", "draft", "u5", adminPerms, fiber.StatusOK},
+	}
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{ID: primitive.NewObjectID(), Title: "Juara"}, nil
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos(tc.status)
+			svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+			app := fiber.New()
+			app.Get("/achievements/:id", asUser(tc.userID, tc.perms), svc.Detail)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/achievements/m1", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestStudent_GetByID_PerRole(t *testing.T) {
+	cases := []struct {
+		name   string
+		userID string
+		perms  []string
+		want   int
+	}{
+		{"self", "u1", studentPerms, fiber.StatusOK},
+		{"other student", "u2", studentPerms, fiber.StatusForbidden},
+		{"advisor", "u3", lecturerPerms, fiber.StatusOK},
+		{"other lecturer", "u4", lecturerPerms, fiber.StatusForbidden},
+		{"user manager", "u6", []string{"user:manage"}, fiber.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos("verified")
+			svc := services.NewStudentService(nil, students, lecturers, refs, &repo.AchievementMongoMockRepo{}, nil, &repo.OutboxMockRepo{})
+
+			app := fiber.New()
+			app.Get("/students/:id", asUser(tc.userID, tc.perms), svc.GetByID)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/students/1", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestReport_Statistics_ScopedToActor(t *testing.T) {
+	cases := []struct {
+		name   string
+		userID string
+		perms  []string
+		total  float64
+	}{
+		{"student sees own", "u1", studentPerms, 1},
+		{"advisor sees advisees", "u3", lecturerPerms, 1},
+		{"other lecturer sees none", "u4", lecturerPerms, 0},
+		{"admin sees all", "u5", adminPerms, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos("verified")
+			students.FindByAdvisorIDFn = func(ctx context.Context, advisorID string) ([]models.Student, error) {
+				if advisorID == "l1" {
+					return []models.Student{{ID: "s1", UserID: "u1", AdvisorID: strPtr("l1")}}, nil
+				}
+				return nil, nil
+			}
+			list, _ := refs.FindAll(context.Background())
+			statisticsFrom(refs, list)
+			svc := services.NewReportService(refs, &repo.AchievementMongoMockRepo{}, students, lecturers)
+
+			app := fiber.New()
+			app.Get("/reports/statistics", asUser(tc.userID, tc.perms), svc.Statistics)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/reports/statistics", nil))
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body struct {
+				Data struct {
+					Global map[string]float64 `json:"global"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tc.total, body.Data.Global["total"])
+		})
+	}
+}
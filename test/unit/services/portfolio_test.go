@@ -0,0 +1,248 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/portfolio"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPortfolio_Code(t *testing.T) {
+	code, err := portfolio.NewCode()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-HJKMNP-Z2-9]{4}-[A-HJKMNP-Z2-9]{4}-[A-HJKMNP-Z2-9]{4}$`, code)
+
+	assert.Equal(t, "ABCD-EFGH-JK23", portfolio.NormalizeCode(" abcd efgh-jk23 "))
+	assert.Equal(t, "", portfolio.NormalizeCode("ABCD-EFGH-JK2"))
+	assert.Equal(t, "", portfolio.NormalizeCode("ABCD-EFGH-JK20"))
+}
+
+// portfolioService menyiapkan mahasiswa s1 dengan dua prestasi
+// terverifikasi, satu prestasi yang masih diajukan dan satu reference
+// terverifikasi tanpa dokumen.
+func portfolioService(store map[string]*models.Portfolio) *services.PortfolioService {
+	students, lecturers, refs := policyRepos("verified")
+
+	const (
+		id1 = "000000000000000000000001"
+		id2 = "000000000000000000000002"
+		id3 = "000000000000000000000003"
+		id4 = "000000000000000000000004"
+	)
+	early := time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)
+	late := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	verifier := "u3"
+
+	refs.FindByStudentIDFn = func(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
+		return []models.AchievementReference{
+			{ID: "ref-1", StudentID: "s1", MongoAchievementID: id1, Status: "verified", VerifiedAt: &late, VerifiedBy: &verifier},
+			{ID: "ref-2", StudentID: "s1", MongoAchievementID: id2, Status: "verified", VerifiedAt: &early, VerifiedBy: &verifier},
+			{ID: "ref-3", StudentID: "s1", MongoAchievementID: id3, Status: "submitted"},
+			{ID: "ref-4", StudentID: "s1", MongoAchievementID: id4, Status: "verified", VerifiedAt: &early},
+		}, nil
+	}
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDsFn: func(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error) {
+			oid := func(hex string) primitive.ObjectID {
+				id, _ := primitive.ObjectIDFromHex(hex)
+				return id
+			}
+			return map[string]*models.AchievementMongo{
+				id1: {ID: oid(id1), Title: "Juara 1 Gemastik", AchievementType: "competition", Points: 70},
+				id2: {ID: oid(id2), Title: "Juara 3 KRI", AchievementType: "competition", Points: 30},
+				id3: {ID: oid(id3), Title: "Draft", AchievementType: "publication", Points: 0},
+			}, nil
+		},
+	}
+
+	users := &repo.UserMockRepo{
+		GetByIDFn: func(id string) (*models.UserWithRole, error) {
+			names := map[string]string{"u1": "Siti Aminah", "u3": "Dr. Budi"}
+			return &models.UserWithRole{ID: id, FullName: names[id]}, nil
+		},
+	}
+
+	types := &repo.AchievementTypeMockRepo{
+		FindAllFn: func(ctx context.Context, activeOnly bool) ([]models.AchievementType, error) {
+			return []models.AchievementType{{Code: "competition", Name: "Kompetisi"}}, nil
+		},
+	}
+
+	portfolios := &repo.PortfolioMockRepo{
+		CreateFn: func(ctx context.Context, p *models.Portfolio) error {
+			// simpan seperti JSONB: dokumen melewati encode/decode JSON
+			raw, err := json.Marshal(p.Document)
+			if err != nil {
+				return err
+			}
+			saved := *p
+			saved.Document = models.PortfolioDocument{}
+			if err := json.Unmarshal(raw, &saved.Document); err != nil {
+				return err
+			}
+			store[p.VerificationCode] = &saved
+			return nil
+		},
+		GetByCodeFn: func(ctx context.Context, code string) (*models.Portfolio, error) {
+			return store[code], nil
+		},
+	}
+
+	svc := services.NewPortfolioService(portfolios, refs, mongo, students, lecturers, users, types, portfolio.Signer{Key: []byte("test-key")})
+	svc.Now = func() time.Time { return time.Date(2025, 6, 1, 9, 30, 15, 123456789, time.Local) }
+	return svc
+}
+
+func issuePortfolio(t *testing.T, svc *services.PortfolioService, userID string, perms []string, url string) (int, []byte) {
+	app := fiber.New()
+	app.Post("/students/:id/portfolio", asUser(userID, perms), svc.Issue)
+
+	resp, err := app.Test(httptest.NewRequest("POST", url, nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, body
+}
+
+func TestPortfolio_Issue(t *testing.T) {
+	store := map[string]*models.Portfolio{}
+	svc := portfolioService(store)
+
+	status, body := issuePortfolio(t, svc, "u1", studentPerms, "/students/s1/portfolio")
+	require.Equal(t, fiber.StatusCreated, status, string(body))
+
+	var out struct {
+		Data struct {
+			Document  models.PortfolioDocument `json:"document"`
+			Signature string                   `json:"signature"`
+			VerifyURL string                   `json:"verify_url"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &out))
+	doc := out.Data.Document
+
+	assert.Equal(t, "Siti Aminah", doc.Student.Name)
+	assert.Equal(t, time.Date(2025, 6, 1, 9, 30, 15, 0, time.Local).UTC(), doc.IssuedAt)
+	assert.Equal(t, "/api/v1/portfolios/verify/"+doc.VerificationCode, out.Data.VerifyURL)
+
+	// hanya prestasi terverifikasi yang dokumennya ada
+	require.Len(t, doc.Groups, 1)
+	g := doc.Groups[0]
+	assert.Equal(t, "Kompetisi", g.TypeName)
+	assert.Equal(t, 100, g.Points)
+	require.Len(t, g.Achievements, 2)
+	assert.Equal(t, "Juara 3 KRI", g.Achievements[0].Title)
+	assert.Equal(t, "Dr. Budi", g.Achievements[0].VerifiedBy)
+	assert.Equal(t, 2, doc.TotalAchievements)
+	assert.Equal(t, 100, doc.TotalPoints)
+
+	require.Contains(t, store, doc.VerificationCode)
+	assert.True(t, svc.Signer.Verify(&store[doc.VerificationCode].Document, out.Data.Signature))
+}
+
+func TestPortfolio_Issue_Forbidden(t *testing.T) {
+	store := map[string]*models.Portfolio{}
+
+	status, _ := issuePortfolio(t, portfolioService(store), "u2", studentPerms, "/students/s1/portfolio")
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, _ = issuePortfolio(t, portfolioService(store), "u1", studentPerms, "/students/s1/portfolio?format=xlsx")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	assert.Empty(t, store)
+}
+
+func TestPortfolio_Issue_PDF(t *testing.T) {
+	store := map[string]*models.Portfolio{}
+
+	status, body := issuePortfolio(t, portfolioService(store), "u3", lecturerPerms, "/students/s1/portfolio?format=pdf")
+	require.Equal(t, fiber.StatusOK, status)
+	require.True(t, bytes.HasPrefix(body, []byte("%PDF-1.4")))
+	require.Len(t, store, 1)
+
+	for code := range store {
+		assert.Contains(t, string(body), "(Kode verifikasi: "+code)
+	}
+	assert.Contains(t, string(body), "(Kompetisi \\(2 prestasi, 100 poin\\))")
+	assert.Regexp(t, regexp.MustCompile(`\(Tanda tangan: [0-9a-f]{64}\)`), string(body))
+}
+
+func TestPortfolio_Verify(t *testing.T) {
+	store := map[string]*models.Portfolio{}
+	svc := portfolioService(store)
+
+	status, _ := issuePortfolio(t, svc, "u1", studentPerms, "/students/s1/portfolio")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Len(t, store, 1)
+
+	var code string
+	for c := range store {
+		code = c
+	}
+
+	app := fiber.New()
+	app.Get("/portfolios/verify/:code", svc.Verify)
+
+	verify := func(code string) (int, map[string]any) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/portfolios/verify/"+code, nil))
+		require.NoError(t, err)
+		var out struct {
+			Data map[string]any `json:"data"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Data
+	}
+
+	// kode boleh diketik tanpa tanda hubung dan huruf kecil
+	status, data := verify(strings.ToLower(strings.ReplaceAll(code, "-", "")))
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, data["valid"])
+	assert.NotNil(t, data["document"])
+
+	// isi dokumen yang diubah di database tidak lagi valid
+	store[code].Document.TotalPoints = 999
+	status, data = verify(code)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, false, data["valid"])
+	assert.Nil(t, data["document"])
+
+	status, _ = verify("AAAA-BBBB-CCCC")
+	assert.Equal(t, fiber.StatusNotFound, status)
+
+	status, _ = verify("not-a-code")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestPortfolio_SignerFromEnv(t *testing.T) {
+	t.Setenv("JWT_SECRET", "jwt-secret")
+
+	t.Setenv("PORTFOLIO_SIGNING_KEY", "")
+	_, err := portfolio.SignerFromEnv()
+	assert.Error(t, err, "tanpa kunci tidak boleh memakai JWT_SECRET")
+
+	t.Setenv("PORTFOLIO_SIGNING_KEY", "jwt-secret")
+	_, err = portfolio.SignerFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("PORTFOLIO_SIGNING_KEY", "portfolio-key")
+	signer, err := portfolio.SignerFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("portfolio-key"), signer.Key)
+}
@@ -0,0 +1,354 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/outbox"
+	"uas/app/policy"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lecturerUUID = "5f0e4a8e-1111-4c1b-9a0e-1d2c3b4a5f60"
+
+func withProfile(perms []string) []string {
+	return append(append([]string{}, perms...), "profile:update")
+}
+
+func putJSON(t *testing.T, app *fiber.App, url, body string) (int, models.MetaInfo) {
+	t.Helper()
+
+	req := httptest.NewRequest("PUT", url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var meta models.MetaInfo
+	_ = json.NewDecoder(resp.Body).Decode(&meta)
+	return resp.StatusCode, meta
+}
+
+func TestPolicy_Can_Profile(t *testing.T) {
+	student := policy.Subject{StudentID: "s1", AdvisorID: strPtr("l1")}
+	lecturer := policy.Subject{LecturerID: "l1"}
+
+	owner := policy.Actor{Permissions: withProfile(studentPerms), StudentID: "s1"}
+	advisor := policy.Actor{Permissions: withProfile(lecturerPerms), LecturerID: "l1"}
+	otherLecturer := policy.Actor{Permissions: withProfile(lecturerPerms), LecturerID: "l2"}
+	manager := policy.Actor{Permissions: []string{"user:manage"}}
+
+	assert.True(t, policy.Can(owner, policy.UpdateContact, student))
+	assert.False(t, policy.Can(owner, policy.UpdateProfile, student))
+	assert.False(t, policy.Can(policy.Actor{Permissions: studentPerms, StudentID: "s1"}, policy.UpdateContact, student))
+	assert.False(t, policy.Can(advisor, policy.UpdateContact, student))
+	assert.True(t, policy.Can(advisor, policy.UpdateContact, lecturer))
+	assert.False(t, policy.Can(advisor, policy.UpdateProfile, lecturer))
+	assert.False(t, policy.Can(otherLecturer, policy.UpdateContact, lecturer))
+	assert.True(t, policy.Can(manager, policy.UpdateProfile, lecturer))
+}
+
+func TestStudent_UpdateProfile_Access(t *testing.T) {
+	cases := []struct {
+		name   string
+		userID string
+		perms  []string
+		body   string
+		want   int
+	}{
+		{"owner updates contact", "u1", withProfile(studentPerms), `{"phone":"0812 3456 7890","address":"Jl. Airlangga 4"}`, fiber.StatusOK},
+		{"owner without permission", "u1", studentPerms, `{"phone":"081234567890"}`, fiber.StatusForbidden},
+		{"owner updates NIM", "u1", withProfile(studentPerms), `{"student_id":"434221001"}`, fiber.StatusForbidden},
+		{"advisor updates contact", "u3", withProfile(lecturerPerms), `{"phone":"081234567890"}`, fiber.StatusForbidden},
+		{"other student", "u2", withProfile(studentPerms), `{"phone":"081234567890"}`, fiber.StatusForbidden},
+		{"admin updates academic", "u5", adminPerms, `{"student_id":"434221001","program_study":"Teknik Informatika","academic_year":"2022"}`, fiber.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos("")
+			var saved *models.Student
+			students.UpdateProfileFn = func(ctx context.Context, s *models.Student) error {
+				saved = s
+				return nil
+			}
+
+			svc := services.NewStudentService(nil, students, lecturers, refs, &repo.AchievementMongoMockRepo{}, nil, &repo.OutboxMockRepo{})
+			app := fiber.New()
+			app.Put("/students/:id/profile", asUser(tc.userID, tc.perms), svc.UpdateProfile)
+
+			status, _ := putJSON(t, app, "/students/1/profile", tc.body)
+			assert.Equal(t, tc.want, status)
+			if tc.want != fiber.StatusOK {
+				assert.Nil(t, saved)
+			}
+		})
+	}
+}
+
+func TestStudent_UpdateProfile_SavesFields(t *testing.T) {
+	students, lecturers, refs := policyRepos("")
+	students.FindByIDFn = func(ctx context.Context, id string) (*models.Student, error) {
+		return &models.Student{ID: "s1", UserID: "u1", StudentID: "STD-1a2b3c4d", ProgramStudy: strPtr("Sistem Informasi")}, nil
+	}
+	var (
+		saved *models.Student
+		msg   *models.OutboxMessage
+	)
+	students.UpdateProfileWithOutboxFn = func(ctx context.Context, s *models.Student, m *models.OutboxMessage) error {
+		saved, msg = s, m
+		return nil
+	}
+
+	var renamed []string
+	mongo := &repo.AchievementMongoMockRepo{
+		UpdateStudentCodeFn: func(ctx context.Context, oldCode, newCode string) error {
+			renamed = []string{oldCode, newCode}
+			return nil
+		},
+	}
+
+	svc := services.NewStudentService(nil, students, lecturers, refs, mongo, nil, &repo.OutboxMockRepo{})
+	app := fiber.New()
+	app.Put("/students/:id/profile", asUser("u5", adminPerms), svc.UpdateProfile)
+
+	status, _ := putJSON(t, app, "/students/1/profile", `{"student_id":" 434221001 ","program_study":"","phone":"+62 812-3456-7890"}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.NotNil(t, saved)
+	assert.Equal(t, "434221001", saved.StudentID)
+	assert.Nil(t, saved.ProgramStudy)
+	assert.Equal(t, "+6281234567890", *saved.Phone)
+	assert.Nil(t, saved.AcademicYear)
+
+	// penggantian NIM di dokumen prestasi ikut ditulis ke outbox
+	require.NotNil(t, msg)
+	assert.Equal(t, outbox.OpRenameStudent, msg.Operation)
+	assert.Equal(t, "s1", msg.AggregateID)
+	assert.JSONEq(t, `{"old_code":"STD-1a2b3c4d","new_code":"434221001"}`, string(msg.Payload))
+	assert.Equal(t, []string{"STD-1a2b3c4d", "434221001"}, renamed)
+}
+
+func TestStudent_UpdateProfile_RenameLeftForOutboxWorker(t *testing.T) {
+	students, lecturers, refs := policyRepos("")
+	students.FindByIDFn = func(ctx context.Context, id string) (*models.Student, error) {
+		return &models.Student{ID: "s1", UserID: "u1", StudentID: "434221001"}, nil
+	}
+	mongo := &repo.AchievementMongoMockRepo{
+		UpdateStudentCodeFn: func(ctx context.Context, oldCode, newCode string) error {
+			return errors.New("mongo down")
+		},
+	}
+	var failed int
+	outboxRepo := &repo.OutboxMockRepo{
+		MarkFailedFn: func(ctx context.Context, id int64, reason string) error {
+			failed++
+			return nil
+		},
+	}
+
+	svc := services.NewStudentService(nil, students, lecturers, refs, mongo, nil, outboxRepo)
+	app := fiber.New()
+	app.Put("/students/:id/profile", asUser("u5", adminPerms), svc.UpdateProfile)
+
+	// profil tersimpan; penggantian NIM diulang worker outbox
+	status, _ := putJSON(t, app, "/students/1/profile", `{"student_id":"434221002"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1, failed)
+
+	// tanpa perubahan NIM tidak ada pesan outbox
+	var msg *models.OutboxMessage
+	students.UpdateProfileWithOutboxFn = func(ctx context.Context, s *models.Student, m *models.OutboxMessage) error {
+		msg = m
+		return nil
+	}
+	status, _ = putJSON(t, app, "/students/1/profile", `{"phone":"081234567890"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Nil(t, msg)
+}
+
+func TestStudent_UpdateProfile_Validation(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"empty NIM", `{"student_id":""}`, "student_id"},
+		{"NIM not numeric", `{"student_id":"STD-123"}`, "student_id"},
+		{"NIM taken", `{"student_id":"434221999"}`, "student_id"},
+		{"academic year", `{"academic_year":"22"}`, "academic_year"},
+		{"phone", `{"phone":"12ab"}`, "phone"},
+		{"program study too long", `{"program_study":"` + strings.Repeat("x", 101) + `"}`, "program_study"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos("")
+			students.FindTakenStudentIDsFn = func(ctx context.Context, ids []string) (map[string]bool, error) {
+				return map[string]bool{"434221999": true}, nil
+			}
+			students.UpdateProfileFn = func(ctx context.Context, s *models.Student) error {
+				t.Fatal("profil tidak valid tidak boleh disimpan")
+				return nil
+			}
+
+			svc := services.NewStudentService(nil, students, lecturers, refs, &repo.AchievementMongoMockRepo{}, nil, &repo.OutboxMockRepo{})
+			app := fiber.New()
+			app.Put("/students/:id/profile", asUser("u5", adminPerms), svc.UpdateProfile)
+
+			status, meta := putJSON(t, app, "/students/1/profile", tc.body)
+			assert.Equal(t, fiber.StatusBadRequest, status)
+			errs, _ := meta.Errors.(map[string]any)
+			assert.Contains(t, errs, tc.field)
+		})
+	}
+}
+
+func lecturerProfileRepos() *repo.LecturerMockRepo {
+	return &repo.LecturerMockRepo{
+		GetByUserIDFn: func(ctx context.Context, uid string) (*models.Lecturer, error) {
+			switch uid {
+			case "u3":
+				return &models.Lecturer{ID: lecturerUUID, UserID: "u3", LecturerID: "0011223344"}, nil
+			case "u4":
+				return &models.Lecturer{ID: "l2", UserID: "u4"}, nil
+			}
+			return nil, nil
+		},
+		GetByIDFn: func(ctx context.Context, id string) (*models.Lecturer, error) {
+			if id == lecturerUUID {
+				return &models.Lecturer{ID: lecturerUUID, UserID: "u3", LecturerID: "0011223344", Department: strPtr("Informatika")}, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestLecturer_UpdateProfile(t *testing.T) {
+	cases := []struct {
+		name   string
+		userID string
+		perms  []string
+		body   string
+		want   int
+	}{
+		{"self updates contact", "u3", withProfile(lecturerPerms), `{"phone":"031-5914042","office":"Gedung C Lt. 3"}`, fiber.StatusOK},
+		{"self updates department", "u3", withProfile(lecturerPerms), `{"department":"Sistem Informasi"}`, fiber.StatusForbidden},
+		{"other lecturer", "u4", withProfile(lecturerPerms), `{"phone":"0315914042"}`, fiber.StatusForbidden},
+		{"admin updates NIDN", "u5", adminPerms, `{"lecturer_id":"0099887766","department":"Sistem Informasi"}`, fiber.StatusOK},
+		{"admin invalid NIDN", "u5", adminPerms, `{"lecturer_id":"12345"}`, fiber.StatusBadRequest},
+		{"admin taken NIDN", "u5", adminPerms, `{"lecturer_id":"0000000001"}`, fiber.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lecturers := lecturerProfileRepos()
+			lecturers.FindTakenLecturerIDsFn = func(ctx context.Context, ids []string) (map[string]bool, error) {
+				return map[string]bool{"0000000001": true}, nil
+			}
+			var saved *models.Lecturer
+			lecturers.UpdateProfileFn = func(ctx context.Context, l *models.Lecturer) error {
+				saved = l
+				return nil
+			}
+
+			svc := services.NewLecturerService(lecturers, &repo.StudentMockRepo{})
+			app := fiber.New()
+			app.Put("/lecturers/:id/profile", asUser(tc.userID, tc.perms), svc.UpdateProfile)
+
+			status, _ := putJSON(t, app, "/lecturers/"+lecturerUUID+"/profile", tc.body)
+			assert.Equal(t, tc.want, status)
+			if tc.want == fiber.StatusOK {
+				require.NotNil(t, saved)
+				assert.Equal(t, lecturerUUID, saved.ID)
+				assert.NotNil(t, saved.Department)
+			} else {
+				assert.Nil(t, saved)
+			}
+		})
+	}
+}
+
+func TestUser_Create_WithIdentifiers(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile string
+		body    string
+		want    int
+		wantID  string
+	}{
+		{"student with NIM", models.ProfileStudent, `"student_id":"434221001"`, fiber.StatusCreated, "434221001"},
+		{"student without NIM", models.ProfileStudent, `"student_id":""`, fiber.StatusCreated, "STD-"},
+		{"invalid NIM", models.ProfileStudent, `"student_id":"NIM-1"`, fiber.StatusBadRequest, ""},
+		{"taken NIM", models.ProfileStudent, `"student_id":"434221999"`, fiber.StatusBadRequest, ""},
+		{"NIM for lecturer role", models.ProfileLecturer, `"student_id":"434221001"`, fiber.StatusBadRequest, ""},
+		{"lecturer with NIDN", models.ProfileLecturer, `"lecturer_id":"0011223344"`, fiber.StatusCreated, "0011223344"},
+		{"invalid NIDN", models.ProfileLecturer, `"lecturer_id":"123"`, fiber.StatusBadRequest, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			created := ""
+			service := services.NewUserService(
+				db,
+				&repo.UserMockRepo{
+					CreateFn: func(tx *sql.Tx, user *models.Users) (string, error) {
+						return "user-789", nil
+					},
+				},
+				&repo.StudentMockRepo{
+					CreateFn: func(tx *sql.Tx, userID, studentID string) error {
+						created = studentID
+						return nil
+					},
+					FindTakenStudentIDsFn: func(ctx context.Context, ids []string) (map[string]bool, error) {
+						return map[string]bool{"434221999": true}, nil
+					},
+				},
+				&repo.LecturerMockRepo{
+					CreateFn: func(tx *sql.Tx, userID, lecturerID string) error {
+						created = lecturerID
+						return nil
+					},
+				},
+				&repo.RoleMockRepo{
+					GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+						return &models.Role{ID: id, ProfileType: tc.profile}, nil
+					},
+				},
+			)
+
+			app := fiber.New()
+			app.Post("/users", service.Create)
+
+			body := `{"username":"baru","email":"baru@test.com","password":"password123","full_name":"Baru","role_id":"` + lecturerUUID + `",` + tc.body + `}`
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resp.StatusCode)
+			if tc.wantID != "" {
+				assert.True(t, strings.HasPrefix(created, tc.wantID), created)
+			} else {
+				assert.Empty(t, created)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/export"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_Negotiate(t *testing.T) {
+	tests := []struct {
+		query, accept string
+		want          export.Format
+		wantErr       bool
+	}{
+		{"", "", "", false},
+		{"", "application/json", "", false},
+		{"", "text/html,application/xhtml+xml,*/*;q=0.8", "", false},
+		{"", "text/csv", export.CSV, false},
+		{"", "application/pdf;q=0.9", export.PDF, false},
+		{"", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", export.XLSX, false},
+		{"XLSX", "application/pdf", export.XLSX, false},
+		{"json", "application/pdf", "", false},
+		{"docx", "", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := export.Negotiate(tt.query, tt.accept)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, export.ErrUnknownFormat)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "query=%q accept=%q", tt.query, tt.accept)
+	}
+}
+
+func exportRequest(t *testing.T, handler fiber.Handler, route, url, accept string) (int, string, []byte) {
+	app := fiber.New()
+	app.Get(route, asUser("u5", adminPerms), handler)
+
+	req := httptest.NewRequest("GET", url, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header.Get("Content-Disposition"), body
+}
+
+func statisticsService() *services.ReportService {
+	students, lecturers, refs := policyRepos("verified")
+	statisticsFrom(refs, []models.AchievementReference{
+		{ID: "ref-1", StudentID: "s2", StudentName: "Budi", Status: "verified"},
+		{ID: "ref-2", StudentID: "s1", StudentName: "=HYPERLINK(\"x\")", Status: "submitted"},
+		{ID: "ref-3", StudentID: "s2", StudentName: "Budi", Status: "draft"},
+		{ID: "ref-4", StudentID: "s2", StudentName: "Budi", Status: "revision"},
+	})
+	return services.NewReportService(refs, batchMongo(new(int)), students, lecturers)
+}
+
+// statisticsFrom mengisi agregasi statistik refs dari list, disaring dengan
+// StudentIDs seperti klausa WHERE di repository.
+func statisticsFrom(refs *repo.AchievementReferenceMockRepo, list []models.AchievementReference) {
+	scoped := func(f models.AchievementFilter) []models.AchievementReference {
+		out := []models.AchievementReference{}
+		for _, ref := range list {
+			if f.StudentIDs == nil || slices.Contains(f.StudentIDs, ref.StudentID) {
+				out = append(out, ref)
+			}
+		}
+		return out
+	}
+
+	refs.CountByStatusFn = func(ctx context.Context, f models.AchievementFilter) (map[string]int, error) {
+		counts := map[string]int{}
+		for _, ref := range scoped(f) {
+			counts[ref.Status]++
+		}
+		return counts, nil
+	}
+	refs.EachStudentStatFn = func(ctx context.Context, f models.AchievementFilter, fn func(models.StudentStat) error) error {
+		stats := map[string]*models.StudentStat{}
+		for _, ref := range scoped(f) {
+			st, ok := stats[ref.StudentID]
+			if !ok {
+				st = &models.StudentStat{StudentID: ref.StudentID, StudentName: ref.StudentName}
+				stats[ref.StudentID] = st
+			}
+			st.Total++
+			switch ref.Status {
+			case "draft":
+				st.Draft++
+			case "submitted":
+				st.Submitted++
+			case "verified":
+				st.Verified++
+			case "rejected":
+				st.Rejected++
+			case "revision":
+				st.Revision++
+			}
+		}
+
+		ordered := make([]models.StudentStat, 0, len(stats))
+		for _, st := range stats {
+			ordered = append(ordered, *st)
+		}
+		sort.Slice(ordered, func(i, j int) bool { return ordered[i].StudentName < ordered[j].StudentName })
+
+		for _, st := range ordered {
+			if err := fn(st); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestReport_Statistics_ExportCSV(t *testing.T) {
+	svc := statisticsService()
+
+	status, disposition, body := exportRequest(t, svc.Statistics, "/reports/statistics", "/reports/statistics?format=csv", "")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Regexp(t, `^attachment; filename="statistik-prestasi-\d{8}\.csv"$`, disposition)
+
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Status", "Jumlah"}, records[0])
+	assert.Equal(t, []string{"Total", "4"}, records[1])
+	assert.Equal(t, []string{"Diajukan", "1"}, records[3])
+	assert.Equal(t, []string{"Revisi", "1"}, records[6])
+
+	var perStudent [][]string
+	for i, rec := range records {
+		if len(rec) == 1 && rec[0] == "Statistik per Mahasiswa" {
+			perStudent = records[i+2:]
+		}
+	}
+	require.Len(t, perStudent, 2)
+	// diurutkan berdasarkan nama; sel diawali "=" dinetralkan
+	assert.Equal(t, "'=HYPERLINK(\"x\")", perStudent[0][0])
+	assert.Equal(t, []string{"Budi", "3", "1", "0", "1", "0", "1"}, perStudent[1])
+}
+
+func TestReport_Statistics_UnknownFormat(t *testing.T) {
+	status, _, _ := exportRequest(t, statisticsService().Statistics, "/reports/statistics", "/reports/statistics?format=docx", "")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func studentReportService(n int) *services.ReportService {
+	students, lecturers, refs := policyRepos("verified")
+	verified := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	const id1 = "000000000000000000000001"
+	refs.FindByStudentIDFn = func(ctx context.Context, studentID string) ([]models.AchievementReference, error) {
+		list := []models.AchievementReference{}
+		for i := 0; i < n; i++ {
+			mongoID := fmt.Sprintf("%024d", i+1)
+			list = append(list, models.AchievementReference{
+				ID: "ref-" + strconv.Itoa(i), StudentID: "s1", StudentName: "Siti Ä", MongoAchievementID: mongoID,
+				Status: "verified", VerifiedAt: &verified,
+			})
+		}
+		return list, nil
+	}
+	return services.NewReportService(refs, batchMongo(new(int), id1), students, lecturers)
+}
+
+func TestReport_StudentReport_ExportXLSX(t *testing.T) {
+	svc := studentReportService(2)
+
+	status, disposition, body := exportRequest(t, svc.StudentReport, "/reports/student/:id", "/reports/student/s1?format=xlsx", "")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Contains(t, disposition, ".xlsx")
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Daftar Prestasi" sheetId="1" r:id="rId1"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="B1" s="1" t="inlineStr"><is><t xml:space="preserve">Judul</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2"><v>1</v></c>`)
+	assert.Contains(t, sheet, `Prestasi 1`)
+	assert.Contains(t, sheet, `(data prestasi tidak ditemukan)`)
+	assert.Contains(t, sheet, `2025-03-01 10:00`)
+}
+
+func TestReport_StudentReport_ExportPDF(t *testing.T) {
+	t.Setenv("REPORT_INSTITUTION_NAME", "Universitas (Contoh)")
+
+	// cukup banyak baris untuk beberapa halaman
+	svc := studentReportService(120)
+
+	status, disposition, body := exportRequest(t, svc.StudentReport, "/reports/student/:id", "/reports/student/s1", "application/pdf")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Contains(t, disposition, ".pdf")
+
+	require.True(t, bytes.HasPrefix(body, []byte("%PDF-1.4")))
+	require.True(t, bytes.HasSuffix(body, []byte("%%EOF\n")))
+
+	pdf := string(body)
+	assert.Contains(t, pdf, `(Universitas \(Contoh\))`)
+	assert.Contains(t, pdf, `(Mahasiswa: Siti \304 \(\))`)
+	assert.Contains(t, pdf, `(Daftar Prestasi \(lanjutan\))`)
+
+	count := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindStringSubmatch(pdf)
+	require.Len(t, count, 2)
+	pages, _ := strconv.Atoi(count[1])
+	assert.Greater(t, pages, 1)
+	assert.Contains(t, pdf, fmt.Sprintf("(Halaman %d)", pages))
+
+	// setiap entri xref harus menunjuk ke awal objek yang benar
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.Len(t, startxref, 2)
+	xrefAt, _ := strconv.Atoi(startxref[1])
+	require.True(t, strings.HasPrefix(pdf[xrefAt:], "xref\n"))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xrefAt:], -1)
+	require.NotEmpty(t, entries)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "objek %d", i+1)
+	}
+}
+
+func TestReferenceRepo_EachStudentStat_Scoped(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	refRepo := repository.NewAchievementReferenceRepository(db)
+	filter := models.AchievementFilter{StudentIDs: []string{"s1", "s2"}}
+
+	mock.ExpectQuery(`GROUP BY ar.status`).WithArgs(pq.Array(filter.StudentIDs)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("verified", 2).AddRow("draft", 1))
+	counts, err := refRepo.CountByStatus(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"verified": 2, "draft": 1}, counts)
+
+	mock.ExpectQuery(`ar.student_id = ANY\(\$1\)(.|\n)*GROUP BY ar.student_id`).WithArgs(pq.Array(filter.StudentIDs)).
+		WillReturnRows(sqlmock.NewRows([]string{"student_id", "full_name", "total", "draft", "submitted", "verified", "rejected", "revision"}).
+			AddRow("s2", "Budi", 2, 1, 0, 1, 0, 0).
+			AddRow("s1", "Siti", 1, 0, 0, 1, 0, 0))
+
+	var names []string
+	err = refRepo.EachStudentStat(context.Background(), filter, func(st models.StudentStat) error {
+		names = append(names, st.StudentName)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Budi", "Siti"}, names)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,162 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reviewNow = time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+type reviewCalls struct {
+	advisor      string
+	limit        int
+	offset       int
+	breachBefore time.Time
+}
+
+func reviewService(calls *reviewCalls) *services.ReviewService {
+	students, lecturers, refs := policyRepos("submitted")
+
+	students.FindByAdvisorIDFn = func(ctx context.Context, advisorID string) ([]models.Student, error) {
+		calls.advisor = advisorID
+		return []models.Student{{ID: "s1"}, {ID: "s3"}}, nil
+	}
+
+	old := reviewNow.Add(-100 * time.Hour)
+	fresh := reviewNow.Add(-90 * time.Minute)
+	refs.FindForAdvisorPaginatedFn = func(ctx context.Context, ids []string, limit, offset int) ([]models.AchievementReference, int, error) {
+		calls.limit, calls.offset = limit, offset
+		return []models.AchievementReference{
+			{ID: "ref-1", StudentID: "s1", StudentName: "Siti", MongoAchievementID: "000000000000000000000001", Status: "submitted", SubmittedAt: &old},
+			{ID: "ref-2", StudentID: "s3", StudentName: "Andi", MongoAchievementID: "000000000000000000000002", Status: "submitted", SubmittedAt: &fresh},
+		}, 7, nil
+	}
+
+	reviews := &repo.ReviewMockRepo{
+		PendingByStudentFn: func(ctx context.Context, ids []string, breachBefore time.Time) ([]models.AdviseeQueueStat, error) {
+			calls.breachBefore = breachBefore
+			return []models.AdviseeQueueStat{
+				{StudentID: "s1", Pending: 4, Breached: 2, OldestAt: &old},
+				{StudentID: "s3", Pending: 3, OldestAt: &fresh},
+			}, nil
+		},
+		MetricsFn: func(ctx context.Context, from, to *time.Time, breachBefore time.Time) ([]models.ReviewerMetrics, error) {
+			calls.breachBefore = breachBefore
+			avg1, avg2 := 10.0, 40.0
+			return []models.ReviewerMetrics{
+				{LecturerID: "l1", Pending: 3, Breached: 1, Reviewed: 3, Approved: 2, Rejected: 1, AvgReviewHours: &avg1},
+				{LecturerID: "l2", Reviewed: 1, Approved: 1, AvgReviewHours: &avg2},
+				{LecturerID: "l3"},
+			}, nil
+		},
+	}
+
+	svc := services.NewReviewService(refs, reviews, batchMongo(new(int), "000000000000000000000001"), students, lecturers)
+	svc.SLA = 72 * time.Hour
+	svc.Now = func() time.Time { return reviewNow }
+	return svc
+}
+
+func reviewRequest(t *testing.T, handler fiber.Handler, route, userID string, perms []string, url string) (int, map[string]any) {
+	app := fiber.New()
+	app.Get(route, asUser(userID, perms), handler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	return resp.StatusCode, out
+}
+
+func TestReview_Queue(t *testing.T) {
+	calls := &reviewCalls{}
+	svc := reviewService(calls)
+
+	status, out := reviewRequest(t, svc.Queue, "/lecturers/review-queue", "u3", lecturerPerms, "/lecturers/review-queue?page=2&limit=5")
+	require.Equal(t, fiber.StatusOK, status)
+
+	assert.Equal(t, "l1", calls.advisor)
+	assert.Equal(t, 5, calls.limit)
+	assert.Equal(t, 5, calls.offset)
+	assert.Equal(t, reviewNow.Add(-72*time.Hour), calls.breachBefore)
+
+	data := out["data"].(map[string]any)
+	items := data["items"].([]any)
+	require.Len(t, items, 2)
+
+	first := items[0].(map[string]any)
+	assert.Equal(t, "Prestasi 1", first["title"])
+	assert.Equal(t, 100.0, first["waiting_hours"])
+	assert.Equal(t, true, first["sla_breached"])
+
+	second := items[1].(map[string]any)
+	assert.Equal(t, 1.5, second["waiting_hours"])
+	assert.Equal(t, false, second["sla_breached"])
+	assert.Equal(t, true, second["missing"])
+
+	summary := data["summary"].(map[string]any)
+	assert.Equal(t, 7.0, summary["pending"])
+	assert.Equal(t, 2.0, summary["breached"])
+	assert.Equal(t, 72.0, summary["sla_hours"])
+	assert.Equal(t, reviewNow.Add(-100*time.Hour).Format(time.RFC3339), summary["oldest_submitted_at"])
+	assert.Len(t, data["per_advisee"], 2)
+
+	meta := out["meta"].(map[string]any)
+	assert.Equal(t, 7.0, meta["total_data"])
+	assert.Equal(t, 2.0, meta["total_pages"])
+}
+
+func TestReview_Queue_Access(t *testing.T) {
+	calls := &reviewCalls{}
+
+	// dosen tidak dapat melihat antrian dosen lain
+	status, _ := reviewRequest(t, reviewService(calls).Queue, "/q", "u3", lecturerPerms, "/q?lecturer_id=l2")
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// mahasiswa tidak memiliki antrian
+	status, _ = reviewRequest(t, reviewService(calls).Queue, "/q", "u1", studentPerms, "/q")
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Empty(t, calls.advisor)
+
+	// admin memilih dosen lewat query
+	status, _ = reviewRequest(t, reviewService(calls).Queue, "/q", "u5", adminPerms, "/q?lecturer_id=l2")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "l2", calls.advisor)
+}
+
+func TestReview_Metrics(t *testing.T) {
+	calls := &reviewCalls{}
+	svc := reviewService(calls)
+
+	status, out := reviewRequest(t, svc.Metrics, "/m", "u5", adminPerms, "/m?date_from=2025-01-01")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, reviewNow.Add(-72*time.Hour), calls.breachBefore)
+
+	data := out["data"].(map[string]any)
+	assert.Len(t, data["lecturers"], 3)
+
+	overall := data["overall"].(map[string]any)
+	assert.Equal(t, 3.0, overall["pending"])
+	assert.Equal(t, 4.0, overall["reviewed"])
+	assert.Equal(t, 0.75, overall["approval_rate"])
+	// rata-rata tertimbang jumlah review: (3*10 + 1*40) / 4
+	assert.Equal(t, 17.5, overall["avg_review_hours"])
+
+	lecturers := data["lecturers"].([]any)
+	assert.Nil(t, lecturers[2].(map[string]any)["approval_rate"])
+
+	status, _ = reviewRequest(t, svc.Metrics, "/m", "u5", adminPerms, "/m?date_to=2025-13-01")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
@@ -0,0 +1,299 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/app/workflow"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func submissions(n int) []models.AchievementStatusHistory {
+	list := []models.AchievementStatusHistory{}
+	for i := 0; i < n; i++ {
+		list = append(list,
+			models.AchievementStatusHistory{ToStatus: "submitted"},
+			models.AchievementStatusHistory{ToStatus: "rejected"},
+		)
+	}
+	return list
+}
+
+func TestWorkflow_Diff(t *testing.T) {
+	before := models.AchievementSnapshot{
+		AchievementType: "competition",
+		Title:           "Juara 2",
+		Details:         map[string]interface{}{"rank": 2, "location": "Surabaya"},
+		Tags:            []string{},
+	}
+	after := models.AchievementSnapshot{
+		AchievementType: "competition",
+		Title:           "Juara 1",
+		Details:         map[string]interface{}{"rank": int32(1), "location": "Surabaya", "organizer": "Kemdikbud"},
+		Attachments:     []string{"sertifikat.pdf"},
+	}
+
+	changes := workflow.Diff(before, after)
+
+	fields := []string{}
+	for _, ch := range changes {
+		fields = append(fields, ch.Field)
+	}
+	assert.Equal(t, []string{"title", "attachments", "details.organizer", "details.rank"}, fields)
+}
+
+func TestAchievement_Update_RejectedMovesToRevision(t *testing.T) {
+	students, lecturers, refs := policyRepos("rejected")
+
+	var events []string
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		events = append(events, *entry.FromStatus+"->"+entry.ToStatus)
+		return nil
+	}
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{ID: primitive.NewObjectID(), Title: "Lama"}, nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, typeRegistry(), &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Patch("/achievements/:id", asUser("u1", studentPerms), svc.Update)
+
+	req := httptest.NewRequest("PATCH", "/achievements/m1", strings.NewReader(`{"achievement_type":"competition","title":"Baru","details":{"competition_name":"Gemastik","competition_level":"national"}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"rejected->revision"}, events)
+}
+
+func TestAchievement_Update_StaleStatusConflict(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+
+	// prestasi sudah disubmit request lain setelah dibaca
+	var touched []string
+	refs.TouchFn = func(ctx context.Context, id, expectedStatus string) error {
+		touched = append(touched, id+":"+expectedStatus)
+		return repository.ErrStatusConflict
+	}
+
+	updated := false
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{ID: primitive.NewObjectID(), Title: "Lama"}, nil
+		},
+		UpdateFn: func(ctx context.Context, a *models.AchievementMongo) error {
+			updated = true
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, typeRegistry(), &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Patch("/achievements/:id", asUser("u1", studentPerms), svc.Update)
+
+	req := httptest.NewRequest("PATCH", "/achievements/m1", strings.NewReader(`{"achievement_type":"competition","title":"Baru","details":{"competition_name":"Gemastik","competition_level":"national"}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	assert.Equal(t, []string{"ref-1:draft"}, touched)
+	assert.False(t, updated, "isi prestasi tidak ditulis jika status sudah berubah")
+}
+
+func TestAchievement_Update_VerifiedNotEditable(t *testing.T) {
+	students, lecturers, refs := policyRepos("verified")
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Patch("/achievements/:id", asUser("u1", studentPerms), svc.Update)
+
+	req := httptest.NewRequest("PATCH", "/achievements/m1", strings.NewReader(`{"title":"Baru"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAchievement_Resubmit(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     string
+		submitted  int
+		max        int
+		wantStatus int
+		wantEvents []string
+	}{
+		{"rejected resubmits", "rejected", 1, 3, fiber.StatusOK, []string{"rejected->revision", "revision->submitted"}},
+		{"revision resubmits", "revision", 2, 3, fiber.StatusOK, []string{"revision->submitted"}},
+		{"limit reached", "revision", 4, 3, fiber.StatusBadRequest, nil},
+		{"unlimited", "revision", 10, 0, fiber.StatusOK, []string{"revision->submitted"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			students, lecturers, refs := policyRepos(tc.status)
+
+			var events []string
+			refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+				events = append(events, *entry.FromStatus+"->"+entry.ToStatus)
+				return nil
+			}
+
+			history := &repo.AchievementHistoryMockRepo{
+				FindByReferenceIDFn: func(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error) {
+					return submissions(tc.submitted), nil
+				},
+			}
+
+			svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, history, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+			svc.MaxResubmissions = tc.max
+
+			app := fiber.New()
+			app.Post("/achievements/:id/submit", asUser("u1", studentPerms), svc.Submit)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/submit", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			assert.Equal(t, tc.wantEvents, events)
+		})
+	}
+}
+
+func TestAchievement_Reject_SavesSnapshot(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara 2", AchievementType: "competition"}, nil
+		},
+	}
+
+	var saved *models.AchievementReviewSnapshot
+	var entry *models.AchievementStatusHistory
+	refs.TransitionWithSnapshotFn = func(ctx context.Context, ref *models.AchievementReference, e *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error {
+		entry = e
+		saved = snap
+		return nil
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/reject", asUser("u3", lecturerPerms), svc.Reject)
+
+	req := httptest.NewRequest("POST", "/achievements/m1/reject", strings.NewReader(`{"note":"ranking salah"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, "ref-1", saved.AchievementRefID)
+	assert.Equal(t, "u3", *saved.ReviewedBy)
+	assert.Equal(t, "Juara 2", saved.Content.Title)
+	require.NotNil(t, entry)
+	assert.Equal(t, "rejected", entry.ToStatus)
+}
+
+func TestAchievement_Reject_ConflictKeepsNoSnapshot(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara 2"}, nil
+		},
+	}
+
+	// transisi dan snapshot gagal bersama; snapshot repo tidak boleh dipakai terpisah
+	refs.TransitionWithSnapshotFn = func(ctx context.Context, ref *models.AchievementReference, e *models.AchievementStatusHistory, snap *models.AchievementReviewSnapshot) error {
+		return repository.ErrStatusConflict
+	}
+	snapshots := &repo.ReviewSnapshotMockRepo{
+		CreateFn: func(ctx context.Context, snap *models.AchievementReviewSnapshot) error {
+			t.Fatal("snapshot tidak boleh disimpan di luar transaksi reject")
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, snapshots, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/reject", asUser("u3", lecturerPerms), svc.Reject)
+
+	req := httptest.NewRequest("POST", "/achievements/m1/reject", strings.NewReader(`{"note":"ranking salah"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestAchievement_Diff_AdvisorSeesChanges(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara 1", AchievementType: "competition"}, nil
+		},
+	}
+	snapshots := &repo.ReviewSnapshotMockRepo{
+		LatestFn: func(ctx context.Context, refID string) (*models.AchievementReviewSnapshot, error) {
+			return &models.AchievementReviewSnapshot{
+				Content: models.AchievementSnapshot{Title: "Juara 2", AchievementType: "competition"},
+			}, nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, snapshots, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/achievements/:id/diff", asUser("u3", lecturerPerms), svc.Diff)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements/m1/diff", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data struct {
+			Changes []models.FieldChange `json:"changes"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	require.Len(t, body.Data.Changes, 1)
+	assert.Equal(t, "title", body.Data.Changes[0].Field)
+	assert.Equal(t, "Juara 2", body.Data.Changes[0].Before)
+	assert.Equal(t, "Juara 1", body.Data.Changes[0].After)
+}
+
+func TestAchievement_Diff_NotReviewed(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/achievements/:id/diff", asUser("u1", studentPerms), svc.Diff)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements/m1/diff", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
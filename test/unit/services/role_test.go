@@ -0,0 +1,273 @@
+package services_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/services"
+	"uas/middleware"
+	"uas/test/unit/repo"
+	"uas/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func protectedApp(permission string) *fiber.App {
+	app := fiber.New()
+	app.Get("/protected", middleware.AuthRequired(), middleware.RequirePermission(permission), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func callProtected(t *testing.T, app *fiber.App, role string, perms []string) int {
+	token, err := utils.GenerateToken("user-1", role, "session-1", perms)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestRequirePermission_Wildcard(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_EXPIRED", "1")
+
+	app := protectedApp("role:manage")
+
+	assert.Equal(t, fiber.StatusOK, callProtected(t, app, "Superuser", []string{"*"}))
+	assert.Equal(t, fiber.StatusForbidden, callProtected(t, app, "Admin", []string{"user:manage"}),
+		"nama role Admin tidak lagi memberi akses otomatis")
+}
+
+func TestAuthRequired_ReloadsPermissionsFromDatabase(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_EXPIRED", "1")
+
+	access := &models.UserAccess{RoleName: "Dosen Wali", IsActive: true, Permissions: []string{"achievement:read"}}
+	roles := &repo.RoleMockRepo{
+		GetAccessFn: func(ctx context.Context, userID string) (*models.UserAccess, error) {
+			return access, nil
+		},
+	}
+
+	middleware.InitAuth(nil, nil, roles)
+	defer middleware.InitAuth(nil, nil, nil)
+
+	app := protectedApp("achievement:verify")
+
+	// token lama masih membawa permission yang sudah dicabut
+	assert.Equal(t, fiber.StatusForbidden, callProtected(t, app, "Dosen Wali", []string{"achievement:verify"}))
+
+	access.Permissions = append(access.Permissions, "achievement:verify")
+	assert.Equal(t, fiber.StatusOK, callProtected(t, app, "Dosen Wali", []string{"achievement:read"}))
+
+	access.IsActive = false
+	assert.Equal(t, fiber.StatusForbidden, callProtected(t, app, "Dosen Wali", []string{"achievement:verify"}))
+}
+
+func TestRole_Create_Validation(t *testing.T) {
+	var created *models.Role
+	roles := &repo.RoleMockRepo{
+		CreateFn: func(ctx context.Context, role *models.Role) error {
+			created = role
+			role.ID = "role-1"
+			return nil
+		},
+	}
+
+	service := services.NewRoleService(roles, &repo.PermissionMockRepo{})
+
+	app := fiber.New()
+	app.Post("/roles", service.Create)
+
+	post := func(body string) int {
+		req := httptest.NewRequest("POST", "/roles", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusBadRequest, post(`{"name":"","profile_type":"student"}`))
+	assert.Equal(t, fiber.StatusBadRequest, post(`{"name":"Kaprodi","profile_type":"staff"}`))
+	assert.Nil(t, created)
+
+	assert.Equal(t, fiber.StatusCreated, post(`{"name":"Kaprodi","mfa_required":true}`))
+	require.NotNil(t, created)
+	assert.Equal(t, models.ProfileNone, created.ProfileType)
+	assert.True(t, created.MFARequired)
+}
+
+func TestRole_Delete_InUse(t *testing.T) {
+	deleted := false
+	roles := &repo.RoleMockRepo{
+		GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+			return &models.Role{ID: id, Name: "Mahasiswa"}, nil
+		},
+		CountUsersFn: func(ctx context.Context, id string) (int, error) {
+			return 3, nil
+		},
+		DeleteFn: func(ctx context.Context, id string) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	service := services.NewRoleService(roles, &repo.PermissionMockRepo{})
+
+	app := fiber.New()
+	app.Delete("/roles/:id", service.Delete)
+
+	req := httptest.NewRequest("DELETE", "/roles/2c35d326-a744-4bdd-8a53-f78bbd2c58d3", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.False(t, deleted)
+}
+
+func TestRole_SetPermissions_UnknownPermission(t *testing.T) {
+	roles := &repo.RoleMockRepo{
+		GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+			return &models.Role{ID: id}, nil
+		},
+		SetPermissionsFn: func(ctx context.Context, roleID string, permissionIDs []string) error {
+			t.Fatal("permission tidak dikenal tidak boleh disimpan")
+			return nil
+		},
+	}
+
+	service := services.NewRoleService(roles, &repo.PermissionMockRepo{})
+
+	app := fiber.New()
+	app.Put("/roles/:id/permissions", service.SetPermissions)
+
+	body := `{"permission_ids":["5f0e4a8e-1111-4c1b-9a0e-1d2c3b4a5f60"]}`
+	req := httptest.NewRequest("PUT", "/roles/2c35d326-a744-4bdd-8a53-f78bbd2c58d3/permissions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRole_Update_ProfileTypeInUse(t *testing.T) {
+	var updated *models.Role
+	roles := &repo.RoleMockRepo{
+		GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+			return &models.Role{ID: id, Name: "Mahasiswa", ProfileType: models.ProfileStudent}, nil
+		},
+		CountUsersFn: func(ctx context.Context, id string) (int, error) {
+			return 3, nil
+		},
+		UpdateFn: func(ctx context.Context, role *models.Role) error {
+			updated = role
+			return nil
+		},
+	}
+
+	service := services.NewRoleService(roles, &repo.PermissionMockRepo{})
+
+	app := fiber.New()
+	app.Put("/roles/:id", service.Update)
+
+	put := func(body string) int {
+		req := httptest.NewRequest("PUT", "/roles/2c35d326-a744-4bdd-8a53-f78bbd2c58d3", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusBadRequest, put(`{"name":"Mahasiswa","profile_type":"none"}`))
+	assert.Nil(t, updated)
+
+	// field lain tetap bisa diubah selama profile_type sama
+	assert.Equal(t, fiber.StatusOK, put(`{"name":"Mahasiswa Aktif","profile_type":"student"}`))
+	require.NotNil(t, updated)
+	assert.Equal(t, "Mahasiswa Aktif", updated.Name)
+}
+
+func TestRole_Permissions_KeepAdminRole(t *testing.T) {
+	const (
+		roleID   = "2c35d326-a744-4bdd-8a53-f78bbd2c58d3"
+		manageID = "5f0e4a8e-1111-4c1b-9a0e-1d2c3b4a5f60"
+		readID   = "5f0e4a8e-2222-4c1b-9a0e-1d2c3b4a5f60"
+	)
+	perms := map[string]*models.Permission{
+		manageID: {ID: manageID, Name: "role:manage"},
+		readID:   {ID: readID, Name: "achievement:read"},
+	}
+
+	otherAdmins := 0
+	saved := false
+	roles := &repo.RoleMockRepo{
+		GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+			return &models.Role{ID: id, Name: "Admin"}, nil
+		},
+		ListPermissionsFn: func(ctx context.Context, roleID string) ([]models.Permission, error) {
+			return []models.Permission{*perms[manageID], *perms[readID]}, nil
+		},
+		CountRolesWithPermissionFn: func(ctx context.Context, names []string, exceptRoleID string) (int, error) {
+			assert.ElementsMatch(t, []string{"*", "role:manage"}, names)
+			assert.Equal(t, roleID, exceptRoleID)
+			return otherAdmins, nil
+		},
+		SetPermissionsFn: func(ctx context.Context, roleID string, permissionIDs []string) error {
+			saved = true
+			return nil
+		},
+		RemovePermissionFn: func(ctx context.Context, roleID string, permissionID string) (bool, error) {
+			saved = true
+			return true, nil
+		},
+	}
+	permissions := &repo.PermissionMockRepo{
+		GetByIDFn: func(ctx context.Context, id string) (*models.Permission, error) {
+			return perms[id], nil
+		},
+	}
+
+	service := services.NewRoleService(roles, permissions)
+
+	app := fiber.New()
+	app.Put("/roles/:id/permissions", service.SetPermissions)
+	app.Delete("/roles/:id/permissions/:permissionId", service.RemovePermission)
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	setBody := `{"permission_ids":["` + readID + `"]}`
+	removePath := "/roles/" + roleID + "/permissions/" + manageID
+
+	// role ini satu-satunya pemegang role:manage
+	assert.Equal(t, fiber.StatusBadRequest, do("PUT", "/roles/"+roleID+"/permissions", setBody))
+	assert.Equal(t, fiber.StatusBadRequest, do("DELETE", removePath, ""))
+	assert.False(t, saved)
+
+	// permission admin tetap ada di daftar baru
+	assert.Equal(t, fiber.StatusOK, do("PUT", "/roles/"+roleID+"/permissions", `{"permission_ids":["`+manageID+`"]}`))
+	assert.Equal(t, fiber.StatusOK, do("DELETE", "/roles/"+roleID+"/permissions/"+readID, ""))
+
+	// role lain masih memegang permission admin
+	otherAdmins = 1
+	saved = false
+	assert.Equal(t, fiber.StatusOK, do("PUT", "/roles/"+roleID+"/permissions", setBody))
+	assert.Equal(t, fiber.StatusOK, do("DELETE", removePath, ""))
+	assert.True(t, saved)
+}
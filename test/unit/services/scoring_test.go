@@ -0,0 +1,290 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uas/app/jobs"
+	"uas/app/models"
+	"uas/app/outbox"
+	"uas/app/scoring"
+	"uas/app/services"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var competitionRules = &models.PointRuleSet{
+	Version: 2,
+	Rules: []models.PointRule{
+		{AchievementType: "competition", Points: 10},
+		{AchievementType: "competition", Field: "competition_level", Value: "national", Points: 30},
+		{AchievementType: "competition", Field: "rank", Value: "1", Points: 30},
+		{AchievementType: "competition", Field: "medalType", Value: "gold", Points: 15},
+		{AchievementType: "publication", Points: 20},
+	},
+}
+
+func TestScoring_Score(t *testing.T) {
+	cases := []struct {
+		name string
+		ach  models.AchievementMongo
+		want int
+	}{
+		{
+			"national champion with gold medal",
+			models.AchievementMongo{AchievementType: "competition", Details: map[string]interface{}{
+				"competition_level": "National", "rank": float64(1), "medalType": "gold",
+			}},
+			85,
+		},
+		{
+			"rank stored as int32",
+			models.AchievementMongo{AchievementType: "competition", Details: map[string]interface{}{"rank": int32(1)}},
+			40,
+		},
+		{
+			"base only",
+			models.AchievementMongo{AchievementType: "publication"},
+			20,
+		},
+		{
+			"unknown type",
+			models.AchievementMongo{AchievementType: "other", Details: map[string]interface{}{"rank": 1}},
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, scoring.Score(competitionRules.Rules, &tc.ach))
+		})
+	}
+}
+
+func TestScoring_Validate(t *testing.T) {
+	errs := scoring.Validate([]models.PointRule{
+		{AchievementType: "competition", Points: 10},
+		{AchievementType: "", Points: 5},
+		{AchievementType: "competition", Field: "rank", Points: 5},
+		{AchievementType: "competition", Value: "1", Points: 5},
+		{AchievementType: "competition", Points: 10},
+	})
+
+	assert.NotContains(t, errs, "rules[0]")
+	assert.Contains(t, errs, "rules[1]")
+	assert.Contains(t, errs, "rules[2]")
+	assert.Contains(t, errs, "rules[3]")
+	assert.Equal(t, "aturan duplikat", errs["rules[4]"])
+
+	assert.Contains(t, scoring.Validate(nil), "rules")
+}
+
+func TestAchievement_Verify_AwardsPoints(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	var points, version int
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{
+				AchievementType: "competition",
+				Details:         map[string]interface{}{"competition_level": "national", "rank": float64(1)},
+			}, nil
+		},
+		SetPointsFn: func(ctx context.Context, id string, p, v int) error {
+			points, version = p, v
+			return nil
+		},
+	}
+
+	// poin ikut ditulis ke outbox dalam transaksi verifikasi
+	var msg *models.OutboxMessage
+	refs.TransitionWithOutboxFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, m *models.OutboxMessage) error {
+		assert.Equal(t, "verified", entry.ToStatus)
+		msg = m
+		return nil
+	}
+	rules := &repo.PointRuleMockRepo{
+		ActiveFn: func(ctx context.Context) (*models.PointRuleSet, error) {
+			return competitionRules, nil
+		},
+	}
+
+	var facts models.AchievementFacts
+	refs.UpdateFactsFn = func(ctx context.Context, mongoID string, f models.AchievementFacts) error {
+		facts = f
+		return nil
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, rules, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/verify", asUser("u3", lecturerPerms), svc.Verify)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/verify", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, msg)
+	assert.Equal(t, outbox.OpSetPoints, msg.Operation)
+	assert.Equal(t, "m1", msg.AggregateID)
+	assert.JSONEq(t, `{"points":70,"points_version":2}`, string(msg.Payload))
+	assert.Equal(t, 70, points)
+	assert.Equal(t, 2, version)
+
+	// salinan analitik ikut diperbarui
+	assert.Equal(t, models.AchievementFacts{AchievementType: "competition", CompetitionLevel: "national", Points: 70}, facts)
+}
+
+func TestAchievement_Verify_MongoFailureKeepsPointsInOutbox(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{
+				AchievementType: "competition",
+				Details:         map[string]interface{}{"competition_level": "national"},
+			}, nil
+		},
+		SetPointsFn: func(ctx context.Context, id string, p, v int) error {
+			return errors.New("mongo down")
+		},
+	}
+	rules := &repo.PointRuleMockRepo{
+		ActiveFn: func(ctx context.Context) (*models.PointRuleSet, error) {
+			return competitionRules, nil
+		},
+	}
+
+	var msg *models.OutboxMessage
+	refs.TransitionWithOutboxFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory, m *models.OutboxMessage) error {
+		msg = m
+		return nil
+	}
+	var failed []int64
+	outboxRepo := &repo.OutboxMockRepo{
+		MarkFailedFn: func(ctx context.Context, id int64, reason string) error {
+			failed = append(failed, id)
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, rules, &repo.AchievementTypeMockRepo{}, outboxRepo, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/verify", asUser("u3", lecturerPerms), svc.Verify)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/verify", nil))
+	require.NoError(t, err)
+
+	// verifikasi tetap berhasil; poin menunggu di outbox untuk diulang worker
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, msg)
+	assert.Equal(t, outbox.OpSetPoints, msg.Operation)
+	assert.Len(t, failed, 1)
+}
+
+func TestPointRule_Create(t *testing.T) {
+	var (
+		created  *models.PointRuleSet
+		activate bool
+	)
+	svc := services.NewPointRuleService(&repo.PointRuleMockRepo{
+		CreateFn: func(ctx context.Context, set *models.PointRuleSet, act bool) error {
+			created, activate = set, act
+			set.Version = 3
+			return nil
+		},
+	})
+
+	app := fiber.New()
+	app.Post("/point-rules", asUser("admin-1", adminPerms), svc.Create)
+
+	send := func(body string) int {
+		req := httptest.NewRequest("POST", "/point-rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusBadRequest, send(`{"rules":[{"achievement_type":"competition","field":"rank","points":5}]}`))
+	assert.Nil(t, created)
+
+	assert.Equal(t, fiber.StatusCreated, send(`{"note":"naikkan poin juara","rules":[{"achievement_type":" competition ","field":"rank","value":"1","points":40}]}`))
+	require.NotNil(t, created)
+	assert.True(t, activate)
+	assert.Equal(t, "competition", created.Rules[0].AchievementType)
+	assert.Equal(t, "admin-1", *created.CreatedBy)
+
+	assert.Equal(t, fiber.StatusCreated, send(`{"activate":false,"rules":[{"achievement_type":"publication","points":20}]}`))
+	assert.False(t, activate)
+}
+
+func TestJobs_RecalculatePoints(t *testing.T) {
+	store := map[string]*models.AchievementMongo{
+		"m1": {AchievementType: "competition", Points: 10, PointsVersion: 1, Details: map[string]interface{}{"rank": "1"}},
+		"m2": {AchievementType: "publication", Points: 20, PointsVersion: 2},
+		"m3": {AchievementType: "competition"},
+	}
+
+	var msgs []*models.OutboxMessage
+	refs := &repo.AchievementReferenceMockRepo{
+		FindAllFn: func(ctx context.Context) ([]models.AchievementReference, error) {
+			return []models.AchievementReference{
+				{MongoAchievementID: "m1", Status: "verified"},
+				{MongoAchievementID: "m2", Status: "verified"},
+				{MongoAchievementID: "m3", Status: "submitted"},
+				{MongoAchievementID: "m4", Status: "verified"},
+			}, nil
+		},
+		UpdateFactsWithOutboxFn: func(ctx context.Context, mongoID string, facts models.AchievementFacts, msg *models.OutboxMessage) error {
+			assert.Equal(t, "m1", mongoID)
+			assert.Equal(t, outbox.OpSetPoints, msg.Operation)
+			msgs = append(msgs, msg)
+			return nil
+		},
+	}
+
+	lookups := 0
+	var points []int
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDsFn: func(ctx context.Context, ids []string, fields ...string) (map[string]*models.AchievementMongo, error) {
+			lookups++
+			assert.ElementsMatch(t, []string{"m1", "m2", "m4"}, ids)
+			docs := map[string]*models.AchievementMongo{}
+			for _, id := range ids {
+				if a, ok := store[id]; ok {
+					copy := *a
+					docs[id] = &copy
+				}
+			}
+			return docs, nil
+		},
+		SetPointsFn: func(ctx context.Context, id string, p, version int) error {
+			points = append(points, p)
+			return nil
+		},
+		UpdateFn: func(ctx context.Context, a *models.AchievementMongo) error {
+			t.Fatal("poin disimpan lewat outbox, bukan Update")
+			return nil
+		},
+	}
+
+	res, err := jobs.RecalculatePoints(context.Background(), refs, mongo, &repo.OutboxMockRepo{}, competitionRules, true)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.RecalculateResult{Checked: 3, Changed: 1, Failed: 1}, res)
+	assert.Empty(t, msgs)
+
+	res, err = jobs.RecalculatePoints(context.Background(), refs, mongo, &repo.OutboxMockRepo{}, competitionRules, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Changed)
+	assert.Len(t, msgs, 1)
+	assert.Len(t, points, 1)
+	assert.Equal(t, 2, lookups, "satu query $in per batch")
+}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -10,7 +11,6 @@ import (
 
 	"uas/app/services"
 	"uas/test/unit/repo"
-	"uas/utils"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gofiber/fiber/v2"
@@ -43,11 +43,18 @@ func TestUser_Create_Mahasiswa_Success(t *testing.T) {
 
     lecturerRepo := &repo.LecturerMockRepo{}
 
+    roleRepo := &repo.RoleMockRepo{
+        GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+            return &models.Role{ID: id, Name: "Mahasiswa", ProfileType: models.ProfileStudent}, nil
+        },
+    }
+
     service := services.NewUserService(
         db,
         userRepo,
         studentRepo,
         lecturerRepo,
+        roleRepo,
     )
 
     app := fiber.New()
@@ -58,7 +65,7 @@ func TestUser_Create_Mahasiswa_Success(t *testing.T) {
         "email": "panji@test.com",
         "password": "password123",
         "full_name": "Panji",
-        "role_id": "` + utils.ROLE_MAHASISWA + `"
+        "role_id": "2c35d326-a744-4bdd-8a53-f78bbd2c58d3"
     }`
 
     req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
@@ -69,3 +76,75 @@ func TestUser_Create_Mahasiswa_Success(t *testing.T) {
 
     assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
 }
+
+func TestUser_Create_Lecturer_UsesRoleProfileType(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    require.NoError(t, err)
+    defer db.Close()
+
+    mock.ExpectBegin()
+    mock.ExpectCommit()
+
+    lecturerCreated := false
+
+    service := services.NewUserService(
+        db,
+        &repo.UserMockRepo{
+            CreateFn: func(tx *sql.Tx, user *models.Users) (string, error) {
+                return "user-456", nil
+            },
+        },
+        &repo.StudentMockRepo{
+            CreateFn: func(tx *sql.Tx, userID, studentID string) error {
+                t.Fatal("role lecturer tidak boleh membuat profil mahasiswa")
+                return nil
+            },
+        },
+        &repo.LecturerMockRepo{
+            CreateFn: func(tx *sql.Tx, userID, lecturerID string) error {
+                lecturerCreated = true
+                return nil
+            },
+        },
+        &repo.RoleMockRepo{
+            GetByIDFn: func(ctx context.Context, id string) (*models.Role, error) {
+                return &models.Role{ID: id, Name: "Dosen Pembimbing", ProfileType: models.ProfileLecturer}, nil
+            },
+        },
+    )
+
+    app := fiber.New()
+    app.Post("/users", service.Create)
+
+    body := `{"username":"dosen2","email":"dosen2@test.com","password":"password123","full_name":"Dosen Dua","role_id":"5f0e4a8e-1111-4c1b-9a0e-1d2c3b4a5f60"}`
+
+    req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := app.Test(req)
+    require.NoError(t, err)
+
+    assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+    assert.True(t, lecturerCreated)
+}
+
+func TestUser_Create_UnknownRole(t *testing.T) {
+    db, _, err := sqlmock.New()
+    require.NoError(t, err)
+    defer db.Close()
+
+    service := services.NewUserService(db, &repo.UserMockRepo{}, &repo.StudentMockRepo{}, &repo.LecturerMockRepo{}, &repo.RoleMockRepo{})
+
+    app := fiber.New()
+    app.Post("/users", service.Create)
+
+    body := `{"username":"x","email":"x@test.com","password":"password123","full_name":"X","role_id":"5f0e4a8e-1111-4c1b-9a0e-1d2c3b4a5f60"}`
+
+    req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := app.Test(req)
+    require.NoError(t, err)
+
+    assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,389 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"uas/app/events"
+	"uas/app/models"
+	"uas/app/services"
+	"uas/app/webhook"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSubID  = "00000000-0000-0000-0000-0000000000a1"
+	testSecret = "rahasia-penerima-webhook"
+)
+
+// webhookStore menyimpan pengiriman di memori layaknya webhook_deliveries.
+type webhookStore struct {
+	sub        models.WebhookSubscription
+	deliveries []*models.WebhookDelivery
+}
+
+func newWebhookStore(url string) (*webhookStore, *repo.WebhookMockRepo) {
+	st := &webhookStore{sub: models.WebhookSubscription{
+		ID:       testSubID,
+		URL:      url,
+		Secret:   testSecret,
+		Events:   []string{webhook.EventCreated, webhook.EventVerified},
+		IsActive: true,
+	}}
+
+	find := func(id string) *models.WebhookDelivery {
+		for _, d := range st.deliveries {
+			if d.ID == id {
+				return d
+			}
+		}
+		return nil
+	}
+
+	mock := &repo.WebhookMockRepo{
+		GetSubscriptionFn: func(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+			if id != st.sub.ID {
+				return nil, nil
+			}
+			sub := st.sub
+			return &sub, nil
+		},
+		ActiveSubscriptionsForFn: func(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+			for _, e := range st.sub.Events {
+				if e == eventType && st.sub.IsActive {
+					return []models.WebhookSubscription{st.sub}, nil
+				}
+			}
+			return nil, nil
+		},
+		CreateDeliveryFn: func(ctx context.Context, d *models.WebhookDelivery) error {
+			d.ID = fmt.Sprintf("00000000-0000-0000-0000-%012d", len(st.deliveries)+1)
+			saved := *d
+			st.deliveries = append(st.deliveries, &saved)
+			return nil
+		},
+		GetDeliveryFn: func(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+			if d := find(id); d != nil {
+				copied := *d
+				return &copied, nil
+			}
+			return nil, nil
+		},
+		ClaimDueFn: func(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+			var due []models.WebhookDelivery
+			for _, d := range st.deliveries {
+				if d.Status == models.WebhookPending && d.NextAttemptAt != nil {
+					due = append(due, *d)
+				}
+			}
+			return due, nil
+		},
+		SaveAttemptFn: func(ctx context.Context, d *models.WebhookDelivery) error {
+			*find(d.ID) = *d
+			return nil
+		},
+	}
+
+	return st, mock
+}
+
+// receiver adalah penerima webhook lokal yang memverifikasi signature dan
+// merespon sesuai urutan codes (kode terakhir diulang).
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+	verified []bool
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	rc.verified = append(rc.verified, webhook.Verify(testSecret, ts, body, r.Header.Get(webhook.HeaderSignature)))
+
+	code := rc.codes[len(rc.codes)-1]
+	if len(rc.requests) <= len(rc.codes) {
+		code = rc.codes[len(rc.requests)-1]
+	}
+	w.WriteHeader(code)
+	fmt.Fprintf(w, "respon %d", code)
+}
+
+func createdEvent() *models.Event {
+	ref := &models.AchievementReference{ID: "ref-1", StudentID: "s1", MongoAchievementID: "m1", Status: "draft"}
+	return events.StatusChanged(ref, "", "l1")
+}
+
+func TestWebhook_SignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	sig := webhook.Sign("secret", 1700000000, body)
+
+	assert.True(t, strings.HasPrefix(sig, "sha256="))
+	assert.True(t, webhook.Verify("secret", 1700000000, body, sig))
+	assert.False(t, webhook.Verify("lain", 1700000000, body, sig))
+	assert.False(t, webhook.Verify("secret", 1700000001, body, sig))
+	assert.False(t, webhook.Verify("secret", 1700000000, []byte(`{"id":"2"}`), sig))
+}
+
+func TestWebhook_Backoff(t *testing.T) {
+	d := webhook.NewDispatcher(&repo.WebhookMockRepo{})
+
+	assert.Equal(t, 30*time.Second, d.Backoff(1))
+	assert.Equal(t, time.Minute, d.Backoff(2))
+	assert.Equal(t, 4*time.Minute, d.Backoff(4))
+	assert.Equal(t, 6*time.Hour, d.Backoff(20))
+}
+
+func TestWebhook_PublishedEventIsDelivered(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusOK}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st, mock := newWebhookStore(srv.URL)
+	dispatcher := webhook.NewDispatcher(mock)
+
+	var log []models.Event
+	publisher := events.NewPublisher(eventLog(&log), events.NewMemoryBroker(), dispatcher)
+	publisher.Publish(context.Background(), createdEvent())
+
+	require.Len(t, st.deliveries, 1)
+	assert.Equal(t, webhook.EventCreated, st.deliveries[0].EventType)
+	assert.Equal(t, "1", st.deliveries[0].EventID)
+
+	select {
+	case <-dispatcher.Woken():
+	default:
+		t.Fatal("worker tidak dibangunkan")
+	}
+
+	done, failed, err := dispatcher.ProcessDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 0, failed)
+
+	require.Len(t, rc.requests, 1)
+	req := rc.requests[0]
+	assert.True(t, rc.verified[0])
+	assert.Equal(t, webhook.EventCreated, req.Header.Get(webhook.HeaderEvent))
+	assert.Equal(t, "1", req.Header.Get(webhook.HeaderEventID))
+	assert.Equal(t, st.deliveries[0].ID, req.Header.Get(webhook.HeaderDelivery))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(rc.bodies[0], &payload))
+	assert.Equal(t, webhook.EventCreated, payload["type"])
+	assert.Equal(t, "m1", payload["data"].(map[string]any)["achievement_id"])
+
+	d := st.deliveries[0]
+	assert.Equal(t, models.WebhookSucceeded, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	require.NotNil(t, d.ResponseCode)
+	assert.Equal(t, http.StatusOK, *d.ResponseCode)
+	assert.NotNil(t, d.DeliveredAt)
+}
+
+func TestWebhook_HandleIgnoresUnsubscribedTransitions(t *testing.T) {
+	st, mock := newWebhookStore("http://127.0.0.1:0")
+	dispatcher := webhook.NewDispatcher(mock)
+
+	ref := &models.AchievementReference{ID: "ref-1", StudentID: "s1", Status: "revision"}
+	dispatcher.Handle(context.Background(), events.StatusChanged(ref, "rejected", "l1"))
+	// tidak dilanggan
+	ref.Status = "submitted"
+	dispatcher.Handle(context.Background(), events.StatusChanged(ref, "draft", "l1"))
+	// kembali ke draft bukan pembuatan prestasi
+	ref.Status = "draft"
+	dispatcher.Handle(context.Background(), events.StatusChanged(ref, "submitted", "l1"))
+	dispatcher.Handle(context.Background(), &models.Event{Type: events.TypeNotificationCreated, UserID: "u1"})
+
+	assert.Empty(t, st.deliveries)
+}
+
+func TestWebhook_RetryWithBackoff(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusInternalServerError, http.StatusOK}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st, mock := newWebhookStore(srv.URL)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	dispatcher := webhook.NewDispatcher(mock)
+	dispatcher.Now = func() time.Time { return now }
+
+	dispatcher.Handle(context.Background(), &models.Event{ID: 9, Type: events.TypeStatusChanged, Data: map[string]any{"status": "verified", "from_status": "submitted"}})
+	require.Len(t, st.deliveries, 1)
+	d := st.deliveries[0]
+
+	_, failed, err := dispatcher.ProcessDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, models.WebhookPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, *d.ResponseCode)
+	assert.Equal(t, "respon 500", *d.ResponseBody)
+	assert.Contains(t, *d.LastError, "500")
+	assert.Equal(t, now.Add(30*time.Second), *d.NextAttemptAt)
+
+	done, _, err := dispatcher.ProcessDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, done)
+	assert.Equal(t, models.WebhookSucceeded, d.Status)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Nil(t, d.LastError)
+	assert.Nil(t, d.NextAttemptAt)
+	assert.True(t, rc.verified[1])
+}
+
+func TestWebhook_FailsAfterMaxAttempts(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st, mock := newWebhookStore(srv.URL)
+	dispatcher := webhook.NewDispatcher(mock)
+	dispatcher.MaxAttempts = 3
+
+	dispatcher.Handle(context.Background(), createdEvent())
+	for i := 0; i < 5; i++ {
+		_, _, err := dispatcher.ProcessDue(context.Background(), 10)
+		require.NoError(t, err)
+	}
+
+	d := st.deliveries[0]
+	assert.Len(t, rc.requests, 3)
+	assert.Equal(t, models.WebhookFailed, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Nil(t, d.NextAttemptAt)
+}
+
+func TestWebhook_InactiveSubscriptionAbandonsDelivery(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusOK}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st, mock := newWebhookStore(srv.URL)
+	dispatcher := webhook.NewDispatcher(mock)
+
+	dispatcher.Handle(context.Background(), createdEvent())
+	st.sub.IsActive = false
+
+	_, failed, err := dispatcher.ProcessDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+	assert.Empty(t, rc.requests)
+	assert.Equal(t, models.WebhookFailed, st.deliveries[0].Status)
+}
+
+func TestWebhook_Redeliver(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusBadGateway, http.StatusOK}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st, mock := newWebhookStore(srv.URL)
+	dispatcher := webhook.NewDispatcher(mock)
+	dispatcher.MaxAttempts = 1
+
+	dispatcher.Handle(context.Background(), createdEvent())
+	_, _, err := dispatcher.ProcessDue(context.Background(), 10)
+	require.NoError(t, err)
+	original := st.deliveries[0]
+	require.Equal(t, models.WebhookFailed, original.Status)
+
+	svc := services.NewWebhookService(mock, dispatcher)
+	app := fiber.New()
+	app.Post("/webhooks/deliveries/:id/redeliver", asUser("u5", adminPerms), svc.Redeliver)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/webhooks/deliveries/"+original.ID+"/redeliver", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, st.deliveries, 2)
+	redelivery := st.deliveries[1]
+	assert.NotEqual(t, original.ID, redelivery.ID)
+	assert.Equal(t, original.EventID, redelivery.EventID)
+	assert.Equal(t, models.WebhookSucceeded, redelivery.Status)
+	assert.Equal(t, models.WebhookFailed, original.Status)
+
+	require.Len(t, rc.requests, 2)
+	assert.Equal(t, rc.bodies[0], rc.bodies[1])
+	assert.Equal(t, original.EventID, rc.requests[1].Header.Get(webhook.HeaderEventID))
+	assert.Equal(t, redelivery.ID, rc.requests[1].Header.Get(webhook.HeaderDelivery))
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/webhooks/deliveries/00000000-0000-0000-0000-000000000099/redeliver", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestWebhook_Create(t *testing.T) {
+	var saved *models.WebhookSubscription
+	mock := &repo.WebhookMockRepo{
+		CreateSubscriptionFn: func(ctx context.Context, sub *models.WebhookSubscription) error {
+			sub.ID = testSubID
+			saved = sub
+			return nil
+		},
+	}
+	svc := services.NewWebhookService(mock, webhook.NewDispatcher(mock))
+
+	app := fiber.New()
+	app.Post("/webhooks", asUser("u5", adminPerms), svc.Create)
+
+	post := func(body string) *http.Response {
+		req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("tidak valid", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url":"ftp://example.com","events":["achievement.created"]}`,
+			`{"url":"https://example.com/hook","events":[]}`,
+			`{"url":"https://example.com/hook","events":["achievement.updated"]}`,
+			`{"url":"https://example.com/hook","events":["achievement.created"],"secret":"pendek"}`,
+		} {
+			assert.Equal(t, fiber.StatusBadRequest, post(body).StatusCode, body)
+		}
+		assert.Nil(t, saved)
+	})
+
+	t.Run("secret dibuatkan server", func(t *testing.T) {
+		resp := post(`{"url":"https://example.com/hook","events":["achievement.created","achievement.created","achievement.verified"]}`)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+		var body struct {
+			Data struct {
+				Subscription map[string]any `json:"subscription"`
+				Secret       string         `json:"secret"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		require.NotNil(t, saved)
+		assert.True(t, strings.HasPrefix(body.Data.Secret, "whsec_"))
+		assert.Equal(t, saved.Secret, body.Data.Secret)
+		assert.NotContains(t, body.Data.Subscription, "secret")
+		assert.Equal(t, []string{webhook.EventCreated, webhook.EventVerified}, saved.Events)
+		assert.True(t, saved.IsActive)
+		assert.Equal(t, "u5", *saved.CreatedBy)
+	})
+}
@@ -0,0 +1,213 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/app/services"
+	"uas/app/workflow"
+	"uas/test/unit/repo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Transitions(t *testing.T) {
+	cases := []struct {
+		from  string
+		event workflow.Event
+		to    string
+		ok    bool
+	}{
+		{"draft", workflow.Submit, "submitted", true},
+		{"draft", workflow.Delete, "deleted", true},
+		{"draft", workflow.Verify, "", false},
+		{"submitted", workflow.Verify, "verified", true},
+		{"submitted", workflow.Reject, "rejected", true},
+		{"submitted", workflow.Delete, "", false},
+		{"rejected", workflow.Revise, "revision", true},
+		{"rejected", workflow.Submit, "", false},
+		{"revision", workflow.Submit, "submitted", true},
+		{"verified", workflow.Reject, "", false},
+		{"deleted", workflow.Submit, "", false},
+	}
+
+	for _, tc := range cases {
+		to, err := workflow.Next(tc.from, tc.event)
+		if !tc.ok {
+			assert.True(t, errors.Is(err, workflow.ErrInvalidTransition), "%s -%s->", tc.from, tc.event)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.to, to)
+	}
+}
+
+func TestWorkflow_Apply_Reject(t *testing.T) {
+	now := time.Now()
+	ref := &models.AchievementReference{ID: "ref-1", Status: "submitted"}
+
+	entry, err := workflow.Apply(ref, workflow.Reject, "u3", "kurang bukti", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "rejected", ref.Status)
+	assert.Equal(t, "kurang bukti", *ref.RejectionNote)
+	assert.Equal(t, "u3", *ref.VerifiedBy)
+
+	assert.Equal(t, "ref-1", entry.AchievementRefID)
+	assert.Equal(t, "submitted", *entry.FromStatus)
+	assert.Equal(t, "rejected", entry.ToStatus)
+	assert.Equal(t, "u3", *entry.ActorID)
+	assert.Equal(t, "kurang bukti", *entry.Note)
+}
+
+func TestAchievement_Submit_RecordsTransition(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+
+	var recorded *models.AchievementStatusHistory
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		recorded = entry
+		return nil
+	}
+
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/submit", asUser("u1", studentPerms), svc.Submit)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/submit", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, "draft", *recorded.FromStatus)
+	assert.Equal(t, "submitted", recorded.ToStatus)
+	assert.Equal(t, "u1", *recorded.ActorID)
+}
+
+func TestAchievement_Reject_RecordsNote(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	var recorded *models.AchievementStatusHistory
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		recorded = entry
+		return nil
+	}
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara"}, nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/reject", asUser("u3", lecturerPerms), svc.Reject)
+
+	req := httptest.NewRequest("POST", "/achievements/m1/reject", strings.NewReader(`{"note":"lampiran kurang"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, "rejected", recorded.ToStatus)
+	assert.Equal(t, "lampiran kurang", *recorded.Note)
+}
+
+func TestAchievement_Verify_InvalidTransition(t *testing.T) {
+	students, lecturers, refs := policyRepos("draft")
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		t.Fatal("Transition tidak boleh dipanggil")
+		return nil
+	}
+
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/verify", asUser("u3", lecturerPerms), svc.Verify)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/verify", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAchievement_History_FromTable(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	rejected, submitted := "rejected", "submitted"
+	note := "kurang bukti"
+	history := &repo.AchievementHistoryMockRepo{
+		FindByReferenceIDFn: func(ctx context.Context, refID string) ([]models.AchievementStatusHistory, error) {
+			assert.Equal(t, "ref-1", refID)
+			draft, revision := "draft", "revision"
+			return []models.AchievementStatusHistory{
+				{FromStatus: &draft, ToStatus: submitted},
+				{FromStatus: &submitted, ToStatus: rejected, Note: &note},
+				{FromStatus: &rejected, ToStatus: revision},
+				{FromStatus: &revision, ToStatus: submitted},
+			}, nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, &repo.AchievementMongoMockRepo{}, refs, lecturers, &repo.UserMockRepo{}, history, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/achievements/:id/history", asUser("u1", studentPerms), svc.History)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements/m1/history", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			Status string  `json:"status"`
+			Note   *string `json:"note"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	require.Len(t, body.Data, 5)
+	assert.Equal(t, "draft", body.Data[0].Status)
+	assert.Equal(t, "rejected", body.Data[2].Status)
+	assert.Equal(t, note, *body.Data[2].Note)
+	assert.Equal(t, "submitted", body.Data[4].Status)
+}
+
+func TestAchievement_Verify_StaleStatusConflict(t *testing.T) {
+	students, lecturers, refs := policyRepos("submitted")
+
+	// request lain sudah menolak prestasi ini lebih dulu
+	refs.TransitionFn = func(ctx context.Context, ref *models.AchievementReference, entry *models.AchievementStatusHistory) error {
+		return repository.ErrStatusConflict
+	}
+
+	mongo := &repo.AchievementMongoMockRepo{
+		FindByIDFn: func(ctx context.Context, id string) (*models.AchievementMongo, error) {
+			return &models.AchievementMongo{Title: "Juara"}, nil
+		},
+		UpdateFn: func(ctx context.Context, ach *models.AchievementMongo) error {
+			t.Fatal("poin tidak boleh disimpan jika transisi gagal")
+			return nil
+		},
+	}
+
+	svc := services.NewAchievementService(students, mongo, refs, lecturers, &repo.UserMockRepo{}, &repo.AchievementHistoryMockRepo{}, &repo.ReviewSnapshotMockRepo{}, &repo.PointRuleMockRepo{}, &repo.AchievementTypeMockRepo{}, &repo.OutboxMockRepo{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Post("/achievements/:id/verify", asUser("u3", lecturerPerms), svc.Verify)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/m1/verify", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
@@ -1,3 +0,0 @@
-package utils
-
-var TokenBlacklist = make(map[string]bool)
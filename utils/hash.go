@@ -1,6 +1,22 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UnusablePasswordHash disimpan untuk akun yang belum memiliki password.
+// Nilainya bukan hash bcrypt sehingga tidak ada password yang cocok;
+// pemilik akun mengaturnya lewat fitur lupa password.
+const UnusablePasswordHash = "!"
+
+// dummyPasswordHash adalah hash bcrypt (DefaultCost) yang dibandingkan
+// saat akun tidak ditemukan atau belum memiliki password, sehingga waktu
+// respon login tidak membedakan akun yang ada dan yang tidak.
+const dummyPasswordHash = "$2a$10$5cJ68se1dyhL63z3EnKDzedgfxCoILlQzQjbhImZWf9hxY7bCRKWy"
 
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password),
@@ -9,6 +25,25 @@ func HashPassword(password string) (string, error) {
 }
 
 func CheckPassword(password, hash string) bool {
+	if hash == "" || hash == UnusablePasswordHash {
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return false
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
+
+// GenerateRandomToken menghasilkan token acak n byte dalam bentuk hex.
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan sha256 hex dari token, untuk disimpan di database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"strconv"
 	"time"
@@ -8,6 +9,7 @@ import (
 
 	"uas/app/models"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func GetJWTSecret() []byte {
@@ -18,14 +20,16 @@ func GetJWTSecret() []byte {
 	return []byte(secret)
 }
 
-func GenerateToken(userID, roleID string, permissions []string) (string, error) {
+func GenerateToken(userID, roleID, sessionID string, permissions []string) (string, error) {
 	expHour, _ := strconv.Atoi(os.Getenv("JWT_EXPIRED"))
 
 	claims := models.JWTClaims{
 		UserID:      userID,
 		RoleID:      roleID,
+		SessionID:   sessionID,
 		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expHour) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -48,15 +52,73 @@ func ValidateToken(tokenString string) (*models.JWTClaims, error) {
 	return claims, nil
 }
 
-func GenerateRefreshToken(userID string) (string, error) {
+func GenerateRefreshToken(userID, sessionID string) (string, *models.RefreshClaims, error) {
 	expHour, _ := strconv.Atoi(os.Getenv("JWT_REFRESH_EXPIRED"))
 
-	claims := jwt.RegisteredClaims{
-		Subject:  userID,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expHour) * time.Hour)),
-		IssuedAt: jwt.NewNumericDate(time.Now()),
+	claims := &models.RefreshClaims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expHour) * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("JWT_REFRESH_SECRET")))
+	signed, err := token.SignedString([]byte(os.Getenv("JWT_REFRESH_SECRET")))
+	return signed, claims, err
+}
+
+func ValidateRefreshToken(tokenString string) (*models.RefreshClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.RefreshClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_REFRESH_SECRET")), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("refresh token tidak valid")
+	}
+
+	return token.Claims.(*models.RefreshClaims), nil
+}
+
+const mfaAudience = "mfa"
+
+// GenerateMFAChallengeToken menerbitkan token sementara untuk langkah kedua
+// login. Token ini tidak memiliki sid sehingga ditolak AuthRequired.
+func GenerateMFAChallengeToken(userID, purpose string, ttl time.Duration) (string, *models.MFAChallengeClaims, error) {
+	claims := &models.MFAChallengeClaims{
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{mfaAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(GetJWTSecret())
+	return signed, claims, err
+}
+
+func ValidateMFAChallengeToken(tokenString, purpose string) (*models.MFAChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.MFAChallengeClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return GetJWTSecret(), nil
+	}, jwt.WithAudience(mfaAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims.(*models.MFAChallengeClaims)
+	if !token.Valid || claims.Purpose != purpose || claims.Subject == "" || claims.ID == "" {
+		return nil, errors.New("challenge token tidak valid")
+	}
+
+	return claims, nil
 }
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah abstraksi pengiriman email keluar.
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// NewMailerFromEnv memilih implementasi berdasarkan MAIL_DRIVER
+// ("smtp" atau "log", default "log").
+func NewMailerFromEnv() Mailer {
+	if strings.ToLower(os.Getenv("MAIL_DRIVER")) == "smtp" {
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+	}
+
+	path := os.Getenv("MAIL_LOG_PATH")
+	if path == "" {
+		path = "logs/mail.log"
+	}
+	return &LogMailer{Path: path}
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg MailMessage) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(
+		m.Host+":"+m.Port,
+		auth,
+		m.From,
+		[]string{msg.To},
+		buildMailBody(m.From, msg),
+	)
+}
+
+// LogMailer menulis email ke file, untuk development lokal.
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.Path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "=== %s ===\n%s\n\n", time.Now().Format(time.RFC3339), buildMailBody("", msg))
+	return err
+}
+
+func buildMailBody(from string, msg MailMessage) []byte {
+	var b strings.Builder
+
+	if from != "" {
+		b.WriteString("From: " + from + "\r\n")
+	}
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	return []byte(b.String())
+}
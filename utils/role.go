@@ -1,7 +1,15 @@
 package utils
 
-const (
-    ROLE_ADMIN      = "decfc792-de7a-432e-bb8f-94e50761ddde"
-    ROLE_MAHASISWA  = "2c35d326-a744-4bdd-8a53-f78bbd2c58d3"
-    ROLE_DOSEN      = "06d89f4a-bdc8-423f-a513-8b5361c47ecd"
-)
+// PermissionAll memberikan akses ke seluruh permission (dipakai role Admin).
+const PermissionAll = "*"
+
+// HasPermission memeriksa apakah daftar permission memuat permission
+// yang diminta, atau wildcard "*".
+func HasPermission(perms []string, permission string) bool {
+	for _, p := range perms {
+		if p == permission || p == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
@@ -5,5 +5,6 @@ const (
     AchievementStatusSubmitted = "submitted"
     AchievementStatusVerified  = "verified"
     AchievementStatusRejected  = "rejected"
+	AchievementStatusRevision  = "revision"
 	AchievementStatusDeleted = "deleted"
 )
\ No newline at end of file
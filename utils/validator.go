@@ -1,9 +1,46 @@
 package utils
 
-import "github.com/google/uuid"
+import (
+	"net/mail"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+var (
+	nimPattern          = regexp.MustCompile(`^[0-9]{5,20}$`)
+	academicYearPattern = regexp.MustCompile(`^(19|20)[0-9]{2}$`)
+	nidnPattern         = regexp.MustCompile(`^[0-9]{10}$`)
+	phonePattern        = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+)
 
 func IsUUID(s string) bool {
     _, err := uuid.Parse(s)
     return err == nil
 }
 
+// IsEmail menerima alamat email tunggal tanpa nama tampilan.
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// IsNIM memeriksa format Nomor Induk Mahasiswa: 5-20 digit angka.
+func IsNIM(s string) bool {
+	return nimPattern.MatchString(s)
+}
+
+// IsAcademicYear memeriksa format angkatan, mis. "2024".
+func IsAcademicYear(s string) bool {
+	return academicYearPattern.MatchString(s)
+}
+
+// IsNIDN memeriksa format Nomor Induk Dosen Nasional: tepat 10 digit angka.
+func IsNIDN(s string) bool {
+	return nidnPattern.MatchString(s)
+}
+
+// IsPhone memeriksa nomor telepon 8-15 digit dengan awalan "+" opsional.
+func IsPhone(s string) bool {
+	return phonePattern.MatchString(s)
+}